@@ -1,12 +1,9 @@
 package auroraclient
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -15,8 +12,6 @@ import (
 
 	"github.com/hcnet/go/txnbuild"
 
-	"github.com/manucorporat/sse"
-
 	hProtocol "github.com/hcnet/go/protocols/aurora"
 	"github.com/hcnet/go/protocols/aurora/effects"
 	"github.com/hcnet/go/protocols/aurora/operations"
@@ -119,143 +114,28 @@ func (c *Client) sendRequestURL(requestURL string, method string, a interface{})
 	if c.auroraTimeout == 0 {
 		c.auroraTimeout = AuroraTimeout
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*c.auroraTimeout)
-	resp, err := c.HTTP.Do(req.WithContext(ctx))
-	if err != nil {
-		cancel()
+
+	if c.RetryPolicy == nil {
+		_, err = c.sendHTTPRequest(req, a)
 		return
 	}
-
-	err = decodeResponse(resp, &a, c)
-	cancel()
-	return
+	return c.RetryPolicy.do(req.Method, requestURL, func() (*http.Response, error) {
+		return c.sendHTTPRequest(req, a)
+	})
 }
 
-// stream handles connections to endpoints that support streaming on a aurora server
-func (c *Client) stream(
-	ctx context.Context,
-	streamURL string,
-	handler func(data []byte) error,
-) error {
-	su, err := url.Parse(streamURL)
-	if err != nil {
-		return errors.Wrap(err, "error parsing stream url")
-	}
-
-	query := su.Query()
-	if query.Get("cursor") == "" {
-		query.Set("cursor", "now")
-	}
-
-	for {
-		// updates the url with new cursor
-		su.RawQuery = query.Encode()
-		req, err := http.NewRequest("GET", su.String(), nil)
-		if err != nil {
-			return errors.Wrap(err, "error creating HTTP request")
-		}
-		req.Header.Set("Accept", "text/event-stream")
-		c.setDefaultClient()
-		c.setClientAppHeaders(req)
-
-		// We can use c.HTTP here because we set Timeout per request not on the client. See sendRequest()
-		resp, err := c.HTTP.Do(req)
-		if err != nil {
-			return errors.Wrap(err, "error sending HTTP request")
-		}
-
-		// Expected statusCode are 200-299
-		if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-			return fmt.Errorf("got bad HTTP status code %d", resp.StatusCode)
-		}
-		defer resp.Body.Close()
-
-		reader := bufio.NewReader(resp.Body)
-
-		// Read events one by one. Break this loop when there is no more data to be
-		// read from resp.Body (io.EOF).
-	Events:
-		for {
-			// Read until empty line = event delimiter. The perfect solution would be to read
-			// as many bytes as possible and forward them to sse.Decode. However this
-			// requires much more complicated code.
-			// We could also write our own `sse` package that works fine with streams directly
-			// (github.com/manucorporat/sse is just using io/ioutils.ReadAll).
-			var buffer bytes.Buffer
-			nonEmptylinesRead := 0
-			for {
-				// Check if ctx is not cancelled
-				select {
-				case <-ctx.Done():
-					return nil
-				default:
-					// Continue
-				}
-
-				line, err := reader.ReadString('\n')
-				if err != nil {
-					if err == io.EOF || err == io.ErrUnexpectedEOF {
-						// We catch EOF errors to handle two possible situations:
-						// - The last line before closing the stream was not empty. This should never
-						//   happen in Aurora as it always sends an empty line after each event.
-						// - The stream was closed by the server/proxy because the connection was idle.
-						//
-						// In the former case, that (again) should never happen in Aurora, we need to
-						// check if there are any events we need to decode. We do this in the `if`
-						// statement below just in case if Aurora behaviour changes in a future.
-						//
-						// From spec:
-						// > Once the end of the file is reached, the user agent must dispatch the
-						// > event one final time, as defined below.
-						if nonEmptylinesRead == 0 {
-							break Events
-						}
-					} else {
-						return errors.Wrap(err, "error reading line")
-					}
-				}
-				buffer.WriteString(line)
-
-				if strings.TrimRight(line, "\n\r") == "" {
-					break
-				}
-
-				nonEmptylinesRead++
-			}
-
-			events, err := sse.Decode(strings.NewReader(buffer.String()))
-			if err != nil {
-				return errors.Wrap(err, "error decoding event")
-			}
+// sendHTTPRequest makes a single attempt of req and decodes the response
+// into a. The response is returned, with its body closed, when one was
+// received.
+func (c *Client) sendHTTPRequest(req *http.Request, a interface{}) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*c.auroraTimeout)
+	defer cancel()
 
-			// Right now len(events) should always be 1. This loop will be helpful after writing
-			// new SSE decoder that can handle io.Reader without using ioutils.ReadAll().
-			for _, event := range events {
-				if event.Event != "message" {
-					continue
-				}
-
-				// Update cursor with event ID
-				if event.Id != "" {
-					query.Set("cursor", event.Id)
-				}
-
-				switch data := event.Data.(type) {
-				case string:
-					err = handler([]byte(data))
-					err = errors.Wrap(err, "handler error")
-				case []byte:
-					err = handler(data)
-					err = errors.Wrap(err, "handler error")
-				default:
-					err = errors.New("invalid event.Data type")
-				}
-				if err != nil {
-					return err
-				}
-			}
-		}
+	resp, err := c.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
 	}
+	return resp, decodeResponse(resp, &a, c)
 }
 
 func (c *Client) setClientAppHeaders(req *http.Request) {
@@ -687,6 +567,12 @@ func (c *Client) NextAccountsPage(page hProtocol.AccountsPage) (accounts hProtoc
 	return
 }
 
+// PrevAccountsPage returns the previous page of accounts.
+func (c *Client) PrevAccountsPage(page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendRequestURL(page.Links.Prev.Href, "get", &accounts)
+	return
+}
+
 // NextAssetsPage returns the next page of assets.
 func (c *Client) NextAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
 	err = c.sendRequestURL(page.Links.Next.Href, "get", &assets)
@@ -823,5 +709,17 @@ func (c *Client) ClaimableBalance(id string) (cb hProtocol.ClaimableBalance, err
 	return
 }
 
+// NextClaimableBalancesPage returns the next page of claimable balances.
+func (c *Client) NextClaimableBalancesPage(page hProtocol.ClaimableBalances) (cb hProtocol.ClaimableBalances, err error) {
+	err = c.sendRequestURL(page.Links.Next.Href, "get", &cb)
+	return
+}
+
+// PrevClaimableBalancesPage returns the previous page of claimable balances.
+func (c *Client) PrevClaimableBalancesPage(page hProtocol.ClaimableBalances) (cb hProtocol.ClaimableBalances, err error) {
+	err = c.sendRequestURL(page.Links.Prev.Href, "get", &cb)
+	return
+}
+
 // ensure that the aurora client implements ClientInterface
 var _ ClientInterface = &Client{}
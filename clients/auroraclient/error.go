@@ -2,6 +2,7 @@ package auroraclient
 
 import (
 	"encoding/json"
+	"time"
 
 	hProtocol "github.com/hcnet/go/protocols/aurora"
 	"github.com/hcnet/go/support/errors"
@@ -78,3 +79,21 @@ func (herr *Error) ResultCodes() (*hProtocol.TransactionResultCodes, error) {
 
 	return &result, nil
 }
+
+// RetryAfter returns the delay Aurora asked to wait for before sending
+// another request, using the Retry-After header of the response.
+func (herr *Error) RetryAfter() (time.Duration, bool) {
+	if herr.Response == nil {
+		return 0, false
+	}
+	return parseRetryAfter(herr.Response.Header, time.Now())
+}
+
+// RateLimit returns the rate limit of the client as reported by the
+// X-RateLimit-* headers of the response.
+func (herr *Error) RateLimit() RateLimit {
+	if herr.Response == nil {
+		return RateLimit{}
+	}
+	return parseRateLimit(herr.Response.Header)
+}
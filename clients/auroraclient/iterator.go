@@ -0,0 +1,407 @@
+package auroraclient
+
+import (
+	"context"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/protocols/aurora/operations"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/render/hal"
+)
+
+// IteratorOptions configures the iterators returned by the Iterate* methods.
+type IteratorOptions struct {
+	// MaxItems is the maximum number of records returned by the iterator.
+	// Zero means no limit.
+	MaxItems int
+	// Prefetch is the number of pages fetched in the background before
+	// their records are needed. Zero fetches every page when its first
+	// record is needed.
+	Prefetch int
+}
+
+// pageFetcher returns the records of the page at url and the link to the
+// next page.
+type pageFetcher func(url string) ([]interface{}, hal.Link, error)
+
+type iteratorPage struct {
+	records []interface{}
+	err     error
+}
+
+// iterator implements the logic shared by all collection iterators. It
+// follows the `next` links of the pages returned by Aurora until a page is
+// empty.
+type iterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   IteratorOptions
+	fetch  pageFetcher
+
+	// nextURL, fetched and done are only used by the goroutine fetching
+	// pages.
+	nextURL string
+	fetched int
+	done    bool
+	pages   chan iteratorPage
+
+	records  []interface{}
+	value    interface{}
+	returned int
+	err      error
+	// finished is set once all records have been returned so Next keeps
+	// returning false without waiting for the closed iterator.
+	finished bool
+}
+
+func (c *Client) newIterator(
+	ctx context.Context,
+	request AuroraRequest,
+	opts IteratorOptions,
+	fetch pageFetcher,
+) *iterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &iterator{
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+		fetch:  fetch,
+	}
+
+	endpoint, err := request.BuildURL()
+	if err != nil {
+		it.err = errors.Wrap(err, "error building request url")
+		return it
+	}
+	it.nextURL = c.fixAuroraURL() + endpoint
+
+	if opts.Prefetch > 0 {
+		it.pages = make(chan iteratorPage, opts.Prefetch)
+		go it.prefetch()
+	}
+	return it
+}
+
+// Next advances the iterator to the next record, which is then available
+// through Value. It returns false when there are no more records or an
+// error occurred, in which case it's returned by Err.
+func (it *iterator) Next() bool {
+	if it.err != nil || it.finished {
+		return false
+	}
+	if it.opts.MaxItems > 0 && it.returned >= it.opts.MaxItems {
+		it.finished = true
+		it.Close()
+		return false
+	}
+
+	for len(it.records) == 0 {
+		page, ok := it.nextPage()
+		if !ok {
+			it.finished = true
+			it.Close()
+			return false
+		}
+		if page.err != nil {
+			it.err = page.err
+			it.Close()
+			return false
+		}
+		it.records = page.records
+	}
+
+	it.value = it.records[0]
+	it.records = it.records[1:]
+	it.returned++
+	return true
+}
+
+// Err returns the error which stopped the iterator, if any.
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Close stops fetching pages in the background. It should be called when
+// the iterator is not consumed until Next returns false.
+func (it *iterator) Close() {
+	it.cancel()
+}
+
+func (it *iterator) nextPage() (iteratorPage, bool) {
+	if it.pages == nil {
+		if it.done {
+			return iteratorPage{}, false
+		}
+		return it.fetchPage(), true
+	}
+
+	select {
+	case page, ok := <-it.pages:
+		return page, ok
+	case <-it.ctx.Done():
+		return iteratorPage{err: it.ctx.Err()}, true
+	}
+}
+
+func (it *iterator) prefetch() {
+	defer close(it.pages)
+	for !it.done {
+		page := it.fetchPage()
+		select {
+		case it.pages <- page:
+		case <-it.ctx.Done():
+			return
+		}
+		if page.err != nil {
+			return
+		}
+	}
+}
+
+func (it *iterator) fetchPage() iteratorPage {
+	if err := it.ctx.Err(); err != nil {
+		return iteratorPage{err: err}
+	}
+
+	records, next, err := it.fetch(it.nextURL)
+	if err != nil {
+		return iteratorPage{err: err}
+	}
+
+	it.fetched += len(records)
+	if len(records) == 0 || next.Href == "" || next.Href == it.nextURL ||
+		(it.opts.MaxItems > 0 && it.fetched >= it.opts.MaxItems) {
+		it.done = true
+	}
+	it.nextURL = next.Href
+	return iteratorPage{records: records}
+}
+
+// AccountIterator iterates over accounts.
+type AccountIterator struct{ *iterator }
+
+// Value returns the current account.
+func (it *AccountIterator) Value() hProtocol.Account {
+	value, _ := it.value.(hProtocol.Account)
+	return value
+}
+
+// IterateAccounts returns an iterator over all accounts matching request.
+func (c *Client) IterateAccounts(ctx context.Context, request AccountsRequest, opts IteratorOptions) *AccountIterator {
+	return &AccountIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.AccountsPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// AssetIterator iterates over asset stats.
+type AssetIterator struct{ *iterator }
+
+// Value returns the current asset.
+func (it *AssetIterator) Value() hProtocol.AssetStat {
+	value, _ := it.value.(hProtocol.AssetStat)
+	return value
+}
+
+// IterateAssets returns an iterator over all assets matching request.
+func (c *Client) IterateAssets(ctx context.Context, request AssetRequest, opts IteratorOptions) *AssetIterator {
+	return &AssetIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.AssetsPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// ClaimableBalanceIterator iterates over claimable balances.
+type ClaimableBalanceIterator struct{ *iterator }
+
+// Value returns the current claimable balance.
+func (it *ClaimableBalanceIterator) Value() hProtocol.ClaimableBalance {
+	value, _ := it.value.(hProtocol.ClaimableBalance)
+	return value
+}
+
+// IterateClaimableBalances returns an iterator over all claimable balances
+// matching request.
+func (c *Client) IterateClaimableBalances(ctx context.Context, request ClaimableBalanceRequest, opts IteratorOptions) *ClaimableBalanceIterator {
+	return &ClaimableBalanceIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.ClaimableBalances
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// EffectIterator iterates over effects.
+type EffectIterator struct{ *iterator }
+
+// Value returns the current effect.
+func (it *EffectIterator) Value() effects.Effect {
+	value, _ := it.value.(effects.Effect)
+	return value
+}
+
+// IterateEffects returns an iterator over all effects matching request.
+func (c *Client) IterateEffects(ctx context.Context, request EffectRequest, opts IteratorOptions) *EffectIterator {
+	return &EffectIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page effects.EffectsPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// LedgerIterator iterates over ledgers.
+type LedgerIterator struct{ *iterator }
+
+// Value returns the current ledger.
+func (it *LedgerIterator) Value() hProtocol.Ledger {
+	value, _ := it.value.(hProtocol.Ledger)
+	return value
+}
+
+// IterateLedgers returns an iterator over all ledgers matching request.
+func (c *Client) IterateLedgers(ctx context.Context, request LedgerRequest, opts IteratorOptions) *LedgerIterator {
+	return &LedgerIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.LedgersPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// OfferIterator iterates over offers.
+type OfferIterator struct{ *iterator }
+
+// Value returns the current offer.
+func (it *OfferIterator) Value() hProtocol.Offer {
+	value, _ := it.value.(hProtocol.Offer)
+	return value
+}
+
+// IterateOffers returns an iterator over all offers matching request.
+func (c *Client) IterateOffers(ctx context.Context, request OfferRequest, opts IteratorOptions) *OfferIterator {
+	return &OfferIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.OffersPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// OperationIterator iterates over operations or payments.
+type OperationIterator struct{ *iterator }
+
+// Value returns the current operation.
+func (it *OperationIterator) Value() operations.Operation {
+	value, _ := it.value.(operations.Operation)
+	return value
+}
+
+func (c *Client) iterateOperations(ctx context.Context, request *OperationRequest, opts IteratorOptions) *OperationIterator {
+	return &OperationIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page operations.OperationsPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// IterateOperations returns an iterator over all operations matching
+// request.
+func (c *Client) IterateOperations(ctx context.Context, request OperationRequest, opts IteratorOptions) *OperationIterator {
+	return c.iterateOperations(ctx, request.SetOperationsEndpoint(), opts)
+}
+
+// IteratePayments returns an iterator over all payments matching request.
+func (c *Client) IteratePayments(ctx context.Context, request OperationRequest, opts IteratorOptions) *OperationIterator {
+	return c.iterateOperations(ctx, request.SetPaymentsEndpoint(), opts)
+}
+
+// TradeIterator iterates over trades.
+type TradeIterator struct{ *iterator }
+
+// Value returns the current trade.
+func (it *TradeIterator) Value() hProtocol.Trade {
+	value, _ := it.value.(hProtocol.Trade)
+	return value
+}
+
+// IterateTrades returns an iterator over all trades matching request.
+func (c *Client) IterateTrades(ctx context.Context, request TradeRequest, opts IteratorOptions) *TradeIterator {
+	return &TradeIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.TradesPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
+
+// TransactionIterator iterates over transactions.
+type TransactionIterator struct{ *iterator }
+
+// Value returns the current transaction.
+func (it *TransactionIterator) Value() hProtocol.Transaction {
+	value, _ := it.value.(hProtocol.Transaction)
+	return value
+}
+
+// IterateTransactions returns an iterator over all transactions matching
+// request.
+func (c *Client) IterateTransactions(ctx context.Context, request TransactionRequest, opts IteratorOptions) *TransactionIterator {
+	return &TransactionIterator{c.newIterator(ctx, request, opts, func(url string) ([]interface{}, hal.Link, error) {
+		var page hProtocol.TransactionsPage
+		if err := c.sendRequestURL(url, "get", &page); err != nil {
+			return nil, hal.Link{}, err
+		}
+		records := make([]interface{}, len(page.Embedded.Records))
+		for i, record := range page.Embedded.Records {
+			records[i] = record
+		}
+		return records, page.Links.Next, nil
+	})}
+}
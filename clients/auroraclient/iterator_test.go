@@ -0,0 +1,124 @@
+package auroraclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hcnet/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledgersPage returns a page of ledgers with a link to the page starting
+// after the last ledger.
+func ledgersPage(sequences ...int) string {
+	records := make([]string, len(sequences))
+	next := "https://localhost/ledgers?cursor=end"
+	for i, sequence := range sequences {
+		records[i] = fmt.Sprintf(`{"sequence": %d, "paging_token": "%d"}`, sequence, sequence)
+		next = fmt.Sprintf("https://localhost/ledgers?cursor=%d", sequence)
+	}
+	return fmt.Sprintf(
+		`{"_links": {"next": {"href": "%s"}}, "_embedded": {"records": [%s]}}`,
+		next,
+		strings.Join(records, ","),
+	)
+}
+
+func mockLedgerPages(hmock *httptest.Client) {
+	hmock.On("GET", "https://localhost/ledgers?limit=2").
+		ReturnString(200, ledgersPage(1, 2))
+	hmock.On("GET", "https://localhost/ledgers?cursor=2").
+		ReturnString(200, ledgersPage(3, 4))
+	hmock.On("GET", "https://localhost/ledgers?cursor=4").
+		ReturnString(200, ledgersPage(5))
+	hmock.On("GET", "https://localhost/ledgers?cursor=5").
+		ReturnString(200, ledgersPage())
+}
+
+func TestIterateLedgers(t *testing.T) {
+	for _, prefetch := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("prefetch %d", prefetch), func(t *testing.T) {
+			hmock := httptest.NewClient()
+			client := &Client{AuroraURL: "https://localhost/", HTTP: hmock}
+			mockLedgerPages(hmock)
+
+			it := client.IterateLedgers(context.Background(), LedgerRequest{Limit: 2}, IteratorOptions{Prefetch: prefetch})
+			defer it.Close()
+
+			var sequences []int32
+			for it.Next() {
+				sequences = append(sequences, it.Value().Sequence)
+			}
+			require.NoError(t, it.Err())
+			assert.Equal(t, []int32{1, 2, 3, 4, 5}, sequences)
+			// Next keeps returning false without error once exhausted.
+			for i := 0; i < 20; i++ {
+				assert.False(t, it.Next())
+				assert.NoError(t, it.Err())
+			}
+		})
+	}
+}
+
+func TestIterateMaxItems(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{AuroraURL: "https://localhost/", HTTP: hmock}
+	mockLedgerPages(hmock)
+
+	it := client.IterateLedgers(context.Background(), LedgerRequest{Limit: 2}, IteratorOptions{MaxItems: 3})
+	var sequences []int32
+	for it.Next() {
+		sequences = append(sequences, it.Value().Sequence)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []int32{1, 2, 3}, sequences)
+}
+
+func TestIterateError(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{AuroraURL: "https://localhost/", HTTP: hmock}
+	hmock.On("GET", "https://localhost/ledgers?limit=2").
+		ReturnString(200, ledgersPage(1, 2))
+	hmock.On("GET", "https://localhost/ledgers?cursor=2").
+		ReturnString(500, `{"status": 500, "title": "Internal Server Error"}`)
+
+	it := client.IterateLedgers(context.Background(), LedgerRequest{Limit: 2}, IteratorOptions{Prefetch: 1})
+	var count int
+	for it.Next() {
+		count++
+	}
+	assert.Equal(t, 2, count)
+	hErr := GetError(it.Err())
+	require.NotNil(t, hErr)
+	assert.Equal(t, 500, hErr.Problem.Status)
+
+	// Invalid requests fail before sending any request.
+	opIt := client.IterateOperations(context.Background(), OperationRequest{ForAccount: "a", ForLedger: 1}, IteratorOptions{})
+	assert.False(t, opIt.Next())
+	assert.Error(t, opIt.Err())
+}
+
+func TestIterateClaimableBalances(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{AuroraURL: "https://localhost/", HTTP: hmock}
+	hmock.On("GET", "https://localhost/claimable_balances?limit=1&sponsor=GA").
+		ReturnString(200, `{
+  "_links": {"next": {"href": "https://localhost/claimable_balances?cursor=a&limit=1&sponsor=GA"}},
+  "_embedded": {"records": [{"id": "a", "amount": "10.0000000"}]}
+}`)
+	hmock.On("GET", "https://localhost/claimable_balances?cursor=a&limit=1&sponsor=GA").
+		ReturnString(200, `{
+  "_links": {"next": {"href": "https://localhost/claimable_balances?cursor=a&limit=1&sponsor=GA"}},
+  "_embedded": {"records": []}
+}`)
+
+	it := client.IterateClaimableBalances(context.Background(), ClaimableBalanceRequest{Sponsor: "GA", Limit: 1}, IteratorOptions{})
+	require.True(t, it.Next())
+	assert.Equal(t, "a", it.Value().BalanceID)
+	assert.Equal(t, "10.0000000", it.Value().Amount)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
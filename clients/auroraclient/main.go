@@ -142,6 +142,14 @@ type Client struct {
 	auroraTimeout time.Duration
 	isTestNet      bool
 
+	// RetryPolicy configures retries of failed requests. Requests are sent
+	// only once when nil.
+	RetryPolicy *RetryPolicy
+
+	// StreamOptions configures reconnections, cursor persistence and
+	// buffering of streams. Streams stop on the first error when nil.
+	StreamOptions *StreamOptions
+
 	// clock is a Clock returning the current time.
 	clock *clock.Clock
 }
@@ -188,6 +196,7 @@ type ClientInterface interface {
 	StreamOrderBooks(ctx context.Context, request OrderBookRequest, handler OrderBookHandler) error
 	Root() (hProtocol.Root, error)
 	NextAccountsPage(hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
+	PrevAccountsPage(hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
 	NextAssetsPage(hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
 	PrevAssetsPage(hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
 	NextLedgersPage(hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
@@ -207,6 +216,8 @@ type ClientInterface interface {
 	HomeDomainForAccount(aid string) (string, error)
 	NextTradeAggregationsPage(hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
 	PrevTradeAggregationsPage(hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	NextClaimableBalancesPage(hProtocol.ClaimableBalances) (hProtocol.ClaimableBalances, error)
+	PrevClaimableBalancesPage(hProtocol.ClaimableBalances) (hProtocol.ClaimableBalances, error)
 }
 
 // DefaultTestNetClient is a default client to connect to test network.
@@ -417,6 +428,9 @@ type ClaimableBalanceRequest struct {
 	Asset    string
 	Sponsor  string
 	Claimant string
+	Order    Order
+	Cursor   string
+	Limit    uint
 }
 
 // ServerTimeRecord contains data for the current unix time of a aurora server instance, and the local time when it was recorded.
@@ -217,6 +217,12 @@ func (m *MockClient) NextAccountsPage(page hProtocol.AccountsPage) (hProtocol.Ac
 	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
 }
 
+// PrevAccountsPage is a mocking method
+func (m *MockClient) PrevAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
 // NextAssetsPage is a mocking method
 func (m *MockClient) NextAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
 	a := m.Called(page)
@@ -329,5 +335,17 @@ func (m *MockClient) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsP
 	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
 }
 
+// NextClaimableBalancesPage is a mocking method
+func (m *MockClient) NextClaimableBalancesPage(page hProtocol.ClaimableBalances) (hProtocol.ClaimableBalances, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ClaimableBalances), a.Error(1)
+}
+
+// PrevClaimableBalancesPage is a mocking method
+func (m *MockClient) PrevClaimableBalancesPage(page hProtocol.ClaimableBalances) (hProtocol.ClaimableBalances, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.ClaimableBalances), a.Error(1)
+}
+
 // ensure that the MockClient implements ClientInterface
 var _ ClientInterface = &MockClient{}
@@ -0,0 +1,321 @@
+package auroraclient
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// ErrCircuitOpen is returned when a request is not sent because the circuit
+// breaker of the client is open.
+var ErrCircuitOpen = errors.New("aurora circuit breaker is open")
+
+// RetryPolicy configures retries of failed requests. GET requests are
+// retried after network errors and responses with one of RetryStatusCodes.
+// Transaction submissions are only retried when Aurora (or the client)
+// times out waiting for the transaction. The same transaction, with the
+// same hash, is submitted again so it can't be applied twice.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a single request.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry. It's doubled after
+	// every retry and a random jitter is applied. Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts. Requests are not
+	// retried when Aurora asks (with the Retry-After header) to wait longer
+	// than MaxBackoff. Defaults to 30s.
+	MaxBackoff time.Duration
+	// RetryStatusCodes are the HTTP status codes of GET requests that are
+	// retried. Defaults to 429, 502, 503 and 504.
+	RetryStatusCodes []int
+	// CircuitBreaker makes requests fail fast with ErrCircuitOpen when Aurora
+	// is unhealthy. Optional.
+	CircuitBreaker *CircuitBreaker
+	// OnAttempt is called after every attempt, it can be used for metrics
+	// and logging. Optional.
+	OnAttempt func(RequestAttempt)
+}
+
+// RequestAttempt describes a single attempt of a request.
+type RequestAttempt struct {
+	Method string
+	URL    string
+	// Attempt is 0 for the first attempt, 1 for the first retry, and so on.
+	Attempt int
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+	// Retry is true when the request is going to be retried after Backoff.
+	Retry     bool
+	Backoff   time.Duration
+	RateLimit RateLimit
+}
+
+// RateLimit contains the values of the X-RateLimit-* headers of a response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	// Reset is the number of seconds until the limit is reset.
+	Reset int
+}
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// do calls send until it succeeds, the error can't be retried or there are
+// no retries left.
+func (p *RetryPolicy) do(method, url string, send func() (*http.Response, error)) error {
+	minBackoff := p.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = 500 * time.Millisecond
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		if p.CircuitBreaker != nil && !p.CircuitBreaker.allow() {
+			return ErrCircuitOpen
+		}
+
+		start := time.Now()
+		resp, err := send()
+		event := RequestAttempt{
+			Method:   method,
+			URL:      url,
+			Attempt:  attempt,
+			Err:      err,
+			Duration: time.Since(start),
+		}
+		if resp != nil {
+			event.StatusCode = resp.StatusCode
+			event.RateLimit = parseRateLimit(resp.Header)
+		}
+
+		if p.CircuitBreaker != nil {
+			p.CircuitBreaker.record(isServerFailure(event.StatusCode, err))
+		}
+
+		if err != nil && attempt < p.MaxRetries && p.retryable(method, event.StatusCode, err) {
+			event.Retry = true
+			event.Backoff = exponentialBackoff(minBackoff, maxBackoff, attempt)
+			if resp != nil {
+				if retryAfter, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+					event.Backoff = retryAfter
+					event.Retry = retryAfter <= maxBackoff
+				}
+			}
+		}
+
+		if p.OnAttempt != nil {
+			p.OnAttempt(event)
+		}
+		if !event.Retry {
+			return err
+		}
+		time.Sleep(event.Backoff)
+	}
+}
+
+func (p *RetryPolicy) retryable(method string, statusCode int, err error) bool {
+	if method == http.MethodPost {
+		return statusCode == http.StatusGatewayTimeout || (statusCode == 0 && isNetTimeout(err))
+	}
+
+	if statusCode == 0 {
+		// The request failed without a response.
+		return true
+	}
+	codes := p.RetryStatusCodes
+	if codes == nil {
+		codes = defaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func isNetTimeout(err error) bool {
+	netErr, ok := errors.Cause(err).(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// isServerFailure returns true when the result of a request means that
+// Aurora is unhealthy.
+func isServerFailure(statusCode int, err error) bool {
+	if statusCode == 0 {
+		return err != nil
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// exponentialBackoff returns the delay before retry number attempt+1 with
+// a random jitter of up to half of the delay.
+func exponentialBackoff(minBackoff, maxBackoff time.Duration, attempt int) time.Duration {
+	backoff := minBackoff
+	for i := 0; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := int64(backoff / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// parseRetryAfter parses the Retry-After header which contains either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func parseRateLimit(header http.Header) RateLimit {
+	var rateLimit RateLimit
+	rateLimit.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	rateLimit.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	rateLimit.Reset, _ = strconv.Atoi(header.Get("X-RateLimit-Reset"))
+	return rateLimit
+}
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed means requests are sent normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means requests fail with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen means a single trial request is sent to check whether
+	// Aurora recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker opens after FailureThreshold consecutive failures (network
+// errors, 429 and 5xx responses). While it's open requests fail immediately.
+// After OpenDuration a single trial request is sent: the circuit is closed
+// when it succeeds and opened again when it fails. It must be used as a
+// pointer.
+type CircuitBreaker struct {
+	// FailureThreshold defaults to 5.
+	FailureThreshold int
+	// OpenDuration defaults to 30s.
+	OpenDuration time.Duration
+	// OnStateChange is called when the state of the circuit changes. It's
+	// called with the circuit breaker locked so it must not call its
+	// methods. Optional.
+	OnStateChange func(from, to CircuitState)
+
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.state
+}
+
+func (cb *CircuitBreaker) allow() bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		openDuration := cb.OpenDuration
+		if openDuration <= 0 {
+			openDuration = 30 * time.Second
+		}
+		if time.Since(cb.openedAt) < openDuration {
+			return false
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.trial = true
+		return true
+	case CircuitHalfOpen:
+		if cb.trial {
+			return false
+		}
+		cb.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *CircuitBreaker) record(failure bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if !failure {
+		cb.failures = 0
+		cb.trial = false
+		cb.setState(CircuitClosed)
+		return
+	}
+
+	cb.failures++
+	threshold := cb.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cb.state == CircuitHalfOpen || cb.failures >= threshold {
+		cb.trial = false
+		cb.openedAt = time.Now()
+		cb.setState(CircuitOpen)
+	}
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	if cb.OnStateChange != nil {
+		cb.OnStateChange(from, state)
+	}
+}
@@ -0,0 +1,231 @@
+package auroraclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hcnet/go/support/http/httptest"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const retryLedgerResponse = `{"id": "abc", "sequence": 1}`
+
+const retryProblemResponse = `{
+  "type": "https://hcnet.org/aurora-errors/server_error",
+  "title": "Service Unavailable",
+  "status": 503
+}`
+
+// sequenceResponder returns the responses in order, repeating the last
+// one, and counts the requests it received.
+func sequenceResponder(calls *int, responses ...*http.Response) httpmock.Responder {
+	return func(*http.Request) (*http.Response, error) {
+		i := *calls
+		if i >= len(responses) {
+			i = len(responses) - 1
+		}
+		*calls++
+		return responses[i], nil
+	}
+}
+
+func newResponse(status int, body string, header http.Header) *http.Response {
+	resp := httpmock.NewStringResponse(status, body)
+	for key, values := range header {
+		resp.Header[key] = values
+	}
+	return resp
+}
+
+func TestRetryGetRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	var attempts []RequestAttempt
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries: 3,
+			MinBackoff: time.Millisecond,
+			OnAttempt: func(attempt RequestAttempt) {
+				attempts = append(attempts, attempt)
+			},
+		},
+	}
+
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers/1").Return(sequenceResponder(
+		&calls,
+		newResponse(503, retryProblemResponse, nil),
+		newResponse(503, retryProblemResponse, http.Header{"X-Ratelimit-Remaining": []string{"10"}}),
+		newResponse(200, retryLedgerResponse, nil),
+	))
+
+	ledger, err := client.LedgerDetail(1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), ledger.Sequence)
+	assert.Equal(t, 3, calls)
+
+	require.Len(t, attempts, 3)
+	assert.True(t, attempts[0].Retry)
+	assert.Equal(t, 503, attempts[0].StatusCode)
+	assert.Equal(t, 10, attempts[1].RateLimit.Remaining)
+	assert.Equal(t, 2, attempts[2].Attempt)
+	assert.False(t, attempts[2].Retry)
+	assert.NoError(t, attempts[2].Err)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries: 2,
+			MinBackoff: time.Millisecond,
+		},
+	}
+
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers/1").Return(sequenceResponder(
+		&calls,
+		newResponse(503, retryProblemResponse, nil),
+	))
+	_, err := client.LedgerDetail(1)
+	assert.Equal(t, 503, GetError(err).Problem.Status)
+	assert.Equal(t, 3, calls)
+
+	// Not found errors are not retried.
+	calls = 0
+	hmock.On("GET", "https://localhost/ledgers/2").Return(sequenceResponder(
+		&calls,
+		newResponse(404, `{"status": 404}`, nil),
+	))
+	_, err = client.LedgerDetail(2)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryAfter(t *testing.T) {
+	hmock := httptest.NewClient()
+	var backoffs []time.Duration
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries: 2,
+			MinBackoff: time.Hour,
+			MaxBackoff: time.Second,
+			OnAttempt: func(attempt RequestAttempt) {
+				backoffs = append(backoffs, attempt.Backoff)
+			},
+		},
+	}
+
+	// Retry-After overrides the exponential backoff.
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers/1").Return(sequenceResponder(
+		&calls,
+		newResponse(429, `{"status": 429}`, http.Header{"Retry-After": []string{"0"}}),
+		newResponse(200, retryLedgerResponse, nil),
+	))
+	_, err := client.LedgerDetail(1)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{0, 0}, backoffs)
+
+	// Requests are not retried when Aurora asks to wait longer than
+	// MaxBackoff.
+	calls = 0
+	hmock.On("GET", "https://localhost/ledgers/2").Return(sequenceResponder(
+		&calls,
+		newResponse(429, `{"status": 429}`, http.Header{
+			"Retry-After":       []string{"60"},
+			"X-Ratelimit-Limit": []string{"3600"},
+		}),
+	))
+	_, err = client.LedgerDetail(2)
+	assert.Equal(t, 1, calls)
+	hErr := GetError(err)
+	require.NotNil(t, hErr)
+	retryAfter, ok := hErr.RetryAfter()
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, retryAfter)
+	assert.Equal(t, 3600, hErr.RateLimit().Limit)
+}
+
+func TestRetrySubmission(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		RetryPolicy: &RetryPolicy{
+			MaxRetries: 2,
+			MinBackoff: time.Millisecond,
+		},
+	}
+
+	// Submissions are not retried after other errors than timeouts.
+	var calls int
+	hmock.On("POST", "https://localhost/transactions?tx=a").Return(sequenceResponder(
+		&calls,
+		newResponse(503, retryProblemResponse, nil),
+	))
+	_, err := client.SubmitTransactionXDR("a")
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	hmock.On("POST", "https://localhost/transactions?tx=b").Return(sequenceResponder(
+		&calls,
+		newResponse(504, `{"status": 504}`, nil),
+		newResponse(200, `{"hash": "b"}`, nil),
+	))
+	tx, err := client.SubmitTransactionXDR("b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", tx.Hash)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	hmock := httptest.NewClient()
+	var transitions []CircuitState
+	breaker := &CircuitBreaker{
+		FailureThreshold: 2,
+		OpenDuration:     10 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, to)
+		},
+	}
+	client := &Client{
+		AuroraURL:   "https://localhost/",
+		HTTP:        hmock,
+		RetryPolicy: &RetryPolicy{CircuitBreaker: breaker},
+	}
+
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers/1").Return(sequenceResponder(
+		&calls,
+		newResponse(503, retryProblemResponse, nil),
+		newResponse(503, retryProblemResponse, nil),
+		newResponse(200, retryLedgerResponse, nil),
+	))
+
+	_, err := client.LedgerDetail(1)
+	assert.Error(t, err)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	_, err = client.LedgerDetail(1)
+	assert.Error(t, err)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	_, err = client.LedgerDetail(1)
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, 2, calls)
+
+	time.Sleep(10 * time.Millisecond)
+	_, err = client.LedgerDetail(1)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, transitions)
+}
@@ -0,0 +1,444 @@
+package auroraclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manucorporat/sse"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// streamDedupWindow is the number of most recent event IDs remembered to
+// skip events received again after reconnecting.
+const streamDedupWindow = 1000
+
+// StreamOptions configures how Stream* methods recover from errors. When
+// Client.StreamOptions is nil streams return on the first network error or
+// non-2xx response.
+type StreamOptions struct {
+	// MaxReconnects is the maximum number of consecutive failed connection
+	// attempts before the stream returns an error. Zero means no limit.
+	MaxReconnects int
+	// MinBackoff is the delay before the first reconnection. It's doubled
+	// after every consecutive failure. Defaults to 1s.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between reconnections. Defaults to 1m.
+	MaxBackoff time.Duration
+	// CursorStore persists the paging token of the last handled event of
+	// every stream. Streams resume from the stored cursor, overriding the
+	// cursor of the request. Optional.
+	CursorStore CursorStore
+	// QueueSize is the number of events buffered between reading the stream
+	// and calling the handler. When the queue is full the stream is not
+	// read until the handler catches up. Zero calls the handler directly.
+	QueueSize int
+	// OnReconnect is called before reconnecting after an error. Optional.
+	OnReconnect func(err error, attempt int, backoff time.Duration)
+}
+
+// CursorStore saves the cursors of streams. Keys are stream URLs without
+// the cursor parameter.
+type CursorStore interface {
+	// GetCursor returns the cursor saved for key or an empty string.
+	GetCursor(key string) (string, error)
+	SetCursor(key, cursor string) error
+}
+
+// MemoryCursorStore is a CursorStore keeping cursors in memory.
+type MemoryCursorStore struct {
+	mutex   sync.Mutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore returns an empty MemoryCursorStore.
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: map[string]string{}}
+}
+
+// GetCursor implements CursorStore.
+func (s *MemoryCursorStore) GetCursor(key string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cursors[key], nil
+}
+
+// SetCursor implements CursorStore.
+func (s *MemoryCursorStore) SetCursor(key, cursor string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cursors[key] = cursor
+	return nil
+}
+
+// FileCursorStore is a CursorStore keeping cursors in a JSON file. The file
+// is replaced atomically every time a cursor changes.
+type FileCursorStore struct {
+	Path string
+
+	mutex   sync.Mutex
+	cursors map[string]string
+}
+
+// GetCursor implements CursorStore.
+func (s *FileCursorStore) GetCursor(key string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	return s.cursors[key], nil
+}
+
+// SetCursor implements CursorStore.
+func (s *FileCursorStore) SetCursor(key, cursor string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.cursors[key] = cursor
+
+	data, err := json.Marshal(s.cursors)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling cursors")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "error creating cursor file")
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "error writing cursor file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), s.Path), "error replacing cursor file")
+}
+
+func (s *FileCursorStore) load() error {
+	if s.cursors != nil {
+		return nil
+	}
+	s.cursors = map[string]string{}
+
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "error reading cursor file")
+	}
+	if err = json.Unmarshal(data, &s.cursors); err != nil {
+		s.cursors = nil
+		return errors.Wrap(err, "error decoding cursor file")
+	}
+	return nil
+}
+
+// recentIDs remembers the last streamDedupWindow event IDs.
+type recentIDs struct {
+	ids  []string
+	next int
+	set  map[string]bool
+}
+
+func newRecentIDs() *recentIDs {
+	return &recentIDs{
+		ids: make([]string, streamDedupWindow),
+		set: map[string]bool{},
+	}
+}
+
+func (r *recentIDs) contains(id string) bool {
+	return r.set[id]
+}
+
+func (r *recentIDs) add(id string) {
+	delete(r.set, r.ids[r.next])
+	r.ids[r.next] = id
+	r.set[id] = true
+	r.next = (r.next + 1) % len(r.ids)
+}
+
+type streamEvent struct {
+	id   string
+	data []byte
+}
+
+// stream handles connections to endpoints that support streaming on a aurora server
+func (c *Client) stream(
+	ctx context.Context,
+	streamURL string,
+	handler func(data []byte) error,
+) error {
+	su, err := url.Parse(streamURL)
+	if err != nil {
+		return errors.Wrap(err, "error parsing stream url")
+	}
+
+	var opts StreamOptions
+	reconnect := c.StreamOptions != nil
+	if reconnect {
+		opts = *c.StreamOptions
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	query := su.Query()
+	key := streamCursorKey(*su)
+	if opts.CursorStore != nil {
+		var stored string
+		stored, err = opts.CursorStore.GetCursor(key)
+		if err != nil {
+			return errors.Wrap(err, "error loading stream cursor")
+		}
+		if stored != "" {
+			query.Set("cursor", stored)
+		}
+	}
+	if query.Get("cursor") == "" {
+		query.Set("cursor", "now")
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var handlerErr error
+	handle := func(event streamEvent) {
+		if err := handler(event.data); err != nil {
+			handlerErr = errors.Wrap(err, "handler error")
+			cancel()
+			return
+		}
+		if opts.CursorStore != nil && event.id != "" {
+			if err := opts.CursorStore.SetCursor(key, event.id); err != nil {
+				handlerErr = errors.Wrap(err, "error saving stream cursor")
+				cancel()
+			}
+		}
+	}
+
+	var queue chan streamEvent
+	var wg sync.WaitGroup
+	if opts.QueueSize > 0 {
+		queue = make(chan streamEvent, opts.QueueSize)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range queue {
+				if streamCtx.Err() == nil {
+					handle(event)
+				}
+			}
+		}()
+	}
+	// stopQueue waits for the handler to return, events left in the queue
+	// are dropped if the stream is stopped.
+	stopQueue := func() {
+		if queue != nil {
+			close(queue)
+			wg.Wait()
+			queue = nil
+		}
+	}
+	defer stopQueue()
+
+	seen := newRecentIDs()
+	failures := 0
+	for {
+		// updates the url with new cursor
+		su.RawQuery = query.Encode()
+		received := false
+		err := c.streamOnce(streamCtx, su.String(), func(event streamEvent) error {
+			received = true
+			if event.id != "" {
+				if seen.contains(event.id) {
+					return nil
+				}
+				seen.add(event.id)
+				// Update cursor with event ID
+				query.Set("cursor", event.id)
+			}
+
+			if queue == nil {
+				handle(event)
+				return nil
+			}
+			select {
+			case queue <- event:
+			case <-streamCtx.Done():
+			}
+			return nil
+		})
+
+		if streamCtx.Err() != nil {
+			stopQueue()
+			return handlerErr
+		}
+		if err == nil {
+			// The server closed the stream, reconnect.
+			continue
+		}
+		if _, ok := err.(streamDecodeError); ok || !reconnect {
+			return err
+		}
+
+		if received {
+			failures = 0
+		}
+		failures++
+		if opts.MaxReconnects > 0 && failures > opts.MaxReconnects {
+			return err
+		}
+		backoff := exponentialBackoff(opts.MinBackoff, opts.MaxBackoff, failures-1)
+		if opts.OnReconnect != nil {
+			opts.OnReconnect(err, failures, backoff)
+		}
+		select {
+		case <-streamCtx.Done():
+			stopQueue()
+			return handlerErr
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// streamDecodeError is returned by streamOnce when an event can't be
+// decoded. Streams are not resumed after such errors.
+type streamDecodeError struct {
+	error
+}
+
+// streamCursorKey returns streamURL without the cursor parameter.
+func streamCursorKey(streamURL url.URL) string {
+	query := streamURL.Query()
+	query.Del("cursor")
+	streamURL.RawQuery = query.Encode()
+	return streamURL.String()
+}
+
+// streamOnce connects to streamURL and passes all events to handler until
+// the server closes the stream (in which case it returns nil), an error
+// occurs or ctx is done.
+func (c *Client) streamOnce(
+	ctx context.Context,
+	streamURL string,
+	handler func(event streamEvent) error,
+) error {
+	req, err := http.NewRequest("GET", streamURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.setDefaultClient()
+	c.setClientAppHeaders(req)
+
+	// We can use c.HTTP here because we set Timeout per request not on the client. See sendRequest()
+	resp, err := c.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "error sending HTTP request")
+	}
+	defer resp.Body.Close()
+
+	// Expected statusCode are 200-299
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return fmt.Errorf("got bad HTTP status code %d", resp.StatusCode)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	// Read events one by one. Return when there is no more data to be read
+	// from resp.Body (io.EOF).
+	for {
+		// Read until empty line = event delimiter. The perfect solution would be to read
+		// as many bytes as possible and forward them to sse.Decode. However this
+		// requires much more complicated code.
+		// We could also write our own `sse` package that works fine with streams directly
+		// (github.com/manucorporat/sse is just using io/ioutils.ReadAll).
+		var buffer bytes.Buffer
+		nonEmptylinesRead := 0
+		for {
+			// Check if ctx is not cancelled
+			select {
+			case <-ctx.Done():
+				return nil
+			default:
+				// Continue
+			}
+
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					// We catch EOF errors to handle two possible situations:
+					// - The last line before closing the stream was not empty. This should never
+					//   happen in Aurora as it always sends an empty line after each event.
+					// - The stream was closed by the server/proxy because the connection was idle.
+					//
+					// In the former case, that (again) should never happen in Aurora, we need to
+					// check if there are any events we need to decode. We do this in the `if`
+					// statement below just in case if Aurora behaviour changes in a future.
+					//
+					// From spec:
+					// > Once the end of the file is reached, the user agent must dispatch the
+					// > event one final time, as defined below.
+					if nonEmptylinesRead == 0 {
+						return nil
+					}
+				} else {
+					return errors.Wrap(err, "error reading line")
+				}
+			}
+			buffer.WriteString(line)
+
+			if strings.TrimRight(line, "\n\r") == "" {
+				break
+			}
+
+			nonEmptylinesRead++
+		}
+
+		events, err := sse.Decode(strings.NewReader(buffer.String()))
+		if err != nil {
+			return streamDecodeError{errors.Wrap(err, "error decoding event")}
+		}
+
+		// Right now len(events) should always be 1. This loop will be helpful after writing
+		// new SSE decoder that can handle io.Reader without using ioutils.ReadAll().
+		for _, event := range events {
+			if event.Event != "message" {
+				continue
+			}
+
+			var data []byte
+			switch d := event.Data.(type) {
+			case string:
+				data = []byte(d)
+			case []byte:
+				data = d
+			default:
+				return streamDecodeError{errors.New("invalid event.Data type")}
+			}
+			if err = handler(streamEvent{id: event.Id, data: data}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package auroraclient
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ledgerEvents(sequences ...int) string {
+	var events string
+	for _, sequence := range sequences {
+		events += fmt.Sprintf("id: %d\ndata: {\"sequence\": %d}\n\n", sequence, sequence)
+	}
+	return events
+}
+
+func TestStreamReconnects(t *testing.T) {
+	hmock := httptest.NewClient()
+	store := NewMemoryCursorStore()
+	var reconnects []int
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		StreamOptions: &StreamOptions{
+			MinBackoff:  time.Millisecond,
+			CursorStore: store,
+			OnReconnect: func(err error, attempt int, backoff time.Duration) {
+				reconnects = append(reconnects, attempt)
+			},
+		},
+	}
+
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers?cursor=now").Return(sequenceResponder(
+		&calls,
+		newResponse(500, "", nil),
+		newResponse(503, "", nil),
+		newResponse(200, ledgerEvents(1, 2), nil),
+	))
+	// Events received again after reconnecting are skipped.
+	hmock.On("GET", "https://localhost/ledgers?cursor=2").
+		ReturnString(200, ledgerEvents(2, 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := client.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		if ledger.Sequence == 3 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3}, sequences)
+	assert.Equal(t, []int{1, 2}, reconnects)
+
+	cursor, err := store.GetCursor("https://localhost/ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "3", cursor)
+}
+
+func TestStreamResumesFromCursorStore(t *testing.T) {
+	hmock := httptest.NewClient()
+	store := NewMemoryCursorStore()
+	require.NoError(t, store.SetCursor("https://localhost/ledgers", "10"))
+	client := &Client{
+		AuroraURL:     "https://localhost/",
+		HTTP:          hmock,
+		StreamOptions: &StreamOptions{CursorStore: store},
+	}
+
+	hmock.On("GET", "https://localhost/ledgers?cursor=10").
+		ReturnString(200, ledgerEvents(11))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := client.StreamLedgers(ctx, LedgerRequest{Cursor: "1"}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		cancel()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{11}, sequences)
+}
+
+func TestStreamMaxReconnects(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL: "https://localhost/",
+		HTTP:      hmock,
+		StreamOptions: &StreamOptions{
+			MaxReconnects: 2,
+			MinBackoff:    time.Millisecond,
+		},
+	}
+
+	var calls int
+	hmock.On("GET", "https://localhost/ledgers?cursor=now").Return(sequenceResponder(
+		&calls,
+		newResponse(500, "", nil),
+	))
+
+	err := client.StreamLedgers(context.Background(), LedgerRequest{}, func(ledger hProtocol.Ledger) {})
+	assert.EqualError(t, err, "got bad HTTP status code 500")
+	assert.Equal(t, 3, calls)
+}
+
+func TestStreamQueue(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		AuroraURL:     "https://localhost/",
+		HTTP:          hmock,
+		StreamOptions: &StreamOptions{QueueSize: 2},
+	}
+
+	hmock.On("GET", "https://localhost/ledgers?cursor=now").
+		ReturnString(200, ledgerEvents(1, 2, 3, 4, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := client.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		time.Sleep(time.Millisecond)
+		sequences = append(sequences, ledger.Sequence)
+		if ledger.Sequence == 5 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3, 4, 5}, sequences)
+}
+
+func TestFileCursorStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cursors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cursors.json")
+
+	store := &FileCursorStore{Path: path}
+	cursor, err := store.GetCursor("a")
+	require.NoError(t, err)
+	assert.Equal(t, "", cursor)
+	require.NoError(t, store.SetCursor("a", "1"))
+	require.NoError(t, store.SetCursor("b", "2"))
+
+	store = &FileCursorStore{Path: path}
+	cursor, err = store.GetCursor("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", cursor)
+	cursor, err = store.GetCursor("b")
+	require.NoError(t, err)
+	assert.Equal(t, "2", cursor)
+}
@@ -0,0 +1,122 @@
+package txsubmitter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// FeeStrategy returns the base fee (the fee per operation, in stroops) of
+// new transactions.
+type FeeStrategy interface {
+	BaseFee() (int64, error)
+}
+
+// FixedFee is a FeeStrategy always returning the same base fee.
+type FixedFee int64
+
+// BaseFee returns f.
+func (f FixedFee) BaseFee() (int64, error) {
+	return int64(f), nil
+}
+
+// FeeStatsStrategy is a FeeStrategy choosing the base fee from a percentile
+// of the fees charged in the last ledgers, as returned by Client.FeeStats.
+// The fee is never lower than the base fee of the last ledger. It must be
+// used as a pointer.
+type FeeStatsStrategy struct {
+	Client auroraclient.ClientInterface
+	// Percentile of the fee_charged distribution. One of 10, 20, 30, 40, 50,
+	// 60, 70, 80, 90, 95, 99. Defaults to 50.
+	Percentile int
+	// MaxFee caps the returned base fee. Zero means no limit.
+	MaxFee int64
+	// CacheDuration is the time fee stats are reused for before they are
+	// fetched again. Defaults to 5s.
+	CacheDuration time.Duration
+
+	mutex     sync.Mutex
+	fee       int64
+	fetchedAt time.Time
+}
+
+// BaseFee returns the base fee computed from the current fee stats.
+func (s *FeeStatsStrategy) BaseFee() (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cacheDuration := s.CacheDuration
+	if cacheDuration == 0 {
+		cacheDuration = 5 * time.Second
+	}
+	if s.fee != 0 && time.Since(s.fetchedAt) < cacheDuration {
+		return s.fee, nil
+	}
+
+	stats, err := s.Client.FeeStats()
+	if err != nil {
+		return 0, errors.Wrap(err, "error getting fee stats")
+	}
+
+	percentile := s.Percentile
+	if percentile == 0 {
+		percentile = 50
+	}
+	fee, err := percentileFee(stats.FeeCharged, percentile)
+	if err != nil {
+		return 0, err
+	}
+	if fee < stats.LastLedgerBaseFee {
+		fee = stats.LastLedgerBaseFee
+	}
+	fee = capFee(fee, s.MaxFee)
+
+	s.fee = fee
+	s.fetchedAt = time.Now()
+	return fee, nil
+}
+
+func percentileFee(distribution hProtocol.FeeDistribution, percentile int) (int64, error) {
+	switch percentile {
+	case 10:
+		return distribution.P10, nil
+	case 20:
+		return distribution.P20, nil
+	case 30:
+		return distribution.P30, nil
+	case 40:
+		return distribution.P40, nil
+	case 50:
+		return distribution.P50, nil
+	case 60:
+		return distribution.P60, nil
+	case 70:
+		return distribution.P70, nil
+	case 80:
+		return distribution.P80, nil
+	case 90:
+		return distribution.P90, nil
+	case 95:
+		return distribution.P95, nil
+	case 99:
+		return distribution.P99, nil
+	default:
+		return 0, errors.Errorf("unsupported fee percentile: %d", percentile)
+	}
+}
+
+// capFee limits fee to maxFee (when non-zero) and makes sure it's not lower
+// than the network minimum.
+func capFee(fee, maxFee int64) int64 {
+	if maxFee > 0 && fee > maxFee {
+		fee = maxFee
+	}
+	if fee < txnbuild.MinBaseFee {
+		fee = txnbuild.MinBaseFee
+	}
+	return fee
+}
@@ -0,0 +1,57 @@
+package txsubmitter
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeStatsStrategy(t *testing.T) {
+	stats := hProtocol.FeeStats{
+		LastLedgerBaseFee: 100,
+		FeeCharged: hProtocol.FeeDistribution{
+			P10: 50,
+			P50: 300,
+			P90: 2000,
+		},
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		strategy *FeeStatsStrategy
+		expected int64
+	}{
+		{"default percentile", &FeeStatsStrategy{}, 300},
+		{"high percentile", &FeeStatsStrategy{Percentile: 90}, 2000},
+		{"last ledger base fee", &FeeStatsStrategy{Percentile: 10}, 100},
+		{"max fee", &FeeStatsStrategy{Percentile: 90, MaxFee: 1000}, 1000},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := &auroraclient.MockClient{}
+			client.On("FeeStats").Return(stats, nil).Once()
+			testCase.strategy.Client = client
+
+			fee, err := testCase.strategy.BaseFee()
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, fee)
+
+			// Fee stats are cached.
+			fee, err = testCase.strategy.BaseFee()
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, fee)
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFeeStatsStrategyInvalidPercentile(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	client.On("FeeStats").Return(hProtocol.FeeStats{}, nil).Once()
+
+	strategy := &FeeStatsStrategy{Client: client, Percentile: 15}
+	_, err := strategy.BaseFee()
+	assert.EqualError(t, err, "unsupported fee percentile: 15")
+}
@@ -0,0 +1,303 @@
+/*
+Package txsubmitter submits transactions built with txnbuild from a pool of
+channel accounts. It is meant for services sending many transactions per
+second.
+
+Every channel account is used by at most one transaction at a time. Sequence
+numbers are tracked locally and refreshed from Aurora when a transaction
+fails with tx_bad_seq. Base fees are returned by a FeeStrategy, for example
+FeeStatsStrategy which follows the fee stats of the network. Transactions
+stuck in the queue (Aurora returns a timeout) are resubmitted wrapped in a
+fee-bump transaction with a higher fee.
+*/
+package txsubmitter
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// ErrNoChannels is returned by New when no channel accounts are configured.
+var ErrNoChannels = errors.New("at least one channel account is required")
+
+// Config configures a Submitter.
+type Config struct {
+	Client            auroraclient.ClientInterface
+	NetworkPassphrase string
+	// Channels are the keypairs of the channel accounts used as sources of
+	// the submitted transactions.
+	Channels []*keypair.Full
+	// FeeAccount pays the fee of fee-bump transactions. Channel accounts pay
+	// them when nil.
+	FeeAccount *keypair.Full
+	// FeeStrategy returns the base fee of new transactions. Defaults to
+	// txnbuild.MinBaseFee.
+	FeeStrategy FeeStrategy
+	// MaxConcurrency is the maximum number of transactions submitted at the
+	// same time. Defaults to the number of channels.
+	MaxConcurrency int
+	// Timeout is added to the current time to build the max time of the
+	// transactions. Defaults to 5 minutes.
+	Timeout time.Duration
+	// MaxFeeBumps is the number of times a stuck transaction is resubmitted
+	// in a fee-bump transaction. Zero disables fee bumps.
+	MaxFeeBumps int
+	// MaxFee caps the base fee of fee-bump transactions. Zero means no limit.
+	MaxFee int64
+	// MaxSequenceRetries is the number of times a transaction is rebuilt
+	// after failing with tx_bad_seq. Defaults to 3.
+	MaxSequenceRetries int
+	// SubmitOptions are passed to the Aurora client.
+	SubmitOptions auroraclient.SubmitTxOpts
+}
+
+// Request is a transaction to submit. The channel account is the source of
+// the transaction so operations should have their source account set.
+type Request struct {
+	Operations []txnbuild.Operation
+	Memo       txnbuild.Memo
+	// Signers sign the transaction in addition to the channel account,
+	// usually the source accounts of the operations.
+	Signers []*keypair.Full
+}
+
+// Submitter submits transactions using a pool of channel accounts. It is
+// safe for concurrent use.
+type Submitter struct {
+	config   Config
+	channels chan *channel
+	slots    chan struct{}
+}
+
+type channel struct {
+	keypair  *keypair.Full
+	sequence int64
+	synced   bool
+}
+
+// New returns a Submitter using the given config.
+func New(config Config) (*Submitter, error) {
+	if config.Client == nil {
+		return nil, errors.New("aurora client is required")
+	}
+	if config.NetworkPassphrase == "" {
+		return nil, errors.New("network passphrase is required")
+	}
+	if len(config.Channels) == 0 {
+		return nil, ErrNoChannels
+	}
+	if config.FeeStrategy == nil {
+		config.FeeStrategy = FixedFee(txnbuild.MinBaseFee)
+	}
+	if config.MaxConcurrency <= 0 || config.MaxConcurrency > len(config.Channels) {
+		config.MaxConcurrency = len(config.Channels)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Minute
+	}
+	if config.MaxSequenceRetries <= 0 {
+		config.MaxSequenceRetries = 3
+	}
+
+	s := &Submitter{
+		config:   config,
+		channels: make(chan *channel, len(config.Channels)),
+		slots:    make(chan struct{}, config.MaxConcurrency),
+	}
+	for _, kp := range config.Channels {
+		s.channels <- &channel{keypair: kp}
+	}
+	return s, nil
+}
+
+// Submit builds a transaction from req using a free channel account and
+// submits it. It blocks until a channel account is available, the
+// transaction is included in a ledger or fails, or ctx is done.
+func (s *Submitter) Submit(ctx context.Context, req Request) (hProtocol.Transaction, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return hProtocol.Transaction{}, ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	var ch *channel
+	select {
+	case ch = <-s.channels:
+	case <-ctx.Done():
+		return hProtocol.Transaction{}, ctx.Err()
+	}
+	defer func() { s.channels <- ch }()
+
+	for attempt := 0; ; attempt++ {
+		if !ch.synced {
+			if err := s.syncSequence(ch); err != nil {
+				return hProtocol.Transaction{}, err
+			}
+		}
+
+		tx, err := s.buildTransaction(ch, req)
+		if err != nil {
+			return hProtocol.Transaction{}, err
+		}
+
+		resp, err := s.submit(ctx, ch, tx)
+		switch {
+		case err == nil:
+			ch.sequence = tx.SourceAccount().Sequence
+			return resp, nil
+		case resultCode(err) == "tx_failed":
+			// Failed transactions consume the sequence number.
+			ch.sequence = tx.SourceAccount().Sequence
+			return resp, err
+		case resultCode(err) == "tx_bad_seq" && attempt < s.config.MaxSequenceRetries:
+			ch.synced = false
+			continue
+		default:
+			// The state of the channel account is unknown, it's loaded
+			// again before the next transaction.
+			ch.synced = false
+			return resp, err
+		}
+	}
+}
+
+func (s *Submitter) syncSequence(ch *channel) error {
+	account, err := s.config.Client.AccountDetail(auroraclient.AccountRequest{
+		AccountID: ch.keypair.Address(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error loading channel account %s", ch.keypair.Address())
+	}
+	ch.sequence, err = account.GetSequenceNumber()
+	if err != nil {
+		return errors.Wrapf(err, "error parsing sequence of channel account %s", ch.keypair.Address())
+	}
+	ch.synced = true
+	return nil
+}
+
+func (s *Submitter) buildTransaction(ch *channel, req Request) (*txnbuild.Transaction, error) {
+	baseFee, err := s.config.FeeStrategy.BaseFee()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting base fee")
+	}
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{
+			AccountID: ch.keypair.Address(),
+			Sequence:  ch.sequence,
+		},
+		IncrementSequenceNum: true,
+		Operations:           req.Operations,
+		BaseFee:              baseFee,
+		Memo:                 req.Memo,
+		Timebounds:           txnbuild.NewTimeout(int64(s.config.Timeout.Seconds())),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error building transaction")
+	}
+
+	signers := append([]*keypair.Full{ch.keypair}, req.Signers...)
+	tx, err = tx.Sign(s.config.NetworkPassphrase, signers...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing transaction")
+	}
+	return tx, nil
+}
+
+// submit submits tx and resubmits it in fee-bump transactions while Aurora
+// times out waiting for it to be included in a ledger.
+func (s *Submitter) submit(ctx context.Context, ch *channel, tx *txnbuild.Transaction) (hProtocol.Transaction, error) {
+	resp, err := s.config.Client.SubmitTransactionWithOptions(tx, s.config.SubmitOptions)
+
+	baseFee := tx.BaseFee()
+	bumps := 0
+	for ; bumps < s.config.MaxFeeBumps && isTimeout(err); bumps++ {
+		if ctx.Err() != nil {
+			return resp, ctx.Err()
+		}
+
+		newBaseFee := capFee(2*baseFee, s.config.MaxFee)
+		if newBaseFee <= baseFee {
+			// The fee can't be increased anymore.
+			break
+		}
+		baseFee = newBaseFee
+
+		var feeBump *txnbuild.FeeBumpTransaction
+		feeBump, err = s.buildFeeBump(ch, tx, baseFee)
+		if err != nil {
+			return resp, err
+		}
+		resp, err = s.config.Client.SubmitFeeBumpTransactionWithOptions(feeBump, s.config.SubmitOptions)
+	}
+
+	if err != nil && bumps > 0 {
+		// The original transaction could have been included while it was
+		// being fee-bumped, in which case the fee-bump fails.
+		if included, ok := s.findIncluded(tx); ok {
+			return included, nil
+		}
+	}
+	return resp, err
+}
+
+func (s *Submitter) buildFeeBump(ch *channel, tx *txnbuild.Transaction, baseFee int64) (*txnbuild.FeeBumpTransaction, error) {
+	feeAccount := s.config.FeeAccount
+	if feeAccount == nil {
+		feeAccount = ch.keypair
+	}
+
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      tx,
+		FeeAccount: feeAccount.Address(),
+		BaseFee:    baseFee,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error building fee-bump transaction")
+	}
+	feeBump, err = feeBump.Sign(s.config.NetworkPassphrase, feeAccount)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing fee-bump transaction")
+	}
+	return feeBump, nil
+}
+
+func (s *Submitter) findIncluded(tx *txnbuild.Transaction) (hProtocol.Transaction, bool) {
+	hash, err := tx.Hash(s.config.NetworkPassphrase)
+	if err != nil {
+		return hProtocol.Transaction{}, false
+	}
+	resp, err := s.config.Client.TransactionDetail(hex.EncodeToString(hash[:]))
+	if err != nil || !resp.Successful {
+		return hProtocol.Transaction{}, false
+	}
+	return resp, true
+}
+
+// resultCode returns the transaction result code of a failed submission.
+func resultCode(err error) string {
+	hErr := auroraclient.GetError(err)
+	if hErr == nil {
+		return ""
+	}
+	codes, err := hErr.ResultCodes()
+	if err != nil {
+		return ""
+	}
+	return codes.TransactionCode
+}
+
+func isTimeout(err error) bool {
+	hErr := auroraclient.GetError(err)
+	return hErr != nil && hErr.Problem.Status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,170 @@
+package txsubmitter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSubmitter(t *testing.T, client *auroraclient.MockClient, config Config) (*Submitter, *keypair.Full) {
+	channel := keypair.MustRandom()
+	config.Client = client
+	config.NetworkPassphrase = network.TestNetworkPassphrase
+	config.Channels = []*keypair.Full{channel}
+	submitter, err := New(config)
+	require.NoError(t, err)
+	return submitter, channel
+}
+
+func paymentRequest() Request {
+	source := keypair.MustRandom()
+	return Request{
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination:   keypair.MustRandom().Address(),
+			Amount:        "10",
+			Asset:         txnbuild.NativeAsset{},
+			SourceAccount: &txnbuild.SimpleAccount{AccountID: source.Address()},
+		}},
+		Signers: []*keypair.Full{source},
+	}
+}
+
+func txFailedError(code string) error {
+	return &auroraclient.Error{
+		Problem: problem.P{
+			Status: http.StatusBadRequest,
+			Extras: map[string]interface{}{
+				"result_codes": hProtocol.TransactionResultCodes{TransactionCode: code},
+			},
+		},
+	}
+}
+
+func sequenceMatcher(sequence int64) interface{} {
+	return mock.MatchedBy(func(tx *txnbuild.Transaction) bool {
+		return tx.SourceAccount().Sequence == sequence
+	})
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	_, err := New(Config{Client: &auroraclient.MockClient{}, NetworkPassphrase: network.TestNetworkPassphrase})
+	assert.Equal(t, ErrNoChannels, err)
+
+	_, err = New(Config{Channels: []*keypair.Full{keypair.MustRandom()}})
+	assert.EqualError(t, err, "aurora client is required")
+}
+
+func TestSubmitTracksSequence(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	submitter, channel := newTestSubmitter(t, client, Config{})
+
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(hProtocol.Account{AccountID: channel.Address(), Sequence: "100"}, nil).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(101), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Hash: "a", Successful: true}, nil).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(102), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Hash: "b", Successful: true}, nil).Once()
+
+	resp, err := submitter.Submit(context.Background(), paymentRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Hash)
+
+	resp, err = submitter.Submit(context.Background(), paymentRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "b", resp.Hash)
+
+	client.AssertExpectations(t)
+}
+
+func TestSubmitResyncsOnBadSequence(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	submitter, channel := newTestSubmitter(t, client, Config{})
+
+	request := auroraclient.AccountRequest{AccountID: channel.Address()}
+	client.On("AccountDetail", request).
+		Return(hProtocol.Account{AccountID: channel.Address(), Sequence: "100"}, nil).Once()
+	client.On("AccountDetail", request).
+		Return(hProtocol.Account{AccountID: channel.Address(), Sequence: "200"}, nil).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(101), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, txFailedError("tx_bad_seq")).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(201), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Hash: "a", Successful: true}, nil).Once()
+
+	resp, err := submitter.Submit(context.Background(), paymentRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Hash)
+	client.AssertExpectations(t)
+}
+
+func TestSubmitFailedTransactionConsumesSequence(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	submitter, channel := newTestSubmitter(t, client, Config{})
+
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(hProtocol.Account{AccountID: channel.Address(), Sequence: "100"}, nil).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(101), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, txFailedError("tx_failed")).Once()
+	client.On("SubmitTransactionWithOptions", sequenceMatcher(102), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Hash: "b", Successful: true}, nil).Once()
+
+	_, err := submitter.Submit(context.Background(), paymentRequest())
+	assert.Error(t, err)
+
+	_, err = submitter.Submit(context.Background(), paymentRequest())
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestSubmitFeeBumpsStuckTransaction(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	feeAccount := keypair.MustRandom()
+	submitter, channel := newTestSubmitter(t, client, Config{
+		FeeStrategy: FixedFee(200),
+		FeeAccount:  feeAccount,
+		MaxFeeBumps: 2,
+		MaxFee:      500,
+	})
+
+	timeout := &auroraclient.Error{Problem: problem.P{Status: http.StatusGatewayTimeout}}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: channel.Address()}).
+		Return(hProtocol.Account{AccountID: channel.Address(), Sequence: "100"}, nil).Once()
+	client.On("SubmitTransactionWithOptions", mock.Anything, auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, timeout).Once()
+	client.On("SubmitFeeBumpTransactionWithOptions", mock.MatchedBy(func(tx *txnbuild.FeeBumpTransaction) bool {
+		return tx.BaseFee() == 400 && tx.FeeAccount() == feeAccount.Address()
+	}), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, timeout).Once()
+	client.On("SubmitFeeBumpTransactionWithOptions", mock.MatchedBy(func(tx *txnbuild.FeeBumpTransaction) bool {
+		return tx.BaseFee() == 500
+	}), auroraclient.SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Hash: "bump", Successful: true}, nil).Once()
+
+	resp, err := submitter.Submit(context.Background(), paymentRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "bump", resp.Hash)
+	client.AssertExpectations(t)
+}
+
+func TestSubmitCanceledContext(t *testing.T) {
+	client := &auroraclient.MockClient{}
+	submitter, _ := newTestSubmitter(t, client, Config{})
+
+	// Take the only channel.
+	ch := <-submitter.channels
+	defer func() { submitter.channels <- ch }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := submitter.Submit(ctx, paymentRequest())
+	assert.Equal(t, context.Canceled, err)
+}
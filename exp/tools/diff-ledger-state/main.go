@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	stdio "io"
+	"os"
+	"strings"
+
+	"github.com/hcnet/go/historyarchive"
+	"github.com/hcnet/go/ingest/io"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/xdr"
+)
+
+// diffEntry is a single row of the tool output.
+type diffEntry struct {
+	Change    string `json:"change"`
+	EntryType string `json:"entry_type"`
+	Key       string `json:"key"`
+	Pre       string `json:"pre,omitempty"`
+	Post      string `json:"post,omitempty"`
+}
+
+var changeNames = map[xdr.LedgerEntryChangeType]string{
+	xdr.LedgerEntryChangeTypeLedgerEntryCreated: "added",
+	xdr.LedgerEntryChangeTypeLedgerEntryRemoved: "removed",
+	xdr.LedgerEntryChangeTypeLedgerEntryUpdated: "modified",
+}
+
+func newDiffEntry(change io.Change) (diffEntry, error) {
+	entry := diffEntry{
+		Change:    changeNames[change.LedgerEntryChangeType()],
+		EntryType: change.Type.String(),
+	}
+
+	var key xdr.LedgerKey
+	if change.Pre != nil {
+		key = change.Pre.LedgerKey()
+		pre, err := xdr.MarshalBase64(change.Pre)
+		if err != nil {
+			return entry, errors.Wrap(err, "could not marshal pre entry")
+		}
+		entry.Pre = pre
+	}
+	if change.Post != nil {
+		key = change.Post.LedgerKey()
+		post, err := xdr.MarshalBase64(change.Post)
+		if err != nil {
+			return entry, errors.Wrap(err, "could not marshal post entry")
+		}
+		entry.Post = post
+	}
+
+	var err error
+	entry.Key, err = key.MarshalBinaryBase64()
+	if err != nil {
+		return entry, errors.Wrap(err, "could not marshal ledger key")
+	}
+	return entry, nil
+}
+
+// diffWriter writes changes in the selected format and counts them.
+type diffWriter struct {
+	format string
+	out    stdio.Writer
+	csv    *csv.Writer
+	count  int
+	stats  *io.StatsChangeProcessor
+}
+
+func newDiffWriter(format string, out stdio.Writer) (*diffWriter, error) {
+	w := &diffWriter{
+		format: format,
+		out:    out,
+		stats:  &io.StatsChangeProcessor{},
+	}
+
+	switch format {
+	case "csv":
+		w.csv = csv.NewWriter(out)
+		w.csv.Write([]string{"change", "entry_type", "key", "pre", "post"})
+	case "json":
+		if _, err := fmt.Fprint(out, "{\"changes\":["); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown output format: %s", format)
+	}
+	return w, nil
+}
+
+func (w *diffWriter) ProcessChange(change io.Change) error {
+	if err := w.stats.ProcessChange(change); err != nil {
+		return err
+	}
+
+	entry, err := newDiffEntry(change)
+	if err != nil {
+		return err
+	}
+
+	switch w.format {
+	case "csv":
+		w.csv.Write([]string{entry.Change, entry.EntryType, entry.Key, entry.Pre, entry.Post})
+		if err = w.csv.Error(); err != nil {
+			return errors.Wrap(err, "Error during csv.Writer.Write")
+		}
+	case "json":
+		if w.count > 0 {
+			if _, err = fmt.Fprint(w.out, ","); err != nil {
+				return err
+			}
+		}
+		if err = json.NewEncoder(w.out).Encode(entry); err != nil {
+			return err
+		}
+	}
+	w.count++
+	return nil
+}
+
+// close finishes the output. Summary counts are written to the JSON output
+// and logged for both formats.
+func (w *diffWriter) close() error {
+	summary := w.stats.GetResults()
+
+	switch w.format {
+	case "csv":
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return errors.Wrap(err, "Error during csv.Writer.Flush")
+		}
+	case "json":
+		if _, err := fmt.Fprint(w.out, "],\"summary\":"); err != nil {
+			return err
+		}
+		if err := json.NewEncoder(w.out).Encode(summary.Map()); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w.out, "}"); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.F(summary.Map())).
+		WithField("changes", w.count).
+		Info("Finished diffing ledger state")
+	return nil
+}
+
+func main() {
+	testnet := flag.Bool("testnet", false, "connect to the Hcnet test network")
+	archiveURL := flag.String("archive-url", "", "history archive URL, overrides -testnet")
+	from := flag.Uint("from", 0, "checkpoint ledger of the old state")
+	to := flag.Uint("to", 0, "checkpoint ledger of the new state")
+	entryTypes := flag.String("type", "", "comma separated list of entry types: account, trustline, offer, data, claimable_balance")
+	accounts := flag.String("account", "", "comma separated list of accounts")
+	assets := flag.String("asset", "", "comma separated list of assets in the canonical form (native or CODE:ISSUER)")
+	format := flag.String("format", "json", "output format: json or csv")
+	output := flag.String("output", "", "output file, stdout when empty")
+	flag.Parse()
+
+	log.SetLevel(log.InfoLevel)
+
+	if *from == 0 || *to == 0 {
+		log.Fatal("-from and -to are required")
+	}
+
+	filter, err := buildFilter(*entryTypes, *accounts, *assets)
+	if err != nil {
+		log.WithField("err", err).Fatal("invalid filter")
+	}
+
+	archive, err := archive(*testnet, *archiveURL)
+	if err != nil {
+		log.WithField("err", err).Fatal("cannot connect to history archive")
+	}
+
+	var out stdio.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.WithField("err", err).Fatal("cannot create output file")
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer, err := newDiffWriter(*format, out)
+	if err != nil {
+		log.WithField("err", err).Fatal("cannot create output writer")
+	}
+
+	fromReader, err := io.MakeSingleLedgerStateReader(context.Background(), archive, uint32(*from))
+	if err != nil {
+		log.WithField("err", err).Fatal("cannot construct `from` change reader")
+	}
+	defer fromReader.Close()
+
+	toReader, err := io.MakeSingleLedgerStateReader(context.Background(), archive, uint32(*to))
+	if err != nil {
+		log.WithField("err", err).Fatal("cannot construct `to` change reader")
+	}
+	defer toReader.Close()
+
+	log.WithField("from", *from).
+		WithField("to", *to).
+		Info("Diffing entries from History Archive Snapshots")
+
+	if err = io.DiffStates(fromReader, toReader, filter, writer); err != nil {
+		log.WithField("err", err).Fatal("could not diff ledger states")
+	}
+
+	if err = writer.close(); err != nil {
+		log.WithField("err", err).Fatal("could not write output")
+	}
+}
+
+var entryTypeNames = map[string]xdr.LedgerEntryType{
+	"account":           xdr.LedgerEntryTypeAccount,
+	"trustline":         xdr.LedgerEntryTypeTrustline,
+	"offer":             xdr.LedgerEntryTypeOffer,
+	"data":              xdr.LedgerEntryTypeData,
+	"claimable_balance": xdr.LedgerEntryTypeClaimableBalance,
+}
+
+func buildFilter(entryTypes, accounts, assets string) (io.StateDiffFilter, error) {
+	var filter io.StateDiffFilter
+
+	for _, name := range splitList(entryTypes) {
+		entryType, ok := entryTypeNames[name]
+		if !ok {
+			return filter, errors.Errorf("unknown entry type: %s", name)
+		}
+		filter.EntryTypes = append(filter.EntryTypes, entryType)
+	}
+
+	for _, address := range splitList(accounts) {
+		var account xdr.AccountId
+		if err := account.SetAddress(address); err != nil {
+			return filter, errors.Wrapf(err, "invalid account: %s", address)
+		}
+		filter.Accounts = append(filter.Accounts, account)
+	}
+
+	var err error
+	filter.Assets, err = xdr.BuildAssets(assets)
+	if err != nil {
+		return filter, errors.Wrap(err, "invalid assets")
+	}
+
+	return filter, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func archive(testnet bool, archiveURL string) (*historyarchive.Archive, error) {
+	if archiveURL != "" {
+		return historyarchive.Connect(archiveURL, historyarchive.ConnectOptions{})
+	}
+
+	if testnet {
+		return historyarchive.Connect(
+			"https://history.hcnet.org/prd/core-testnet/core_testnet_001",
+			historyarchive.ConnectOptions{},
+		)
+	}
+
+	return historyarchive.Connect(
+		"https://history.hcnet.org/prd/core-live/core_live_001/",
+		historyarchive.ConnectOptions{},
+	)
+}
@@ -19,6 +19,20 @@ func (b *FsArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
 	return os.Open(path.Join(b.prefix, pth))
 }
 
+// GetFileFrom returns the contents of the file starting at the given byte
+// offset.
+func (b *FsArchiveBackend) GetFileFrom(pth string, offset int64) (io.ReadCloser, int64, error) {
+	file, err := os.Open(path.Join(b.prefix, pth))
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, offset, nil
+}
+
 func (b *FsArchiveBackend) Exists(pth string) (bool, error) {
 	pth = path.Join(b.prefix, pth)
 	_, err := os.Stat(pth)
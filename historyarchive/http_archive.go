@@ -5,9 +5,11 @@
 package historyarchive
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
@@ -31,11 +33,23 @@ func checkResp(r *http.Response) error {
 }
 
 func (b *HttpArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
+	rdr, _, err := b.GetFileFrom(pth, 0)
+	return rdr, err
+}
+
+// GetFileFrom returns the contents of the file starting at the given byte
+// offset using an HTTP range request. Servers are free to ignore range
+// requests so the offset of the returned data is returned as well: it's
+// either equal to offset or 0 when the full file is returned.
+func (b *HttpArchiveBackend) GetFileFrom(pth string, offset int64) (io.ReadCloser, int64, error) {
 	var derived url.URL = b.base
 	derived.Path = path.Join(derived.Path, pth)
 	req, err := http.NewRequest("GET", derived.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 	req = req.WithContext(b.ctx)
 	resp, err := b.client.Do(req)
@@ -43,16 +57,24 @@ func (b *HttpArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
 		if resp != nil && resp.Body != nil {
 			resp.Body.Close()
 		}
-		return nil, err
+		return nil, 0, err
+	}
+	if offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// Nothing left to read after offset.
+		resp.Body.Close()
+		return ioutil.NopCloser(bytes.NewReader(nil)), offset, nil
 	}
 	err = checkResp(resp)
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			resp.Body.Close()
 		}
-		return nil, err
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		offset = 0
 	}
-	return resp.Body, nil
+	return resp.Body, offset, nil
 }
 
 func (b *HttpArchiveBackend) Head(pth string) (*http.Response, error) {
@@ -5,6 +5,7 @@
 package historyarchive
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -13,6 +14,9 @@ import (
 	"github.com/hcnet/go/support/errors"
 )
 
+// mirrorRetries is the number of times a failed bucket download is retried.
+const mirrorRetries = 3
+
 func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 	rootHAS, e := src.GetRootHAS()
 	if e != nil {
@@ -23,9 +27,11 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 
 	log.Printf("copying range %s\n", opts.Range)
 
-	// Make a bucket-fetch map that shows which buckets are
-	// already-being-fetched
+	// Make a bucket-fetch map that shows which buckets are already queued
+	// for download. Buckets are downloaded by a Prefetcher once all
+	// checkpoint files are copied.
 	bucketFetch := make(map[Hash]bool)
+	bucketPaths := []string{}
 	var bucketFetchMutex sync.Mutex
 
 	var errs uint32
@@ -60,20 +66,14 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 					panic(errors.Wrap(err, "error getting buckets"))
 				}
 
+				bucketFetchMutex.Lock()
 				for _, bucket := range buckets {
-					alreadyFetching := false
-					bucketFetchMutex.Lock()
-					_, alreadyFetching = bucketFetch[bucket]
-					if !alreadyFetching {
+					if !bucketFetch[bucket] {
 						bucketFetch[bucket] = true
-					}
-					bucketFetchMutex.Unlock()
-					if !alreadyFetching {
-						pth := BucketPath(bucket)
-						err = copyPath(src, dst, pth, opts)
-						atomic.AddUint32(&errs, noteError(err))
+						bucketPaths = append(bucketPaths, BucketPath(bucket))
 					}
 				}
+				bucketFetchMutex.Unlock()
 
 				for _, cat := range Categories() {
 					pth := CategoryCheckpointPath(cat, ix)
@@ -90,9 +90,28 @@ func Mirror(src *Archive, dst *Archive, opts *CommandOptions) error {
 	}
 
 	wg.Wait()
+	close(tick)
+
+	if opts.DryRun {
+		log.Printf("dryrun skipping %d buckets", len(bucketPaths))
+	} else {
+		prefetcher := NewPrefetcher(src, dst, PrefetchOptions{
+			Concurrency: opts.Concurrency,
+			MaxRetries:  mirrorRetries,
+			Force:       opts.Force,
+			Progress: func(p PrefetchProgress) {
+				if p.DoneFiles%100 == 0 || p.DoneFiles == p.TotalFiles {
+					log.Printf("Copied %d/%d buckets (%d MiB), %d failed",
+						p.DoneFiles, p.TotalFiles, p.BytesDownloaded>>20, p.FailedFiles)
+				}
+			},
+		})
+		if e = prefetcher.Prefetch(context.Background(), bucketPaths); e != nil {
+			errs += noteError(e)
+		}
+	}
 	log.Printf("copied %d checkpoints, %d buckets, range %s",
 		opts.Range.Size(), len(bucketFetch), opts.Range)
-	close(tick)
 	if rootHAS.CurrentLedger == opts.Range.High {
 		log.Printf("updating destination archive current-ledger pointer to 0x%8.8x",
 			rootHAS.CurrentLedger)
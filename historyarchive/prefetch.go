@@ -0,0 +1,355 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// rangeArchiveBackend is implemented by backends able to return a file
+// starting at a given offset. Downloads from such backends are resumed
+// instead of restarted.
+type rangeArchiveBackend interface {
+	GetFileFrom(pth string, offset int64) (io.ReadCloser, int64, error)
+}
+
+var bucketPathRx = regexp.MustCompile("bucket-([0-9a-f]{64})\\.xdr\\.gz$")
+
+// PrefetchOptions configures a Prefetcher.
+type PrefetchOptions struct {
+	// Concurrency is the number of files downloaded in parallel. Defaults to 1.
+	Concurrency int
+	// MaxRetries is the number of times a failed download is retried.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry. It's doubled after
+	// every consecutive failure of the same file. Defaults to 1s.
+	RetryBackoff time.Duration
+	// Force downloads files that already exist in the destination.
+	Force bool
+	// VerifyBuckets checks the hash of every downloaded bucket. Buckets
+	// with unexpected hashes are downloaded again.
+	VerifyBuckets bool
+	// TempDir is the directory where partially downloaded files are kept
+	// when the destination is not a local file archive. Partial files are
+	// resumed on the next run as long as the directory is preserved.
+	// Defaults to os.TempDir().
+	TempDir string
+	// Progress is called after every file is downloaded or fails.
+	Progress func(PrefetchProgress)
+}
+
+// PrefetchProgress describes the state of a Prefetch call.
+type PrefetchProgress struct {
+	TotalFiles      int
+	DoneFiles       int
+	FailedFiles     int
+	BytesDownloaded int64
+}
+
+// Prefetcher downloads files from one archive into another one in parallel.
+// Partial downloads are kept on disk and resumed when the source backend
+// supports ranged reads (HTTP and local file archives).
+type Prefetcher struct {
+	src  *Archive
+	dst  *Archive
+	opts PrefetchOptions
+
+	mutex    sync.Mutex
+	progress PrefetchProgress
+}
+
+// NewPrefetcher returns a Prefetcher copying files from src to dst.
+func NewPrefetcher(src, dst *Archive, opts PrefetchOptions) *Prefetcher {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.TempDir == "" {
+		opts.TempDir = os.TempDir()
+	}
+	return &Prefetcher{src: src, dst: dst, opts: opts}
+}
+
+// CheckpointPaths returns paths of all buckets referenced by has followed
+// by the files of the given categories for the has checkpoint.
+func CheckpointPaths(has HistoryArchiveState, categories ...string) ([]string, error) {
+	buckets, err := has.Buckets()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting buckets")
+	}
+
+	seen := map[Hash]bool{}
+	var paths []string
+	for _, bucket := range buckets {
+		if seen[bucket] {
+			continue
+		}
+		seen[bucket] = true
+		paths = append(paths, BucketPath(bucket))
+	}
+	for _, cat := range categories {
+		paths = append(paths, CategoryCheckpointPath(cat, has.CurrentLedger))
+	}
+	return paths, nil
+}
+
+// PrefetchCheckpoint downloads the HAS of the checkpoint, all the buckets
+// it references and the files of the given categories. After it returns
+// the destination archive can be used to read the state at the checkpoint.
+func (p *Prefetcher) PrefetchCheckpoint(ctx context.Context, chk uint32, categories ...string) error {
+	has, err := p.src.GetCheckpointHAS(chk)
+	if err != nil {
+		return errors.Wrapf(err, "error getting HAS for checkpoint 0x%8.8x", chk)
+	}
+
+	paths, err := CheckpointPaths(has, categories...)
+	if err != nil {
+		return err
+	}
+	if err = p.Prefetch(ctx, paths); err != nil {
+		return err
+	}
+
+	return p.dst.PutCheckpointHAS(chk, has, &CommandOptions{Force: p.opts.Force})
+}
+
+// Prefetch downloads all paths using opts.Concurrency workers. It returns
+// an error when any of the files could not be downloaded after all retries.
+func (p *Prefetcher) Prefetch(ctx context.Context, paths []string) error {
+	p.mutex.Lock()
+	p.progress = PrefetchProgress{TotalFiles: len(paths)}
+	p.mutex.Unlock()
+
+	var errs uint32
+	var wg sync.WaitGroup
+	queue := make(chan string)
+
+	wg.Add(p.opts.Concurrency)
+	for i := 0; i < p.opts.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for pth := range queue {
+				err := p.fetchWithRetries(ctx, pth)
+				atomic.AddUint32(&errs, noteError(err))
+				p.reportDone(err != nil)
+			}
+		}()
+	}
+
+	for _, pth := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- pth
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if errs != 0 {
+		return fmt.Errorf("%d errors while prefetching", errs)
+	}
+	return nil
+}
+
+// Prune removes all files of the destination archive, including partial
+// downloads, except the files in keep. It bounds the disk usage of a local
+// archive used as a download cache. Only local file archives are supported.
+func (p *Prefetcher) Prune(keep []string) error {
+	fs, ok := p.dst.backend.(*FsArchiveBackend)
+	if !ok {
+		return errors.New("only local file archives can be pruned")
+	}
+
+	kept := map[string]bool{}
+	for _, pth := range keep {
+		kept[filepath.Join(fs.prefix, pth)] = true
+	}
+
+	return filepath.Walk(filepath.Clean(fs.prefix), func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || kept[strings.TrimSuffix(pth, ".part")] {
+			return nil
+		}
+		return os.Remove(pth)
+	})
+}
+
+// Progress returns the progress of the current (or last) Prefetch call.
+func (p *Prefetcher) Progress() PrefetchProgress {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.progress
+}
+
+func (p *Prefetcher) reportDone(failed bool) {
+	p.mutex.Lock()
+	p.progress.DoneFiles++
+	if failed {
+		p.progress.FailedFiles++
+	}
+	progress := p.progress
+	p.mutex.Unlock()
+
+	if p.opts.Progress != nil {
+		p.opts.Progress(progress)
+	}
+}
+
+func (p *Prefetcher) addBytes(n int64) {
+	p.mutex.Lock()
+	p.progress.BytesDownloaded += n
+	p.mutex.Unlock()
+}
+
+func (p *Prefetcher) fetchWithRetries(ctx context.Context, pth string) error {
+	if !p.opts.Force {
+		exists, err := p.dst.backend.Exists(pth)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	backoff := p.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := p.fetch(pth)
+		if err == nil || attempt >= p.opts.MaxRetries {
+			return errors.Wrapf(err, "error downloading %s", pth)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// partPath returns the local path where pth is downloaded to. Files
+// downloaded into a local file archive are stored next to their final
+// location so they can be renamed once complete.
+func (p *Prefetcher) partPath(pth string) (string, bool) {
+	if fs, ok := p.dst.backend.(*FsArchiveBackend); ok {
+		return filepath.Join(fs.prefix, pth) + ".part", true
+	}
+	return filepath.Join(p.opts.TempDir, strings.Replace(pth, "/", "_", -1)+".part"), false
+}
+
+func (p *Prefetcher) fetch(pth string) error {
+	partPath, local := p.partPath(pth)
+	if err := os.MkdirAll(filepath.Dir(partPath), 0755); err != nil {
+		return err
+	}
+
+	if err := p.download(pth, partPath); err != nil {
+		return err
+	}
+
+	if m := bucketPathRx.FindStringSubmatch(pth); m != nil && p.opts.VerifyBuckets {
+		if err := verifyBucketFile(partPath, MustDecodeHash(m[1])); err != nil {
+			// Start from scratch next time.
+			os.Remove(partPath)
+			return err
+		}
+	}
+
+	if local {
+		return os.Rename(partPath, strings.TrimSuffix(partPath, ".part"))
+	}
+
+	file, err := os.Open(partPath)
+	if err != nil {
+		return err
+	}
+	err = p.dst.backend.PutFile(pth, file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(partPath)
+}
+
+// download appends the missing part of pth to partPath.
+func (p *Prefetcher) download(pth string, partPath string) error {
+	out, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	var rdr io.ReadCloser
+	if rangeBackend, ok := p.src.backend.(rangeArchiveBackend); ok {
+		rdr, offset, err = rangeBackend.GetFileFrom(pth, offset)
+	} else {
+		rdr, err = p.src.backend.GetFile(pth)
+		offset = 0
+	}
+	if err != nil {
+		return err
+	}
+	defer rdr.Close()
+
+	if err = out.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(out, rdr)
+	p.addBytes(n)
+	return err
+}
+
+func verifyBucketFile(pth string, expect Hash) error {
+	file, err := os.Open(pth)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rdr, err := gzip.NewReader(bufReadCloser(file))
+	if err != nil {
+		return err
+	}
+	defer rdr.Close()
+
+	hsh := sha256.New()
+	if _, err = io.Copy(hsh, rdr); err != nil {
+		return err
+	}
+	return checkBucketHash(hsh, expect)
+}
@@ -0,0 +1,187 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addRandomGzBucket adds a gzipped bucket with random contents and returns
+// its hash and the size of the compressed file.
+func (arch *Archive) addRandomGzBucket(t *testing.T) (Hash, int64) {
+	buf := make([]byte, 64*1024)
+	_, err := rand.Read(buf)
+	require.NoError(t, err)
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, err = w.Write(buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	h := Hash(sha256.Sum256(buf))
+	size := int64(gz.Len())
+	require.NoError(t, arch.backend.PutFile(BucketPath(h), ioutil.NopCloser(&gz)))
+	return h, size
+}
+
+func makePrefetchTestArchives(t *testing.T) (*Archive, *Archive, HistoryArchiveState, func()) {
+	srcDir, err := ioutil.TempDir("", "prefetch-src")
+	require.NoError(t, err)
+	dstDir, err := ioutil.TempDir("", "prefetch-dst")
+	require.NoError(t, err)
+
+	local := MustConnect("file://"+srcDir, ConnectOptions{})
+	has := HistoryArchiveState{CurrentLedger: 127}
+	for i := 0; i < 3; i++ {
+		curr, _ := local.addRandomGzBucket(t)
+		snap, _ := local.addRandomGzBucket(t)
+		has.CurrentBuckets[i].Curr = curr.String()
+		has.CurrentBuckets[i].Snap = snap.String()
+	}
+	require.NoError(t, local.PutCheckpointHAS(127, has, &CommandOptions{}))
+	require.NoError(t, local.AddRandomCheckpointFile("ledger", 127))
+
+	server := httptest.NewServer(http.FileServer(http.Dir(srcDir)))
+	src := MustConnect(server.URL, ConnectOptions{})
+	dst := MustConnect("file://"+dstDir, ConnectOptions{})
+
+	return src, dst, has, func() {
+		server.Close()
+		os.RemoveAll(srcDir)
+		os.RemoveAll(dstDir)
+	}
+}
+
+func TestPrefetchCheckpoint(t *testing.T) {
+	src, dst, has, done := makePrefetchTestArchives(t)
+	defer done()
+
+	var reports int
+	prefetcher := NewPrefetcher(src, dst, PrefetchOptions{
+		Concurrency:   4,
+		VerifyBuckets: true,
+		Progress: func(p PrefetchProgress) {
+			reports++
+		},
+	})
+	require.NoError(t, prefetcher.PrefetchCheckpoint(context.Background(), 127, "ledger"))
+
+	buckets, err := has.Buckets()
+	require.NoError(t, err)
+	for _, bucket := range buckets {
+		assert.NoError(t, dst.VerifyBucketHash(bucket))
+	}
+	exists, err := dst.CategoryCheckpointExists("ledger", 127)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	dstHAS, err := dst.GetCheckpointHAS(127)
+	require.NoError(t, err)
+	assert.Equal(t, has, dstHAS)
+
+	progress := prefetcher.Progress()
+	assert.Equal(t, len(buckets)+1, progress.TotalFiles)
+	assert.Equal(t, progress.TotalFiles, progress.DoneFiles)
+	assert.Equal(t, 0, progress.FailedFiles)
+	assert.Equal(t, progress.TotalFiles, reports)
+}
+
+func TestPrefetchResumesPartialDownload(t *testing.T) {
+	src, dst, has, done := makePrefetchTestArchives(t)
+	defer done()
+
+	bucket := MustDecodeHash(has.CurrentBuckets[0].Curr)
+	pth := BucketPath(bucket)
+	size, err := src.backend.Size(pth)
+	require.NoError(t, err)
+
+	// Leave the first half of the bucket as a partial download.
+	rdr, err := src.backend.GetFile(pth)
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(rdr)
+	require.NoError(t, err)
+	rdr.Close()
+	partPath := filepath.Join(dst.backend.(*FsArchiveBackend).prefix, pth) + ".part"
+	require.NoError(t, os.MkdirAll(filepath.Dir(partPath), 0755))
+	require.NoError(t, ioutil.WriteFile(partPath, contents[:size/2], 0644))
+
+	prefetcher := NewPrefetcher(src, dst, PrefetchOptions{VerifyBuckets: true})
+	require.NoError(t, prefetcher.Prefetch(context.Background(), []string{pth}))
+
+	assert.Equal(t, size-size/2, prefetcher.Progress().BytesDownloaded)
+	assert.NoError(t, dst.VerifyBucketHash(bucket))
+	_, err = os.Stat(partPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPrefetchRetriesAndFails(t *testing.T) {
+	src, dst, _, done := makePrefetchTestArchives(t)
+	defer done()
+
+	var missing Hash
+	prefetcher := NewPrefetcher(src, dst, PrefetchOptions{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	err := prefetcher.Prefetch(context.Background(), []string{BucketPath(missing)})
+	assert.EqualError(t, err, "1 errors while prefetching")
+	assert.Equal(t, 1, prefetcher.Progress().FailedFiles)
+}
+
+func TestPrefetchPrune(t *testing.T) {
+	src, dst, has, done := makePrefetchTestArchives(t)
+	defer done()
+
+	prefetcher := NewPrefetcher(src, dst, PrefetchOptions{})
+	require.NoError(t, prefetcher.PrefetchCheckpoint(context.Background(), 127))
+	buckets, err := has.Buckets()
+	require.NoError(t, err)
+
+	// Keep the first bucket and a partial download of it.
+	kept := BucketPath(buckets[0])
+	prefix := dst.backend.(*FsArchiveBackend).prefix
+	partPath := filepath.Join(prefix, kept) + ".part"
+	require.NoError(t, ioutil.WriteFile(partPath, []byte("partial"), 0644))
+
+	require.NoError(t, prefetcher.Prune([]string{kept}))
+	for _, bucket := range buckets {
+		exists, err := dst.BucketExists(bucket)
+		require.NoError(t, err)
+		assert.Equal(t, bucket == buckets[0], exists)
+	}
+	exists, err := dst.CategoryCheckpointExists("history", 127)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = os.Stat(partPath)
+	assert.NoError(t, err)
+
+	require.NoError(t, prefetcher.Prune(nil))
+	var files []string
+	require.NoError(t, filepath.Walk(prefix, func(pth string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, pth)
+		}
+		return err
+	}))
+	assert.Empty(t, files)
+
+	// Only local file archives can be pruned.
+	assert.Error(t, NewPrefetcher(dst, src, PrefetchOptions{}).Prune(nil))
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// LedgerSource is the subset of ledgerbackend.LedgerBackend used by the
+// publisher. Any LedgerBackend satisfies it.
+type LedgerSource interface {
+	// The first returned value is false when the ledger does not exist in a backend.
+	GetLedger(sequence uint32) (bool, xdr.LedgerCloseMeta, error)
+}
+
+// PublishOptions configures how checkpoints are written by Publish.
+type PublishOptions struct {
+	CommandOptions
+	// NetworkPassphrase is written into every HAS file. When empty the
+	// archive's network passphrase is used.
+	NetworkPassphrase string
+	// Server is written into the `server` field of every HAS file.
+	Server string
+}
+
+// checkpointFiles holds the XDR streams of a single checkpoint while it is
+// being assembled.
+type checkpointFiles struct {
+	ledgers      []xdr.LedgerHeaderHistoryEntry
+	transactions []xdr.TransactionHistoryEntry
+	results      []xdr.TransactionHistoryResultEntry
+	scp          []xdr.ScpHistoryEntry
+}
+
+// Publish writes the ledger, transactions, results and scp checkpoint files,
+// together with the checkpoint HAS files, for every checkpoint in
+// opts.Range. Ledger data is read from source. The root HAS is moved forward
+// when the last published checkpoint is newer than the current one.
+//
+// The bucket list cannot be derived from ledger meta so the published HAS
+// files reference empty buckets only. Archives created this way contain
+// full ledger history but cannot be used to catch up from buckets.
+func (arch *Archive) Publish(source LedgerSource, opts *PublishOptions) error {
+	if opts.NetworkPassphrase == "" {
+		opts.NetworkPassphrase = arch.networkPassphrase
+	}
+
+	opts.Range = MakeRange(opts.Range.Low, opts.Range.High)
+	log.Printf("publishing range %s\n", opts.Range)
+
+	var last uint32
+	for i := uint64(opts.Range.Low); i <= uint64(opts.Range.High); i += uint64(CheckpointFreq) {
+		chk := uint32(i)
+		if err := arch.PublishCheckpoint(source, chk, opts); err != nil {
+			return errors.Wrapf(err, "error publishing checkpoint 0x%8.8x", chk)
+		}
+		last = chk
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+
+	root, err := arch.GetRootHAS()
+	if err == nil && root.CurrentLedger >= last {
+		log.Printf("leaving archive current-ledger pointer at 0x%8.8x",
+			root.CurrentLedger)
+		return nil
+	}
+
+	log.Printf("updating archive current-ledger pointer to 0x%8.8x", last)
+	return arch.PutRootHAS(arch.publishedHAS(last, opts), &opts.CommandOptions)
+}
+
+// PublishCheckpoint writes the checkpoint files and the checkpoint HAS for
+// the checkpoint ending at ledger chk. Ledger 1 (genesis) is skipped when
+// source does not contain it, every other ledger in the checkpoint must be
+// available.
+func (arch *Archive) PublishCheckpoint(source LedgerSource, chk uint32, opts *PublishOptions) error {
+	if !IsCheckpoint(chk) {
+		return errors.Errorf("ledger %d is not a checkpoint ledger", chk)
+	}
+
+	low := chk + 1 - CheckpointFreq
+	if low == 0 {
+		low = 1
+	}
+
+	var files checkpointFiles
+	for seq := low; seq <= chk; seq++ {
+		exists, meta, err := source.GetLedger(seq)
+		if err != nil {
+			return errors.Wrapf(err, "error getting ledger %d", seq)
+		}
+		if !exists {
+			if seq == 1 {
+				continue
+			}
+			return errors.Errorf("ledger %d not found in ledger source", seq)
+		}
+		if err := files.add(meta); err != nil {
+			return errors.Wrapf(err, "error processing ledger %d", seq)
+		}
+	}
+
+	if opts.DryRun {
+		log.Printf("dryrun skipping checkpoint 0x%8.8x", chk)
+		return nil
+	}
+
+	streams := []struct {
+		category string
+		entries  []interface{}
+	}{
+		{"ledger", files.ledgerEntries()},
+		{"transactions", files.transactionEntries()},
+		{"results", files.resultEntries()},
+		{"scp", files.scpEntries()},
+	}
+	for _, s := range streams {
+		if len(s.entries) == 0 && !categoryRequired(s.category) {
+			continue
+		}
+		err := arch.putXdrStream(CategoryCheckpointPath(s.category, chk), s.entries, &opts.CommandOptions)
+		if err != nil {
+			return errors.Wrapf(err, "error writing %s file", s.category)
+		}
+	}
+
+	return arch.PutCheckpointHAS(chk, arch.publishedHAS(chk, opts), &opts.CommandOptions)
+}
+
+func (arch *Archive) publishedHAS(chk uint32, opts *PublishOptions) HistoryArchiveState {
+	has := HistoryArchiveState{
+		Version:           1,
+		Server:            opts.Server,
+		CurrentLedger:     chk,
+		NetworkPassphrase: opts.NetworkPassphrase,
+	}
+	zero := Hash{}.String()
+	for i := range has.CurrentBuckets {
+		has.CurrentBuckets[i].Curr = zero
+		has.CurrentBuckets[i].Snap = zero
+	}
+	return has
+}
+
+// putXdrStream writes entries as a gzipped stream of framed XDR values,
+// the format read by XdrStream.
+func (arch *Archive) putXdrStream(pth string, entries []interface{}, opts *CommandOptions) error {
+	exists, err := arch.backend.Exists(pth)
+	if err != nil {
+		return err
+	}
+	if exists && !opts.Force {
+		log.Printf("skipping existing " + pth)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	for _, entry := range entries {
+		if err = xdr.MarshalFramed(zw, entry); err != nil {
+			return err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	return arch.backend.PutFile(pth, ioutil.NopCloser(&buf))
+}
+
+func (f *checkpointFiles) add(meta xdr.LedgerCloseMeta) error {
+	v0, ok := meta.GetV0()
+	if !ok {
+		return fmt.Errorf("unsupported LedgerCloseMeta version: %d", meta.V)
+	}
+
+	seq := v0.LedgerHeader.Header.LedgerSeq
+	if n := len(f.ledgers); n > 0 && f.ledgers[n-1].Header.LedgerSeq+1 != seq {
+		return fmt.Errorf("ledger %d does not follow ledger %d",
+			seq, f.ledgers[n-1].Header.LedgerSeq)
+	}
+	f.ledgers = append(f.ledgers, v0.LedgerHeader)
+
+	// Like hcnet-core, transactions and results entries are only written
+	// for ledgers with a non-empty transaction set.
+	if len(v0.TxSet.Txs) > 0 {
+		f.transactions = append(f.transactions, xdr.TransactionHistoryEntry{
+			LedgerSeq: seq,
+			TxSet:     v0.TxSet,
+		})
+
+		results := make([]xdr.TransactionResultPair, len(v0.TxProcessing))
+		for i, tx := range v0.TxProcessing {
+			results[i] = tx.Result
+		}
+		f.results = append(f.results, xdr.TransactionHistoryResultEntry{
+			LedgerSeq:   seq,
+			TxResultSet: xdr.TransactionResultSet{Results: results},
+		})
+	}
+
+	f.scp = append(f.scp, v0.ScpInfo...)
+	return nil
+}
+
+func (f *checkpointFiles) ledgerEntries() []interface{} {
+	entries := make([]interface{}, len(f.ledgers))
+	for i := range f.ledgers {
+		entries[i] = f.ledgers[i]
+	}
+	return entries
+}
+
+func (f *checkpointFiles) transactionEntries() []interface{} {
+	entries := make([]interface{}, len(f.transactions))
+	for i := range f.transactions {
+		entries[i] = f.transactions[i]
+	}
+	return entries
+}
+
+func (f *checkpointFiles) resultEntries() []interface{} {
+	entries := make([]interface{}, len(f.results))
+	for i := range f.results {
+		entries[i] = f.results[i]
+	}
+	return entries
+}
+
+func (f *checkpointFiles) scpEntries() []interface{} {
+	entries := make([]interface{}, len(f.scp))
+	for i := range f.scp {
+		entries[i] = f.scp[i]
+	}
+	return entries
+}
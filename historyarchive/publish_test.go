@@ -0,0 +1,135 @@
+// Copyright 2016 Hcnet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hcnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLedgerSource map[uint32]xdr.LedgerCloseMeta
+
+func (s testLedgerSource) GetLedger(sequence uint32) (bool, xdr.LedgerCloseMeta, error) {
+	meta, ok := s[sequence]
+	return ok, meta, nil
+}
+
+// makeTestLedgerChain builds a hash-linked chain of ledgers [2, last]. Every
+// 10th ledger contains a single transaction.
+func makeTestLedgerChain(t *testing.T, last uint32) testLedgerSource {
+	source := testLedgerSource{}
+	var prev xdr.Hash
+	for seq := uint32(2); seq <= last; seq++ {
+		txSet := xdr.TransactionSet{PreviousLedgerHash: prev}
+		var processing []xdr.TransactionResultMeta
+		if seq%10 == 0 {
+			txSet.Txs = []xdr.TransactionEnvelope{{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: xdr.MustMuxedAddress("GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A"),
+						Fee:           100,
+						SeqNum:        xdr.SequenceNumber(seq),
+						Operations: []xdr.Operation{{
+							Body: xdr.OperationBody{
+								Type:           xdr.OperationTypeBumpSequence,
+								BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 1},
+							},
+						}},
+					},
+				},
+			}}
+			processing = []xdr.TransactionResultMeta{{
+				Result: xdr.TransactionResultPair{
+					TransactionHash: sha256.Sum256([]byte{byte(seq)}),
+					Result: xdr.TransactionResult{
+						FeeCharged: 100,
+						Result: xdr.TransactionResultResult{
+							Code: xdr.TransactionResultCodeTxBadSeq,
+						},
+					},
+				},
+			}}
+		}
+
+		txSetHash := HashEmptyTxSet(Hash(prev))
+		if len(txSet.Txs) > 0 {
+			var err error
+			txSetHash, err = HashTxSet(&txSet)
+			require.NoError(t, err)
+		}
+		results := make([]xdr.TransactionResultPair, len(processing))
+		for i := range processing {
+			results[i] = processing[i].Result
+		}
+		resultsHash, err := HashXdr(&xdr.TransactionResultSet{Results: results})
+		require.NoError(t, err)
+
+		header := xdr.LedgerHeader{
+			LedgerSeq:          xdr.Uint32(seq),
+			PreviousLedgerHash: prev,
+			ScpValue:           xdr.HcnetValue{TxSetHash: xdr.Hash(txSetHash)},
+			TxSetResultHash:    xdr.Hash(resultsHash),
+		}
+		headerHash, err := HashXdr(&header)
+		require.NoError(t, err)
+
+		source[seq] = xdr.LedgerCloseMeta{
+			V: 0,
+			V0: &xdr.LedgerCloseMetaV0{
+				LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+					Hash:   xdr.Hash(headerHash),
+					Header: header,
+				},
+				TxSet:        txSet,
+				TxProcessing: processing,
+			},
+		}
+		prev = xdr.Hash(headerHash)
+	}
+	return source
+}
+
+func TestPublishVerifies(t *testing.T) {
+	defer cleanup()
+	arch := GetTestArchive()
+	source := makeTestLedgerChain(t, 191)
+
+	opts := &PublishOptions{
+		CommandOptions:    CommandOptions{Range: Range{Low: 63, High: 191}},
+		NetworkPassphrase: "test network",
+	}
+	require.NoError(t, arch.Publish(source, opts))
+
+	root, err := arch.GetRootHAS()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(191), root.CurrentLedger)
+	assert.Equal(t, "test network", root.NetworkPassphrase)
+
+	header, err := arch.GetLedgerHeader(100)
+	require.NoError(t, err)
+	assert.Equal(t, source[100].V0.LedgerHeader, header)
+
+	scanOpts := &CommandOptions{Range: root.Range(), Concurrency: 4, Verify: true}
+	require.NoError(t, arch.Scan(scanOpts))
+	assert.NoError(t, arch.ReportMissing(scanOpts))
+	assert.NoError(t, arch.ReportInvalid(scanOpts))
+}
+
+func TestPublishMissingLedger(t *testing.T) {
+	arch := GetTestMockArchive()
+	source := makeTestLedgerChain(t, 127)
+	delete(source, 70)
+
+	err := arch.PublishCheckpoint(source, 127, &PublishOptions{})
+	assert.EqualError(t, err, "ledger 70 not found in ledger source")
+
+	err = arch.PublishCheckpoint(source, 100, &PublishOptions{})
+	assert.EqualError(t, err, "ledger 100 is not a checkpoint ledger")
+}
@@ -2,6 +2,8 @@ package adapters
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 
 	"github.com/hcnet/go/historyarchive"
 	"github.com/hcnet/go/ingest/io"
@@ -25,6 +27,104 @@ func MakeHistoryArchiveAdapter(archive historyarchive.ArchiveInterface) HistoryA
 	return &HistoryArchiveAdapter{archive: archive}
 }
 
+// PrefetchingHistoryArchiveAdapter is a HistoryArchiveAdapter that downloads
+// all buckets of a checkpoint in parallel to a local directory before
+// streaming the state from the local copy.
+type PrefetchingHistoryArchiveAdapter struct {
+	HistoryArchiveAdapter
+	local      *historyarchive.Archive
+	prefetcher *historyarchive.Prefetcher
+}
+
+// PrefetchSubdir is the directory, created in the directory passed to
+// MakePrefetchingHistoryArchiveAdapter, where files are downloaded.
+const PrefetchSubdir = "history-archive-prefetch"
+
+// MakePrefetchingHistoryArchiveAdapter is a factory method to make a
+// PrefetchingHistoryArchiveAdapter. Files are downloaded to the
+// PrefetchSubdir directory of dir, which is owned by the adapter: other files
+// of dir are never touched. Files of the checkpoint being prefetched are kept
+// between runs so interrupted downloads are resumed, all other files are
+// removed, and the directory is emptied once the state reader is closed.
+func MakePrefetchingHistoryArchiveAdapter(
+	archive *historyarchive.Archive,
+	dir string,
+	networkPassphrase string,
+	opts historyarchive.PrefetchOptions,
+) (HistoryArchiveAdapterInterface, error) {
+	dir = filepath.Join(dir, PrefetchSubdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "error creating prefetch directory")
+	}
+
+	local, err := historyarchive.Connect(
+		"file://"+dir,
+		historyarchive.ConnectOptions{NetworkPassphrase: networkPassphrase},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error connecting to local archive")
+	}
+
+	opts.VerifyBuckets = true
+	return &PrefetchingHistoryArchiveAdapter{
+		HistoryArchiveAdapter: HistoryArchiveAdapter{archive: archive},
+		local:                 local,
+		prefetcher:            historyarchive.NewPrefetcher(archive, local, opts),
+	}, nil
+}
+
+// GetState downloads the buckets of the checkpoint and returns a reader
+// with the state of the ledger at the provided sequence number.
+func (pha *PrefetchingHistoryArchiveAdapter) GetState(ctx context.Context, sequence uint32) (io.ChangeReader, error) {
+	exists, err := pha.archive.CategoryCheckpointExists("history", sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "error checking if category checkpoint exists")
+	}
+	if !exists {
+		return nil, errors.Errorf("history checkpoint does not exist for ledger %d", sequence)
+	}
+
+	has, err := pha.archive.GetCheckpointHAS(sequence)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get checkpoint HAS at ledger sequence %d", sequence)
+	}
+	paths, err := historyarchive.CheckpointPaths(has)
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, historyarchive.CategoryCheckpointPath("history", sequence))
+	// Remove files of other checkpoints left by previous runs.
+	if err = pha.prefetcher.Prune(paths); err != nil {
+		return nil, errors.Wrap(err, "could not prune prefetch directory")
+	}
+
+	if err = pha.prefetcher.PrefetchCheckpoint(ctx, sequence); err != nil {
+		return nil, errors.Wrap(err, "could not prefetch checkpoint")
+	}
+
+	sr, e := io.MakeSingleLedgerStateReader(ctx, pha.local, sequence)
+	if e != nil {
+		return nil, errors.Wrap(e, "could not make memory state reader")
+	}
+
+	return &prefetchedStateReader{ChangeReader: sr, prefetcher: pha.prefetcher}, nil
+}
+
+// prefetchedStateReader removes the prefetched files once the state has
+// been read.
+type prefetchedStateReader struct {
+	io.ChangeReader
+	prefetcher *historyarchive.Prefetcher
+}
+
+// Close closes the state reader and removes all prefetched files.
+func (r *prefetchedStateReader) Close() error {
+	if err := r.ChangeReader.Close(); err != nil {
+		return err
+	}
+	return errors.Wrap(r.prefetcher.Prune(nil), "could not remove prefetched files")
+}
+
 // GetLatestLedgerSequence returns the latest ledger sequence or an error
 func (haa *HistoryArchiveAdapter) GetLatestLedgerSequence() (uint32, error) {
 	has, err := haa.archive.GetRootHAS()
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	stdio "io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hcnet/go/historyarchive"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetState_Read(t *testing.T) {
@@ -34,6 +38,49 @@ func TestGetState_Read(t *testing.T) {
 	assert.Equal(t, "GAFBQT4VRORLEVEECUYDQGWNVQ563ZN76LGRJR7T7KDL32EES54UOQST", lec.Post.Data.Account.AccountId.Address())
 }
 
+func TestPrefetchingGetStateKeepsOtherFiles(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "prefetch-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+	dir, err := ioutil.TempDir("", "prefetch-dir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// A checkpoint with an empty bucket list.
+	src := historyarchive.MustConnect("file://"+srcDir, historyarchive.ConnectOptions{})
+	has := historyarchive.HistoryArchiveState{CurrentLedger: 63}
+	for i := range has.CurrentBuckets {
+		has.CurrentBuckets[i].Curr = historyarchive.Hash{}.String()
+		has.CurrentBuckets[i].Snap = historyarchive.Hash{}.String()
+	}
+	require.NoError(t, src.PutCheckpointHAS(63, has, nil))
+
+	// Files of the operator, including one looking like an archive file.
+	unrelated := []string{
+		filepath.Join(dir, "notes.txt"),
+		filepath.Join(dir, historyarchive.CategoryCheckpointPath("history", 127)),
+	}
+	for _, pth := range unrelated {
+		require.NoError(t, os.MkdirAll(filepath.Dir(pth), 0755))
+		require.NoError(t, ioutil.WriteFile(pth, []byte("keep"), 0644))
+	}
+
+	haa, err := MakePrefetchingHistoryArchiveAdapter(src, dir, "", historyarchive.PrefetchOptions{})
+	require.NoError(t, err)
+	sr, err := haa.GetState(context.Background(), 63)
+	require.NoError(t, err)
+	for _, pth := range unrelated {
+		assert.FileExists(t, pth)
+	}
+
+	_, err = sr.Read()
+	assert.Equal(t, stdio.EOF, err)
+	require.NoError(t, sr.Close())
+	for _, pth := range unrelated {
+		assert.FileExists(t, pth)
+	}
+}
+
 func getTestArchive() (*historyarchive.Archive, error) {
 	return historyarchive.Connect(
 		fmt.Sprintf("s3://history.hcnet.org/prd/core-live/core_live_001/"),
@@ -0,0 +1,218 @@
+package io
+
+import (
+	"bytes"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// StateDiffFilter limits the entries compared by DiffStates. Empty fields
+// do not filter anything. An entry must match every non-empty field.
+type StateDiffFilter struct {
+	// EntryTypes is a list of ledger entry types to compare.
+	EntryTypes []xdr.LedgerEntryType
+	// Accounts is a list of accounts. Account, trust line, offer and data
+	// entries match when they are owned by one of the accounts, claimable
+	// balances match when one of the accounts is a claimant.
+	Accounts []xdr.AccountId
+	// Assets is a list of assets. Trust lines and claimable balances match
+	// when they hold one of the assets, offers match when they buy or sell
+	// one of the assets. Accounts match the native asset.
+	Assets []xdr.Asset
+}
+
+// Matches returns true if the entry passes the filter.
+func (f StateDiffFilter) Matches(entry xdr.LedgerEntry) bool {
+	if len(f.EntryTypes) > 0 {
+		found := false
+		for _, t := range f.EntryTypes {
+			if t == entry.Data.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Accounts) > 0 && !f.matchesAccount(entry) {
+		return false
+	}
+
+	if len(f.Assets) > 0 && !f.matchesAsset(entry) {
+		return false
+	}
+
+	return true
+}
+
+func (f StateDiffFilter) matchesAccount(entry xdr.LedgerEntry) bool {
+	var accounts []xdr.AccountId
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		accounts = append(accounts, entry.Data.MustAccount().AccountId)
+	case xdr.LedgerEntryTypeTrustline:
+		accounts = append(accounts, entry.Data.MustTrustLine().AccountId)
+	case xdr.LedgerEntryTypeOffer:
+		accounts = append(accounts, entry.Data.MustOffer().SellerId)
+	case xdr.LedgerEntryTypeData:
+		accounts = append(accounts, entry.Data.MustData().AccountId)
+	case xdr.LedgerEntryTypeClaimableBalance:
+		for _, claimant := range entry.Data.MustClaimableBalance().Claimants {
+			accounts = append(accounts, claimant.MustV0().Destination)
+		}
+	}
+
+	for _, account := range accounts {
+		for _, filtered := range f.Accounts {
+			if account.Equals(filtered) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f StateDiffFilter) matchesAsset(entry xdr.LedgerEntry) bool {
+	var assets []xdr.Asset
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		assets = append(assets, xdr.MustNewNativeAsset())
+	case xdr.LedgerEntryTypeTrustline:
+		assets = append(assets, entry.Data.MustTrustLine().Asset)
+	case xdr.LedgerEntryTypeOffer:
+		offer := entry.Data.MustOffer()
+		assets = append(assets, offer.Selling, offer.Buying)
+	case xdr.LedgerEntryTypeClaimableBalance:
+		assets = append(assets, entry.Data.MustClaimableBalance().Asset)
+	}
+
+	for _, asset := range assets {
+		for _, filtered := range f.Assets {
+			if asset.Equals(filtered) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DiffStates compares the ledger state read from `from` with the state read
+// from `to` (usually two SingleLedgerStateReaders at different checkpoints)
+// and sends the differences to processor in ledger key order:
+//
+// - entries only present in `to` are sent as created (Pre is nil),
+// - entries only present in `from` are sent as removed (Post is nil),
+// - entries present in both but with different contents are sent as updated.
+//
+// State readers don't return entries in ledger key order so the entries
+// matching filter are first sorted in runs of bounded size, written to
+// temporary files, and both sorted states are then merged and compared
+// entry by entry.
+func DiffStates(
+	from, to ChangeReader,
+	filter StateDiffFilter,
+	processor ChangeProcessor,
+) error {
+	sorter, err := newStateSorter()
+	if err != nil {
+		return err
+	}
+	defer sorter.Close()
+
+	fromState, err := sorter.sort(from, filter)
+	if err != nil {
+		return errors.Wrap(err, "error reading `from` state")
+	}
+	toState, err := sorter.sort(to, filter)
+	if err != nil {
+		return errors.Wrap(err, "error reading `to` state")
+	}
+
+	pre, inFrom, err := nextStateRecord(fromState)
+	if err != nil {
+		return errors.Wrap(err, "error reading `from` state")
+	}
+	post, inTo, err := nextStateRecord(toState)
+	if err != nil {
+		return errors.Wrap(err, "error reading `to` state")
+	}
+
+	for inFrom || inTo {
+		// Keys are raw XDR so this orders entries by type first.
+		var order int
+		switch {
+		case !inTo:
+			order = -1
+		case !inFrom:
+			order = 1
+		default:
+			order = bytes.Compare(pre.key, post.key)
+		}
+
+		var change Change
+		switch {
+		case order == 0 && bytes.Equal(pre.entry, post.entry):
+		case order == 0:
+			if change.Pre, err = unmarshalEntry(pre.entry); err != nil {
+				return err
+			}
+			if change.Post, err = unmarshalEntry(post.entry); err != nil {
+				return err
+			}
+			change.Type = change.Post.Data.Type
+		case order < 0:
+			if change.Pre, err = unmarshalEntry(pre.entry); err != nil {
+				return err
+			}
+			change.Type = change.Pre.Data.Type
+		default:
+			if change.Post, err = unmarshalEntry(post.entry); err != nil {
+				return err
+			}
+			change.Type = change.Post.Data.Type
+		}
+
+		if change.Pre != nil || change.Post != nil {
+			if err = processor.ProcessChange(change); err != nil {
+				return errors.Wrap(err, "could not process change")
+			}
+		}
+
+		if order <= 0 {
+			if pre, inFrom, err = nextStateRecord(fromState); err != nil {
+				return errors.Wrap(err, "error reading `from` state")
+			}
+		}
+		if order >= 0 {
+			if post, inTo, err = nextStateRecord(toState); err != nil {
+				return errors.Wrap(err, "error reading `to` state")
+			}
+		}
+	}
+
+	return nil
+}
+
+// nextStateRecord returns the next record of state and whether there was
+// one.
+func nextStateRecord(state stateIterator) (stateRecord, bool, error) {
+	record, err := state.next()
+	if err == EOF {
+		return stateRecord{}, false, nil
+	}
+	if err != nil {
+		return stateRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+func unmarshalEntry(raw []byte) (*xdr.LedgerEntry, error) {
+	var entry xdr.LedgerEntry
+	if err := entry.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal ledger entry")
+	}
+	return &entry, nil
+}
@@ -0,0 +1,229 @@
+package io
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sliceChangeReader struct {
+	changes []Change
+}
+
+func (r *sliceChangeReader) Read() (Change, error) {
+	if len(r.changes) == 0 {
+		return Change{}, EOF
+	}
+	change := r.changes[0]
+	r.changes = r.changes[1:]
+	return change, nil
+}
+
+func (r *sliceChangeReader) Close() error {
+	return nil
+}
+
+type collectingChangeProcessor struct {
+	changes []Change
+}
+
+func (p *collectingChangeProcessor) ProcessChange(change Change) error {
+	p.changes = append(p.changes, change)
+	return nil
+}
+
+func accountStateEntry(address string, balance xdr.Int64) Change {
+	return Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: xdr.MustAddress(address),
+					Balance:   balance,
+				},
+			},
+		},
+	}
+}
+
+func trustLineStateEntry(address string, asset xdr.Asset, balance xdr.Int64) Change {
+	return Change{
+		Type: xdr.LedgerEntryTypeTrustline,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTrustline,
+				TrustLine: &xdr.TrustLineEntry{
+					AccountId: xdr.MustAddress(address),
+					Asset:     asset,
+					Balance:   balance,
+				},
+			},
+		},
+	}
+}
+
+const (
+	diffAccountA = "GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A"
+	diffAccountB = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	diffAccountC = "GCCD6AJOYZCUAQLX32ZJF2MKFFAUJ53PVCFQI3RHWKL3V47QYE2BNAUT"
+)
+
+func TestDiffStates(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", diffAccountC)
+	from := &sliceChangeReader{changes: []Change{
+		accountStateEntry(diffAccountA, 100),
+		accountStateEntry(diffAccountB, 200),
+		trustLineStateEntry(diffAccountA, usd, 10),
+	}}
+	to := &sliceChangeReader{changes: []Change{
+		trustLineStateEntry(diffAccountA, usd, 20),
+		accountStateEntry(diffAccountA, 100),
+		accountStateEntry(diffAccountC, 300),
+	}}
+
+	processor := &collectingChangeProcessor{}
+	require.NoError(t, DiffStates(from, to, StateDiffFilter{}, processor))
+	require.Len(t, processor.changes, 3)
+
+	// Accounts are sorted before trust lines.
+	for _, change := range processor.changes[:2] {
+		assert.Equal(t, xdr.LedgerEntryTypeAccount, change.Type)
+	}
+	var created, removed int
+	for _, change := range processor.changes[:2] {
+		switch change.LedgerEntryChangeType() {
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			created++
+			assert.Equal(t, diffAccountC, change.Post.Data.Account.AccountId.Address())
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			removed++
+			assert.Equal(t, diffAccountB, change.Pre.Data.Account.AccountId.Address())
+		}
+	}
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 1, removed)
+
+	updated := processor.changes[2]
+	assert.Equal(t, xdr.LedgerEntryChangeTypeLedgerEntryUpdated, updated.LedgerEntryChangeType())
+	assert.Equal(t, xdr.Int64(10), updated.Pre.Data.MustTrustLine().Balance)
+	assert.Equal(t, xdr.Int64(20), updated.Post.Data.MustTrustLine().Balance)
+}
+
+func diffAccount(i byte) string {
+	var key xdr.Uint256
+	key[0] = i
+	account := xdr.AccountId{Type: xdr.PublicKeyTypePublicKeyTypeEd25519, Ed25519: &key}
+	return account.Address()
+}
+
+func TestDiffStatesKeyOrder(t *testing.T) {
+	defer func(size int) { stateSortRunSize = size }(stateSortRunSize)
+	// Spill runs to disk.
+	stateSortRunSize = 3
+
+	var fromChanges, toChanges []Change
+	for i := byte(0); i < 20; i++ {
+		switch i % 4 {
+		case 0: // removed
+			fromChanges = append(fromChanges, accountStateEntry(diffAccount(i), 1))
+		case 1: // created
+			toChanges = append(toChanges, accountStateEntry(diffAccount(i), 1))
+		case 2: // updated
+			fromChanges = append(fromChanges, accountStateEntry(diffAccount(i), 1))
+			toChanges = append(toChanges, accountStateEntry(diffAccount(i), 2))
+		case 3: // unchanged
+			fromChanges = append(fromChanges, accountStateEntry(diffAccount(i), 1))
+			toChanges = append(toChanges, accountStateEntry(diffAccount(i), 1))
+		}
+	}
+
+	check := func(t *testing.T, from, to []Change) {
+		processor := &collectingChangeProcessor{}
+		require.NoError(
+			t,
+			DiffStates(&sliceChangeReader{changes: from}, &sliceChangeReader{changes: to}, StateDiffFilter{}, processor),
+		)
+		require.Len(t, processor.changes, 15)
+
+		i := byte(0)
+		for _, change := range processor.changes {
+			if i%4 == 3 {
+				i++
+			}
+			var expected xdr.LedgerEntryChangeType
+			switch i % 4 {
+			case 0:
+				expected = xdr.LedgerEntryChangeTypeLedgerEntryRemoved
+			case 1:
+				expected = xdr.LedgerEntryChangeTypeLedgerEntryCreated
+			case 2:
+				expected = xdr.LedgerEntryChangeTypeLedgerEntryUpdated
+			}
+			assert.Equal(t, expected, change.LedgerEntryChangeType(), "account %d", i)
+
+			entry := change.Post
+			if entry == nil {
+				entry = change.Pre
+			}
+			assert.Equal(t, diffAccount(i), entry.Data.Account.AccountId.Address())
+			i++
+		}
+	}
+
+	t.Run("key ordered readers", func(t *testing.T) {
+		check(t, fromChanges, toChanges)
+	})
+	t.Run("unordered readers", func(t *testing.T) {
+		reverse := func(changes []Change) []Change {
+			var reversed []Change
+			for i := len(changes) - 1; i >= 0; i-- {
+				reversed = append(reversed, changes[i])
+			}
+			return reversed
+		}
+		check(t, reverse(fromChanges), reverse(toChanges))
+	})
+}
+
+func TestDiffStatesFilter(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", diffAccountC)
+	eur := xdr.MustNewCreditAsset("EUR", diffAccountC)
+
+	for _, testCase := range []struct {
+		name     string
+		filter   StateDiffFilter
+		expected int
+	}{
+		{"no filter", StateDiffFilter{}, 4},
+		{"entry type", StateDiffFilter{EntryTypes: []xdr.LedgerEntryType{xdr.LedgerEntryTypeTrustline}}, 2},
+		{"account", StateDiffFilter{Accounts: []xdr.AccountId{xdr.MustAddress(diffAccountB)}}, 2},
+		{"asset", StateDiffFilter{Assets: []xdr.Asset{eur}}, 1},
+		{"native asset", StateDiffFilter{Assets: []xdr.Asset{xdr.MustNewNativeAsset()}}, 2},
+		{
+			"account and asset",
+			StateDiffFilter{
+				Accounts: []xdr.AccountId{xdr.MustAddress(diffAccountA)},
+				Assets:   []xdr.Asset{usd},
+			},
+			1,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			from := &sliceChangeReader{}
+			to := &sliceChangeReader{changes: []Change{
+				accountStateEntry(diffAccountA, 100),
+				accountStateEntry(diffAccountB, 200),
+				trustLineStateEntry(diffAccountA, usd, 10),
+				trustLineStateEntry(diffAccountB, eur, 10),
+			}}
+
+			processor := &StatsChangeProcessor{}
+			require.NoError(t, DiffStates(from, to, testCase.filter, processor))
+			results := processor.GetResults()
+			assert.Equal(t, int64(testCase.expected), results.AccountsCreated+results.TrustLinesCreated)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// stateSortRunSize is the maximum number of entries sorted in memory by
+// stateSorter. Larger states are sorted in runs written to temporary files and
+// merged when read.
+var stateSortRunSize = 500000
+
+// stateRecord is a ledger entry and its ledger key, both XDR encoded.
+type stateRecord struct {
+	key   []byte
+	entry []byte
+}
+
+// stateIterator returns state records in ledger key order. next returns
+// io.EOF when there are no more records.
+type stateIterator interface {
+	next() (stateRecord, error)
+}
+
+// memoryRun iterates over a sorted slice of records.
+type memoryRun struct {
+	records []stateRecord
+}
+
+func (r *memoryRun) next() (stateRecord, error) {
+	if len(r.records) == 0 {
+		return stateRecord{}, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
+
+// fileRun iterates over the records of a run written by writeRun.
+type fileRun struct {
+	reader *bufio.Reader
+}
+
+func (r *fileRun) next() (stateRecord, error) {
+	key, err := readRunField(r.reader)
+	if err == io.EOF {
+		return stateRecord{}, io.EOF
+	}
+	if err != nil {
+		return stateRecord{}, errors.Wrap(err, "could not read sorted run")
+	}
+	entry, err := readRunField(r.reader)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return stateRecord{}, errors.Wrap(err, "could not read sorted run")
+	}
+	return stateRecord{key: key, entry: entry}, nil
+}
+
+func readRunField(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	field := make([]byte, length)
+	if _, err = io.ReadFull(reader, field); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+// stateSorter sorts states, writing the runs of large states to files of a
+// temporary directory which is removed by Close.
+type stateSorter struct {
+	dir   string
+	files []*os.File
+}
+
+func newStateSorter() (*stateSorter, error) {
+	dir, err := ioutil.TempDir("", "state-sort")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create temporary directory")
+	}
+	return &stateSorter{dir: dir}, nil
+}
+
+// Close closes and removes the files of all runs.
+func (s *stateSorter) Close() error {
+	for _, file := range s.files {
+		file.Close()
+	}
+	s.files = nil
+	return os.RemoveAll(s.dir)
+}
+
+// writeRun writes sorted records to a new file and returns an iterator
+// reading them back.
+func (s *stateSorter) writeRun(records []stateRecord) (*fileRun, error) {
+	file, err := ioutil.TempFile(s.dir, "run")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create sorted run")
+	}
+	s.files = append(s.files, file)
+
+	writer := bufio.NewWriter(file)
+	var length [binary.MaxVarintLen64]byte
+	for _, record := range records {
+		for _, field := range [][]byte{record.key, record.entry} {
+			n := binary.PutUvarint(length[:], uint64(len(field)))
+			if _, err = writer.Write(length[:n]); err != nil {
+				break
+			}
+			if _, err = writer.Write(field); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not write sorted run")
+	}
+	return &fileRun{reader: bufio.NewReader(file)}, nil
+}
+
+// runMerger merges sorted runs into a single stateIterator.
+type runMerger struct {
+	runs  []stateIterator
+	heads []stateRecord
+	// order is a heap of the indexes of the runs which are not exhausted.
+	order []int
+}
+
+func newRunMerger(runs []stateIterator) (*runMerger, error) {
+	m := &runMerger{runs: runs, heads: make([]stateRecord, len(runs))}
+	for i, run := range runs {
+		record, err := run.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.heads[i] = record
+		m.order = append(m.order, i)
+	}
+	heap.Init(m)
+	return m, nil
+}
+
+func (m *runMerger) Len() int { return len(m.order) }
+
+func (m *runMerger) Less(i, j int) bool {
+	return bytes.Compare(m.heads[m.order[i]].key, m.heads[m.order[j]].key) < 0
+}
+
+func (m *runMerger) Swap(i, j int) { m.order[i], m.order[j] = m.order[j], m.order[i] }
+
+func (m *runMerger) Push(x interface{}) { m.order = append(m.order, x.(int)) }
+
+func (m *runMerger) Pop() interface{} {
+	last := m.order[len(m.order)-1]
+	m.order = m.order[:len(m.order)-1]
+	return last
+}
+
+func (m *runMerger) next() (stateRecord, error) {
+	if len(m.order) == 0 {
+		return stateRecord{}, io.EOF
+	}
+	run := m.order[0]
+	record := m.heads[run]
+
+	head, err := m.runs[run].next()
+	switch {
+	case err == io.EOF:
+		heap.Pop(m)
+	case err != nil:
+		return stateRecord{}, err
+	default:
+		m.heads[run] = head
+		heap.Fix(m, 0)
+	}
+	return record, nil
+}
+
+// sort reads the entries of reader matching filter and returns them in ledger
+// key order. At most stateSortRunSize entries are kept in memory, larger
+// states are sorted in runs written to temporary files.
+func (s *stateSorter) sort(reader ChangeReader, filter StateDiffFilter) (stateIterator, error) {
+	var (
+		runs    []stateIterator
+		records []stateRecord
+	)
+	sortRecords := func() {
+		sort.Slice(records, func(i, j int) bool {
+			return bytes.Compare(records[i].key, records[j].key) < 0
+		})
+	}
+
+	for {
+		change, err := reader.Read()
+		if err == EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read change")
+		}
+		if change.Post == nil {
+			return nil, errors.New("state reader returned a change without post entry")
+		}
+		if !filter.Matches(*change.Post) {
+			continue
+		}
+
+		key, err := change.Post.LedgerKey().MarshalBinary()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal ledger key")
+		}
+		entry, err := change.Post.MarshalBinary()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal ledger entry")
+		}
+		records = append(records, stateRecord{key: key, entry: entry})
+
+		if len(records) >= stateSortRunSize {
+			sortRecords()
+			run, err := s.writeRun(records)
+			if err != nil {
+				return nil, err
+			}
+			runs = append(runs, run)
+			records = nil
+		}
+	}
+
+	sortRecords()
+	if len(runs) == 0 {
+		return &memoryRun{records: records}, nil
+	}
+	if len(records) > 0 {
+		runs = append(runs, &memoryRun{records: records})
+	}
+	return newRunMerger(runs)
+}
@@ -0,0 +1,28 @@
+/*
+Package pkcs11signer implements keypair.Signer with ed25519 keys held by a
+PKCS#11 token, for example a hardware security module or SoftHSM.
+
+The token must support the CKM_EDDSA mechanism defined by PKCS#11 v3.0.
+PKCS#11 modules are loaded with cgo, when cgo is disabled New and
+GenerateKey return ErrUnsupported.
+*/
+package pkcs11signer
+
+import "github.com/hcnet/go/support/errors"
+
+// ErrUnsupported is returned when the package is built without cgo, which is
+// required to load PKCS#11 modules.
+var ErrUnsupported = errors.New("PKCS#11 is not supported by this build, it requires cgo")
+
+// Config configures the token and key used by a Signer.
+type Config struct {
+	// Module is the path of the PKCS#11 library, for example
+	// /usr/lib/softhsm/libsofthsm2.so.
+	Module string
+	// TokenLabel is the label of the token holding the key.
+	TokenLabel string
+	// PIN is the user PIN of the token.
+	PIN string
+	// KeyLabel is the label (CKA_LABEL) of the ed25519 key pair.
+	KeyLabel string
+}
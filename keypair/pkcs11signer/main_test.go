@@ -0,0 +1,81 @@
+package pkcs11signer
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// testConfig returns the configuration of the test token, see README.md.
+func testConfig(t *testing.T) Config {
+	module := os.Getenv("PKCS11_MODULE")
+	if module == "" {
+		t.Skip("PKCS11_MODULE not set, skipping PKCS#11 tests")
+	}
+	return Config{
+		Module:     module,
+		TokenLabel: os.Getenv("PKCS11_TOKEN_LABEL"),
+		PIN:        os.Getenv("PKCS11_PIN"),
+		KeyLabel:   fmt.Sprintf("test-%d", time.Now().UnixNano()),
+	}
+}
+
+func TestSigner(t *testing.T) {
+	config := testConfig(t)
+
+	address, err := GenerateKey(config)
+	require.NoError(t, err)
+
+	signer, err := New(config)
+	require.NoError(t, err)
+	defer signer.Close()
+
+	assert.Equal(t, address, signer.Address())
+	kp, err := keypair.ParseAddress(address)
+	require.NoError(t, err)
+	assert.Equal(t, kp.Hint(), signer.Hint())
+
+	message := []byte("hello")
+	sig, err := signer.Sign(message)
+	require.NoError(t, err)
+	assert.NoError(t, kp.Verify(message, sig))
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: address, Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 10}},
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	tx, err = tx.SignWith(network.TestNetworkPassphrase, signer)
+	require.NoError(t, err)
+
+	hash, err := tx.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	require.Len(t, tx.Signatures(), 1)
+	assert.NoError(t, kp.Verify(hash[:], tx.Signatures()[0].Signature))
+}
+
+func TestNewUnknownKey(t *testing.T) {
+	config := testConfig(t)
+
+	_, err := New(config)
+	assert.EqualError(t, err, fmt.Sprintf("ed25519 key %q not found", config.KeyLabel))
+}
+
+func TestNewUnknownToken(t *testing.T) {
+	config := testConfig(t)
+	config.TokenLabel = "unknown token"
+
+	_, err := New(config)
+	assert.EqualError(t, err, `token "unknown token" not found`)
+}
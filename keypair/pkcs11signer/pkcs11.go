@@ -0,0 +1,266 @@
+//go:build cgo
+// +build cgo
+
+package pkcs11signer
+
+import (
+	"sync"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+)
+
+// PKCS#11 v3.0 constants missing from github.com/miekg/pkcs11.
+const (
+	ckkECEdwards            = 0x00000040
+	ckmECEdwardsKeyPairGen  = 0x00001055
+	ckmEdDSA                = 0x00001057
+	ed25519PublicKeyLength  = 32
+	derOctetStringTag       = 0x04
+	derObjectIdentifierTag  = 0x06
+	ed25519ObjectIdentifier = "\x2b\x65\x70" // 1.3.101.112
+)
+
+// Signer signs with an ed25519 private key which never leaves the PKCS#11
+// token. It's safe for concurrent use.
+type Signer struct {
+	ctx        *pkcs11.Ctx
+	session    pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	address    string
+	hint       [4]byte
+
+	mutex sync.Mutex
+}
+
+var _ keypair.Signer = (*Signer)(nil)
+
+// New opens a session on the token and finds the key pair labeled
+// config.KeyLabel. The signer must be closed with Close.
+func New(config Config) (*Signer, error) {
+	ctx, session, err := open(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Signer{ctx: ctx, session: session}
+	if err = s.load(config.KeyLabel); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// GenerateKey generates an ed25519 key pair labeled config.KeyLabel on the
+// token and returns its address. The private key is not extractable.
+func GenerateKey(config Config) (string, error) {
+	ctx, session, err := open(config)
+	if err != nil {
+		return "", err
+	}
+	defer closeSession(ctx, session)
+
+	ecParams := []byte{derObjectIdentifierTag, byte(len(ed25519ObjectIdentifier))}
+	ecParams = append(ecParams, ed25519ObjectIdentifier...)
+	public := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, config.KeyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+	}
+	private := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, config.KeyLabel),
+	}
+	publicKey, _, err := ctx.GenerateKeyPair(
+		session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(ckmECEdwardsKeyPairGen, nil)},
+		public,
+		private,
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "error generating key pair")
+	}
+	raw, err := publicKeyBytes(ctx, session, publicKey)
+	if err != nil {
+		return "", err
+	}
+	return strkey.Encode(strkey.VersionByteAccountID, raw)
+}
+
+// Address returns the G... address of the key.
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// Hint returns the signature hint of the key.
+func (s *Signer) Hint() [4]byte {
+	return s.hint
+}
+
+// Sign signs input on the token.
+func (s *Signer) Sign(input []byte) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}, s.privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error initializing signature")
+	}
+	sig, err := s.ctx.Sign(s.session, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing")
+	}
+	return sig, nil
+}
+
+// Close closes the session and unloads the PKCS#11 module.
+func (s *Signer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return closeSession(s.ctx, s.session)
+}
+
+func (s *Signer) load(label string) error {
+	privateKey, err := findObject(s.ctx, s.session, pkcs11.CKO_PRIVATE_KEY, label)
+	if err != nil {
+		return err
+	}
+	publicKey, err := findObject(s.ctx, s.session, pkcs11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return err
+	}
+	raw, err := publicKeyBytes(s.ctx, s.session, publicKey)
+	if err != nil {
+		return err
+	}
+
+	s.privateKey = privateKey
+	s.address, err = strkey.Encode(strkey.VersionByteAccountID, raw)
+	if err != nil {
+		return errors.Wrap(err, "error encoding public key")
+	}
+	copy(s.hint[:], raw[len(raw)-4:])
+	return nil
+}
+
+func open(config Config) (*pkcs11.Ctx, pkcs11.SessionHandle, error) {
+	ctx := pkcs11.New(config.Module)
+	if ctx == nil {
+		return nil, 0, errors.Errorf("error loading PKCS#11 module %s", config.Module)
+	}
+	if err := ctx.Initialize(); err != nil && !isError(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, 0, errors.Wrap(err, "error initializing PKCS#11 module")
+	}
+
+	slot, err := findSlot(ctx, config.TokenLabel)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, errors.Wrap(err, "error opening session")
+	}
+	err = ctx.Login(session, pkcs11.CKU_USER, config.PIN)
+	if err != nil && !isError(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		ctx.CloseSession(session)
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, 0, errors.Wrap(err, "error logging in")
+	}
+	return ctx, session, nil
+}
+
+func closeSession(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+	if ctx == nil {
+		return nil
+	}
+	ctx.Logout(session)
+	err := ctx.CloseSession(session)
+	ctx.Finalize()
+	ctx.Destroy()
+	return errors.Wrap(err, "error closing session")
+}
+
+func findSlot(ctx *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.Wrap(err, "error listing slots")
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, errors.Wrap(err, "error getting token info")
+		}
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+	return 0, errors.Errorf("token %q not found", label)
+}
+
+func findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, ckkECEdwards),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, errors.Wrap(err, "error finding key")
+	}
+	objects, _, err := ctx.FindObjects(session, 2)
+	if finalErr := ctx.FindObjectsFinal(session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "error finding key")
+	}
+	switch len(objects) {
+	case 0:
+		return 0, errors.Errorf("ed25519 key %q not found", label)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, errors.Errorf("several ed25519 keys labeled %q", label)
+	}
+}
+
+// publicKeyBytes returns the raw ed25519 public key. Tokens return
+// CKA_EC_POINT either raw or DER encoded as an octet string.
+func publicKeyBytes(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, publicKey pkcs11.ObjectHandle) ([]byte, error) {
+	attributes, err := ctx.GetAttributeValue(session, publicKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting public key")
+	}
+	if len(attributes) != 1 {
+		return nil, errors.New("public key has no CKA_EC_POINT")
+	}
+	point := attributes[0].Value
+	if len(point) == ed25519PublicKeyLength+2 &&
+		point[0] == derOctetStringTag && point[1] == ed25519PublicKeyLength {
+		point = point[2:]
+	}
+	if len(point) != ed25519PublicKeyLength {
+		return nil, errors.Errorf("unexpected public key length %d", len(point))
+	}
+	return point, nil
+}
+
+func isError(err error, code uint) bool {
+	pkcs11Err, ok := err.(pkcs11.Error)
+	return ok && uint(pkcs11Err) == code
+}
@@ -0,0 +1,42 @@
+//go:build !cgo
+// +build !cgo
+
+package pkcs11signer
+
+import "github.com/hcnet/go/keypair"
+
+// Signer is a placeholder for the PKCS#11 signer of cgo builds, it can't be
+// created.
+type Signer struct{}
+
+var _ keypair.Signer = (*Signer)(nil)
+
+// New returns ErrUnsupported.
+func New(config Config) (*Signer, error) {
+	return nil, ErrUnsupported
+}
+
+// GenerateKey returns ErrUnsupported.
+func GenerateKey(config Config) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Address returns an empty address.
+func (s *Signer) Address() string {
+	return ""
+}
+
+// Hint returns an empty hint.
+func (s *Signer) Hint() [4]byte {
+	return [4]byte{}
+}
+
+// Sign returns ErrUnsupported.
+func (s *Signer) Sign(input []byte) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+// Close does nothing.
+func (s *Signer) Close() error {
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !cgo
+// +build !cgo
+
+package pkcs11signer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsupportedWithoutCgo(t *testing.T) {
+	signer, err := New(Config{Module: "/usr/lib/softhsm/libsofthsm2.so"})
+	assert.Nil(t, signer)
+	assert.Equal(t, ErrUnsupported, err)
+
+	_, err = GenerateKey(Config{})
+	assert.Equal(t, ErrUnsupported, err)
+}
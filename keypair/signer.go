@@ -0,0 +1,30 @@
+package keypair
+
+import (
+	"github.com/hcnet/go/xdr"
+)
+
+// Signer is implemented by keys able to sign data, whether the secret key is
+// held in memory (Full), by a hardware security module or by a remote
+// service.
+type Signer interface {
+	Address() string
+	Hint() [4]byte
+	Sign(input []byte) ([]byte, error)
+}
+
+var _ Signer = (*Full)(nil)
+
+// SignDecorated signs input with signer and decorates the signature with the
+// hint of the signer.
+func SignDecorated(signer Signer, input []byte) (xdr.DecoratedSignature, error) {
+	sig, err := signer.Sign(input)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(signer.Hint()),
+		Signature: xdr.Signature(sig),
+	}, nil
+}
@@ -0,0 +1,21 @@
+package keypair
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair.SignDecorated()", func() {
+	It("decorates the signature with the hint of the signer", func() {
+		kp := MustParseFull(seed)
+		sig, err := SignDecorated(kp, []byte("hello"))
+		Expect(err).To(BeNil())
+		Expect([4]byte(sig.Hint)).To(Equal(kp.Hint()))
+		Expect(kp.Verify([]byte("hello"), sig.Signature)).To(BeNil())
+	})
+
+	It("returns errors of the signer", func() {
+		_, err := SignDecorated(MustParseAddress(address), []byte("hello"))
+		Expect(err).To(Equal(ErrCannotSign))
+	})
+})
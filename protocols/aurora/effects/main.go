@@ -235,6 +235,8 @@ type Base struct {
 	ID              string    `json:"id"`
 	PT              string    `json:"paging_token"`
 	Account         string    `json:"account"`
+	AccountMuxed    string    `json:"account_muxed,omitempty"`
+	AccountMuxedID  string    `json:"account_muxed_id,omitempty"`
 	Type            string    `json:"type"`
 	TypeI           int32     `json:"type_i"`
 	LedgerCloseTime time.Time `json:"created_at"`
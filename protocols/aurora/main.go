@@ -165,10 +165,61 @@ type AssetStat struct {
 	} `json:"_links"`
 
 	base.Asset
-	PT          string       `json:"paging_token"`
-	Amount      string       `json:"amount"`
-	NumAccounts int32        `json:"num_accounts"`
-	Flags       AccountFlags `json:"flags"`
+	PT                      string               `json:"paging_token"`
+	Accounts                AssetStatAccounts    `json:"accounts"`
+	NumClaimableBalances    int32                `json:"num_claimable_balances"`
+	Balances                AssetStatBalances    `json:"balances"`
+	ClaimableBalancesAmount string               `json:"claimable_balances_amount"`
+	Liabilities             AssetStatLiabilities `json:"liabilities"`
+	Amount                  string               `json:"amount"`
+	NumAccounts             int32                `json:"num_accounts"`
+	Flags                   AccountFlags         `json:"flags"`
+}
+
+// AssetHolder represents a trustline of an asset in the list of its holders
+type AssetHolder struct {
+	Links struct {
+		Account hal.Link `json:"account"`
+	} `json:"_links"`
+
+	PT                                string `json:"paging_token"`
+	AccountID                         string `json:"account_id"`
+	Balance                           string `json:"balance"`
+	Limit                             string `json:"limit"`
+	BuyingLiabilities                 string `json:"buying_liabilities"`
+	SellingLiabilities                string `json:"selling_liabilities"`
+	IsAuthorized                      bool   `json:"is_authorized"`
+	IsAuthorizedToMaintainLiabilities bool   `json:"is_authorized_to_maintain_liabilities"`
+	LastModifiedLedger                uint32 `json:"last_modified_ledger"`
+	Sponsor                           string `json:"sponsor,omitempty"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AssetHolder) PagingToken() string {
+	return res.PT
+}
+
+// AssetStatAccounts represents the number of trustlines of an asset in each
+// authorization state.
+type AssetStatAccounts struct {
+	Authorized                      int32 `json:"authorized"`
+	AuthorizedToMaintainLiabilities int32 `json:"authorized_to_maintain_liabilities"`
+	Unauthorized                    int32 `json:"unauthorized"`
+}
+
+// AssetStatBalances represents the amounts of an asset held by trustlines in
+// each authorization state.
+type AssetStatBalances struct {
+	Authorized                      string `json:"authorized"`
+	AuthorizedToMaintainLiabilities string `json:"authorized_to_maintain_liabilities"`
+	Unauthorized                    string `json:"unauthorized"`
+}
+
+// AssetStatLiabilities represents the total buying and selling liabilities of
+// the trustlines of an asset, that is the amounts of the asset in offers.
+type AssetStatLiabilities struct {
+	Buying  string `json:"buying"`
+	Selling string `json:"selling"`
 }
 
 // PagingToken implementation for hal.Pageable
@@ -318,6 +369,19 @@ type Root struct {
 	NetworkPassphrase            string `json:"network_passphrase"`
 	CurrentProtocolVersion       int32  `json:"current_protocol_version"`
 	CoreSupportedProtocolVersion int32  `json:"core_supported_protocol_version"`
+
+	HistoryIngestion HistoryIngestion `json:"history_ingestion"`
+}
+
+// HistoryIngestion describes the history ingested by a Aurora instance.
+type HistoryIngestion struct {
+	// Processors lists the history processors run during ingestion. The
+	// endpoints of other processors are not available.
+	Processors []string `json:"processors"`
+	// Accounts and Assets are set when only the history of transactions with
+	// one of Accounts as participant or involving one of Assets is ingested.
+	Accounts []string `json:"accounts,omitempty"`
+	Assets   []string `json:"assets,omitempty"`
 }
 
 // Signer represents one of an account's signers.
@@ -431,8 +495,12 @@ type Transaction struct {
 	Ledger             int32               `json:"ledger"`
 	LedgerCloseTime    time.Time           `json:"created_at"`
 	Account            string              `json:"source_account"`
+	AccountMuxed       string              `json:"source_account_muxed,omitempty"`
+	AccountMuxedID     string              `json:"source_account_muxed_id,omitempty"`
 	AccountSequence    string              `json:"source_account_sequence"`
 	FeeAccount         string              `json:"fee_account"`
+	FeeAccountMuxed    string              `json:"fee_account_muxed,omitempty"`
+	FeeAccountMuxedID  string              `json:"fee_account_muxed_id,omitempty"`
 	FeeCharged         int64               `json:"fee_charged,string"`
 	MaxFee             int64               `json:"max_fee,string"`
 	OperationCount     int32               `json:"operation_count"`
@@ -672,10 +740,7 @@ type ClaimableBalance struct {
 }
 
 type ClaimableBalances struct {
-	Links struct {
-		Self hal.Link `json:"self"`
-	} `json:"_links"`
-
+	Links    hal.Links `json:"_links"`
 	Embedded struct {
 		Records []ClaimableBalance `json:"records"`
 	} `json:"_embedded"`
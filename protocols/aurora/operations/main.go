@@ -51,6 +51,8 @@ type Base struct {
 	// successful transaction.
 	TransactionSuccessful bool      `json:"transaction_successful"`
 	SourceAccount         string    `json:"source_account"`
+	SourceAccountMuxed    string    `json:"source_account_muxed,omitempty"`
+	SourceAccountMuxedID  string    `json:"source_account_muxed_id,omitempty"`
 	Type                  string    `json:"type"`
 	TypeI                 int32     `json:"type_i"`
 	LedgerCloseTime       time.Time `json:"created_at"`
@@ -90,9 +92,13 @@ type CreateAccount struct {
 type Payment struct {
 	Base
 	base.Asset
-	From   string `json:"from"`
-	To     string `json:"to"`
-	Amount string `json:"amount"`
+	From        string `json:"from"`
+	FromMuxed   string `json:"from_muxed,omitempty"`
+	FromMuxedID string `json:"from_muxed_id,omitempty"`
+	To          string `json:"to"`
+	ToMuxed     string `json:"to_muxed,omitempty"`
+	ToMuxedID   string `json:"to_muxed_id,omitempty"`
+	Amount      string `json:"amount"`
 }
 
 // PathPayment is the json resource representing a single operation whose type
@@ -207,8 +213,12 @@ type AllowTrust struct {
 // is AccountMerge.
 type AccountMerge struct {
 	Base
-	Account string `json:"account"`
-	Into    string `json:"into"`
+	Account        string `json:"account"`
+	AccountMuxed   string `json:"account_muxed,omitempty"`
+	AccountMuxedID string `json:"account_muxed_id,omitempty"`
+	Into           string `json:"into"`
+	IntoMuxed      string `json:"into_muxed,omitempty"`
+	IntoMuxedID    string `json:"into_muxed_id,omitempty"`
 }
 
 // Inflation is the json resource representing a single operation whose type is
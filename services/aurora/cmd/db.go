@@ -12,7 +12,9 @@ import (
 	"github.com/spf13/viper"
 
 	aurora "github.com/hcnet/go/services/aurora/internal"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/services/aurora/internal/export"
 	"github.com/hcnet/go/services/aurora/internal/ingest"
 	support "github.com/hcnet/go/support/config"
 	"github.com/hcnet/go/support/db"
@@ -107,6 +109,195 @@ var dbMigrateCmd = &cobra.Command{
 	},
 }
 
+var checkSchemaExpectedDBURL string
+
+var dbCheckSchemaCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "expected-db-url",
+		ConfigKey:   &checkSchemaExpectedDBURL,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage: "[optional] empty postgres database which is migrated to build the expected schema, " +
+			"a temporary database is created on the server of --db-url when it's not set",
+	},
+}
+
+var dbCheckSchemaCmd = &cobra.Command{
+	Use:   "check-schema",
+	Short: "checks the schema of the db",
+	Long: "check-schema compares the tables, columns, indexes, types and functions of the db with " +
+		"the schema created by the migrations of this version of aurora and reports any drift, " +
+		"including unapplied migrations and invalid indexes left by failed concurrent index builds. " +
+		"It exits with a non-zero status when drift is found.",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range dbCheckSchemaCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+		dbURLConfigOption.Require()
+		dbURLConfigOption.SetValue()
+
+		drift, err := schema.CheckSchema(viper.GetString("db-url"), checkSchemaExpectedDBURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(drift) == 0 {
+			log.Println("Schema matches the expected schema.")
+			return
+		}
+		for _, d := range drift {
+			log.Println(d)
+		}
+		log.Fatalf("Found %d differences with the expected schema.", len(drift))
+	},
+}
+
+var (
+	exportOutputDir      string
+	exportFormat         string
+	exportTables         string
+	exportLedgersPerFile uint32
+)
+
+var dbExportCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "output-dir",
+		ConfigKey:   &exportOutputDir,
+		OptType:     types.String,
+		Required:    true,
+		FlagDefault: "",
+		Usage:       "directory the exported files are written to, one subdirectory per table",
+	},
+	{
+		Name:        "format",
+		ConfigKey:   &exportFormat,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: string(export.ParquetFormat),
+		Usage:       "[optional] format of the exported files, parquet or csv",
+	},
+	{
+		Name:        "tables",
+		ConfigKey:   &exportTables,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "ledgers,transactions,operations,effects,trades",
+		Usage:       "[optional] comma-separated list of exported history tables",
+	},
+	{
+		Name:        "ledgers-per-file",
+		ConfigKey:   &exportLedgersPerFile,
+		OptType:     types.Uint32,
+		Required:    false,
+		FlagDefault: uint32(10000),
+		Usage:       "[optional] size of the ledger ranges the exported files are partitioned by",
+	},
+}
+
+var dbExportCmd = &cobra.Command{
+	Use:   "export [Start sequence number] [End sequence number]",
+	Short: "exports history tables to parquet or csv files",
+	Long: "export writes the history of the ledgers between X and Y sequence number (closed " +
+		"interval) to one file per table and range of --ledgers-per-file ledgers. Files " +
+		"which already exist are skipped, so an interrupted export is resumed by running " +
+		"the same command again.",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, co := range dbExportCmdOpts {
+			co.Require()
+			co.SetValue()
+		}
+		dbURLConfigOption.Require()
+		dbURLConfigOption.SetValue()
+
+		if len(args) != 2 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		argsUint32 := make([]uint32, 2)
+		for i, arg := range args {
+			seq, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				cmd.Usage()
+				log.Fatalf(`Invalid sequence number "%s"`, arg)
+			}
+			argsUint32[i] = uint32(seq)
+		}
+
+		format, err := export.ParseFormat(exportFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tables, err := export.ParseTables(exportTables)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		auroraSession, err := db.Open("postgres", viper.GetString("db-url"))
+		if err != nil {
+			log.Fatalf("cannot open Aurora DB: %v", err)
+		}
+
+		exporter := &export.Exporter{
+			HistoryQ:       &history.Q{auroraSession},
+			Dir:            exportOutputDir,
+			Format:         format,
+			LedgersPerFile: exportLedgersPerFile,
+		}
+		for _, table := range tables {
+			if err = exporter.Export(table, argsUint32[0], argsUint32[1]); err != nil {
+				log.Fatal(err)
+			}
+		}
+		hlog.Info("Export completed successfully!")
+	},
+}
+
+// defaultLedgersPerPartition is about a week of ledgers.
+const defaultLedgersPerPartition = 120960
+
+var dbPartitionCmd = &cobra.Command{
+	Use:   "partition [LEDGERS_PER_PARTITION]",
+	Short: "partitions history tables by ledger range",
+	Long: "partition converts the history tables into tables partitioned by ranges of " +
+		"LEDGERS_PER_PARTITION ledgers (120960 by default, about a week). The existing " +
+		"tables are kept as legacy partitions without copying data. Once partitioned, " +
+		"ingestion creates partitions ahead of time and the reaper drops whole partitions " +
+		"instead of deleting rows. Requires PostgreSQL 11 or later, ingestion must be stopped.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			cmd.Usage()
+			os.Exit(1)
+		}
+
+		size := uint64(defaultLedgersPerPartition)
+		if len(args) == 1 {
+			var err error
+			size, err = strconv.ParseUint(args[0], 10, 32)
+			if err != nil || size == 0 {
+				log.Println("LEDGERS_PER_PARTITION must be a positive integer")
+				cmd.Usage()
+				os.Exit(1)
+			}
+		}
+
+		dbURLConfigOption.Require()
+		dbURLConfigOption.SetValue()
+
+		db, err := sql.Open("postgres", viper.GetString("db-url"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err = schema.EnablePartitioning(db, uint32(size)); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("History tables partitioned by %d ledgers.\n", size)
+	},
+}
+
 var dbReapCmd = &cobra.Command{
 	Use:   "reap",
 	Short: "reaps (i.e. removes) any reapable history data",
@@ -227,6 +418,7 @@ var dbReingestRangeCmd = &cobra.Command{
 			EnableCaptiveCore:           config.EnableCaptiveCoreIngestion,
 			HcnetCoreBinaryPath:       config.HcnetCoreBinaryPath,
 			RemoteCaptiveCoreURL:        config.RemoteCaptiveCoreURL,
+			History:                     config.History,
 		}
 
 		if !ingestConfig.EnableCaptiveCore {
@@ -286,6 +478,22 @@ var dbReingestRangeCmd = &cobra.Command{
 }
 
 func init() {
+	for _, co := range dbExportCmdOpts {
+		err := co.Init(dbExportCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	viper.BindPFlags(dbExportCmd.PersistentFlags())
+
+	for _, co := range dbCheckSchemaCmdOpts {
+		err := co.Init(dbCheckSchemaCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	viper.BindPFlags(dbCheckSchemaCmd.PersistentFlags())
+
 	for _, co := range reingestRangeCmdOpts {
 		err := co.Init(dbReingestRangeCmd)
 		if err != nil {
@@ -297,8 +505,11 @@ func init() {
 
 	rootCmd.AddCommand(dbCmd)
 	dbCmd.AddCommand(
+		dbCheckSchemaCmd,
+		dbExportCmd,
 		dbInitCmd,
 		dbMigrateCmd,
+		dbPartitionCmd,
 		dbReapCmd,
 		dbReingestCmd,
 	)
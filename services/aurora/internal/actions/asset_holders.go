@@ -0,0 +1,223 @@
+package actions
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/services/aurora/internal/resourceadapter"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+)
+
+// assetHoldersCSVBatchSize is the number of trust lines loaded at once when
+// exporting the holders of an asset.
+const assetHoldersCSVBatchSize = 1000
+
+var assetHoldersCSVHeader = []string{
+	"account_id",
+	"balance",
+	"limit",
+	"buying_liabilities",
+	"selling_liabilities",
+	"is_authorized",
+	"is_authorized_to_maintain_liabilities",
+	"last_modified_ledger",
+	"sponsor",
+}
+
+// AssetHoldersQuery query struct for assets/{asset}/holders end-point
+type AssetHoldersQuery struct {
+	AssetParam string `schema:"asset" valid:"asset"`
+	AtLedger   uint32 `schema:"at_ledger" valid:"-"`
+}
+
+// Validate rejects the native asset which has no trust lines.
+func (q AssetHoldersQuery) Validate() error {
+	if strings.ToLower(q.AssetParam) == "native" {
+		return problem.MakeInvalidFieldProblem(
+			"asset",
+			errors.New("native asset has no holders, use a string of the form \"Code:IssuerAccountID\""),
+		)
+	}
+	return nil
+}
+
+// Asset returns the xdr.Asset from the request query
+func (q AssetHoldersQuery) Asset() xdr.Asset {
+	parts := strings.Split(q.AssetParam, ":")
+	return xdr.MustNewCreditAsset(parts[0], parts[1])
+}
+
+// GetAssetHoldersHandler is the action handler for the assets/{asset}/holders
+// end-point and for the CSV export of the holders of an asset.
+type GetAssetHoldersHandler struct{}
+
+// GetResourcePage returns a page of the holders of an asset.
+func (handler GetAssetHoldersHandler) GetResourcePage(
+	w HeaderWriter,
+	r *http.Request,
+) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	qp, historyQ, err := loadAssetHoldersQuery(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pq, err := GetPageQuery(r, DisableCursorValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	if pq.Cursor != "" {
+		if _, _, err = history.ParseAssetHoldersCursor(pq.Cursor); err != nil {
+			return nil, problem.MakeInvalidFieldProblem(
+				"cursor",
+				errors.New("The first part should be a non-negative balance and the second part should be a valid account ID"),
+			)
+		}
+	}
+
+	trustLines, err := historyQ.GetAssetHolders(qp.Asset(), pq)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []hal.Pageable
+	for _, record := range trustLines {
+		var holder aurora.AssetHolder
+		resourceadapter.PopulateAssetHolder(ctx, &holder, record)
+		response = append(response, holder)
+	}
+
+	return response, nil
+}
+
+// WriteRawResponse writes all the holders of an asset as CSV, ordered by
+// balance in descending order. The request is checked and the first holders
+// are loaded before writing anything, so these errors are rendered as
+// problems. Once the CSV has started, a problem would be appended to a 200
+// response, so the connection is aborted instead and clients don't mistake
+// a truncated export for a complete one.
+func (handler GetAssetHoldersHandler) WriteRawResponse(w io.Writer, r *http.Request) error {
+	qp, historyQ, err := loadAssetHoldersQuery(r)
+	if err != nil {
+		return err
+	}
+
+	pq := db2.PageQuery{Order: db2.OrderDescending, Limit: assetHoldersCSVBatchSize}
+	trustLines, err := historyQ.GetAssetHolders(qp.Asset(), pq)
+	if err != nil {
+		return err
+	}
+
+	if hw, ok := w.(HeaderWriter); ok {
+		hw.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		hw.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=\"%s-holders.csv\"", strings.Replace(qp.AssetParam, ":", "-", 1)),
+		)
+	}
+
+	err = writeAssetHoldersCSV(w, trustLines, func(cursor string) ([]history.TrustLine, error) {
+		pq.Cursor = cursor
+		return historyQ.GetAssetHolders(qp.Asset(), pq)
+	})
+	if err != nil {
+		log.Ctx(r.Context()).WithError(err).Error("could not export asset holders, aborting response")
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+// writeAssetHoldersCSV writes the CSV header and the trust lines of
+// trustLines, followed by the ones returned by next until a page has fewer
+// than assetHoldersCSVBatchSize trust lines. next returns the page after the
+// trust line with the given paging token.
+func writeAssetHoldersCSV(
+	w io.Writer,
+	trustLines []history.TrustLine,
+	next func(cursor string) ([]history.TrustLine, error),
+) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(assetHoldersCSVHeader); err != nil {
+		return err
+	}
+
+	for {
+		for _, trustLine := range trustLines {
+			if err := cw.Write(assetHolderCSVRecord(trustLine)); err != nil {
+				return err
+			}
+		}
+
+		if len(trustLines) < assetHoldersCSVBatchSize {
+			break
+		}
+		var err error
+		trustLines, err = next(trustLines[len(trustLines)-1].HolderPagingToken())
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func assetHolderCSVRecord(trustLine history.TrustLine) []string {
+	return []string{
+		trustLine.AccountID,
+		amount.StringFromInt64(trustLine.Balance),
+		amount.StringFromInt64(trustLine.Limit),
+		amount.StringFromInt64(trustLine.BuyingLiabilities),
+		amount.StringFromInt64(trustLine.SellingLiabilities),
+		strconv.FormatBool(trustLine.IsAuthorized()),
+		strconv.FormatBool(trustLine.IsAuthorizedToMaintainLiabilities()),
+		strconv.FormatUint(uint64(trustLine.LastModifiedLedger), 10),
+		trustLine.Sponsor.String,
+	}
+}
+
+// loadAssetHoldersQuery parses the request and checks that the state is at
+// the ledger requested with at_ledger, if any.
+func loadAssetHoldersQuery(r *http.Request) (AssetHoldersQuery, *history.Q, error) {
+	qp := AssetHoldersQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return qp, nil, err
+	}
+
+	historyQ, err := auroraContext.HistoryQFromRequest(r)
+	if err != nil {
+		return qp, nil, err
+	}
+
+	if qp.AtLedger != 0 {
+		lastIngestedLedger, err := historyQ.GetLastLedgerExpIngestNonBlocking()
+		if err != nil {
+			return qp, nil, err
+		}
+		if qp.AtLedger != lastIngestedLedger {
+			p := hProblem.LedgerNotAvailable
+			p.Extras = map[string]interface{}{
+				"at_ledger":     qp.AtLedger,
+				"latest_ledger": lastIngestedLedger,
+			}
+			return qp, nil, &p
+		}
+	}
+
+	return qp, historyQ, nil
+}
@@ -0,0 +1,197 @@
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/support/render/problem"
+	"github.com/hcnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildAssetHolderTrustLine(accountID string, asset xdr.Asset, balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 1234,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: xdr.MustAddress(accountID),
+				Asset:     asset,
+				Balance:   balance,
+				Limit:     1000000000,
+				Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+			},
+		},
+	}
+}
+
+func TestGetAssetHolders(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &history.Q{tt.AuroraSession()}
+
+	issuer := "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	usd := xdr.MustNewCreditAsset("USD", issuer)
+	tt.Assert.NoError(q.UpsertTrustLines([]xdr.LedgerEntry{
+		buildAssetHolderTrustLine("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML", usd, 300),
+		buildAssetHolderTrustLine("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", usd, 100),
+		buildAssetHolderTrustLine("GCYVFGI3SEQJGBNQQG7YCMFWEYOHK3XPVOVPA6C566PXWN4SN7LILZSM", usd, 200),
+		buildAssetHolderTrustLine(
+			"GCYVFGI3SEQJGBNQQG7YCMFWEYOHK3XPVOVPA6C566PXWN4SN7LILZSM",
+			xdr.MustNewCreditAsset("EUR", issuer),
+			500,
+		),
+	}))
+	tt.Assert.NoError(q.UpdateLastLedgerExpIngest(1234))
+
+	handler := GetAssetHoldersHandler{}
+	routeParams := map[string]string{"asset": "USD:" + issuer}
+	response, err := handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"order": "desc"},
+		routeParams,
+		q.Session,
+	))
+	tt.Assert.NoError(err)
+	tt.Assert.Len(response, 3)
+	for i, balance := range []string{"0.0000300", "0.0000200", "0.0000100"} {
+		tt.Assert.Equal(balance, response[i].(protocol.AssetHolder).Balance)
+	}
+
+	response, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{
+			"order":     "desc",
+			"limit":     "1",
+			"cursor":    response[0].PagingToken(),
+			"at_ledger": "1234",
+		},
+		routeParams,
+		q.Session,
+	))
+	tt.Assert.NoError(err)
+	tt.Assert.Len(response, 1)
+	tt.Assert.Equal("0.0000200", response[0].(protocol.AssetHolder).Balance)
+
+	_, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"at_ledger": "1233"},
+		routeParams,
+		q.Session,
+	))
+	p := err.(*problem.P)
+	tt.Assert.Equal("ledger_not_available", p.Type)
+	tt.Assert.Equal(uint32(1234), p.Extras["latest_ledger"])
+
+	_, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"cursor": "1234"},
+		routeParams,
+		q.Session,
+	))
+	p = err.(*problem.P)
+	tt.Assert.Equal("bad_request", p.Type)
+	tt.Assert.Equal("cursor", p.Extras["invalid_field"])
+
+	_, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{},
+		map[string]string{"asset": "native"},
+		q.Session,
+	))
+	p = err.(*problem.P)
+	tt.Assert.Equal("bad_request", p.Type)
+	tt.Assert.Equal("asset", p.Extras["invalid_field"])
+
+	w := httptest.NewRecorder()
+	err = handler.WriteRawResponse(w, makeRequest(t, map[string]string{}, routeParams, q.Session))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	tt.Assert.Len(lines, 4)
+	tt.Assert.Equal(strings.Join(assetHoldersCSVHeader, ","), lines[0])
+	tt.Assert.Equal(
+		"GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML,0.0000300,100.0000000,0.0000000,0.0000000,true,false,1234,",
+		lines[1],
+	)
+
+	// Errors before the CSV starts are rendered as problems.
+	w = httptest.NewRecorder()
+	err = handler.WriteRawResponse(w, makeRequest(
+		t,
+		map[string]string{"at_ledger": "1233"},
+		routeParams,
+		q.Session,
+	))
+	p = err.(*problem.P)
+	tt.Assert.Equal("ledger_not_available", p.Type)
+	tt.Assert.Empty(w.Header().Get("Content-Type"))
+	tt.Assert.Zero(w.Body.Len())
+
+	// Errors once the CSV has started abort the response.
+	tt.Assert.PanicsWithValue(http.ErrAbortHandler, func() {
+		handler.WriteRawResponse(
+			failingWriter{httptest.NewRecorder()},
+			makeRequest(t, map[string]string{}, routeParams, q.Session),
+		)
+	})
+}
+
+// failingWriter is a response writer whose connection is broken.
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestWriteAssetHoldersCSV(t *testing.T) {
+	page := func(balance int64, n int) []history.TrustLine {
+		var trustLines []history.TrustLine
+		for i := 0; i < n; i++ {
+			trustLines = append(trustLines, history.TrustLine{
+				AccountID: "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML",
+				Balance:   balance,
+			})
+		}
+		return trustLines
+	}
+
+	t.Run("single page", func(t *testing.T) {
+		var b strings.Builder
+		err := writeAssetHoldersCSV(&b, page(3, 2), func(string) ([]history.TrustLine, error) {
+			t.Fatal("unexpected page")
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, strings.Split(strings.TrimSpace(b.String()), "\n"), 3)
+	})
+
+	t.Run("several pages", func(t *testing.T) {
+		var b strings.Builder
+		var cursors []string
+		err := writeAssetHoldersCSV(&b, page(3, assetHoldersCSVBatchSize), func(cursor string) ([]history.TrustLine, error) {
+			cursors = append(cursors, cursor)
+			return page(2, 1), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"3_GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"}, cursors)
+		assert.Len(t, strings.Split(strings.TrimSpace(b.String()), "\n"), assetHoldersCSVBatchSize+2)
+	})
+
+	t.Run("next page fails", func(t *testing.T) {
+		var b strings.Builder
+		err := writeAssetHoldersCSV(&b, page(3, assetHoldersCSVBatchSize), func(string) ([]history.TrustLine, error) {
+			return nil, errors.New("connection reset")
+		})
+		assert.EqualError(t, err, "connection reset")
+	})
+}
@@ -121,10 +121,36 @@ func TestAssetStats(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: issuer.AccountID,
 		AssetCode:   "USD",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized: 2,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "1",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "0",
+			Selling: "0",
+		},
 		Amount:      "1",
 		NumAccounts: 2,
 	}
 	usdAssetStatResponse := aurora.AssetStat{
+		Accounts: aurora.AssetStatAccounts{
+			Authorized: usdAssetStat.Accounts.Authorized,
+		},
+		Balances: aurora.AssetStatBalances{
+			Authorized:                      "0.0000001",
+			AuthorizedToMaintainLiabilities: "0.0000000",
+			Unauthorized:                    "0.0000000",
+		},
+		ClaimableBalancesAmount: "0.0000000",
+		Liabilities: aurora.AssetStatLiabilities{
+			Buying:  "0.0000000",
+			Selling: "0.0000000",
+		},
 		Amount:      "0.0000001",
 		NumAccounts: usdAssetStat.NumAccounts,
 		Asset: base.Asset{
@@ -140,10 +166,36 @@ func TestAssetStats(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: issuer.AccountID,
 		AssetCode:   "ETHER",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized: 1,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "23",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "0",
+			Selling: "0",
+		},
 		Amount:      "23",
 		NumAccounts: 1,
 	}
 	etherAssetStatResponse := aurora.AssetStat{
+		Accounts: aurora.AssetStatAccounts{
+			Authorized: etherAssetStat.Accounts.Authorized,
+		},
+		Balances: aurora.AssetStatBalances{
+			Authorized:                      "0.0000023",
+			AuthorizedToMaintainLiabilities: "0.0000000",
+			Unauthorized:                    "0.0000000",
+		},
+		ClaimableBalancesAmount: "0.0000000",
+		Liabilities: aurora.AssetStatLiabilities{
+			Buying:  "0.0000000",
+			Selling: "0.0000000",
+		},
 		Amount:      "0.0000023",
 		NumAccounts: etherAssetStat.NumAccounts,
 		Asset: base.Asset{
@@ -159,10 +211,36 @@ func TestAssetStats(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: otherIssuer.AccountID,
 		AssetCode:   "USD",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized: 2,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "1",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "0",
+			Selling: "0",
+		},
 		Amount:      "1",
 		NumAccounts: 2,
 	}
 	otherUSDAssetStatResponse := aurora.AssetStat{
+		Accounts: aurora.AssetStatAccounts{
+			Authorized: otherUSDAssetStat.Accounts.Authorized,
+		},
+		Balances: aurora.AssetStatBalances{
+			Authorized:                      "0.0000001",
+			AuthorizedToMaintainLiabilities: "0.0000000",
+			Unauthorized:                    "0.0000000",
+		},
+		ClaimableBalancesAmount: "0.0000000",
+		Liabilities: aurora.AssetStatLiabilities{
+			Buying:  "0.0000000",
+			Selling: "0.0000000",
+		},
 		Amount:      "0.0000001",
 		NumAccounts: otherUSDAssetStat.NumAccounts,
 		Asset: base.Asset{
@@ -180,10 +258,36 @@ func TestAssetStats(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: otherIssuer.AccountID,
 		AssetCode:   "EUR",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized: 3,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "111",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "0",
+			Selling: "0",
+		},
 		Amount:      "111",
 		NumAccounts: 3,
 	}
 	eurAssetStatResponse := aurora.AssetStat{
+		Accounts: aurora.AssetStatAccounts{
+			Authorized: eurAssetStat.Accounts.Authorized,
+		},
+		Balances: aurora.AssetStatBalances{
+			Authorized:                      "0.0000111",
+			AuthorizedToMaintainLiabilities: "0.0000000",
+			Unauthorized:                    "0.0000000",
+		},
+		ClaimableBalancesAmount: "0.0000000",
+		Liabilities: aurora.AssetStatLiabilities{
+			Buying:  "0.0000000",
+			Selling: "0.0000000",
+		},
 		Amount:      "0.0000111",
 		NumAccounts: eurAssetStat.NumAccounts,
 		Asset: base.Asset{
@@ -342,6 +446,19 @@ func TestAssetStatsIssuerDoesNotExist(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
 		AssetCode:   "USD",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized: 2,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "1",
+			AuthorizedToMaintainLiabilities: "0",
+			Unauthorized:                    "0",
+			ClaimableBalances:               "0",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "0",
+			Selling: "0",
+		},
 		Amount:      "1",
 		NumAccounts: 2,
 	}
@@ -354,6 +471,19 @@ func TestAssetStatsIssuerDoesNotExist(t *testing.T) {
 	tt.Assert.NoError(err)
 
 	expectedAssetStatResponse := aurora.AssetStat{
+		Accounts: aurora.AssetStatAccounts{
+			Authorized: usdAssetStat.Accounts.Authorized,
+		},
+		Balances: aurora.AssetStatBalances{
+			Authorized:                      "0.0000001",
+			AuthorizedToMaintainLiabilities: "0.0000000",
+			Unauthorized:                    "0.0000000",
+		},
+		ClaimableBalancesAmount: "0.0000000",
+		Liabilities: aurora.AssetStatLiabilities{
+			Buying:  "0.0000000",
+			Selling: "0.0000000",
+		},
 		Amount:      "0.0000001",
 		NumAccounts: usdAssetStat.NumAccounts,
 		Asset: base.Asset{
@@ -24,6 +24,7 @@ type GetRootHandler struct {
 	NetworkPassphrase string
 	FriendbotURL      *url.URL
 	AuroraVersion    string
+	HistoryIngestion  aurora.HistoryIngestion
 }
 
 func (handler GetRootHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
@@ -48,5 +49,6 @@ func (handler GetRootHandler) GetResource(w HeaderWriter, r *http.Request) (inte
 		handler.FriendbotURL,
 		templates,
 	)
+	res.HistoryIngestion = handler.HistoryIngestion
 	return res, nil
 }
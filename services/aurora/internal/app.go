@@ -55,6 +55,7 @@ type App struct {
 	config          Config
 	webServer       *httpx.Server
 	historyQ        *history.Q
+	replicaQ        *history.Q
 	ctx             context.Context
 	cancel          func()
 	auroraVersion  string
@@ -64,6 +65,7 @@ type App struct {
 	paths           paths.Finder
 	ingester        ingest.System
 	reaper          *reap.System
+	responseCache   *httpx.ResponseCache
 	ticks           *time.Ticker
 
 	// metrics
@@ -77,6 +79,7 @@ type App struct {
 	dbInUseConnectionsGauge    prometheus.GaugeFunc
 	dbWaitCountCounter         prometheus.CounterFunc
 	dbWaitDurationCounter      prometheus.CounterFunc
+	replicaLagGauge            prometheus.GaugeFunc
 	coreLatestLedgerCounter    prometheus.CounterFunc
 }
 
@@ -166,6 +169,9 @@ func (a *App) waitForDone() {
 // closed" errors.
 func (a *App) CloseDB() {
 	a.historyQ.Session.DB.Close()
+	if a.replicaQ != nil {
+		a.replicaQ.Session.DB.Close()
+	}
 }
 
 // HistoryQ returns a helper object for performing sql queries against the
@@ -224,11 +230,27 @@ func (a *App) UpdateLedgerState() {
 		return
 	}
 
+	if a.replicaQ != nil {
+		// When the replica can't be queried its latest ledger is left unset
+		// so it's considered lagging.
+		next.ReplicaExpHistoryLatest, err = a.replicaQ.GetLastLedgerExpIngestNonBlocking()
+		if err != nil {
+			next.ReplicaExpHistoryLatest = 0
+			logErr(err, "failed to load the latest known exp ledger state from the replica DB")
+		}
+	}
+
 	ledger.SetState(next)
 }
 
 // UpdateFeeStatsState triggers a refresh of several operation fee metrics.
 func (a *App) UpdateFeeStatsState() {
+	// Fee stats are computed with postgres aggregate functions, they are not
+	// served in lite mode.
+	if a.config.Lite {
+		return
+	}
+
 	var (
 		next          operationfeestats.State
 		latest        history.LatestLedger
@@ -414,6 +436,7 @@ func (a *App) init() error {
 	// log
 	log.DefaultLogger.Logger.Level = a.config.LogLevel
 	log.DefaultLogger.Logger.Hooks.Add(logmetrics.DefaultMetrics)
+	log.DefaultLogger.Logger.Hooks.Add(logmetrics.DefaultQueryLimitMetrics)
 
 	// sentry
 	initSentry(a)
@@ -438,12 +461,20 @@ func (a *App) init() error {
 
 	// reaper
 	a.reaper = reap.New(a.config.HistoryRetentionCount, a.AuroraSession(context.Background()))
+	a.reaper.TableRetentionCounts = a.config.HistoryTableRetentionCounts
+	a.reaper.BatchSize = a.config.HistoryRetentionBatchSize
+	a.reaper.BatchPause = a.config.HistoryRetentionBatchPause
+	a.reaper.DryRun = a.config.HistoryRetentionDryRun
+
+	// response cache
+	initResponseCache(a)
 
 	// metrics and log.metrics
 	a.prometheusRegistry = prometheus.NewRegistry()
 	for _, meter := range *logmetrics.DefaultMetrics {
 		a.prometheusRegistry.MustRegister(meter)
 	}
+	a.prometheusRegistry.MustRegister(logmetrics.DefaultQueryLimitMetrics.Counter)
 
 	// go metrics
 	initGoMetrics(a)
@@ -460,12 +491,28 @@ func (a *App) init() error {
 	// txsub.metrics
 	initTxSubMetrics(a)
 
+	var replica *httpx.ReplicaSession
+	if a.replicaQ != nil {
+		replica = &httpx.ReplicaSession{
+			Session:       a.replicaQ.Session,
+			LagThreshold:  uint32(a.config.ReplicaLagThreshold),
+			RejectLagging: a.config.RejectLaggingReplica,
+		}
+	}
+
 	routerConfig := httpx.RouterConfig{
 		DBSession:          a.historyQ.Session,
+		Replica:            replica,
+		History:            a.config.History,
 		TxSubmitter:        a.submitter,
 		RateQuota:          a.config.RateQuota,
 		SSEUpdateFrequency: a.config.SSEUpdateFrequency,
 		StaleThreshold:     a.config.StaleThreshold,
+		QueryLimits: httpx.QueryLimits{
+			StatementTimeouts: a.config.StatementTimeouts,
+			MaxQueryCosts:     a.config.MaxQueryCosts,
+		},
+		ResponseCache:      a.responseCache,
 		ConnectionTimeout:  a.config.ConnectionTimeout,
 		NetworkPassphrase:  a.config.NetworkPassphrase,
 		MaxPathLength:      a.config.MaxPathLength,
@@ -474,6 +521,7 @@ func (a *App) init() error {
 		CoreGetter:         a,
 		AuroraVersion:     a.auroraVersion,
 		FriendbotURL:       a.config.FriendbotURL,
+		Lite:               a.config.Lite,
 	}
 
 	var err error
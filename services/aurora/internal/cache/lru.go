@@ -0,0 +1,52 @@
+package cache
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/hcnet/go/services/aurora/internal/reap"
+	"github.com/hcnet/go/support/errors"
+)
+
+// LRUStore is a Store keeping the most recently used entries in memory.
+type LRUStore struct {
+	entries *lru.Cache
+}
+
+// NewLRUStore returns an LRUStore keeping at most size entries.
+func NewLRUStore(size int) (*LRUStore, error) {
+	entries, err := lru.New(size)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create lru cache")
+	}
+	return &LRUStore{entries: entries}, nil
+}
+
+// Get implements Store.
+func (s *LRUStore) Get(key string) (Entry, bool, error) {
+	entry, ok := s.entries.Get(key)
+	if !ok {
+		return Entry{}, false, nil
+	}
+	return entry.(Entry), true, nil
+}
+
+// Set implements Store.
+func (s *LRUStore) Set(key string, entry Entry) error {
+	s.entries.Add(key, entry)
+	return nil
+}
+
+// DeleteBefore implements Store.
+func (s *LRUStore) DeleteBefore(table reap.Table, seq uint32) error {
+	for _, key := range s.entries.Keys() {
+		// Peek doesn't update the recentness of the entry.
+		entry, ok := s.entries.Peek(key)
+		if !ok {
+			continue
+		}
+		if e := entry.(Entry); e.Table == table && e.Ledger < seq {
+			s.entries.Remove(key)
+		}
+	}
+	return nil
+}
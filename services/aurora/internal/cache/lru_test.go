@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/reap"
+)
+
+func TestLRUStore(t *testing.T) {
+	store, err := NewLRUStore(3)
+	require.NoError(t, err)
+
+	_, ok, err := store.Get("/ledgers/1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ledger := Entry{Table: reap.LedgersTable, Ledger: 1, Body: []byte("1")}
+	require.NoError(t, store.Set("/ledgers/1", ledger))
+	entry, ok, err := store.Get("/ledgers/1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ledger, entry)
+
+	require.NoError(t, store.Set("/transactions/a", Entry{Table: reap.TransactionsTable, Ledger: 1}))
+	require.NoError(t, store.Set("/transactions/b", Entry{Table: reap.TransactionsTable, Ledger: 2}))
+
+	// only the entries of the table older than the ledger are removed
+	require.NoError(t, store.DeleteBefore(reap.TransactionsTable, 2))
+	_, ok, _ = store.Get("/transactions/a")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("/transactions/b")
+	assert.True(t, ok)
+	_, ok, _ = store.Get("/ledgers/1")
+	assert.True(t, ok)
+
+	// the least recently used entry is evicted
+	require.NoError(t, store.Set("/transactions/c", Entry{Table: reap.TransactionsTable, Ledger: 3}))
+	require.NoError(t, store.Set("/transactions/d", Entry{Table: reap.TransactionsTable, Ledger: 3}))
+	_, ok, _ = store.Get("/transactions/b")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("/ledgers/1")
+	assert.True(t, ok)
+
+	_, err = NewLRUStore(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+// Package cache stores the rendered responses of history resources which
+// never change once ingested, such as ledgers, transactions and operations,
+// in memory or in Redis.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/hcnet/go/services/aurora/internal/reap"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	// Table is the history table the resource is loaded from, used to remove
+	// the entry when the reaper deletes its ledger.
+	Table reap.Table `json:"table"`
+	// Ledger is the sequence of the ledger of the resource.
+	Ledger      uint32 `json:"ledger"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// ETag returns a strong entity tag of the body of the entry.
+func (e Entry) ETag() string {
+	sum := sha256.Sum256(e.Body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Store stores cached responses by key. Stores implement
+// reap.Invalidator so entries are removed with the history they copy.
+type Store interface {
+	// Get returns the entry stored for key and whether it was found.
+	Get(key string) (Entry, bool, error)
+	// Set stores entry for key.
+	Set(key string, entry Entry) error
+	// DeleteBefore removes the entries of table for ledgers older than seq.
+	DeleteBefore(table reap.Table, seq uint32) error
+}
@@ -0,0 +1,16 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryETag(t *testing.T) {
+	a := Entry{Body: []byte(`{"id":"1"}`)}
+	b := Entry{Body: []byte(`{"id":"2"}`)}
+
+	assert.Equal(t, a.ETag(), Entry{Body: []byte(`{"id":"1"}`), Ledger: 5}.ETag())
+	assert.NotEqual(t, a.ETag(), b.ETag())
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, a.ETag())
+}
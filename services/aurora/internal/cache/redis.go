@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/hcnet/go/services/aurora/internal/reap"
+	"github.com/hcnet/go/support/errors"
+)
+
+const (
+	redisKeyPrefix = "aurora:response-cache:"
+	// redisScanCount is the number of keys examined by every SCAN of
+	// DeleteBefore.
+	redisScanCount = 1000
+)
+
+// RedisStore is a Store keeping entries in Redis, so they are shared by
+// several aurora instances. Entries expire after a TTL.
+type RedisStore struct {
+	pool *redis.Pool
+	ttl  time.Duration
+}
+
+// NewRedisStore returns a RedisStore connecting to the Redis server at url,
+// for example redis://localhost:6379/0. A TTL of 0 keeps entries until they
+// are invalidated or evicted by Redis.
+func NewRedisStore(url string, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		pool: &redis.Pool{
+			MaxIdle:     10,
+			IdleTimeout: 5 * time.Minute,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(url)
+			},
+		},
+		ttl: ttl,
+	}
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(key string) (Entry, bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", redisKeyPrefix+key))
+	if err == redis.ErrNil {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, errors.Wrap(err, "could not get cache entry")
+	}
+
+	var entry Entry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false, errors.Wrap(err, "could not decode cache entry")
+	}
+	return entry, true, nil
+}
+
+// Set implements Store.
+func (s *RedisStore) Set(key string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "could not encode cache entry")
+	}
+
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	args := []interface{}{redisKeyPrefix + key, data}
+	if s.ttl > 0 {
+		args = append(args, "PX", int64(s.ttl/time.Millisecond))
+	}
+	if _, err = conn.Do("SET", args...); err != nil {
+		return errors.Wrap(err, "could not set cache entry")
+	}
+	return nil
+}
+
+// DeleteBefore implements Store. It scans every entry, which is acceptable
+// because it is only called by the reaper.
+func (s *RedisStore) DeleteBefore(table reap.Table, seq uint32) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		reply, err := redis.Values(conn.Do(
+			"SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", redisScanCount,
+		))
+		if err != nil {
+			return errors.Wrap(err, "could not scan cache entries")
+		}
+		var keys []string
+		if _, err = redis.Scan(reply, &cursor, &keys); err != nil {
+			return errors.Wrap(err, "could not scan cache entries")
+		}
+
+		if err = s.deleteBefore(conn, keys, table, seq); err != nil {
+			return err
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *RedisStore) deleteBefore(conn redis.Conn, keys []string, table reap.Table, seq uint32) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+	values, err := redis.ByteSlices(conn.Do("MGET", args...))
+	if err != nil {
+		return errors.Wrap(err, "could not get cache entries")
+	}
+
+	var expired []interface{}
+	for i, data := range values {
+		// data is nil when the entry expired since the scan.
+		if data == nil {
+			continue
+		}
+		var entry Entry
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return errors.Wrap(err, "could not decode cache entry")
+		}
+		if entry.Table == table && entry.Ledger < seq {
+			expired = append(expired, keys[i])
+		}
+	}
+
+	if len(expired) > 0 {
+		if _, err = conn.Do("DEL", expired...); err != nil {
+			return errors.Wrap(err, "could not delete cache entries")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/reap"
+)
+
+func TestRedisStore(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL not set, skipping Redis tests")
+	}
+
+	store := NewRedisStore(url, time.Minute)
+	conn := store.pool.Get()
+	_, err := conn.Do("FLUSHDB")
+	conn.Close()
+	require.NoError(t, err)
+
+	_, ok, err := store.Get("/ledgers/1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ledger := Entry{
+		Table:       reap.LedgersTable,
+		Ledger:      1,
+		ContentType: "application/hal+json; charset=utf-8",
+		Body:        []byte(`{"sequence":1}`),
+	}
+	require.NoError(t, store.Set("/ledgers/1", ledger))
+	entry, ok, err := store.Get("/ledgers/1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ledger, entry)
+
+	require.NoError(t, store.Set("/transactions/a", Entry{Table: reap.TransactionsTable, Ledger: 1}))
+	require.NoError(t, store.Set("/transactions/b", Entry{Table: reap.TransactionsTable, Ledger: 2}))
+
+	require.NoError(t, store.DeleteBefore(reap.TransactionsTable, 2))
+	_, ok, _ = store.Get("/transactions/a")
+	assert.False(t, ok)
+	_, ok, _ = store.Get("/transactions/b")
+	assert.True(t, ok)
+	_, ok, _ = store.Get("/ledgers/1")
+	assert.True(t, ok)
+}
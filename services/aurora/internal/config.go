@@ -6,6 +6,9 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stellar/throttled"
+
+	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/services/aurora/internal/reap"
 )
 
 // Config is the configuration for aurora.  It gets populated by the
@@ -56,17 +59,70 @@ type Config struct {
 	// determining a "retention duration", each ledger roughly corresponds to 10
 	// seconds of real time.
 	HistoryRetentionCount uint
+	// HistoryTableRetentionCounts overrides HistoryRetentionCount for some
+	// history tables.
+	HistoryTableRetentionCounts map[reap.Table]uint
+	// HistoryRetentionBatchSize is the maximum number of ledgers removed by
+	// a single statement of the reaper.
+	HistoryRetentionBatchSize uint
+	// HistoryRetentionBatchPause is the time waited between two batches of
+	// the reaper.
+	HistoryRetentionBatchPause time.Duration
+	// HistoryRetentionDryRun makes the reaper report the history it would
+	// remove instead of removing it.
+	HistoryRetentionDryRun bool
 	// StaleThreshold represents the number of ledgers a history database may be
 	// out-of-date by before aurora begins to respond with an error to history
 	// requests.
 	StaleThreshold uint
+	// StatementTimeouts are the statement timeouts of the database queries of
+	// history requests, keyed by route pattern or "*" for all other routes.
+	StatementTimeouts map[string]time.Duration
+	// MaxQueryCosts are the maximum costs of the database queries of history
+	// requests estimated by the query planner, keyed by route pattern or "*"
+	// for all other routes.
+	MaxQueryCosts map[string]float64
+	// ResponseCacheSize is the number of responses of immutable history
+	// resources cached in memory. 0 disables the cache unless RedisURL is set.
+	ResponseCacheSize uint
+	// RedisURL is the URL of a Redis server storing the response cache
+	// instead of memory.
+	RedisURL string
+	// ResponseCacheMaxAge is the max-age of the Cache-Control header of
+	// cached responses and the TTL of responses cached in Redis.
+	ResponseCacheMaxAge time.Duration
+	// RODatabaseURL is the URL of a read replica of the aurora database.
+	// When set, the read queries of API requests are sent to the replica.
+	RODatabaseURL string
+	// ReplicaLagThreshold is the maximum number of ledgers the read replica
+	// may be behind the primary database before requests stop being served
+	// by the replica.
+	ReplicaLagThreshold uint
+	// RejectLaggingReplica makes aurora respond with an error instead of
+	// querying the primary database when the read replica is lagging.
+	RejectLaggingReplica bool
 	// SkipCursorUpdate causes the ingestor to skip reporting the "last imported
 	// ledger" state to hcnet-core.
 	SkipCursorUpdate bool
 	// IngestDisableStateVerification disables state verification
 	// `System.verifyState()` when set to `true`.
 	IngestDisableStateVerification bool
+	// HistoryArchivePrefetchDir is a local directory where buckets are
+	// downloaded in parallel before building state. When empty buckets are
+	// streamed from the history archive sequentially.
+	HistoryArchivePrefetchDir string
+	// HistoryArchivePrefetchConcurrency is the number of parallel downloads
+	// used when HistoryArchivePrefetchDir is set.
+	HistoryArchivePrefetchConcurrency uint
+	// History selects the history stored during ingestion and served by the
+	// API. It must be the same on all aurora instances using the same
+	// database.
+	History ingest.HistoryConfig
 	// ApplyMigrations will apply pending migrations to the aurora database
 	// before starting the aurora service
 	ApplyMigrations bool
+	// Lite runs aurora on the SQLite database file at DatabaseURL. Lite mode
+	// only stores and serves accounts, offers, trust lines, ledgers and
+	// transactions.
+	Lite bool
 }
@@ -36,7 +36,12 @@ func BaseURL(ctx context.Context) *url.URL {
 	if r == nil {
 		return nil
 	}
+	return RequestBaseURL(r)
+}
 
+// RequestBaseURL returns the "base" url of r, used to build the absolute
+// links of its response.
+func RequestBaseURL(r *http.Request) *url.URL {
 	var scheme string
 	switch {
 	case r.Header.Get("X-Forwarded-Proto") != "":
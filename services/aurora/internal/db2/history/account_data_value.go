@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/base64"
+
+	"github.com/hcnet/go/support/errors"
 )
 
 var _ driver.Valuer = (*AccountDataValue)(nil)
@@ -11,7 +13,17 @@ var _ sql.Scanner = (*AccountDataValue)(nil)
 
 // Scan base64 decodes into an []byte
 func (t *AccountDataValue) Scan(src interface{}) error {
-	decoded, err := base64.StdEncoding.DecodeString(src.(string))
+	var encoded string
+	switch src := src.(type) {
+	case string:
+		encoded = src
+	case []byte:
+		encoded = string(src)
+	default:
+		return errors.Errorf("cannot scan %T", src)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return err
 	}
@@ -95,6 +95,7 @@ func (q *Q) UpsertAccounts(accounts []xdr.LedgerEntry) error {
 	var numSubEntries, flags, lastModifiedLedger, numSponsored, numSponsoring []xdr.Uint32
 	var masterWeight, thresholdLow, thresholdMedium, thresholdHigh []uint8
 	var sponsor []null.String
+	rows := make([]map[string]interface{}, 0, len(accounts))
 
 	for _, entry := range accounts {
 		if entry.Data.Type != xdr.LedgerEntryTypeAccount {
@@ -102,6 +103,7 @@ func (q *Q) UpsertAccounts(accounts []xdr.LedgerEntry) error {
 		}
 
 		m := accountToMap(entry)
+		rows = append(rows, m)
 		accountID = append(accountID, m["account_id"].(string))
 		balance = append(balance, m["balance"].(xdr.Int64))
 		buyingLiabilities = append(buyingLiabilities, m["buying_liabilities"].(xdr.Int64))
@@ -121,6 +123,10 @@ func (q *Q) UpsertAccounts(accounts []xdr.LedgerEntry) error {
 		numSponsoring = append(numSponsoring, m["num_sponsoring"].(xdr.Uint32))
 	}
 
+	if q.Dialect() == "sqlite3" {
+		return q.upsertRows("accounts", "account_id", rows)
+	}
+
 	sql := `
 	WITH r AS
 		(SELECT
@@ -1,6 +1,8 @@
 package history
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -16,11 +18,47 @@ func assetStatToMap(assetStat ExpAssetStat) map[string]interface{} {
 		"asset_type":   assetStat.AssetType,
 		"asset_code":   assetStat.AssetCode,
 		"asset_issuer": assetStat.AssetIssuer,
+		"accounts":     assetStat.Accounts,
+		"balances":     assetStat.Balances,
+		"liabilities":  assetStat.Liabilities,
 		"amount":       assetStat.Amount,
 		"num_accounts": assetStat.NumAccounts,
 	}
 }
 
+func (a ExpAssetStatAccounts) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *ExpAssetStatAccounts) Scan(src interface{}) error {
+	return scanJSON(src, a)
+}
+
+func (b ExpAssetStatBalances) Value() (driver.Value, error) {
+	return json.Marshal(b)
+}
+
+func (b *ExpAssetStatBalances) Scan(src interface{}) error {
+	return scanJSON(src, b)
+}
+
+func (l ExpAssetStatLiabilities) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *ExpAssetStatLiabilities) Scan(src interface{}) error {
+	return scanJSON(src, l)
+}
+
+func scanJSON(src interface{}, dest interface{}) error {
+	source, ok := src.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(source, dest)
+}
+
 func assetStatToPrimaryKeyMap(assetStat ExpAssetStat) map[string]interface{} {
 	return map[string]interface{}{
 		"asset_type":   assetStat.AssetType,
@@ -21,6 +21,22 @@ func TestInsertAssetStats(t *testing.T) {
 			AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 			AssetIssuer: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
 			AssetCode:   "USD",
+			Accounts: ExpAssetStatAccounts{
+				Authorized:                      2,
+				AuthorizedToMaintainLiabilities: 1,
+				Unauthorized:                    3,
+				ClaimableBalances:               4,
+			},
+			Balances: ExpAssetStatBalances{
+				Authorized:                      "1",
+				AuthorizedToMaintainLiabilities: "5",
+				Unauthorized:                    "6",
+				ClaimableBalances:               "7",
+			},
+			Liabilities: ExpAssetStatLiabilities{
+				Buying:  "8",
+				Selling: "9",
+			},
 			Amount:      "1",
 			NumAccounts: 2,
 		},
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"github.com/guregu/null"
 	"github.com/hcnet/go/support/db"
 )
 
@@ -9,6 +10,7 @@ import (
 type EffectBatchInsertBuilder interface {
 	Add(
 		accountID int64,
+		muxedAccount null.String,
 		operationID int64,
 		order uint32,
 		effectType EffectType,
@@ -28,6 +30,7 @@ func (q *Q) NewEffectBatchInsertBuilder(maxBatchSize int) EffectBatchInsertBuild
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_effects"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -35,6 +38,7 @@ func (q *Q) NewEffectBatchInsertBuilder(maxBatchSize int) EffectBatchInsertBuild
 // Add adds a effect to the batch
 func (i *effectBatchInsertBuilder) Add(
 	accountID int64,
+	muxedAccount null.String,
 	operationID int64,
 	order uint32,
 	effectType EffectType,
@@ -42,6 +46,7 @@ func (i *effectBatchInsertBuilder) Add(
 ) error {
 	return i.builder.Row(map[string]interface{}{
 		"history_account_id":   accountID,
+		"address_muxed":        muxedAccount,
 		"history_operation_id": operationID,
 		"\"order\"":            order,
 		"type":                 effectType,
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/guregu/null"
 	"github.com/hcnet/go/services/aurora/internal/test"
 	"github.com/hcnet/go/services/aurora/internal/toid"
 )
@@ -15,6 +16,7 @@ func TestAddEffect(t *testing.T) {
 	q := &Q{tt.AuroraSession()}
 
 	address := "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	muxedAddress := "MBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFUAAAAAAAAAAAACLDI"
 	accounIDs, err := q.CreateAccounts([]string{address}, 1)
 	tt.Assert.NoError(err)
 
@@ -27,6 +29,7 @@ func TestAddEffect(t *testing.T) {
 
 	err = builder.Add(
 		accounIDs[address],
+		null.StringFrom(muxedAddress),
 		toid.New(sequence, 1, 1).ToInt64(),
 		1,
 		3,
@@ -43,6 +46,7 @@ func TestAddEffect(t *testing.T) {
 
 	effect := effects[0]
 	tt.Assert.Equal(address, effect.Account)
+	tt.Assert.Equal(muxedAddress, effect.AccountMuxed.String)
 	tt.Assert.Equal(int64(240518172673), effect.HistoryOperationID)
 	tt.Assert.Equal(int32(1), effect.Order)
 	tt.Assert.Equal(EffectType(3), effect.Type)
@@ -264,6 +264,7 @@ func FeeBumpScenario(tt *test.T, q *Q, successful bool) FeeBumpFixture {
 		xdr.OperationTypeBumpSequence,
 		details,
 		account.Address(),
+		null.StringFrom(fixture.Envelope.SourceAccount().Address()),
 	))
 	tt.Assert.NoError(opBuilder.Exec())
 
@@ -276,6 +277,7 @@ func FeeBumpScenario(tt *test.T, q *Q, successful bool) FeeBumpFixture {
 
 	err = effectBuilder.Add(
 		accounIDs[account.Address()],
+		null.StringFrom(fixture.Envelope.SourceAccount().Address()),
 		toid.New(fixture.Ledger.Sequence, 1, 1).ToInt64(),
 		1,
 		EffectSequenceBumped,
@@ -291,6 +293,7 @@ func FeeBumpScenario(tt *test.T, q *Q, successful bool) FeeBumpFixture {
 			LedgerSequence:       fixture.Ledger.Sequence,
 			ApplicationOrder:     1,
 			Account:              account.Address(),
+			AccountMuxed:         null.StringFrom(fixture.Envelope.SourceAccount().Address()),
 			AccountSequence:      "97",
 			MaxFee:               int64(fixture.Envelope.Fee()),
 			FeeCharged:           int64(resultPair.Result.FeeCharged),
@@ -308,6 +311,7 @@ func FeeBumpScenario(tt *test.T, q *Q, successful bool) FeeBumpFixture {
 			NewMaxFee:            null.IntFrom(int64(fixture.Envelope.FeeBumpFee())),
 			InnerTransactionHash: null.StringFrom(fixture.InnerHash),
 			FeeAccount:           null.StringFrom(feeBumpAccount.Address()),
+			FeeAccountMuxed:      null.StringFrom(fixture.Envelope.FeeBumpAccount().Address()),
 		},
 	}
 
@@ -6,7 +6,7 @@ package history
 // Any aurora database tables which cannot be populated using
 // history archive snapshots will not be truncated.
 func (q *Q) TruncateExpingestStateTables() error {
-	return q.TruncateTables([]string{
+	return q.TruncateTables(q.existingTables([]string{
 		"accounts",
 		"accounts_data",
 		"accounts_signers",
@@ -14,5 +14,5 @@ func (q *Q) TruncateExpingestStateTables() error {
 		"exp_asset_stats",
 		"offers",
 		"trust_lines",
-	})
+	}))
 }
@@ -166,7 +166,9 @@ func (q *Q) getValueFromStore(key string, forUpdate bool) (string, error) {
 		From("key_value_store").
 		Where("key_value_store.key = ?", key)
 
-	if forUpdate {
+	// SQLite has no row locks, the ingestion session locks the whole database
+	// when it begins a transaction instead.
+	if forUpdate && q.Dialect() == "postgres" {
 		query = query.Suffix("FOR UPDATE")
 	}
 
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+)
+
+// hasTable returns true if table exists in the database. SQLite databases,
+// used by lite mode, only contain the tables of schema.LiteTables.
+func (q *Q) hasTable(table string) bool {
+	if q.Dialect() != "sqlite3" {
+		return true
+	}
+	for _, liteTable := range schema.LiteTables {
+		if table == liteTable {
+			return true
+		}
+	}
+	return false
+}
+
+// existingTables returns the tables which exist in the database.
+func (q *Q) existingTables(tables []string) []string {
+	existing := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if q.hasTable(table) {
+			existing = append(existing, table)
+		}
+	}
+	return existing
+}
+
+// upsertRows upserts rows in table with INSERT ... ON CONFLICT statements.
+// It's used on SQLite, which doesn't support the unnest() upserts of postgres.
+// All rows must have the same columns.
+func (q *Q) upsertRows(table, conflictColumn string, rows []map[string]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var updates []string
+	for column := range rows[0] {
+		if column != conflictColumn {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", column, column))
+		}
+	}
+	sort.Strings(updates)
+
+	builder := &db.BatchInsertBuilder{
+		Table: q.GetTable(table),
+		Suffix: fmt.Sprintf(
+			"ON CONFLICT (%s) DO UPDATE SET %s",
+			conflictColumn, strings.Join(updates, ", "),
+		),
+	}
+	for _, row := range rows {
+		if err := builder.Row(row); err != nil {
+			return errors.Wrap(err, "could not add row")
+		}
+	}
+	return builder.Exec()
+}
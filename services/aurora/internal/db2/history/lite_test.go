@@ -0,0 +1,144 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/services/aurora/internal/toid"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/db/dbtest"
+	"github.com/hcnet/go/xdr"
+)
+
+// testDialects runs fn with a Q of the postgres test database and with a Q of
+// a lite mode SQLite database.
+func testDialects(t *testing.T, fn func(t *testing.T, q *Q)) {
+	t.Run("postgres", func(t *testing.T) {
+		tt := test.Start(t)
+		defer tt.Finish()
+		test.ResetAuroraDB(t, tt.AuroraDB)
+		fn(t, &Q{tt.AuroraSession()})
+	})
+	t.Run("sqlite3", func(t *testing.T) {
+		tdb := dbtest.Sqlite(t)
+		defer tdb.Close()
+		conn := tdb.Open()
+		defer conn.Close()
+		require.NoError(t, schema.MigrateSqlite(conn.DB))
+		fn(t, &Q{&db.Session{DB: conn, Ctx: context.Background()}})
+	})
+}
+
+func TestLiteStateQueries(t *testing.T) {
+	testDialects(t, func(t *testing.T, q *Q) {
+		require.NoError(t, q.Begin())
+		defer q.Rollback()
+
+		require.NoError(t, q.UpdateLastLedgerExpIngest(123))
+		lastLedger, err := q.GetLastLedgerExpIngest()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(123), lastLedger)
+
+		require.NoError(t, q.UpsertAccounts([]xdr.LedgerEntry{account1, account2}))
+		modifiedAccount := account1
+		modifiedAccount.LastModifiedLedgerSeq = 1235
+		accountEntry := *account1.Data.Account
+		accountEntry.Balance = 42
+		modifiedAccount.Data.Account = &accountEntry
+		require.NoError(t, q.UpsertAccounts([]xdr.LedgerEntry{modifiedAccount}))
+
+		accounts, err := q.GetAccountsByIDs([]string{account1.Data.Account.AccountId.Address()})
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		assert.Equal(t, int64(42), accounts[0].Balance)
+		assert.Equal(t, uint32(1235), accounts[0].LastModifiedLedger)
+		assert.Equal(t, "hcnet.org", accounts[0].HomeDomain)
+
+		require.NoError(t, q.UpsertTrustLines([]xdr.LedgerEntry{eurTrustLine, usdTrustLine}))
+		require.NoError(t, q.UpsertTrustLines([]xdr.LedgerEntry{eurTrustLine}))
+		trustLines, err := q.GetSortedTrustLinesByAccountID(eurTrustLine.Data.TrustLine.AccountId.Address())
+		require.NoError(t, err)
+		require.Len(t, trustLines, 1)
+		assert.Equal(t, "EUR", trustLines[0].AssetCode)
+		assert.Equal(t, sponsor.Address(), trustLines[0].Sponsor.String)
+
+		_, err = q.InsertAccountData(data1)
+		require.NoError(t, err)
+		data, err := q.GetAccountDataByName(data1.Data.Data.AccountId.Address(), string(data1.Data.Data.DataName))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(data1.Data.Data.DataValue), []byte(data.Value))
+
+		require.NoError(t, q.TruncateExpingestStateTables())
+		count, err := q.CountAccounts()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestLiteHistoryQueries(t *testing.T) {
+	testDialects(t, func(t *testing.T, q *Q) {
+		sequence := uint32(123)
+		ledger := Ledger{
+			Sequence:                   int32(sequence),
+			LedgerHash:                 "4db1e4f145e9ee75162040d26284795e0697e2e84084624e7c6c723ebbf80118",
+			PreviousLedgerHash:         null.NewString("4b0b8bace3b2438b2404776ce57643966855487ba6384724a3c664c7aa4cd9e4", true),
+			TotalOrderID:               TotalOrderID{toid.New(int32(sequence), 0, 0).ToInt64()},
+			ImporterVersion:            321,
+			TransactionCount:           1,
+			SuccessfulTransactionCount: new(int32),
+			FailedTransactionCount:     new(int32),
+			OperationCount:             1,
+			TotalCoins:                 23451,
+			FeePool:                    213,
+			BaseReserve:                687,
+			MaxTxSetSize:               345,
+			ProtocolVersion:            12,
+			BaseFee:                    100,
+			ClosedAt:                   time.Now().UTC().Truncate(time.Second),
+			LedgerHeaderXDR:            null.NewString("temp", true),
+		}
+		*ledger.SuccessfulTransactionCount = 1
+		_, err := q.Exec(sq.Insert("history_ledgers").SetMap(ledgerToMap(ledger)))
+		require.NoError(t, err)
+
+		insertBuilder := q.NewTransactionBatchInsertBuilder(0)
+		require.NoError(t, insertBuilder.Add(buildLedgerTransaction(t, testTransaction{
+			index:         1,
+			envelopeXDR:   "AAAAACiSTRmpH6bHC6Ekna5e82oiGY5vKDEEUgkq9CB//t+rAAAAyAEXUhsAADDRAAAAAAAAAAAAAAABAAAAAAAAAAsBF1IbAABX4QAAAAAAAAAA",
+			resultXDR:     "AAAAAAAAASwAAAAAAAAAAwAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAFAAAAAAAAAAA=",
+			feeChangesXDR: "AAAAAA==",
+			metaXDR:       "AAAAAQAAAAAAAAAA",
+			hash:          "19aaa18db88605aedec04659fb45e06f240b022eb2d429e05133e4d53cd945ba",
+		}), sequence))
+		require.NoError(t, insertBuilder.Exec())
+
+		var found Ledger
+		require.NoError(t, q.LedgerBySequence(&found, int32(sequence)))
+		assert.Equal(t, ledger.LedgerHash, found.LedgerHash)
+		assert.True(t, ledger.ClosedAt.Equal(found.ClosedAt))
+
+		var transaction Transaction
+		require.NoError(t, q.TransactionByHash(&transaction, "19aaa18db88605aedec04659fb45e06f240b022eb2d429e05133e4d53cd945ba"))
+		assert.Equal(t, int32(sequence), transaction.LedgerSequence)
+		assert.True(t, transaction.Successful)
+		assert.True(t, ledger.ClosedAt.Equal(transaction.LedgerCloseTime))
+
+		var transactions []Transaction
+		require.NoError(t, q.Transactions().ForLedger(int32(sequence)).Select(&transactions))
+		assert.Len(t, transactions, 1)
+
+		start := toid.New(int32(sequence), 0, 0).ToInt64()
+		end := toid.New(int32(sequence+1), 0, 0).ToInt64()
+		require.NoError(t, q.DeleteRangeAll(start, end))
+		err = q.LedgerBySequence(&found, int32(sequence))
+		assert.True(t, q.NoRows(err))
+	})
+}
@@ -242,6 +242,7 @@ type IngestionQ interface {
 	GetOfferCompactionSequence() (uint32, error)
 	TruncateExpingestStateTables() error
 	DeleteRangeAll(start, end int64) error
+	EnsureHistoryPartitions(ledger uint32) error
 }
 
 // QAccounts defines account related queries.
@@ -309,13 +310,43 @@ type Asset struct {
 	Issuer string `db:"asset_issuer"`
 }
 
-// ExpAssetStat is a row in the exp_asset_stats table representing the stats per Asset
+// ExpAssetStat is a row in the exp_asset_stats table representing the stats per Asset.
+// Amount and NumAccounts only include authorized trust lines, the breakdown by
+// authorization state and the claimable balances are in Accounts and Balances.
 type ExpAssetStat struct {
-	AssetType   xdr.AssetType `db:"asset_type"`
-	AssetCode   string        `db:"asset_code"`
-	AssetIssuer string        `db:"asset_issuer"`
-	Amount      string        `db:"amount"`
-	NumAccounts int32         `db:"num_accounts"`
+	AssetType   xdr.AssetType           `db:"asset_type"`
+	AssetCode   string                  `db:"asset_code"`
+	AssetIssuer string                  `db:"asset_issuer"`
+	Accounts    ExpAssetStatAccounts    `db:"accounts"`
+	Balances    ExpAssetStatBalances    `db:"balances"`
+	Liabilities ExpAssetStatLiabilities `db:"liabilities"`
+	Amount      string                  `db:"amount"`
+	NumAccounts int32                   `db:"num_accounts"`
+}
+
+// ExpAssetStatAccounts represents the number of trust lines of an asset in
+// each authorization state and the number of claimable balances holding it.
+type ExpAssetStatAccounts struct {
+	Authorized                      int32 `json:"authorized"`
+	AuthorizedToMaintainLiabilities int32 `json:"authorized_to_maintain_liabilities"`
+	Unauthorized                    int32 `json:"unauthorized"`
+	ClaimableBalances               int32 `json:"claimable_balances"`
+}
+
+// ExpAssetStatBalances represents the amounts of an asset (in stroops) held by
+// trust lines in each authorization state and locked in claimable balances.
+type ExpAssetStatBalances struct {
+	Authorized                      string `json:"authorized"`
+	AuthorizedToMaintainLiabilities string `json:"authorized_to_maintain_liabilities"`
+	Unauthorized                    string `json:"unauthorized"`
+	ClaimableBalances               string `json:"claimable_balances"`
+}
+
+// ExpAssetStatLiabilities represents the total buying and selling liabilities
+// (in stroops) of the trust lines of an asset, ie. the amounts in offers.
+type ExpAssetStatLiabilities struct {
+	Buying  string `json:"buying"`
+	Selling string `json:"selling"`
 }
 
 // PagingToken returns a cursor for this asset stat
@@ -347,6 +378,7 @@ type QCreateAccountsHistory interface {
 type Effect struct {
 	HistoryAccountID   int64       `db:"history_account_id"`
 	Account            string      `db:"address"`
+	AccountMuxed       null.String `db:"address_muxed"`
 	HistoryOperationID int64       `db:"history_operation_id"`
 	Order              int32       `db:"order"`
 	Type               EffectType  `db:"type"`
@@ -482,6 +514,7 @@ type Operation struct {
 	Type                  xdr.OperationType `db:"type"`
 	DetailsString         null.String       `db:"details"`
 	SourceAccount         string            `db:"source_account"`
+	SourceAccountMuxed    null.String       `db:"source_account_muxed"`
 	TransactionSuccessful bool              `db:"transaction_successful"`
 }
 
@@ -737,33 +770,24 @@ func (q *Q) CloneIngestionQ() IngestionQ {
 // DeleteRangeAll deletes a range of rows from all history tables between
 // `start` and `end` (exclusive).
 func (q *Q) DeleteRangeAll(start, end int64) error {
-	err := q.DeleteRange(start, end, "history_effects", "history_operation_id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_effects")
-	}
-	err = q.DeleteRange(start, end, "history_operation_participants", "history_operation_id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_operation_participants")
-	}
-	err = q.DeleteRange(start, end, "history_operations", "id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_operations")
-	}
-	err = q.DeleteRange(start, end, "history_transaction_participants", "history_transaction_id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_transaction_participants")
-	}
-	err = q.DeleteRange(start, end, "history_transactions", "id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_transactions")
-	}
-	err = q.DeleteRange(start, end, "history_ledgers", "id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_ledgers")
-	}
-	err = q.DeleteRange(start, end, "history_trades", "history_operation_id")
-	if err != nil {
-		return errors.Wrap(err, "Error clearing history_trades")
+	for _, table := range []struct {
+		name  string
+		idCol string
+	}{
+		{"history_effects", "history_operation_id"},
+		{"history_operation_participants", "history_operation_id"},
+		{"history_operations", "id"},
+		{"history_transaction_participants", "history_transaction_id"},
+		{"history_transactions", "id"},
+		{"history_ledgers", "id"},
+		{"history_trades", "history_operation_id"},
+	} {
+		if !q.hasTable(table.name) {
+			continue
+		}
+		if err := q.DeleteRange(start, end, table.name, table.idCol); err != nil {
+			return errors.Wrapf(err, "Error clearing %s", table.name)
+		}
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -12,6 +13,7 @@ type MockEffectBatchInsertBuilder struct {
 // Add mock
 func (m *MockEffectBatchInsertBuilder) Add(
 	accountID int64,
+	muxedAccount null.String,
 	operationID int64,
 	order uint32,
 	effectType EffectType,
@@ -19,6 +21,7 @@ func (m *MockEffectBatchInsertBuilder) Add(
 ) error {
 	a := m.Called(
 		accountID,
+		muxedAccount,
 		operationID,
 		order,
 		effectType,
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"github.com/guregu/null"
 	"github.com/hcnet/go/xdr"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,6 +19,7 @@ func (m *MockOperationsBatchInsertBuilder) Add(
 	operationType xdr.OperationType,
 	details []byte,
 	sourceAccount string,
+	sourceAccountMuxed null.String,
 ) error {
 	a := m.Called(
 		id,
@@ -26,6 +28,7 @@ func (m *MockOperationsBatchInsertBuilder) Add(
 		operationType,
 		details,
 		sourceAccount,
+		sourceAccountMuxed,
 	)
 	return a.Error(0)
 }
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"github.com/guregu/null"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/xdr"
 )
@@ -15,6 +16,7 @@ type OperationBatchInsertBuilder interface {
 		operationType xdr.OperationType,
 		details []byte,
 		sourceAccount string,
+		sourceAccountMuxed null.String,
 	) error
 	Exec() error
 }
@@ -30,6 +32,7 @@ func (q *Q) NewOperationBatchInsertBuilder(maxBatchSize int) OperationBatchInser
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_operations"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -42,14 +45,16 @@ func (i *operationBatchInsertBuilder) Add(
 	operationType xdr.OperationType,
 	details []byte,
 	sourceAccount string,
+	sourceAccountMuxed null.String,
 ) error {
 	return i.builder.Row(map[string]interface{}{
-		"id":                id,
-		"transaction_id":    transactionID,
-		"application_order": applicationOrder,
-		"type":              operationType,
-		"details":           details,
-		"source_account":    sourceAccount,
+		"id":                   id,
+		"transaction_id":       transactionID,
+		"application_order":    applicationOrder,
+		"type":                 operationType,
+		"details":              details,
+		"source_account":       sourceAccount,
+		"source_account_muxed": sourceAccountMuxed,
 	})
 
 }
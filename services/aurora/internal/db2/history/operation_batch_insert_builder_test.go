@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/guregu/null"
 	"github.com/hcnet/go/services/aurora/internal/test"
 	"github.com/hcnet/go/services/aurora/internal/toid"
 	"github.com/hcnet/go/xdr"
@@ -52,6 +53,7 @@ func TestAddOperation(t *testing.T) {
 		xdr.OperationTypePayment,
 		details,
 		"GANFZDRBCNTUXIODCJEYMACPMCSZEVE4WZGZ3CZDZ3P2SXK4KH75IK6Y",
+		null.String{},
 	)
 	tt.Assert.NoError(err)
 
@@ -25,6 +25,7 @@ func (q *Q) NewOperationParticipantBatchInsertBuilder(maxBatchSize int) Operatio
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_operation_participants"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -28,6 +28,7 @@ func (q *Q) NewTransactionParticipantsBatchInsertBuilder(maxBatchSize int) Trans
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_transaction_participants"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -0,0 +1,209 @@
+package history
+
+import (
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/support/errors"
+)
+
+// HistoryPartitioning describes how history tables are partitioned, see
+// schema.EnablePartitioning.
+type HistoryPartitioning struct {
+	// Size is the number of ledgers per partition. It's zero when history
+	// tables are not partitioned.
+	Size uint32
+	// Start is the first ledger stored in regular partitions, older ledgers
+	// are stored in the legacy partitions.
+	Start uint32
+}
+
+// Enabled returns true when history tables are partitioned.
+func (p HistoryPartitioning) Enabled() bool {
+	return p.Size > 0
+}
+
+// GetHistoryPartitioning returns the partitioning of history tables.
+func (q *Q) GetHistoryPartitioning() (HistoryPartitioning, error) {
+	var partitioning HistoryPartitioning
+	for key, dest := range map[string]*uint32{
+		schema.PartitionSizeKey:  &partitioning.Size,
+		schema.PartitionStartKey: &partitioning.Start,
+	} {
+		value, err := q.getValueFromStore(key, false)
+		if err != nil {
+			return HistoryPartitioning{}, err
+		}
+		if value == "" {
+			return HistoryPartitioning{}, nil
+		}
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return HistoryPartitioning{}, errors.Wrapf(err, "invalid %s value", key)
+		}
+		*dest = uint32(parsed)
+	}
+	return partitioning, nil
+}
+
+// EnsureHistoryPartitions creates the partitions of history tables needed to
+// ingest ledger, and the following ones so they are created ahead of
+// ingestion. It does nothing when history tables are not partitioned.
+func (q *Q) EnsureHistoryPartitions(ledger uint32) error {
+	partitioning, err := q.GetHistoryPartitioning()
+	if err != nil {
+		return err
+	}
+	if !partitioning.Enabled() {
+		return nil
+	}
+
+	current := schema.PartitionStart(ledger, partitioning.Size)
+	for _, start := range []uint32{current, current + partitioning.Size} {
+		if err := q.ensureHistoryPartition(partitioning, start); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureHistoryPartition creates the partitions starting at start if they
+// don't exist. Parallel reingestion workers may create the same partition, so
+// its creation is serialized with an advisory lock held until the end of the
+// transaction. A transaction is started when q is not in one already.
+func (q *Q) ensureHistoryPartition(partitioning HistoryPartitioning, start uint32) error {
+	if q.GetTx() != nil {
+		return q.createHistoryPartition(partitioning, start)
+	}
+
+	if err := q.Begin(); err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer q.Rollback()
+	if err := q.createHistoryPartition(partitioning, start); err != nil {
+		return err
+	}
+	return q.Commit()
+}
+
+func (q *Q) createHistoryPartition(partitioning HistoryPartitioning, start uint32) error {
+	_, err := q.ExecRaw(
+		"SELECT pg_advisory_xact_lock(hashtext($1))",
+		schema.PartitionName("history_ledgers", start),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "could not lock partition %d", start)
+	}
+
+	if start < partitioning.Start {
+		// Old ledgers are stored in the legacy partitions until they are
+		// dropped.
+		exists, err := q.tableExists(schema.LegacyPartitionName("history_ledgers"))
+		if err != nil || exists {
+			return err
+		}
+	}
+
+	// Partitions are created in the order of schema.PartitionedTables, so all
+	// of them exist when the history_ledgers one does.
+	last := schema.PartitionedTables[len(schema.PartitionedTables)-1]
+	exists, err := q.tableExists(schema.PartitionName(last.Name, start))
+	if err != nil || exists {
+		return err
+	}
+
+	for _, table := range schema.PartitionedTables {
+		exists, err = q.tableExists(schema.PartitionName(table.Name, start))
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err = q.ExecRaw(schema.CreatePartitionSQL(table, start, partitioning.Size)); err != nil {
+			return errors.Wrapf(err, "could not create partition of %s", table.Name)
+		}
+	}
+	return nil
+}
+
+// DropHistoryPartitionsBefore drops the partitions of history tables which
+// only contain ledgers older than ledger and returns their names. Partitions
+// also containing newer ledgers are kept, so more ledgers than requested
+// may be retained.
+func (q *Q) DropHistoryPartitionsBefore(ledger uint32) ([]string, error) {
+	var dropped []string
+	for _, table := range schema.PartitionedTables {
+		partitions, err := q.HistoryPartitionsBefore(table.Name, ledger)
+		if err != nil {
+			return dropped, err
+		}
+		for _, partition := range partitions {
+			if err = q.DropHistoryPartition(partition); err != nil {
+				return dropped, err
+			}
+			dropped = append(dropped, partition)
+		}
+	}
+	return dropped, nil
+}
+
+// HistoryPartitionsBefore returns the partitions of the history table which
+// only contain ledgers older than ledger.
+func (q *Q) HistoryPartitionsBefore(table string, ledger uint32) ([]string, error) {
+	partitioning, err := q.GetHistoryPartitioning()
+	if err != nil {
+		return nil, err
+	}
+	if !partitioning.Enabled() {
+		return nil, errors.New("history tables are not partitioned")
+	}
+
+	var partitions []string
+	err = q.SelectRaw(&partitions, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass
+		ORDER BY c.relname`,
+		table,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list partitions of %s", table)
+	}
+
+	var result []string
+	for _, partition := range partitions {
+		var end uint64
+		if partition == schema.LegacyPartitionName(table) {
+			end = uint64(partitioning.Start)
+		} else if start, ok := schema.ParsePartitionName(table, partition); ok {
+			end = uint64(start) + uint64(partitioning.Size)
+		} else {
+			continue
+		}
+		if end <= uint64(ledger) {
+			result = append(result, partition)
+		}
+	}
+	return result, nil
+}
+
+// DropHistoryPartition drops a partition of a history table.
+func (q *Q) DropHistoryPartition(partition string) error {
+	if _, err := q.ExecRaw("DROP TABLE " + pq.QuoteIdentifier(partition)); err != nil {
+		return errors.Wrapf(err, "could not drop partition %s", partition)
+	}
+	return nil
+}
+
+func (q *Q) tableExists(name string) (bool, error) {
+	var exists bool
+	err := q.GetRaw(&exists, "SELECT to_regclass($1) IS NOT NULL", pq.QuoteIdentifier(name))
+	if err != nil {
+		return false, errors.Wrapf(err, "could not check if %s exists", name)
+	}
+	return exists, nil
+}
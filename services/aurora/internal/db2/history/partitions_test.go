@@ -0,0 +1,126 @@
+package history
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/services/aurora/internal/toid"
+)
+
+func TestHistoryPartitions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	defer test.ResetAuroraDB(t, tt.AuroraDB)
+	q := &Q{tt.AuroraSession()}
+
+	partitioning, err := q.GetHistoryPartitioning()
+	tt.Assert.NoError(err)
+	tt.Assert.False(partitioning.Enabled())
+	// Nothing to do when history tables are not partitioned.
+	tt.Assert.NoError(q.EnsureHistoryPartitions(1000))
+
+	insertParticipant := func(ledger int32) {
+		_, err = q.ExecRaw(
+			"INSERT INTO history_operation_participants (history_operation_id, history_account_id) VALUES ($1, 1)",
+			toid.New(ledger, 1, 1).ToInt64(),
+		)
+		tt.Assert.NoError(err)
+	}
+	insertParticipant(50)
+
+	tt.Assert.NoError(schema.EnablePartitioning(tt.AuroraDB.DB, 100))
+	tt.Assert.EqualError(
+		schema.EnablePartitioning(tt.AuroraDB.DB, 100),
+		"history tables are already partitioned",
+	)
+
+	partitioning, err = q.GetHistoryPartitioning()
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(HistoryPartitioning{Size: 100, Start: 100}, partitioning)
+
+	for _, name := range []string{"history_effects_legacy", "history_effects_p100", "history_effects_p200"} {
+		exists, err := q.tableExists(name)
+		tt.Assert.NoError(err)
+		tt.Assert.True(exists, name)
+	}
+
+	// Partitions are created ahead of ingestion.
+	tt.Assert.NoError(q.EnsureHistoryPartitions(250))
+	exists, err := q.tableExists("history_ledgers_p300")
+	tt.Assert.NoError(err)
+	tt.Assert.True(exists)
+
+	insertParticipant(150)
+	insertParticipant(250)
+
+	dropped, err := q.DropHistoryPartitionsBefore(199)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(dropped, len(schema.PartitionedTables))
+
+	dropped, err = q.DropHistoryPartitionsBefore(200)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(dropped, len(schema.PartitionedTables))
+	tt.Assert.Contains(dropped, "history_operation_participants_p100")
+
+	var count int
+	tt.Assert.NoError(q.GetRaw(&count, "SELECT COUNT(*) FROM history_operation_participants"))
+	tt.Assert.Equal(1, count)
+
+	// Old ledgers get regular partitions once the legacy ones are dropped.
+	tt.Assert.NoError(q.EnsureHistoryPartitions(50))
+	insertParticipant(50)
+	tt.Assert.NoError(q.GetRaw(&count, "SELECT COUNT(*) FROM history_operation_participants"))
+	tt.Assert.Equal(2, count)
+}
+
+func TestEnsureHistoryPartitionsConcurrently(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetAuroraDB(t, tt.AuroraDB)
+	defer test.ResetAuroraDB(t, tt.AuroraDB)
+	tt.Assert.NoError(schema.EnablePartitioning(tt.AuroraDB.DB, 100))
+
+	// Reingestion workers whose ranges fall in the same partition ensure it
+	// concurrently, some of them inside ingestion transactions.
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			q := &Q{tt.AuroraSession()}
+			if i%2 == 0 {
+				if err := q.Begin(); err != nil {
+					errs <- err
+					return
+				}
+				defer q.Rollback()
+			}
+			if err := q.EnsureHistoryPartitions(uint32(1000 + i)); err != nil {
+				errs <- err
+				return
+			}
+			if q.GetTx() != nil {
+				errs <- q.Commit()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		tt.Assert.NoError(err)
+	}
+
+	q := &Q{tt.AuroraSession()}
+	for _, table := range schema.PartitionedTables {
+		for _, start := range []uint32{1000, 1100} {
+			exists, err := q.tableExists(schema.PartitionName(table.Name, start))
+			tt.Assert.NoError(err)
+			tt.Assert.True(exists, schema.PartitionName(table.Name, start))
+		}
+	}
+}
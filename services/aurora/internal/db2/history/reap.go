@@ -0,0 +1,50 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// ElderTOID returns the smallest value of column, a TOID column of the
+// history table, or 0 when the table is empty.
+func (q *Q) ElderTOID(table, column string) (int64, error) {
+	var elder int64
+	err := q.GetRaw(&elder, fmt.Sprintf(
+		"SELECT COALESCE(MIN(%s), 0) FROM %s",
+		pq.QuoteIdentifier(column), pq.QuoteIdentifier(table),
+	))
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not get elder row of %s", table)
+	}
+	return elder, nil
+}
+
+// CountTOIDRange returns the number of rows of the history table whose TOID
+// column is in [start, end).
+func (q *Q) CountTOIDRange(table, column string, start, end int64) (int64, error) {
+	var count int64
+	err := q.GetRaw(&count, fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE %s >= $1 AND %s < $2",
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(column), pq.QuoteIdentifier(column),
+	), start, end)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not count rows of %s", table)
+	}
+	return count, nil
+}
+
+// DeleteTOIDRange deletes the rows of the history table whose TOID column is
+// in [start, end) and returns the number of deleted rows.
+func (q *Q) DeleteTOIDRange(table, column string, start, end int64) (int64, error) {
+	result, err := q.ExecRaw(fmt.Sprintf(
+		"DELETE FROM %s WHERE %s >= $1 AND %s < $2",
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(column), pq.QuoteIdentifier(column),
+	), start, end)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not delete rows of %s", table)
+	}
+	return result.RowsAffected()
+}
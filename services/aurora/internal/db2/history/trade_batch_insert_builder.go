@@ -42,6 +42,7 @@ func (q *Q) NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_trades"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -38,6 +38,7 @@ func (q *Q) NewTransactionBatchInsertBuilder(maxBatchSize int) TransactionBatchI
 		builder: db.BatchInsertBuilder{
 			Table:        q.GetTable("history_transactions"),
 			MaxBatchSize: maxBatchSize,
+			UseCopy:      true,
 		},
 	}
 }
@@ -204,6 +205,7 @@ type TransactionWithoutLedger struct {
 	LedgerSequence       int32          `db:"ledger_sequence"`
 	ApplicationOrder     int32          `db:"application_order"`
 	Account              string         `db:"account"`
+	AccountMuxed         null.String    `db:"account_muxed"`
 	AccountSequence      string         `db:"account_sequence"`
 	MaxFee               int64          `db:"max_fee"`
 	FeeCharged           int64          `db:"fee_charged"`
@@ -220,11 +222,21 @@ type TransactionWithoutLedger struct {
 	UpdatedAt            time.Time      `db:"updated_at"`
 	Successful           bool           `db:"successful"`
 	FeeAccount           null.String    `db:"fee_account"`
+	FeeAccountMuxed      null.String    `db:"fee_account_muxed"`
 	InnerTransactionHash null.String    `db:"inner_transaction_hash"`
 	NewMaxFee            null.Int       `db:"new_max_fee"`
 	InnerSignatures      pq.StringArray `db:"inner_signatures"`
 }
 
+// MuxedAddress returns the M... address of account or a null string when
+// account is not a muxed account.
+func MuxedAddress(account xdr.MuxedAccount) null.String {
+	if account.Type != xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		return null.StringFromPtr(nil)
+	}
+	return null.StringFrom(account.Address())
+}
+
 func transactionToRow(transaction io.LedgerTransaction, sequence uint32) (TransactionWithoutLedger, error) {
 	envelopeBase64, err := xdr.MarshalBase64(transaction.Envelope)
 	if err != nil {
@@ -265,18 +277,21 @@ func transactionToRow(transaction io.LedgerTransaction, sequence uint32) (Transa
 		Successful:       transaction.Result.Successful(),
 	}
 	t.TotalOrderID.ID = toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64()
+	t.AccountMuxed = MuxedAddress(transaction.Envelope.SourceAccount())
 
 	if transaction.Envelope.IsFeeBump() {
 		innerHash := transaction.Result.InnerHash()
 		t.InnerTransactionHash = null.StringFrom(hex.EncodeToString(innerHash[:]))
 		feeAccount := transaction.Envelope.FeeBumpAccount().ToAccountId()
 		t.FeeAccount = null.StringFrom(feeAccount.Address())
+		t.FeeAccountMuxed = MuxedAddress(transaction.Envelope.FeeBumpAccount())
 		t.NewMaxFee = null.IntFrom(transaction.Envelope.FeeBumpFee())
 		t.InnerSignatures = signatures(transaction.Envelope.Signatures())
 		t.Signatures = signatures(transaction.Envelope.FeeBumpSignatures())
 	} else {
 		t.InnerTransactionHash = null.StringFromPtr(nil)
 		t.FeeAccount = null.StringFromPtr(nil)
+		t.FeeAccountMuxed = null.StringFromPtr(nil)
 		t.NewMaxFee = null.IntFromPtr(nil)
 		t.InnerSignatures = nil
 		t.Signatures = signatures(transaction.Envelope.Signatures())
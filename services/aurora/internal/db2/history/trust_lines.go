@@ -3,10 +3,14 @@ package history
 import (
 	"database/sql"
 	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/guregu/null"
 	"github.com/lib/pq"
+	"github.com/hcnet/go/services/aurora/internal/db2"
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/xdr"
 )
@@ -143,6 +147,7 @@ func (q *Q) UpsertTrustLines(trustLines []xdr.LedgerEntry) error {
 	var flags, lastModifiedLedger []xdr.Uint32
 	var assetType []xdr.AssetType
 	var sponsor []null.String
+	rows := make([]map[string]interface{}, 0, len(trustLines))
 
 	for _, entry := range trustLines {
 		if entry.Data.Type != xdr.LedgerEntryTypeTrustline {
@@ -155,6 +160,8 @@ func (q *Q) UpsertTrustLines(trustLines []xdr.LedgerEntry) error {
 		}
 
 		m := trustLineToMap(entry)
+		m["ledger_key"] = key
+		rows = append(rows, m)
 		ledgerKey = append(ledgerKey, key)
 		accountID = append(accountID, m["account_id"].(string))
 		assetType = append(assetType, m["asset_type"].(xdr.AssetType))
@@ -169,6 +176,10 @@ func (q *Q) UpsertTrustLines(trustLines []xdr.LedgerEntry) error {
 		sponsor = append(sponsor, m["sponsor"].(null.String))
 	}
 
+	if q.Dialect() == "sqlite3" {
+		return q.upsertRows("trust_lines", "ledger_key", rows)
+	}
+
 	sql := `
 	WITH r AS
 		(SELECT
@@ -256,6 +267,76 @@ func (q *Q) GetSortedTrustLinesByAccountIDs(id []string) ([]TrustLine, error) {
 	return data, err
 }
 
+// HolderPagingToken returns a cursor for this trust line in the list of the
+// holders of its asset.
+func (trustLine TrustLine) HolderPagingToken() string {
+	return fmt.Sprintf("%d_%s", trustLine.Balance, trustLine.AccountID)
+}
+
+// ParseAssetHoldersCursor returns the balance and account id of a cursor
+// returned by HolderPagingToken.
+func ParseAssetHoldersCursor(cursor string) (int64, string, error) {
+	parts := strings.SplitN(cursor, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", errors.Errorf("invalid asset holders cursor: %v", cursor)
+	}
+
+	balance, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || balance < 0 {
+		return 0, "", errors.Errorf("invalid balance in asset holders cursor: %v", cursor)
+	}
+
+	if _, err := xdr.AddressToAccountId(parts[1]); err != nil {
+		return 0, "", errors.Errorf("invalid account id in asset holders cursor: %v", cursor)
+	}
+
+	return balance, parts[1], nil
+}
+
+// GetAssetHolders returns a page of the trust lines of an asset ordered by
+// balance, ties being ordered by account id.
+func (q *Q) GetAssetHolders(asset xdr.Asset, page db2.PageQuery) ([]TrustLine, error) {
+	var assetType xdr.AssetType
+	var code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return nil, errors.Wrap(err, "could not extract asset")
+	}
+
+	sql := selectTrustLines.Where(map[string]interface{}{
+		"asset_type":   assetType,
+		"asset_code":   code,
+		"asset_issuer": issuer,
+	})
+
+	var cursorComparison, orderBy string
+	switch page.Order {
+	case "asc":
+		cursorComparison, orderBy = ">", "asc"
+	case "desc":
+		cursorComparison, orderBy = "<", "desc"
+	default:
+		return nil, fmt.Errorf("invalid page order %s", page.Order)
+	}
+
+	if page.Cursor != "" {
+		balance, accountID, err := ParseAssetHoldersCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		sql = sql.Where("(balance, account_id) "+cursorComparison+" (?, ?)", balance, accountID)
+	}
+
+	sql = sql.OrderBy("balance "+orderBy, "account_id "+orderBy).Limit(page.Limit)
+
+	var results []TrustLine
+	if err := q.Select(&results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+
+	return results, nil
+}
+
 func trustLineEntryToLedgerKeyString(entry xdr.LedgerEntry) (string, error) {
 	ledgerKey := entry.LedgerKey()
 	key, err := ledgerKey.MarshalBinary()
@@ -6,7 +6,9 @@ import (
 
 	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/hcnet/go/services/aurora/internal/db2"
 	"github.com/hcnet/go/services/aurora/internal/test"
 	"github.com/hcnet/go/xdr"
 )
@@ -479,3 +481,67 @@ func TestAssetsForAddress(t *testing.T) {
 
 	tt.Assert.Equal(expected, assetsToBalance)
 }
+
+func TestGetAssetHolders(t *testing.T) {
+	testDialects(t, func(t *testing.T, q *Q) {
+		usdTrustLine3 := usdTrustLine
+		trustLine := *usdTrustLine.Data.TrustLine
+		trustLine.AccountId = account1.Data.Account.AccountId
+		trustLine.Balance = 20000
+		usdTrustLine3.Data.TrustLine = &trustLine
+		require.NoError(t, q.UpsertTrustLines([]xdr.LedgerEntry{
+			eurTrustLine, usdTrustLine, usdTrustLine2, usdTrustLine3,
+		}))
+
+		// usdTrustLine and usdTrustLine2 have the same balance and are ordered
+		// by account id.
+		first, second := usdTrustLine, usdTrustLine2
+		if first.Data.TrustLine.AccountId.Address() > second.Data.TrustLine.AccountId.Address() {
+			first, second = second, first
+		}
+		asc := []string{
+			first.Data.TrustLine.AccountId.Address(),
+			second.Data.TrustLine.AccountId.Address(),
+			usdTrustLine3.Data.TrustLine.AccountId.Address(),
+		}
+
+		asset := usdTrustLine.Data.TrustLine.Asset
+		pq := db2.PageQuery{Order: db2.OrderAscending, Limit: 10}
+		holders, err := q.GetAssetHolders(asset, pq)
+		require.NoError(t, err)
+		require.Len(t, holders, 3)
+		for i, holder := range holders {
+			assert.Equal(t, asc[i], holder.AccountID)
+		}
+
+		pq = db2.PageQuery{Order: db2.OrderAscending, Limit: 1, Cursor: holders[0].HolderPagingToken()}
+		page, err := q.GetAssetHolders(asset, pq)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, asc[1], page[0].AccountID)
+
+		pq = db2.PageQuery{Order: db2.OrderDescending, Limit: 10, Cursor: holders[2].HolderPagingToken()}
+		page, err = q.GetAssetHolders(asset, pq)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, asc[1], page[0].AccountID)
+		assert.Equal(t, asc[0], page[1].AccountID)
+
+		pq = db2.PageQuery{Order: db2.OrderAscending, Limit: 10, Cursor: "invalid"}
+		_, err = q.GetAssetHolders(asset, pq)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseAssetHoldersCursor(t *testing.T) {
+	account := account1.Data.Account.AccountId.Address()
+	balance, accountID, err := ParseAssetHoldersCursor("20000_" + account)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20000), balance)
+	assert.Equal(t, account, accountID)
+
+	for _, cursor := range []string{"", "20000", "-1_" + account, "abc_" + account, "20000_GABC"} {
+		_, _, err = ParseAssetHoldersCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
@@ -37,6 +37,9 @@
 // migrations/40_fix_inner_tx_max_fee_constraint.sql (392B)
 // migrations/41_add_sponsor_to_state_tables.sql (800B)
 // migrations/42_add_num_sponsored_and_num_sponsoring_to_accounts.sql (276B)
+// migrations/43_add_muxed_accounts.sql (525B)
+// migrations/44_asset_stats_breakdown.sql (547B)
+// migrations/45_trust_lines_by_asset_balance.sql (284B)
 // migrations/4_add_protocol_version.sql (188B)
 // migrations/5_create_trades_table.sql (1.1kB)
 // migrations/6_create_assets_table.sql (366B)
@@ -852,6 +855,66 @@ func migrations42_add_num_sponsored_and_num_sponsoring_to_accountsSql() (*asset,
 	return a, nil
 }
 
+var _migrations43_add_muxed_accountsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9d\x90\x3d\x0f\x82\x30\x10\x86\xf7\xfe\x8a\x1b\x35\xc2\x6a\x62\x98\xd0\xb2\x55\x30\x04\x66\xd2\x94\x43\x18\x68\xc9\xb5\xf8\xf1\xef\x75\x2c\x8a\x62\x9c\xef\xbd\xf7\xe3\x09\x43\xd8\xf4\xdd\x99\xa4\x43\x28\x07\xc6\x62\x51\x24\x39\x14\xf1\x5e\x24\xd0\x76\xd6\x19\xba\x57\x8e\xa4\xb6\x52\xb9\xce\x68\xcb\x62\xce\xe1\x90\x89\xf2\x98\x82\x54\xca\x8c\xda\x55\xfd\x78\xc3\x1a\x2e\x92\x54\x2b\x69\xb5\xdd\xad\x21\x2d\x85\x08\x7c\x69\x83\x58\x7d\x97\x47\xf3\xd9\x66\xc0\x67\xb7\xd7\x64\x6b\x46\x52\x7f\x3a\x62\xd3\xa0\x72\xd3\x21\x75\x4d\x68\xed\x67\x9f\xd0\xa3\xc4\xcd\x55\xff\xc0\x89\xe7\xd9\x69\x16\x54\x30\x39\xbd\x81\x59\xe6\xe0\xbf\xcf\x81\x58\xd8\x3d\x29\xe6\x0f\x8f\xd8\x03\xb4\x60\x15\xba\x0d\x02\x00\x00")
+
+func migrations43_add_muxed_accountsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations43_add_muxed_accountsSql,
+		"migrations/43_add_muxed_accounts.sql",
+	)
+}
+
+func migrations43_add_muxed_accountsSql() (*asset, error) {
+	bytes, err := migrations43_add_muxed_accountsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/43_add_muxed_accounts.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x62, 0xca, 0x64, 0x67, 0xdf, 0xd8, 0x5c, 0xa4, 0xa5, 0x5c, 0xb4, 0x73, 0x8f, 0xa6, 0x54, 0xae, 0x3d, 0x8a, 0x15, 0x94, 0x93, 0xf2, 0x6d, 0xd8, 0xed, 0x70, 0x99, 0xd3, 0x2a, 0xa3, 0x2f, 0x5f}}
+	return a, nil
+}
+
+var _migrations44_asset_stats_breakdownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x91\x41\x6b\xc2\x40\x10\x85\xef\xfb\x2b\x86\x5c\x72\x30\x01\xcf\xf5\x14\xbb\xf1\x20\xdb\xa4\xd8\xe4\xbc\x4c\xd2\x45\x07\x36\x1b\x71\x27\xb4\xb5\xf8\xdf\x2b\xa4\xca\x9a\x16\xf1\x30\x87\x79\xef\xcd\xf0\xc1\x4b\x53\x98\x75\xb4\x3d\x20\x1b\xa8\xf7\x42\x64\xaa\xca\x37\x50\x65\x4b\x95\x83\xf9\xdc\x6b\xf4\xde\xb0\xf6\x8c\xec\x45\x26\x25\x3c\x97\xaa\x7e\x29\x00\xdb\xb6\x1f\x1c\x7b\x58\xbf\x95\xc5\x12\x8a\xb2\x82\xa2\x56\x0a\x64\xbe\xca\x6a\x55\x41\xfc\x1d\xe1\xc0\xbb\xfe\x40\x47\xf3\x1e\x3d\xc1\x3c\x81\x40\xd0\xdc\xeb\x0e\xc9\xf1\x79\xb4\x25\x6c\xc8\x12\x93\xf1\xbf\xc1\xc1\xfd\xb9\x6d\x2d\x52\x87\x8d\x35\xba\x41\x8b\xae\x1d\xb3\xa7\x38\x09\xa1\x2e\xd6\xa3\x50\xd1\x3c\x7a\x14\x6b\x8c\x4e\xc0\x46\xf1\x5f\xb4\xb3\x35\x81\x0b\xfe\xdd\xe1\x6b\x86\x2f\x72\xdb\xeb\x6f\x6f\xac\xbd\xee\xa7\x78\x21\x44\x1a\xf4\x25\xfb\x0f\x77\xbf\x31\xb9\x29\x5f\xa7\x95\x25\x37\xea\x85\xf9\x56\x0d\x60\x17\xe2\x07\xc2\x8b\x61\x6e\x23\x02\x00\x00")
+
+func migrations44_asset_stats_breakdownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations44_asset_stats_breakdownSql,
+		"migrations/44_asset_stats_breakdown.sql",
+	)
+}
+
+func migrations44_asset_stats_breakdownSql() (*asset, error) {
+	bytes, err := migrations44_asset_stats_breakdownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/44_asset_stats_breakdown.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1e, 0xdc, 0x30, 0x04, 0xbc, 0x20, 0x2b, 0x44, 0x15, 0xf4, 0x7d, 0x04, 0x32, 0x63, 0x40, 0x7e, 0x1d, 0x6b, 0xcf, 0x49, 0xad, 0x9f, 0x09, 0x80, 0xab, 0xfd, 0xed, 0x4b, 0x13, 0x13, 0x7a, 0x20}}
+	return a, nil
+}
+
+var _migrations45_trust_lines_by_asset_balanceSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x8e\xd1\x0a\xc2\x20\x18\x85\xef\x7d\x8a\xff\xb2\x68\x7b\x82\x5d\xc5\x66\x21\x84\x86\x73\xb0\xae\xc4\x39\x09\x61\xe9\x50\x47\xec\xed\x1b\x2c\x62\x04\x41\x77\x1f\xe7\x1c\x0e\x5f\x9e\xc3\xe1\x61\xef\x41\x25\x03\xcd\x08\xce\xa7\xa0\x5c\x54\x3a\x59\xef\x10\x2a\x39\x3e\x0a\x0c\x84\x56\xb8\x85\x92\xd1\xb2\xe1\x1c\x53\x71\xb9\x01\x39\x01\x65\x02\x70\x4b\x6a\x51\x43\x0a\x53\x4c\x72\xb0\xce\x44\xd9\xcd\x52\xc5\x68\x92\xec\xd4\xa0\x9c\x36\xc0\xe8\xb6\x87\xa6\x26\xf4\x0c\x5d\x0a\xc6\xc0\x6e\x5d\xa6\x79\x34\x19\xac\xac\x7d\xff\x61\x1b\xe3\x64\x42\x06\xef\xa7\x25\xd6\xda\x4f\x6e\x29\xfa\x7d\x81\x50\xbe\x71\xaf\xfc\xd3\x7d\xdb\x57\x9c\x5d\x7f\xb8\xff\xe1\x5d\xa0\x17\x3b\xb8\x10\xad\x1c\x01\x00\x00")
+
+func migrations45_trust_lines_by_asset_balanceSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations45_trust_lines_by_asset_balanceSql,
+		"migrations/45_trust_lines_by_asset_balance.sql",
+	)
+}
+
+func migrations45_trust_lines_by_asset_balanceSql() (*asset, error) {
+	bytes, err := migrations45_trust_lines_by_asset_balanceSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/45_trust_lines_by_asset_balance.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8b, 0x53, 0x3a, 0x2f, 0x1e, 0x64, 0x0b, 0x08, 0xab, 0x9c, 0x6f, 0xfe, 0x06, 0xcb, 0x8b, 0x5e, 0x9b, 0x40, 0x52, 0x43, 0x29, 0xd8, 0x95, 0x5e, 0xde, 0x27, 0xb2, 0x7a, 0xac, 0x88, 0x34, 0xee}}
+	return a, nil
+}
+
 var _migrations4_add_protocol_versionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\xcd\xb1\x0a\xc2\x30\x10\x06\xe0\x3d\x4f\xf1\xef\x52\x70\xef\x14\x4d\x9d\xce\x44\x4a\x32\x38\x15\xd1\xa3\x06\x6a\xae\x5c\x82\xe2\xdb\xbb\xba\x88\x4f\xf0\x75\x1d\x36\x8f\x3c\xeb\xa5\x31\xd2\x6a\x2c\xc5\x61\x44\xb4\x3b\x1a\x10\x3c\x9d\x71\xcf\xb5\x89\xbe\xa7\x85\x6f\x33\x6b\x85\x01\xac\x73\xd8\x07\x4a\x47\x8f\x55\xa5\xc9\x55\x96\xe9\xc9\x5a\xb3\x14\xe4\xd2\x78\x66\x85\x1b\x0e\x36\x51\xc4\x16\x3e\x44\xf8\x44\xd4\x1b\xf3\x6d\x39\x79\x95\xff\x9a\x1b\xc3\xe9\x97\xd5\x9b\x4f\x00\x00\x00\xff\xff\x83\xbb\x30\x2e\xbc\x00\x00\x00")
 
 func migrations4_add_protocol_versionSqlBytes() ([]byte, error) {
@@ -1120,6 +1183,9 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/40_fix_inner_tx_max_fee_constraint.sql":                  migrations40_fix_inner_tx_max_fee_constraintSql,
 	"migrations/41_add_sponsor_to_state_tables.sql":                      migrations41_add_sponsor_to_state_tablesSql,
 	"migrations/42_add_num_sponsored_and_num_sponsoring_to_accounts.sql": migrations42_add_num_sponsored_and_num_sponsoring_to_accountsSql,
+	"migrations/43_add_muxed_accounts.sql":                               migrations43_add_muxed_accountsSql,
+	"migrations/44_asset_stats_breakdown.sql":                            migrations44_asset_stats_breakdownSql,
+	"migrations/45_trust_lines_by_asset_balance.sql":                     migrations45_trust_lines_by_asset_balanceSql,
 	"migrations/4_add_protocol_version.sql":                              migrations4_add_protocol_versionSql,
 	"migrations/5_create_trades_table.sql":                               migrations5_create_trades_tableSql,
 	"migrations/6_create_assets_table.sql":                               migrations6_create_assets_tableSql,
@@ -1208,6 +1274,9 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"40_fix_inner_tx_max_fee_constraint.sql":                  &bintree{migrations40_fix_inner_tx_max_fee_constraintSql, map[string]*bintree{}},
 		"41_add_sponsor_to_state_tables.sql":                      &bintree{migrations41_add_sponsor_to_state_tablesSql, map[string]*bintree{}},
 		"42_add_num_sponsored_and_num_sponsoring_to_accounts.sql": &bintree{migrations42_add_num_sponsored_and_num_sponsoring_to_accountsSql, map[string]*bintree{}},
+		"43_add_muxed_accounts.sql":                               &bintree{migrations43_add_muxed_accountsSql, map[string]*bintree{}},
+		"44_asset_stats_breakdown.sql":                            &bintree{migrations44_asset_stats_breakdownSql, map[string]*bintree{}},
+		"45_trust_lines_by_asset_balance.sql":                     &bintree{migrations45_trust_lines_by_asset_balanceSql, map[string]*bintree{}},
 		"4_add_protocol_version.sql":                              &bintree{migrations4_add_protocol_versionSql, map[string]*bintree{}},
 		"5_create_trades_table.sql":                               &bintree{migrations5_create_trades_tableSql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                               &bintree{migrations6_create_assets_tableSql, map[string]*bintree{}},
@@ -0,0 +1,437 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// Catalog describes the objects of a database schema which are compared to
+// detect drift.
+type Catalog struct {
+	Tables map[string]CatalogTable
+	// Types maps user defined types to their definition.
+	Types map[string]string
+	// Functions holds the signatures of functions and aggregates.
+	Functions map[string]bool
+}
+
+// CatalogTable describes a table of a Catalog. Partitions are not listed,
+// they are part of their partitioned table.
+type CatalogTable struct {
+	// Columns maps column names to their type, nullability and default.
+	Columns map[string]string
+	// Indexes maps index names to their definition.
+	Indexes map[string]string
+	// InvalidIndexes lists indexes which failed to build or, for partitioned
+	// tables, which are not attached to an index of every partition.
+	InvalidIndexes map[string]bool
+	Partitioned    bool
+}
+
+// LoadCatalog reads the catalog of the current schema of db.
+func LoadCatalog(db *sql.DB) (Catalog, error) {
+	catalog := Catalog{
+		Tables:    map[string]CatalogTable{},
+		Types:     map[string]string{},
+		Functions: map[string]bool{},
+	}
+
+	rows, err := db.Query(`
+		SELECT c.relname, c.relkind = 'p'
+		FROM pg_class c
+		WHERE c.relnamespace = current_schema()::regnamespace
+			AND c.relkind IN ('r', 'p')
+			AND NOT EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid)`)
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing tables")
+	}
+	err = scanRows(rows, func() error {
+		var name string
+		table := CatalogTable{
+			Columns:        map[string]string{},
+			Indexes:        map[string]string{},
+			InvalidIndexes: map[string]bool{},
+		}
+		if err := rows.Scan(&name, &table.Partitioned); err != nil {
+			return err
+		}
+		catalog.Tables[name] = table
+		return nil
+	})
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing tables")
+	}
+
+	rows, err = db.Query(`
+		SELECT c.relname, a.attname,
+			format_type(a.atttypid, a.atttypmod)
+			|| CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END
+			|| COALESCE(' DEFAULT ' || pg_get_expr(d.adbin, d.adrelid), '')
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE c.relnamespace = current_schema()::regnamespace
+			AND c.relkind IN ('r', 'p')
+			AND a.attnum > 0 AND NOT a.attisdropped`)
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing columns")
+	}
+	err = scanRows(rows, func() error {
+		var table, column, definition string
+		if err := rows.Scan(&table, &column, &definition); err != nil {
+			return err
+		}
+		if t, ok := catalog.Tables[table]; ok {
+			t.Columns[column] = definition
+		}
+		return nil
+	})
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing columns")
+	}
+
+	rows, err = db.Query(`
+		SELECT t.relname, i.relname, pg_get_indexdef(i.oid), x.indisvalid
+		FROM pg_index x
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_class i ON i.oid = x.indexrelid
+		WHERE t.relnamespace = current_schema()::regnamespace`)
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing indexes")
+	}
+	err = scanRows(rows, func() error {
+		var table, index, definition string
+		var valid bool
+		if err := rows.Scan(&table, &index, &definition, &valid); err != nil {
+			return err
+		}
+		if t, ok := catalog.Tables[table]; ok {
+			t.Indexes[index] = normalizeIndexDefinition(definition)
+			if !valid {
+				t.InvalidIndexes[index] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing indexes")
+	}
+
+	rows, err = db.Query(`
+		SELECT t.typname,
+			CASE t.typtype
+				WHEN 'e' THEN 'ENUM (' || (
+					SELECT string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder)
+					FROM pg_enum e WHERE e.enumtypid = t.oid
+				) || ')'
+				WHEN 'd' THEN 'DOMAIN ' || format_type(t.typbasetype, t.typtypmod)
+				ELSE 'COMPOSITE'
+			END
+		FROM pg_type t
+		LEFT JOIN pg_class c ON c.oid = t.typrelid
+		WHERE t.typnamespace = current_schema()::regnamespace
+			AND (t.typtype IN ('e', 'd') OR (t.typtype = 'c' AND c.relkind = 'c'))`)
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing types")
+	}
+	err = scanRows(rows, func() error {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return err
+		}
+		catalog.Types[name] = definition
+		return nil
+	})
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing types")
+	}
+
+	rows, err = db.Query(`
+		SELECT p.oid::regprocedure::text
+		FROM pg_proc p
+		WHERE p.pronamespace = current_schema()::regnamespace`)
+	if err != nil {
+		return catalog, errors.Wrap(err, "error listing functions")
+	}
+	err = scanRows(rows, func() error {
+		var signature string
+		if err := rows.Scan(&signature); err != nil {
+			return err
+		}
+		catalog.Functions[signature] = true
+		return nil
+	})
+	return catalog, errors.Wrap(err, "error listing functions")
+}
+
+func scanRows(rows *sql.Rows, scan func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// normalizeIndexDefinition removes the schema of the indexed table, which
+// pg_get_indexdef always includes, and the ONLY keyword of the indexes of
+// partitioned tables.
+func normalizeIndexDefinition(definition string) string {
+	if i := strings.Index(definition, " ON "); i >= 0 {
+		rest := strings.TrimPrefix(definition[i+len(" ON "):], "ONLY ")
+		if dot := strings.Index(rest, "."); dot >= 0 && dot < strings.Index(rest, " ") {
+			rest = rest[dot+1:]
+		}
+		definition = definition[:i+len(" ON ")] + rest
+	}
+	return definition
+}
+
+// Diff returns the differences between the catalog of a live database and
+// the catalog expected by this version of Aurora.
+func Diff(expected, actual Catalog) []string {
+	var drift []string
+
+	for name, expectedTable := range expected.Tables {
+		table, ok := actual.Tables[name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("table %s is missing", name))
+			continue
+		}
+		drift = append(drift, diffTable(name, expectedTable, table)...)
+	}
+	for name := range actual.Tables {
+		if _, ok := expected.Tables[name]; !ok {
+			drift = append(drift, fmt.Sprintf("table %s is unexpected", name))
+		}
+	}
+
+	for name, definition := range expected.Types {
+		actualDefinition, ok := actual.Types[name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("type %s is missing", name))
+		} else if actualDefinition != definition {
+			drift = append(drift, fmt.Sprintf("type %s is %s, expected %s", name, actualDefinition, definition))
+		}
+	}
+	for name := range actual.Types {
+		if _, ok := expected.Types[name]; !ok {
+			drift = append(drift, fmt.Sprintf("type %s is unexpected", name))
+		}
+	}
+
+	for signature := range expected.Functions {
+		if !actual.Functions[signature] {
+			drift = append(drift, fmt.Sprintf("function %s is missing", signature))
+		}
+	}
+	for signature := range actual.Functions {
+		if !expected.Functions[signature] {
+			drift = append(drift, fmt.Sprintf("function %s is unexpected", signature))
+		}
+	}
+
+	sort.Strings(drift)
+	return drift
+}
+
+func diffTable(name string, expected, actual CatalogTable) []string {
+	var drift []string
+
+	for column, definition := range expected.Columns {
+		actualDefinition, ok := actual.Columns[column]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("column %s.%s is missing", name, column))
+		} else if actualDefinition != definition {
+			drift = append(drift, fmt.Sprintf(
+				"column %s.%s is %s, expected %s", name, column, actualDefinition, definition,
+			))
+		}
+	}
+	for column := range actual.Columns {
+		if _, ok := expected.Columns[column]; !ok {
+			drift = append(drift, fmt.Sprintf("column %s.%s is unexpected", name, column))
+		}
+	}
+
+	for index, definition := range expected.Indexes {
+		actualDefinition, ok := actual.Indexes[index]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("index %s of %s is missing", index, name))
+			continue
+		}
+		if actual.Partitioned {
+			definition = withPartitionKey(name, definition)
+		}
+		if actualDefinition != definition {
+			drift = append(drift, fmt.Sprintf(
+				"index %s of %s is %q, expected %q", index, name, actualDefinition, definition,
+			))
+		}
+	}
+	for index := range actual.Indexes {
+		if _, ok := expected.Indexes[index]; !ok {
+			drift = append(drift, fmt.Sprintf("index %s of %s is unexpected", index, name))
+		}
+	}
+	for index := range actual.InvalidIndexes {
+		// Indexes of partitioned tables can't be created concurrently, they
+		// are invalid until an index of every partition is attached to them.
+		if actual.Partitioned {
+			drift = append(drift, fmt.Sprintf(
+				"index %s of partitioned table %s is invalid, an index of every partition "+
+					"must be attached to it with ALTER INDEX ... ATTACH PARTITION",
+				index, name,
+			))
+			continue
+		}
+		drift = append(drift, fmt.Sprintf(
+			"index %s of %s is invalid, it was probably left by a failed CREATE INDEX CONCURRENTLY "+
+				"and must be dropped and created again",
+			index, name,
+		))
+	}
+
+	return drift
+}
+
+// withPartitionKey returns the definition of a unique index of the
+// partitioned table with the partition key column appended when it's not
+// included, as done by EnablePartitioning.
+func withPartitionKey(table, definition string) string {
+	if !strings.HasPrefix(definition, "CREATE UNIQUE INDEX ") {
+		return definition
+	}
+	var column string
+	for _, partitioned := range PartitionedTables {
+		if partitioned.Name == table {
+			column = partitioned.Column
+		}
+	}
+	using := indexUsingRegexp.FindString(definition)
+	matches := indexColumnsRegexp.FindStringSubmatch(using)
+	if column == "" || matches == nil {
+		return definition
+	}
+	for _, indexed := range strings.Split(matches[2], ", ") {
+		if strings.Trim(indexed, `"`) == column {
+			return definition
+		}
+	}
+	return strings.TrimSuffix(definition, using) +
+		fmt.Sprintf(" USING %s (%s, %s)", matches[1], matches[2], column)
+}
+
+// DiffMigrations returns the migrations of this version of Aurora which are
+// not applied to db and the migrations applied to db which are unknown to
+// this version.
+func DiffMigrations(db *sql.DB) ([]string, error) {
+	known, err := Migrations.FindMigrations()
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading migrations")
+	}
+	records, err := migrate.GetMigrationRecords(db, "postgres")
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting migration records")
+	}
+
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.Id] = true
+	}
+
+	var drift []string
+	for _, m := range known {
+		if !applied[m.Id] {
+			drift = append(drift, fmt.Sprintf("migration %s is not applied", m.Id))
+		}
+		delete(applied, m.Id)
+	}
+	for id := range applied {
+		drift = append(drift, fmt.Sprintf("migration %s is unknown to this version of Aurora", id))
+	}
+	sort.Strings(drift)
+	return drift, nil
+}
+
+// CheckSchema compares the schema of the database at dbURL with the schema
+// created by running all migrations on the empty database at expectedURL.
+// When expectedURL is empty a temporary database is created on the same
+// server, which requires the CREATEDB privilege. It returns the list of
+// differences, which is empty when the schemas match.
+func CheckSchema(dbURL, expectedURL string) ([]string, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening database")
+	}
+	defer db.Close()
+
+	drift, err := DiffMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedURL == "" {
+		name := fmt.Sprintf("aurora_check_schema_%d", time.Now().UnixNano())
+		if _, err = db.Exec("CREATE DATABASE " + pq.QuoteIdentifier(name)); err != nil {
+			return nil, errors.Wrap(err, "error creating temporary database, use an empty database instead")
+		}
+		defer db.Exec("DROP DATABASE IF EXISTS " + pq.QuoteIdentifier(name))
+
+		expectedURL, err = withDatabase(dbURL, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	expected, err := loadExpectedCatalog(expectedURL)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := LoadCatalog(db)
+	if err != nil {
+		return nil, err
+	}
+
+	drift = append(drift, Diff(expected, actual)...)
+	return drift, nil
+}
+
+// loadExpectedCatalog runs all migrations on the database at url and returns
+// its catalog. The connections to the database are closed so it can be
+// dropped.
+func loadExpectedCatalog(url string) (Catalog, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return Catalog{}, errors.Wrap(err, "error opening expected database")
+	}
+	defer db.Close()
+
+	if _, err = Migrate(db, MigrateUp, 0); err != nil {
+		return Catalog{}, errors.Wrap(err, "error migrating expected database")
+	}
+	return LoadCatalog(db)
+}
+
+// withDatabase returns a connection string connecting to database name with
+// the settings of url.
+func withDatabase(url, name string) (string, error) {
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		var err error
+		url, err = pq.ParseURL(url)
+		if err != nil {
+			return "", errors.Wrap(err, "error parsing database url")
+		}
+	}
+	// The last value of a setting wins.
+	return url + " dbname=" + name, nil
+}
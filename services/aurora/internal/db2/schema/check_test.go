@@ -0,0 +1,166 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/support/db/dbtest"
+)
+
+func TestNormalizeIndexDefinition(t *testing.T) {
+	assert.Equal(
+		t,
+		"CREATE INDEX hop_by_hoid ON history_operation_participants USING btree (history_operation_id)",
+		normalizeIndexDefinition("CREATE INDEX hop_by_hoid ON public.history_operation_participants USING btree (history_operation_id)"),
+	)
+	assert.Equal(
+		t,
+		"CREATE INDEX hs_ledger_by_id ON history_ledgers USING btree (id)",
+		normalizeIndexDefinition("CREATE INDEX hs_ledger_by_id ON ONLY public.history_ledgers USING btree (id)"),
+	)
+}
+
+func TestDiff(t *testing.T) {
+	expected := Catalog{
+		Tables: map[string]CatalogTable{
+			"history_ledgers": {
+				Columns: map[string]string{
+					"id":       "bigint",
+					"sequence": "integer NOT NULL",
+				},
+				Indexes: map[string]string{
+					"history_ledgers_pkey":              "CREATE UNIQUE INDEX history_ledgers_pkey ON history_ledgers USING btree (id)",
+					"index_history_ledgers_on_sequence": "CREATE UNIQUE INDEX index_history_ledgers_on_sequence ON history_ledgers USING btree (sequence)",
+				},
+			},
+			"history_trades": {Columns: map[string]string{"history_operation_id": "bigint NOT NULL"}},
+		},
+		Types:     map[string]string{},
+		Functions: map[string]bool{"first(anyelement)": true},
+	}
+	assert.Empty(t, Diff(expected, expected))
+
+	actual := Catalog{
+		Tables: map[string]CatalogTable{
+			"history_ledgers": {
+				Columns: map[string]string{
+					"id":       "bigint",
+					"sequence": "bigint NOT NULL",
+					"extra":    "text",
+				},
+				Indexes: map[string]string{
+					"history_ledgers_pkey":              "CREATE UNIQUE INDEX history_ledgers_pkey ON history_ledgers USING btree (id)",
+					"index_history_ledgers_on_sequence": "CREATE UNIQUE INDEX index_history_ledgers_on_sequence ON history_ledgers USING btree (sequence, id)",
+					"by_extra":                          "CREATE INDEX by_extra ON history_ledgers USING btree (extra)",
+				},
+				InvalidIndexes: map[string]bool{"by_extra": true},
+				Partitioned:    true,
+			},
+			"history_foo": {},
+		},
+		Types:     map[string]string{"mood": "ENUM ('sad', 'happy')"},
+		Functions: map[string]bool{},
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"column history_ledgers.extra is unexpected",
+			"column history_ledgers.sequence is bigint NOT NULL, expected integer NOT NULL",
+			"function first(anyelement) is missing",
+			"index by_extra of history_ledgers is unexpected",
+			"index by_extra of partitioned table history_ledgers is invalid, an index of every partition must be attached to it with ALTER INDEX ... ATTACH PARTITION",
+			"table history_foo is unexpected",
+			"table history_trades is missing",
+			"type mood is unexpected",
+		},
+		Diff(expected, actual),
+	)
+
+	// Unique indexes of partitioned tables include the partition key but
+	// must stay unique.
+	partitioned := actual.Tables["history_ledgers"]
+	partitioned.Indexes["history_ledgers_pkey"] = "CREATE INDEX history_ledgers_pkey ON history_ledgers USING btree (id)"
+	assert.Contains(
+		t,
+		Diff(expected, actual),
+		`index history_ledgers_pkey of history_ledgers is "CREATE INDEX history_ledgers_pkey ON history_ledgers USING btree (id)", `+
+			`expected "CREATE UNIQUE INDEX history_ledgers_pkey ON history_ledgers USING btree (id)"`,
+	)
+	partitioned.Partitioned = false
+	actual.Tables["history_ledgers"] = partitioned
+	assert.Contains(
+		t,
+		Diff(expected, actual),
+		"index by_extra of history_ledgers is invalid, it was probably left by a failed CREATE INDEX CONCURRENTLY and must be dropped and created again",
+	)
+	assert.Contains(
+		t,
+		Diff(expected, actual),
+		`index index_history_ledgers_on_sequence of history_ledgers is "CREATE UNIQUE INDEX index_history_ledgers_on_sequence ON history_ledgers USING btree (sequence, id)", `+
+			`expected "CREATE UNIQUE INDEX index_history_ledgers_on_sequence ON history_ledgers USING btree (sequence)"`,
+	)
+}
+
+func TestWithDatabase(t *testing.T) {
+	url, err := withDatabase("dbname=aurora sslmode=disable", "check")
+	assert.NoError(t, err)
+	assert.Equal(t, "dbname=aurora sslmode=disable dbname=check", url)
+
+	url, err = withDatabase("postgres://localhost:5432/aurora?sslmode=disable", "check")
+	assert.NoError(t, err)
+	assert.Contains(t, url, "host=localhost")
+	assert.Contains(t, url, "sslmode=disable")
+	assert.Regexp(t, " dbname=check$", url)
+}
+
+func TestCheckSchema(t *testing.T) {
+	tdb := dbtest.Postgres(t)
+	defer tdb.Close()
+	db := tdb.Open()
+	defer db.Close()
+
+	_, err := Migrate(db.DB, MigrateUp, 0)
+	assert.NoError(t, err)
+
+	drift, err := CheckSchema(tdb.DSN, "")
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	_, err = db.Exec("ALTER TABLE history_ledgers ADD COLUMN extra text")
+	assert.NoError(t, err)
+	drift, err = CheckSchema(tdb.DSN, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"column history_ledgers.extra is unexpected"}, drift)
+}
+
+func TestCheckSchemaPartitioned(t *testing.T) {
+	tdb := dbtest.Postgres(t)
+	defer tdb.Close()
+	db := tdb.Open()
+	defer db.Close()
+
+	_, err := Migrate(db.DB, MigrateUp, 0)
+	require.NoError(t, err)
+	require.NoError(t, EnablePartitioning(db.DB, 100))
+
+	drift, err := CheckSchema(tdb.DSN, "")
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// An index created on the partitioned table only stays invalid until
+	// indexes of the partitions are attached to it.
+	_, err = db.Exec("CREATE INDEX by_ledger_hash ON ONLY history_ledgers (ledger_hash)")
+	require.NoError(t, err)
+	drift, err = CheckSchema(tdb.DSN, "")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"index by_ledger_hash of history_ledgers is unexpected",
+			"index by_ledger_hash of partitioned table history_ledgers is invalid, an index of every partition must be attached to it with ALTER INDEX ... ATTACH PARTITION",
+		},
+		drift,
+	)
+}
@@ -4,8 +4,11 @@ import (
 	"database/sql"
 	"errors"
 	stdLog "log"
+	"time"
 
 	migrate "github.com/rubenv/sql-migrate"
+
+	"github.com/hcnet/go/support/log"
 )
 
 //go:generate go-bindata -nometadata -pkg schema -o bindata.go migrations/
@@ -41,7 +44,14 @@ var Migrations migrate.MigrationSource = &migrate.AssetMigrationSource{
 // - redo: migrations are first ran downard `count` times, and then are rand
 // upward back to the current version at the start of the process. If count is
 // 0, a count of 1 will be assumed.
+//
+// The progress of indexes built by migrations is logged periodically.
+// Migrations creating indexes with CREATE INDEX CONCURRENTLY must be marked
+// with "-- +migrate Up notransaction".
 func Migrate(db *sql.DB, dir MigrateDir, count int) (int, error) {
+	stop := reportIndexProgress(db, indexProgressInterval)
+	defer stop()
+
 	switch dir {
 	case MigrateUp:
 		return migrate.ExecMax(db, "postgres", Migrations, migrate.Up, count)
@@ -115,3 +125,72 @@ func GetNumMigrationsDown(dbUrl string) (nMigrations int) {
 	// Return the size difference between the two sets of migrations
 	return len(migrationRecords) - len(allNeededMigrations)
 }
+
+// indexProgressInterval is the time between two reports of the progress of
+// the indexes built by migrations.
+const indexProgressInterval = 10 * time.Second
+
+// reportIndexProgress logs the progress of the indexes being built in the
+// database of db every interval, until the returned function is called.
+// Progress is only available on PostgreSQL 12 or later.
+func reportIndexProgress(db *sql.DB, interval time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			if err := logIndexProgress(db); err != nil {
+				log.WithField("err", err.Error()).Debug("Index build progress is not available")
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func logIndexProgress(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT p.relid::regclass::text, COALESCE(i.relname, ''), p.command, p.phase,
+			p.blocks_done, p.blocks_total, p.tuples_done, p.tuples_total
+		FROM pg_stat_progress_create_index p
+		LEFT JOIN pg_class i ON i.oid = p.index_relid
+		WHERE p.datname = current_database()`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, index, command, phase string
+		var blocksDone, blocksTotal, tuplesDone, tuplesTotal int64
+		err = rows.Scan(&table, &index, &command, &phase, &blocksDone, &blocksTotal, &tuplesDone, &tuplesTotal)
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.F{
+			"table":        table,
+			"index":        index,
+			"command":      command,
+			"phase":        phase,
+			"blocks_done":  blocksDone,
+			"blocks_total": blocksTotal,
+			"tuples_done":  tuplesDone,
+			"tuples_total": tuplesTotal,
+		}).Info("Building index")
+	}
+	return rows.Err()
+}
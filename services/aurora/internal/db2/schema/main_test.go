@@ -2,6 +2,7 @@ package schema
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
@@ -29,3 +30,22 @@ func TestGeneratedAssets(t *testing.T) {
 		t.Fatalf("generated migrations does not match local migrations")
 	}
 }
+
+func TestConcurrentIndexMigrations(t *testing.T) {
+	migrations, err := Migrations.FindMigrations()
+	assert.NoError(t, err)
+
+	// CREATE INDEX CONCURRENTLY cannot run inside a transaction.
+	for _, m := range migrations {
+		for _, statement := range m.Up {
+			if strings.Contains(strings.ToUpper(statement), "CONCURRENTLY") {
+				assert.True(t, m.DisableTransactionUp, "migration %s must be marked notransaction", m.Id)
+			}
+		}
+		for _, statement := range m.Down {
+			if strings.Contains(strings.ToUpper(statement), "CONCURRENTLY") {
+				assert.True(t, m.DisableTransactionDown, "migration %s must be marked notransaction", m.Id)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+-- +migrate Up
+
+ALTER TABLE history_transactions
+ADD COLUMN account_muxed varchar(69) NULL,
+ADD COLUMN fee_account_muxed varchar(69) NULL;
+
+ALTER TABLE history_operations
+ADD COLUMN source_account_muxed varchar(69) NULL;
+
+ALTER TABLE history_effects
+ADD COLUMN address_muxed varchar(69) NULL;
+
+-- +migrate Down
+
+ALTER TABLE history_transactions
+DROP COLUMN account_muxed,
+DROP COLUMN fee_account_muxed;
+
+ALTER TABLE history_operations
+DROP COLUMN source_account_muxed;
+
+ALTER TABLE history_effects
+DROP COLUMN address_muxed;
@@ -0,0 +1,13 @@
+-- +migrate Up
+
+ALTER TABLE exp_asset_stats
+ADD COLUMN accounts JSONB NOT NULL DEFAULT '{"authorized": 0, "authorized_to_maintain_liabilities": 0, "unauthorized": 0, "claimable_balances": 0}',
+ADD COLUMN balances JSONB NOT NULL DEFAULT '{"authorized": "0", "authorized_to_maintain_liabilities": "0", "unauthorized": "0", "claimable_balances": "0"}',
+ADD COLUMN liabilities JSONB NOT NULL DEFAULT '{"buying": "0", "selling": "0"}';
+
+-- +migrate Down
+
+ALTER TABLE exp_asset_stats
+DROP COLUMN accounts,
+DROP COLUMN balances,
+DROP COLUMN liabilities;
@@ -0,0 +1,7 @@
+-- +migrate Up notransaction
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS trust_lines_by_asset_balance ON trust_lines USING btree (asset_type, asset_code, asset_issuer, balance, account_id);
+
+-- +migrate Down notransaction
+
+DROP INDEX CONCURRENTLY IF EXISTS trust_lines_by_asset_balance;
@@ -0,0 +1,416 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/hcnet/go/services/aurora/internal/toid"
+	"github.com/hcnet/go/support/errors"
+)
+
+const (
+	// PartitionSizeKey is the key_value_store key holding the number of
+	// ledgers per history partition. History tables are not partitioned when
+	// it's missing.
+	PartitionSizeKey = "history_partition_ledgers"
+	// PartitionStartKey is the key_value_store key holding the first ledger
+	// stored in regular partitions. Older ledgers are stored in the legacy
+	// partition, the table which existed before partitioning was enabled.
+	PartitionStartKey = "history_partition_start"
+
+	// minPartitioningServerVersion is PostgreSQL 11, the first version
+	// supporting indexes on partitioned tables.
+	minPartitioningServerVersion = 110000
+	legacyPartitionSuffix        = "_legacy"
+	partitionCheckSuffix         = "_partition_check"
+	// partitionKeyIndexSuffix is the suffix of the unique indexes including
+	// the partition key which are built on the legacy partition for unique
+	// indexes not including it.
+	partitionKeyIndexSuffix = "_by_key"
+)
+
+// PartitionedTable is a history table partitioned by ledger range.
+type PartitionedTable struct {
+	Name string
+	// Column is the TOID column the table is partitioned by.
+	Column string
+}
+
+// PartitionedTables lists the tables partitioned by EnablePartitioning.
+var PartitionedTables = []PartitionedTable{
+	{Name: "history_effects", Column: "history_operation_id"},
+	{Name: "history_operation_participants", Column: "history_operation_id"},
+	{Name: "history_operations", Column: "id"},
+	{Name: "history_transaction_participants", Column: "history_transaction_id"},
+	{Name: "history_transactions", Column: "id"},
+	{Name: "history_trades", Column: "history_operation_id"},
+	// history_ledgers must stay last, the partitions of a range are created
+	// in this order so they all exist when the history_ledgers one does.
+	{Name: "history_ledgers", Column: "id"},
+}
+
+// PartitionStart returns the first ledger of the partition containing
+// ledger.
+func PartitionStart(ledger, size uint32) uint32 {
+	return ledger - ledger%size
+}
+
+// PartitionName returns the name of the partition of table starting at
+// ledger start.
+func PartitionName(table string, start uint32) string {
+	return fmt.Sprintf("%s_p%d", table, start)
+}
+
+// LegacyPartitionName returns the name of the partition holding the rows of
+// table ingested before partitioning was enabled.
+func LegacyPartitionName(table string) string {
+	return table + legacyPartitionSuffix
+}
+
+// ParsePartitionName returns the first ledger of the partition name of table.
+// ok is false when name is not a regular partition of table.
+func ParsePartitionName(table, name string) (start uint32, ok bool) {
+	prefix := table + "_p"
+	if len(name) <= len(prefix) || name[:len(prefix)] != prefix {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(name[len(prefix):], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(parsed), true
+}
+
+// CreatePartitionSQL returns the statement creating the partition of table
+// holding the size ledgers starting at start.
+func CreatePartitionSQL(table PartitionedTable, start, size uint32) string {
+	upper := "MAXVALUE"
+	if end := uint64(start) + uint64(size); end <= math.MaxInt32 {
+		upper = strconv.FormatInt(ledgerTOID(uint32(end)), 10)
+	}
+	return fmt.Sprintf(
+		"CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%d) TO (%s)",
+		pq.QuoteIdentifier(PartitionName(table.Name, start)),
+		pq.QuoteIdentifier(table.Name),
+		ledgerTOID(start),
+		upper,
+	)
+}
+
+func ledgerTOID(ledger uint32) int64 {
+	return toid.New(int32(ledger), 0, 0).ToInt64()
+}
+
+// EnablePartitioning converts the history tables into tables partitioned by
+// ranges of size ledgers. Every existing table is renamed to become the
+// legacy partition holding all ledgers up to the next partition boundary and
+// its indexes are attached to the indexes of the partitioned table, so no
+// data is copied. The legacy partition is dropped by the reaper once all its
+// ledgers are reaped.
+//
+// Postgres requires unique indexes of partitioned tables to include the
+// partition key, so the partition key column is appended to unique indexes
+// which don't include it (for example the index of history_ledgers on
+// sequence becomes an index on sequence and id). As ids are derived from
+// ledger sequences, uniqueness of such columns is only enforced across
+// partitions for rows with the same id: ledger sequences stay unique, but
+// duplicate ledger hashes in different ledgers are not rejected. The legacy
+// partition keeps its original unique indexes.
+//
+// Ingestion must be stopped. Validating that the existing rows fit in the
+// legacy partition and building the unique indexes including the partition
+// key scan the tables, but don't block readers.
+func EnablePartitioning(db *sql.DB, size uint32) error {
+	if size == 0 {
+		return errors.New("partition size must be positive")
+	}
+
+	var version int
+	if err := db.QueryRow("SHOW server_version_num").Scan(&version); err != nil {
+		return errors.Wrap(err, "error getting server version")
+	}
+	if version < minPartitioningServerVersion {
+		return errors.Errorf("partitioning requires PostgreSQL 11 or later, server version is %d", version)
+	}
+
+	var existing int
+	err := db.QueryRow("SELECT COUNT(*) FROM key_value_store WHERE key = $1", PartitionSizeKey).Scan(&existing)
+	if err != nil {
+		return errors.Wrap(err, "error checking partitioning")
+	}
+	if existing > 0 {
+		return errors.New("history tables are already partitioned")
+	}
+
+	var latest uint32
+	err = db.QueryRow("SELECT COALESCE(MAX(sequence), 0) FROM history_ledgers").Scan(&latest)
+	if err != nil {
+		return errors.Wrap(err, "error getting latest ledger")
+	}
+	start := PartitionStart(latest, size) + size
+
+	// Validating a CHECK constraint matching the partition bounds before
+	// attaching the table lets Postgres skip scanning it while the tables
+	// are locked.
+	for _, table := range PartitionedTables {
+		check := pq.QuoteIdentifier(table.Name + partitionCheckSuffix)
+		// The check is left behind when a previous attempt failed.
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", pq.QuoteIdentifier(table.Name), check))
+		if err != nil {
+			return errors.Wrapf(err, "error removing partition check from %s", table.Name)
+		}
+		_, err = db.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s IS NOT NULL AND %s < %d) NOT VALID",
+			pq.QuoteIdentifier(table.Name), check, pq.QuoteIdentifier(table.Column),
+			pq.QuoteIdentifier(table.Column), ledgerTOID(start),
+		))
+		if err != nil {
+			return errors.Wrapf(err, "error adding partition check to %s", table.Name)
+		}
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", pq.QuoteIdentifier(table.Name), check))
+		if err != nil {
+			return errors.Wrapf(err, "error validating partition check of %s", table.Name)
+		}
+
+		if err = buildPartitionKeyIndexes(db, table); err != nil {
+			return errors.Wrapf(err, "error building unique indexes of %s", table.Name)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "error starting transaction")
+	}
+	defer tx.Rollback()
+
+	for _, table := range PartitionedTables {
+		if err = partitionTable(tx, table, start, size); err != nil {
+			return errors.Wrapf(err, "error partitioning %s", table.Name)
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO key_value_store (key, value) VALUES ($1, $2), ($3, $4)",
+		PartitionSizeKey, strconv.FormatUint(uint64(size), 10),
+		PartitionStartKey, strconv.FormatUint(uint64(start), 10),
+	)
+	if err != nil {
+		return errors.Wrap(err, "error storing partitioning configuration")
+	}
+
+	return tx.Commit()
+}
+
+type tableIndex struct {
+	name        string
+	definition  string
+	unique      bool
+	includesKey bool
+}
+
+var (
+	indexUsingRegexp   = regexp.MustCompile(` USING .*$`)
+	indexColumnsRegexp = regexp.MustCompile(`^ USING (\w+) \((.+)\)$`)
+)
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// partitionKeyIndexName returns the name of the index including the
+// partition key built for the unique index name.
+func partitionKeyIndexName(name string) string {
+	return name + partitionKeyIndexSuffix
+}
+
+// partitionKeyIndexUsing returns the USING clause of a unique index not
+// including the partition key with the partition key column appended.
+func partitionKeyIndexUsing(table PartitionedTable, index tableIndex) (string, error) {
+	matches := indexColumnsRegexp.FindStringSubmatch(indexUsingRegexp.FindString(index.definition))
+	if matches == nil || strings.Contains(matches[2], " INCLUDE ") || strings.Contains(matches[2], " WHERE ") {
+		return "", errors.Errorf("unsupported unique index %s", index.definition)
+	}
+	return fmt.Sprintf(" USING %s (%s, %s)", matches[1], matches[2], pq.QuoteIdentifier(table.Column)), nil
+}
+
+// buildPartitionKeyIndexes builds, for every unique index of table not
+// including the partition key, a unique index with the partition key column
+// appended. They are attached to the indexes of the partitioned table by
+// partitionTable. Indexes are built concurrently so readers are not blocked.
+func buildPartitionKeyIndexes(db *sql.DB, table PartitionedTable) error {
+	indexes, err := listIndexes(db, table)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if !index.unique || index.includesKey {
+			continue
+		}
+		using, err := partitionKeyIndexUsing(table, index)
+		if err != nil {
+			return err
+		}
+		name := pq.QuoteIdentifier(partitionKeyIndexName(index.name))
+		// An invalid index is left behind when a previous attempt failed.
+		if _, err = db.Exec("DROP INDEX CONCURRENTLY IF EXISTS " + name); err != nil {
+			return errors.Wrapf(err, "error removing index %s", name)
+		}
+		statement := fmt.Sprintf(
+			"CREATE UNIQUE INDEX CONCURRENTLY %s ON %s%s", name, pq.QuoteIdentifier(table.Name), using,
+		)
+		if _, err = db.Exec(statement); err != nil {
+			return errors.Wrapf(err, "error executing %q", statement)
+		}
+	}
+	return nil
+}
+
+func partitionTable(tx *sql.Tx, table PartitionedTable, start, size uint32) error {
+	legacy := LegacyPartitionName(table.Name)
+	quotedTable := pq.QuoteIdentifier(table.Name)
+	quotedLegacy := pq.QuoteIdentifier(legacy)
+
+	allIndexes, err := listIndexes(tx, table)
+	if err != nil {
+		return err
+	}
+	// The indexes built by buildPartitionKeyIndexes are attached with the
+	// unique index they were built for.
+	var indexes []tableIndex
+	for _, index := range allIndexes {
+		if !strings.HasSuffix(index.name, partitionKeyIndexSuffix) {
+			indexes = append(indexes, index)
+		}
+	}
+	foreignKeys, err := listForeignKeys(tx, table.Name)
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotedTable, quotedLegacy),
+	}
+	// The indexes of the legacy partition are renamed so the indexes of the
+	// partitioned table keep the names used by migrations.
+	for _, index := range indexes {
+		statements = append(statements, fmt.Sprintf(
+			"ALTER INDEX %s RENAME TO %s",
+			pq.QuoteIdentifier(index.name), pq.QuoteIdentifier(index.name+legacyPartitionSuffix),
+		))
+	}
+	statements = append(statements,
+		fmt.Sprintf(
+			"CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (%s)",
+			quotedTable, quotedLegacy, pq.QuoteIdentifier(table.Column),
+		),
+		fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quotedTable, pq.QuoteIdentifier(table.Name+partitionCheckSuffix)),
+	)
+	for name, definition := range foreignKeys {
+		statements = append(statements, fmt.Sprintf(
+			"ALTER TABLE %s ADD CONSTRAINT %s %s", quotedTable, pq.QuoteIdentifier(name), definition,
+		))
+	}
+	statements = append(statements, fmt.Sprintf(
+		"ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO (%d)",
+		quotedTable, quotedLegacy, ledgerTOID(start),
+	))
+	// The indexes of the partitioned table are created ON ONLY the table and
+	// the matching index of the legacy partition is attached instead of being
+	// built again. The index becomes valid once it's attached to the index of
+	// every partition so the regular partitions, whose indexes are created
+	// with the partition, are created last.
+	for _, index := range indexes {
+		using := indexUsingRegexp.FindString(index.definition)
+		unique := ""
+		legacyIndex := index.name + legacyPartitionSuffix
+		if index.unique {
+			unique = "UNIQUE "
+			if !index.includesKey {
+				if using, err = partitionKeyIndexUsing(table, index); err != nil {
+					return err
+				}
+				legacyIndex = partitionKeyIndexName(index.name)
+			}
+		}
+		statements = append(statements,
+			fmt.Sprintf(
+				"CREATE %sINDEX %s ON ONLY %s%s", unique, pq.QuoteIdentifier(index.name), quotedTable, using,
+			),
+			fmt.Sprintf(
+				"ALTER INDEX %s ATTACH PARTITION %s", pq.QuoteIdentifier(index.name), pq.QuoteIdentifier(legacyIndex),
+			),
+		)
+	}
+	statements = append(statements,
+		CreatePartitionSQL(table, start, size),
+		CreatePartitionSQL(table, start+size, size),
+	)
+
+	for _, statement := range statements {
+		if _, err = tx.Exec(statement); err != nil {
+			return errors.Wrapf(err, "error executing %q", statement)
+		}
+	}
+	return nil
+}
+
+func listIndexes(q queryer, table PartitionedTable) ([]tableIndex, error) {
+	rows, err := q.Query(`
+		SELECT i.relname, pg_get_indexdef(i.oid), x.indisunique,
+			EXISTS (
+				SELECT 1 FROM pg_attribute a
+				WHERE a.attrelid = t.oid AND a.attname = $2 AND a.attnum = ANY(x.indkey)
+			)
+		FROM pg_index x
+		JOIN pg_class t ON t.oid = x.indrelid
+		JOIN pg_class i ON i.oid = x.indexrelid
+		WHERE t.oid = $1::regclass
+		ORDER BY i.relname`,
+		table.Name, table.Column,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing indexes")
+	}
+	defer rows.Close()
+
+	var indexes []tableIndex
+	for rows.Next() {
+		var index tableIndex
+		if err = rows.Scan(&index.name, &index.definition, &index.unique, &index.includesKey); err != nil {
+			return nil, errors.Wrap(err, "error scanning index")
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, errors.Wrap(rows.Err(), "error listing indexes")
+}
+
+func listForeignKeys(tx *sql.Tx, table string) (map[string]string, error) {
+	rows, err := tx.Query(`
+		SELECT conname, pg_get_constraintdef(oid)
+		FROM pg_constraint
+		WHERE conrelid = $1::regclass AND contype = 'f'`,
+		table,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing foreign keys")
+	}
+	defer rows.Close()
+
+	foreignKeys := map[string]string{}
+	for rows.Next() {
+		var name, definition string
+		if err = rows.Scan(&name, &definition); err != nil {
+			return nil, errors.Wrap(err, "error scanning foreign key")
+		}
+		foreignKeys[name] = definition
+	}
+	return foreignKeys, errors.Wrap(rows.Err(), "error listing foreign keys")
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/support/db/dbtest"
+)
+
+func TestPartitionNames(t *testing.T) {
+	assert.Equal(t, uint32(0), PartitionStart(99, 100))
+	assert.Equal(t, uint32(100), PartitionStart(100, 100))
+	assert.Equal(t, uint32(100), PartitionStart(199, 100))
+
+	assert.Equal(t, "history_effects_p200", PartitionName("history_effects", 200))
+	assert.Equal(t, "history_effects_legacy", LegacyPartitionName("history_effects"))
+
+	start, ok := ParsePartitionName("history_effects", "history_effects_p200")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(200), start)
+
+	for _, name := range []string{"history_effects_legacy", "history_effects_p", "history_operations_p200", "history_effects_pabc"} {
+		_, ok = ParsePartitionName("history_effects", name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestCreatePartitionSQL(t *testing.T) {
+	table := PartitionedTable{Name: "history_operations", Column: "id"}
+	assert.Equal(
+		t,
+		`CREATE TABLE "history_operations_p200" PARTITION OF "history_operations" FOR VALUES FROM (858993459200) TO (1288490188800)`,
+		CreatePartitionSQL(table, 200, 100),
+	)
+	assert.Equal(
+		t,
+		`CREATE TABLE "history_operations_p2147483600" PARTITION OF "history_operations" FOR VALUES FROM (9223371830696345600) TO (MAXVALUE)`,
+		CreatePartitionSQL(table, 2147483600, 100),
+	)
+}
+
+func TestEnablePartitioning(t *testing.T) {
+	tdb := dbtest.Postgres(t)
+	defer tdb.Close()
+	db := tdb.Open()
+	defer db.Close()
+
+	_, err := Migrate(db.DB, MigrateUp, 0)
+	require.NoError(t, err)
+
+	insertLedger := func(sequence uint32, hash string) error {
+		_, err := db.Exec(
+			`INSERT INTO history_ledgers (sequence, ledger_hash, closed_at, id, total_coins, fee_pool,
+				base_fee, base_reserve, max_tx_set_size)
+			VALUES ($1, $2, NOW(), $3, 0, 0, 100, 100, 100)`,
+			sequence, hash, ledgerTOID(sequence),
+		)
+		return err
+	}
+	require.NoError(t, insertLedger(50, fmt.Sprintf("%064d", 50)))
+
+	require.NoError(t, EnablePartitioning(db.DB, 100))
+
+	// All indexes of the partitioned tables are valid and attached to the
+	// legacy partition.
+	var invalid []string
+	require.NoError(t, db.Select(&invalid, `
+		SELECT i.relname FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+		WHERE NOT x.indisvalid`))
+	assert.Empty(t, invalid)
+	var attached int
+	require.NoError(t, db.Get(&attached, `
+		SELECT COUNT(*) FROM pg_inherits h JOIN pg_class i ON i.oid = h.inhrelid
+		WHERE i.relname = 'index_history_ledgers_on_sequence_by_key'`))
+	assert.Equal(t, 1, attached)
+
+	// Unique indexes are enforced in the legacy and regular partitions.
+	assert.Error(t, insertLedger(50, fmt.Sprintf("%064d", 51)))
+	require.NoError(t, insertLedger(150, fmt.Sprintf("%064d", 150)))
+	assert.Error(t, insertLedger(150, fmt.Sprintf("%064d", 151)))
+
+	var unique bool
+	require.NoError(t, db.Get(&unique, `
+		SELECT x.indisunique FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+		WHERE i.relname = 'index_history_ledgers_on_sequence'`))
+	assert.True(t, unique)
+}
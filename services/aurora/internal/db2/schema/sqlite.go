@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"database/sql"
+
+	"github.com/hcnet/go/support/db/sqlutils"
+	"github.com/hcnet/go/support/errors"
+)
+
+// LiteTables are the tables of the SQLite schema used by aurora in lite mode.
+// Lite mode databases only contain the state of accounts, offers and trust
+// lines, and the ledgers and transactions history.
+var LiteTables = []string{
+	"key_value_store",
+	"accounts",
+	"accounts_data",
+	"accounts_signers",
+	"offers",
+	"trust_lines",
+	"history_ledgers",
+	"history_transactions",
+}
+
+// sqliteSchema is the SQLite version of the tables of LiteTables. Columns
+// which are parsed by the driver are declared as timestamp or boolean, and
+// postgres arrays and ranges are stored as text in their postgres format.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS key_value_store (
+    key varchar(255) NOT NULL,
+    value varchar(255) NOT NULL,
+    PRIMARY KEY (key)
+);
+
+INSERT INTO key_value_store (key, value)
+    VALUES ('exp_ingest_last_ledger', '0')
+    ON CONFLICT (key) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS accounts (
+    account_id varchar(56) NOT NULL,
+    balance bigint NOT NULL,
+    buying_liabilities bigint NOT NULL,
+    selling_liabilities bigint NOT NULL,
+    sequence_number bigint NOT NULL,
+    num_subentries integer NOT NULL,
+    inflation_destination varchar(56) NOT NULL,
+    flags integer NOT NULL,
+    home_domain varchar(32) NOT NULL,
+    master_weight smallint NOT NULL,
+    threshold_low smallint NOT NULL,
+    threshold_medium smallint NOT NULL,
+    threshold_high smallint NOT NULL,
+    last_modified_ledger integer NOT NULL,
+    sponsor text,
+    num_sponsored integer DEFAULT 0 CHECK (num_sponsored >= 0),
+    num_sponsoring integer DEFAULT 0 CHECK (num_sponsoring >= 0),
+    PRIMARY KEY (account_id)
+);
+
+CREATE INDEX IF NOT EXISTS accounts_inflation_destination ON accounts (inflation_destination);
+CREATE INDEX IF NOT EXISTS accounts_home_domain ON accounts (home_domain);
+CREATE INDEX IF NOT EXISTS accounts_by_sponsor ON accounts (sponsor);
+
+CREATE TABLE IF NOT EXISTS accounts_data (
+    ledger_key varchar(150) NOT NULL,
+    account_id varchar(56) NOT NULL,
+    name varchar(64) NOT NULL,
+    value varchar(90) NOT NULL,
+    last_modified_ledger integer NOT NULL,
+    sponsor text,
+    PRIMARY KEY (ledger_key)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_data_account_id_name ON accounts_data (account_id, name);
+CREATE INDEX IF NOT EXISTS accounts_data_by_sponsor ON accounts_data (sponsor);
+
+CREATE TABLE IF NOT EXISTS accounts_signers (
+    account_id varchar(64) NOT NULL,
+    signer varchar(64) NOT NULL,
+    weight integer NOT NULL,
+    sponsor text,
+    PRIMARY KEY (signer, account_id)
+);
+
+CREATE INDEX IF NOT EXISTS accounts_signers_by_sponsor ON accounts_signers (sponsor);
+
+CREATE TABLE IF NOT EXISTS offers (
+    seller_id varchar(56) NOT NULL,
+    offer_id bigint NOT NULL,
+    selling_asset text NOT NULL,
+    buying_asset text NOT NULL,
+    amount bigint NOT NULL,
+    pricen integer NOT NULL,
+    priced integer NOT NULL,
+    price double precision NOT NULL,
+    flags integer NOT NULL,
+    last_modified_ledger integer NOT NULL,
+    deleted boolean DEFAULT false,
+    sponsor text,
+    PRIMARY KEY (offer_id)
+);
+
+CREATE INDEX IF NOT EXISTS best_offer ON offers (selling_asset, buying_asset, deleted, price);
+CREATE INDEX IF NOT EXISTS live_offers ON offers (deleted, last_modified_ledger);
+CREATE INDEX IF NOT EXISTS offers_by_seller ON offers (seller_id, deleted);
+CREATE INDEX IF NOT EXISTS offers_by_selling_asset ON offers (selling_asset, deleted);
+CREATE INDEX IF NOT EXISTS offers_by_buying_asset ON offers (buying_asset, deleted);
+CREATE INDEX IF NOT EXISTS offers_by_last_modified_ledger ON offers (last_modified_ledger);
+CREATE INDEX IF NOT EXISTS offers_by_sponsor ON offers (sponsor);
+
+CREATE TABLE IF NOT EXISTS trust_lines (
+    ledger_key varchar(150) NOT NULL,
+    account_id varchar(56) NOT NULL,
+    asset_type integer NOT NULL,
+    asset_issuer varchar(56) NOT NULL,
+    asset_code varchar(12) NOT NULL,
+    balance bigint NOT NULL,
+    trust_line_limit bigint NOT NULL,
+    buying_liabilities bigint NOT NULL,
+    selling_liabilities bigint NOT NULL,
+    flags integer NOT NULL,
+    last_modified_ledger integer NOT NULL,
+    sponsor text,
+    PRIMARY KEY (ledger_key)
+);
+
+CREATE INDEX IF NOT EXISTS trust_lines_by_account_id ON trust_lines (account_id);
+CREATE INDEX IF NOT EXISTS trust_lines_by_type_code_issuer ON trust_lines (asset_type, asset_code, asset_issuer);
+CREATE INDEX IF NOT EXISTS trust_lines_by_issuer ON trust_lines (asset_issuer);
+CREATE INDEX IF NOT EXISTS trust_lines_by_sponsor ON trust_lines (sponsor);
+CREATE INDEX IF NOT EXISTS trust_lines_by_asset_balance ON trust_lines (asset_type, asset_code, asset_issuer, balance, account_id);
+
+CREATE TABLE IF NOT EXISTS history_ledgers (
+    sequence integer NOT NULL,
+    ledger_hash varchar(64) NOT NULL,
+    previous_ledger_hash varchar(64),
+    transaction_count integer DEFAULT 0 NOT NULL,
+    operation_count integer DEFAULT 0 NOT NULL,
+    closed_at timestamp NOT NULL,
+    created_at timestamp,
+    updated_at timestamp,
+    id bigint,
+    importer_version integer DEFAULT 1 NOT NULL,
+    total_coins bigint NOT NULL,
+    fee_pool bigint NOT NULL,
+    base_fee integer NOT NULL,
+    base_reserve integer NOT NULL,
+    max_tx_set_size integer NOT NULL,
+    protocol_version integer DEFAULT 0 NOT NULL,
+    ledger_header text,
+    successful_transaction_count integer,
+    failed_transaction_count integer,
+    tx_set_operation_count integer
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_history_ledgers_on_sequence ON history_ledgers (sequence);
+CREATE UNIQUE INDEX IF NOT EXISTS index_history_ledgers_on_ledger_hash ON history_ledgers (ledger_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS index_history_ledgers_on_id ON history_ledgers (id);
+CREATE INDEX IF NOT EXISTS index_history_ledgers_on_closed_at ON history_ledgers (closed_at);
+
+CREATE TABLE IF NOT EXISTS history_transactions (
+    transaction_hash varchar(64) NOT NULL,
+    ledger_sequence integer NOT NULL,
+    application_order integer NOT NULL,
+    account varchar(64) NOT NULL,
+    account_muxed varchar(69),
+    account_sequence bigint NOT NULL,
+    max_fee bigint NOT NULL,
+    fee_charged bigint,
+    operation_count integer NOT NULL,
+    created_at timestamp,
+    updated_at timestamp,
+    id bigint,
+    tx_envelope text NOT NULL,
+    tx_result text NOT NULL,
+    tx_meta text NOT NULL,
+    tx_fee_meta text NOT NULL,
+    signatures text DEFAULT '{}' NOT NULL,
+    memo_type varchar DEFAULT 'none' NOT NULL,
+    memo varchar,
+    time_bounds text,
+    successful boolean,
+    fee_account text,
+    fee_account_muxed varchar(69),
+    inner_transaction_hash varchar(64),
+    new_max_fee bigint,
+    inner_signatures text
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS hs_transaction_by_id ON history_transactions (id);
+CREATE INDEX IF NOT EXISTS by_account ON history_transactions (account, account_sequence);
+CREATE INDEX IF NOT EXISTS by_hash ON history_transactions (transaction_hash);
+CREATE INDEX IF NOT EXISTS by_inner_hash ON history_transactions (inner_transaction_hash);
+CREATE INDEX IF NOT EXISTS by_ledger ON history_transactions (ledger_sequence, application_order);
+`
+
+// MigrateSqlite creates the tables of LiteTables in a SQLite database if they
+// don't exist yet. Unlike the postgres schema, the SQLite schema is not
+// versioned so a lite mode database must be recreated when it changes.
+func MigrateSqlite(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, statement := range sqlutils.AllStatements(sqliteSchema) {
+		if _, err = tx.Exec(statement); err != nil {
+			return errors.Wrapf(err, "could not execute statement %q", statement)
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "could not commit transaction")
+}
@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/support/db/dbtest"
+)
+
+func TestMigrateSqlite(t *testing.T) {
+	tdb := dbtest.Sqlite(t)
+	defer tdb.Close()
+	db := tdb.Open()
+	defer db.Close()
+
+	require.NoError(t, MigrateSqlite(db.DB))
+	// the schema can be applied to an existing database
+	require.NoError(t, MigrateSqlite(db.DB))
+
+	var tables []string
+	require.NoError(t, db.Select(
+		&tables,
+		"SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name",
+	))
+	assert.ElementsMatch(t, LiteTables, tables)
+
+	var value string
+	require.NoError(t, db.Get(
+		&value,
+		"SELECT value FROM key_value_store WHERE key = 'exp_ingest_last_ledger'",
+	))
+	assert.Equal(t, "0", value)
+}
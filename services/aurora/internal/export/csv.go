@@ -0,0 +1,64 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// csvWriter writes rows to a CSV file with a header line. Null values are
+// written as empty fields and timestamps in RFC 3339 format.
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	fields  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	c := &csvWriter{
+		w:       csv.NewWriter(w),
+		columns: columns,
+		fields:  make([]string, len(columns)),
+	}
+	for i, column := range columns {
+		c.fields[i] = column.Name
+	}
+	if err := c.w.Write(c.fields); err != nil {
+		return nil, errors.Wrap(err, "error writing csv file")
+	}
+	return c, nil
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	if len(row) != len(c.columns) {
+		return errors.Errorf("row has %d values, expected %d", len(row), len(c.columns))
+	}
+	for i, value := range row {
+		switch v := value.(type) {
+		case nil:
+			c.fields[i] = ""
+		case string:
+			c.fields[i] = v
+		case int32:
+			c.fields[i] = strconv.FormatInt(int64(v), 10)
+		case int64:
+			c.fields[i] = strconv.FormatInt(v, 10)
+		case bool:
+			c.fields[i] = strconv.FormatBool(v)
+		case time.Time:
+			c.fields[i] = v.UTC().Format(time.RFC3339)
+		default:
+			return typeError(c.columns[i], value)
+		}
+	}
+	return errors.Wrap(c.w.Write(c.fields), "error writing csv file")
+}
+
+// Close flushes the buffered rows. It doesn't close the underlying writer.
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return errors.Wrap(c.w.Error(), "error writing csv file")
+}
@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []Column{
+		{Name: "id", Type: Int64Column},
+		{Name: "sequence", Type: Int32Column},
+		{Name: "ok", Type: BoolColumn},
+		{Name: "memo", Type: StringColumn, Nullable: true},
+		{Name: "closed_at", Type: TimestampColumn},
+	}
+	w, err := newCSVWriter(&buf, columns)
+	assert.NoError(t, err)
+
+	closedAt := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	assert.NoError(t, w.Write([]interface{}{int64(1), int32(2), true, "a,b", closedAt}))
+	assert.NoError(t, w.Write([]interface{}{int64(3), int32(4), false, nil, closedAt}))
+	assert.Error(t, w.Write([]interface{}{1.5, int32(4), false, nil, closedAt}))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(
+		t,
+		"id,sequence,ok,memo,closed_at\n"+
+			"1,2,true,\"a,b\",2020-09-13T12:26:40Z\n"+
+			"3,4,false,,2020-09-13T12:26:40Z\n",
+		buf.String(),
+	)
+}
@@ -0,0 +1,193 @@
+// Package export writes the history tables of aurora to Parquet or CSV files
+// which can be loaded by analytics tools instead of querying the aurora
+// database.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+// Format is the format of exported files.
+type Format string
+
+const (
+	ParquetFormat Format = "parquet"
+	CSVFormat     Format = "csv"
+)
+
+// ParseFormat validates the name of a format.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case ParquetFormat, CSVFormat:
+		return format, nil
+	default:
+		return "", errors.Errorf("unknown export format %q, expected parquet or csv", s)
+	}
+}
+
+// rowWriter writes the rows of a table to a file.
+type rowWriter interface {
+	Write(row []interface{}) error
+	Close() error
+}
+
+func newRowWriter(format Format, w io.Writer, columns []Column) (rowWriter, error) {
+	if format == CSVFormat {
+		return newCSVWriter(w, columns)
+	}
+	return newParquetWriter(w, columns)
+}
+
+const (
+	defaultLedgersPerFile = 10000
+	defaultBatchSize      = 1000
+)
+
+// Exporter writes the rows of history tables to one file per range of
+// LedgersPerFile ledgers, named <Dir>/<table>/<table>_<first>-<last>.<format>.
+// Files are written to a temporary file renamed once complete and existing
+// files are skipped, so an interrupted export is resumed by running it again
+// with the same settings.
+type Exporter struct {
+	HistoryQ *history.Q
+	Dir      string
+	Format   Format
+	// LedgersPerFile is the size of the ledger ranges of exported files.
+	// Ranges are aligned on multiples of LedgersPerFile.
+	LedgersPerFile uint32
+	// BatchSize is the number of rows loaded by every query.
+	BatchSize uint64
+}
+
+// Export writes the rows of table for ledgers start to end, inclusive. The
+// ledgers must be ingested.
+func (e *Exporter) Export(table Table, start, end uint32) error {
+	if _, ok := tableExporters[table]; !ok {
+		return errors.Errorf("unknown history table %q", table)
+	}
+	if start == 0 || start > end {
+		return errors.Errorf("invalid ledger range [%d, %d]", start, end)
+	}
+
+	var latest uint32
+	if err := e.HistoryQ.LatestLedger(&latest); err != nil {
+		return errors.Wrap(err, "could not get latest ledger")
+	}
+	if end > latest {
+		// Files of ledgers which aren't ingested yet would be incomplete.
+		return errors.Errorf("ledger %d is not ingested, the latest ingested ledger is %d", end, latest)
+	}
+
+	size := e.LedgersPerFile
+	if size == 0 {
+		size = defaultLedgersPerFile
+	}
+	for first := start; first <= end; {
+		last := first - first%size + size - 1
+		if last > end || last < first {
+			last = end
+		}
+		if err := e.exportFile(table, first, last); err != nil {
+			return err
+		}
+		if last == end {
+			break
+		}
+		first = last + 1
+	}
+	return nil
+}
+
+func (e *Exporter) path(table Table, first, last uint32) string {
+	return filepath.Join(
+		e.Dir, string(table),
+		fmt.Sprintf("%s_%d-%d.%s", table, first, last, e.format()),
+	)
+}
+
+func (e *Exporter) format() Format {
+	if e.Format == "" {
+		return ParquetFormat
+	}
+	return e.Format
+}
+
+func (e *Exporter) exportFile(table Table, first, last uint32) error {
+	path := e.path(table, first, last)
+	l := log.WithFields(log.F{"table": table, "first": first, "last": last, "path": path})
+
+	if _, err := os.Stat(path); err == nil {
+		l.Info("Skipping exported file")
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "could not create export directory")
+	}
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return errors.Wrap(err, "could not create export file")
+	}
+	defer file.Close()
+
+	rows, err := e.writeRows(file, table, first, last)
+	if err != nil {
+		return errors.Wrapf(err, "could not export %s", path)
+	}
+	if err = file.Close(); err != nil {
+		return errors.Wrap(err, "could not close export file")
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "could not rename export file")
+	}
+
+	l.WithField("rows", rows).Info("Exported file")
+	return nil
+}
+
+// writeRows writes the rows of table for ledgers first to last to w and
+// returns the number of rows.
+func (e *Exporter) writeRows(w io.Writer, table Table, first, last uint32) (int, error) {
+	exporter := tableExporters[table]
+	writer, err := newRowWriter(e.format(), w, exporter.columns)
+	if err != nil {
+		return 0, err
+	}
+
+	batchSize := e.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+
+	rows := 0
+	cursor := exporter.startCursor(first)
+	for done := false; !done; {
+		records, err := exporter.page(e.HistoryQ, cursor, batchSize)
+		if err != nil {
+			return rows, err
+		}
+		done = uint64(len(records)) < batchSize
+
+		for _, r := range records {
+			if r.ledger > last {
+				done = true
+				break
+			}
+			if err = writer.Write(r.values); err != nil {
+				return rows, err
+			}
+			rows++
+			cursor = r.cursor
+		}
+	}
+
+	return rows, writer.Close()
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/test"
+)
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	assert.NoError(t, err)
+	assert.Equal(t, CSVFormat, format)
+
+	_, err = ParseFormat("json")
+	assert.EqualError(t, err, `unknown export format "json", expected parquet or csv`)
+}
+
+func TestExport(t *testing.T) {
+	tt := test.Start(t).Scenario("base")
+	defer tt.Finish()
+
+	dir, err := ioutil.TempDir("", "export")
+	tt.Require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	q := &history.Q{tt.AuroraSession()}
+	exporter := &Exporter{
+		HistoryQ:       q,
+		Dir:            dir,
+		Format:         CSVFormat,
+		LedgersPerFile: 2,
+		BatchSize:      1,
+	}
+
+	var latest uint32
+	tt.Require.NoError(q.LatestLedger(&latest))
+	tt.Assert.Error(exporter.Export(LedgersTable, 1, latest+1))
+
+	for _, table := range Tables {
+		tt.Require.NoError(exporter.Export(table, 1, latest))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "ledgers", "*.csv"))
+	tt.Require.NoError(err)
+	tt.Assert.Len(files, int(latest/2+1))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "ledgers", "ledgers_2-3.csv"))
+	tt.Require.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	tt.Assert.Len(lines, 3)
+	tt.Assert.True(strings.HasPrefix(lines[0], "id,sequence,ledger_hash,"))
+
+	var operations int
+	tt.Require.NoError(q.GetRaw(&operations, "SELECT COUNT(*) FROM history_operations"))
+	exported := 0
+	files, err = filepath.Glob(filepath.Join(dir, "operations", "*.csv"))
+	tt.Require.NoError(err)
+	for _, file := range files {
+		content, err = ioutil.ReadFile(file)
+		tt.Require.NoError(err)
+		exported += len(strings.Split(strings.TrimSpace(string(content)), "\n")) - 1
+	}
+	tt.Assert.Equal(operations, exported)
+
+	// Exported files are skipped when the export is resumed.
+	path := filepath.Join(dir, "ledgers", "ledgers_2-3.csv")
+	tt.Require.NoError(ioutil.WriteFile(path, []byte("resumed"), 0644))
+	tt.Require.NoError(exporter.Export(LedgersTable, 1, latest))
+	content, err = ioutil.ReadFile(path)
+	tt.Require.NoError(err)
+	tt.Assert.Equal("resumed", string(content))
+}
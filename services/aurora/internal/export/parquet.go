@@ -0,0 +1,448 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// parquetWriter writes rows to a Parquet file with a flat schema. Values are
+// PLAIN encoded in a single uncompressed data page per column and row group,
+// which keeps the writer small while being readable by any Parquet reader.
+type parquetWriter struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	buffers      []*columnBuffer
+	rows         int
+	rowGroupSize int
+	rowGroups    []parquetRowGroup
+	totalRows    int64
+}
+
+type columnBuffer struct {
+	values bytes.Buffer
+	// levels holds the definition level of every value of nullable columns.
+	levels []bool
+	count  int
+	// bits holds the booleans of the current byte of boolean columns.
+	bits  byte
+	nbits uint
+}
+
+type parquetRowGroup struct {
+	columns  []parquetColumnChunk
+	byteSize int64
+	numRows  int64
+}
+
+type parquetColumnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// Parquet physical types, repetitions, converted types and encodings, see
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetDataPage = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// defaultRowGroupSize is the number of rows buffered in memory before they
+// are written as a row group.
+const defaultRowGroupSize = 100000
+
+func newParquetWriter(w io.Writer, columns []Column) (*parquetWriter, error) {
+	p := &parquetWriter{
+		w:            w,
+		columns:      columns,
+		buffers:      make([]*columnBuffer, len(columns)),
+		rowGroupSize: defaultRowGroupSize,
+	}
+	for i := range p.buffers {
+		p.buffers[i] = &columnBuffer{}
+	}
+	if err := p.write(parquetMagic); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return errors.Wrap(err, "error writing parquet file")
+}
+
+// Write buffers row, which must hold a value of the type of every column or
+// nil for null values of nullable columns.
+func (p *parquetWriter) Write(row []interface{}) error {
+	if len(row) != len(p.columns) {
+		return errors.Errorf("row has %d values, expected %d", len(row), len(p.columns))
+	}
+	for i, value := range row {
+		if err := p.buffers[i].add(p.columns[i], value); err != nil {
+			return err
+		}
+	}
+	p.rows++
+	if p.rows >= p.rowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+func (b *columnBuffer) add(column Column, value interface{}) error {
+	b.count++
+	if column.Nullable {
+		b.levels = append(b.levels, value != nil)
+	}
+	if value == nil {
+		if !column.Nullable {
+			return errors.Errorf("column %s is not nullable", column.Name)
+		}
+		return nil
+	}
+
+	var le [8]byte
+	switch column.Type {
+	case BoolColumn:
+		v, ok := value.(bool)
+		if !ok {
+			return typeError(column, value)
+		}
+		if v {
+			b.bits |= 1 << b.nbits
+		}
+		b.nbits++
+		if b.nbits == 8 {
+			b.values.WriteByte(b.bits)
+			b.bits, b.nbits = 0, 0
+		}
+	case Int32Column:
+		v, ok := value.(int32)
+		if !ok {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint32(le[:4], uint32(v))
+		b.values.Write(le[:4])
+	case Int64Column:
+		v, ok := value.(int64)
+		if !ok {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(le[:], uint64(v))
+		b.values.Write(le[:])
+	case TimestampColumn:
+		v, ok := value.(time.Time)
+		if !ok {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint64(le[:], uint64(v.UnixNano()/int64(time.Millisecond)))
+		b.values.Write(le[:])
+	case StringColumn:
+		v, ok := value.(string)
+		if !ok {
+			return typeError(column, value)
+		}
+		binary.LittleEndian.PutUint32(le[:4], uint32(len(v)))
+		b.values.Write(le[:4])
+		b.values.WriteString(v)
+	default:
+		return errors.Errorf("unknown type of column %s", column.Name)
+	}
+	return nil
+}
+
+func typeError(column Column, value interface{}) error {
+	return errors.Errorf("invalid value %v (%T) for column %s", value, value, column.Name)
+}
+
+// page returns the data of the data page holding the buffered values.
+func (b *columnBuffer) page() []byte {
+	var page bytes.Buffer
+	if b.levels != nil {
+		levels := encodeLevels(b.levels)
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+		page.Write(length[:])
+		page.Write(levels)
+	}
+	page.Write(b.values.Bytes())
+	if b.nbits > 0 {
+		page.WriteByte(b.bits)
+	}
+	return page.Bytes()
+}
+
+func (b *columnBuffer) reset() {
+	b.values.Reset()
+	b.levels = b.levels[:0]
+	b.count = 0
+	b.bits, b.nbits = 0, 0
+}
+
+// encodeLevels encodes definition levels with a bit width of 1 using runs of
+// the RLE / bit-packing hybrid encoding.
+func encodeLevels(levels []bool) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = appendUvarint(out, uint64(j-i)<<1)
+		if levels[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// flush writes the buffered rows as a row group.
+func (p *parquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+
+	group := parquetRowGroup{numRows: int64(p.rows)}
+	for i, buffer := range p.buffers {
+		data := buffer.page()
+
+		header := newThriftWriter()
+		header.fieldI32(1, parquetDataPage)
+		header.fieldI32(2, int32(len(data)))
+		header.fieldI32(3, int32(len(data)))
+		header.fieldStructBegin(5)
+		header.fieldI32(1, int32(buffer.count))
+		header.fieldI32(2, parquetPlain)
+		header.fieldI32(3, parquetRLE)
+		header.fieldI32(4, parquetRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunk := parquetColumnChunk{
+			offset:    p.offset,
+			size:      int64(header.buf.Len() + len(data)),
+			numValues: int64(buffer.count),
+		}
+		if err := p.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+		group.byteSize += chunk.size
+		p.buffers[i].reset()
+	}
+
+	p.rowGroups = append(p.rowGroups, group)
+	p.totalRows += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+// Close writes the remaining rows and the file footer. It doesn't close the
+// underlying writer.
+func (p *parquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	meta := newThriftWriter()
+	meta.fieldI32(1, 1)
+	meta.fieldListBegin(2, thriftStruct, len(p.columns)+1)
+	meta.structBegin()
+	meta.fieldBinary(4, "schema")
+	meta.fieldI32(5, int32(len(p.columns)))
+	meta.structEnd()
+	for _, column := range p.columns {
+		meta.structBegin()
+		meta.fieldI32(1, column.parquetType())
+		repetition := int32(parquetRequired)
+		if column.Nullable {
+			repetition = parquetOptional
+		}
+		meta.fieldI32(3, repetition)
+		meta.fieldBinary(4, column.Name)
+		switch column.Type {
+		case StringColumn:
+			meta.fieldI32(6, parquetUTF8)
+		case TimestampColumn:
+			meta.fieldI32(6, parquetTimestampMillis)
+		}
+		meta.structEnd()
+	}
+	meta.fieldI64(3, p.totalRows)
+	meta.fieldListBegin(4, thriftStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		meta.structBegin()
+		meta.fieldListBegin(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			column := p.columns[i]
+			meta.structBegin()
+			meta.fieldI64(2, chunk.offset)
+			meta.fieldStructBegin(3)
+			meta.fieldI32(1, column.parquetType())
+			meta.fieldListBegin(2, thriftI32, 2)
+			meta.i32(parquetPlain)
+			meta.i32(parquetRLE)
+			meta.fieldListBegin(3, thriftBinary, 1)
+			meta.binary(column.Name)
+			meta.fieldI32(4, 0)
+			meta.fieldI64(5, chunk.numValues)
+			meta.fieldI64(6, chunk.size)
+			meta.fieldI64(7, chunk.size)
+			meta.fieldI64(9, chunk.offset)
+			meta.structEnd()
+			meta.structEnd()
+		}
+		meta.fieldI64(2, group.byteSize)
+		meta.fieldI64(3, group.numRows)
+		meta.structEnd()
+	}
+	meta.fieldBinary(6, "aurora")
+	meta.structEnd()
+
+	if err := p.write(meta.buf.Bytes()); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len()))
+	if err := p.write(length[:]); err != nil {
+		return err
+	}
+	return p.write(parquetMagic)
+}
+
+func (c Column) parquetType() int32 {
+	switch c.Type {
+	case BoolColumn:
+		return parquetBoolean
+	case Int32Column:
+		return parquetInt32
+	case Int64Column, TimestampColumn:
+		return parquetInt64
+	default:
+		return parquetByteArray
+	}
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Parquet metadata structures with the Thrift
+// compact protocol.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastField holds the id of the last field written in every struct
+	// being written, field ids are encoded as deltas.
+	lastField []int16
+}
+
+// newThriftWriter returns a writer encoding the fields of a struct, which is
+// ended by structEnd.
+func newThriftWriter() *thriftWriter {
+	t := &thriftWriter{}
+	t.structBegin()
+	return t
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	n := len(t.lastField)
+	last := t.lastField[n-1]
+	t.lastField[n-1] = id
+	if delta := id - last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+		return
+	}
+	t.buf.WriteByte(fieldType)
+	t.buf.Write(appendUvarint(nil, zigzag(int64(id))))
+}
+
+func (t *thriftWriter) structBegin() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0)
+	t.lastField = t.lastField[:len(t.lastField)-1]
+}
+
+func (t *thriftWriter) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.structBegin()
+}
+
+func (t *thriftWriter) fieldI32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) fieldI64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.buf.Write(appendUvarint(nil, zigzag(v)))
+}
+
+func (t *thriftWriter) fieldBinary(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(v)
+}
+
+func (t *thriftWriter) fieldListBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elemType)
+	t.buf.Write(appendUvarint(nil, uint64(size)))
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.buf.Write(appendUvarint(nil, zigzag(int64(v))))
+}
+
+func (t *thriftWriter) binary(v string) {
+	t.buf.Write(appendUvarint(nil, uint64(len(v))))
+	t.buf.WriteString(v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,161 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestEncodeLevels(t *testing.T) {
+	assert.Equal(t, []byte{0x04, 1, 0x02, 0, 0x06, 1}, encodeLevels([]bool{true, true, false, true, true, true}))
+	assert.Empty(t, encodeLevels(nil))
+}
+
+func TestThriftWriter(t *testing.T) {
+	w := newThriftWriter()
+	w.fieldI32(1, -1)
+	w.fieldI64(3, 300)
+	w.fieldStructBegin(20)
+	w.fieldBinary(1, "ab")
+	w.structEnd()
+	w.structEnd()
+	assert.Equal(
+		t,
+		[]byte{
+			0x15, 0x01, // field 1, i32, zigzag(-1)
+			0x26, 0xd8, 0x04, // field 3, i64, zigzag(300)
+			0x0c, 0x28, // field 20, struct, long form
+			0x18, 0x02, 'a', 'b', // field 1, binary
+			0x00, // end of nested struct
+			0x00, // end of struct
+		},
+		w.buf.Bytes(),
+	)
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []Column{
+		{Name: "id", Type: Int64Column},
+		{Name: "ok", Type: BoolColumn},
+		{Name: "memo", Type: StringColumn, Nullable: true},
+		{Name: "closed_at", Type: TimestampColumn},
+	}
+	w, err := newParquetWriter(&buf, columns)
+	assert.NoError(t, err)
+	w.rowGroupSize = 2
+
+	closedAt := time.Unix(1600000000, 0)
+	assert.NoError(t, w.Write([]interface{}{int64(1), true, "a", closedAt}))
+	assert.NoError(t, w.Write([]interface{}{int64(2), false, nil, closedAt}))
+	assert.NoError(t, w.Write([]interface{}{int64(3), true, "c", closedAt}))
+	assert.EqualError(t, w.Write([]interface{}{int64(4), true, nil}), "row has 3 values, expected 4")
+	assert.EqualError(t, w.Write([]interface{}{nil, true, nil, closedAt}), "column id is not nullable")
+	assert.NoError(t, w.Close())
+	assert.Len(t, w.rowGroups, 2)
+	assert.Equal(t, int64(3), w.totalRows)
+
+	file := buf.Bytes()
+	assert.Equal(t, "PAR1", string(file[:4]))
+	assert.Equal(t, "PAR1", string(file[len(file)-4:]))
+	footerLength := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := file[len(file)-8-int(footerLength) : len(file)-8]
+	// The footer starts with the version and the list of 5 schema elements.
+	assert.Equal(t, []byte{0x15, 0x02, 0x19, 0x5c}, footer[:4])
+	assert.Contains(t, string(footer), "closed_at")
+}
+
+// TestParquetWriterRoundTrip reads the written file back with an independent
+// Parquet implementation.
+func TestParquetWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	columns := []Column{
+		{Name: "sequence", Type: Int32Column},
+		{Name: "id", Type: Int64Column},
+		{Name: "successful", Type: BoolColumn},
+		{Name: "memo", Type: StringColumn, Nullable: true},
+		{Name: "closed_at", Type: TimestampColumn},
+		{Name: "fee", Type: Int64Column, Nullable: true},
+	}
+	w, err := newParquetWriter(&buf, columns)
+	require.NoError(t, err)
+	// Several row groups, with more than 8 booleans in some of them.
+	w.rowGroupSize = 9
+
+	closedAt := time.Unix(1600000000, 123000000)
+	var expected [][]interface{}
+	for i := 0; i < 20; i++ {
+		row := []interface{}{
+			int32(-i),
+			int64(i) << 40,
+			i%3 == 0,
+			nil,
+			closedAt.Add(time.Duration(i) * time.Second),
+			nil,
+		}
+		if i%2 == 0 {
+			row[3] = fmt.Sprintf("memo %d", i)
+		}
+		if i%5 != 0 {
+			row[5] = int64(100 * i)
+		}
+		require.NoError(t, w.Write(row))
+		expected = append(expected, row)
+	}
+	require.NoError(t, w.Close())
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), pr.GetNumRows())
+	assert.Len(t, pr.Footer.RowGroups, 3)
+
+	schema := pr.Footer.Schema
+	require.Len(t, schema, len(columns)+1)
+	assert.Equal(t, int32(len(columns)), schema[0].GetNumChildren())
+	expectedSchema := []struct {
+		name          string
+		physical      parquet.Type
+		repetition    parquet.FieldRepetitionType
+		convertedType *parquet.ConvertedType
+	}{
+		{"sequence", parquet.Type_INT32, parquet.FieldRepetitionType_REQUIRED, nil},
+		{"id", parquet.Type_INT64, parquet.FieldRepetitionType_REQUIRED, nil},
+		{"successful", parquet.Type_BOOLEAN, parquet.FieldRepetitionType_REQUIRED, nil},
+		{"memo", parquet.Type_BYTE_ARRAY, parquet.FieldRepetitionType_OPTIONAL, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)},
+		{"closed_at", parquet.Type_INT64, parquet.FieldRepetitionType_REQUIRED, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)},
+		{"fee", parquet.Type_INT64, parquet.FieldRepetitionType_OPTIONAL, nil},
+	}
+	for i, e := range expectedSchema {
+		element := schema[i+1]
+		// The reader renames the schema elements, their names are kept by
+		// the schema handler.
+		assert.Equal(t, e.name, pr.SchemaHandler.GetExName(i+1))
+		assert.Equal(t, e.physical, element.GetType(), e.name)
+		assert.Equal(t, e.repetition, element.GetRepetitionType(), e.name)
+		assert.Equal(t, e.convertedType, element.ConvertedType, e.name)
+	}
+
+	for i, column := range columns {
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), pr.GetNumRows())
+		require.NoError(t, err, column.Name)
+		require.Len(t, values, len(expected), column.Name)
+		for j, row := range expected {
+			want := row[i]
+			switch v := want.(type) {
+			case time.Time:
+				want = v.UnixNano() / int64(time.Millisecond)
+			}
+			assert.Equal(t, want, values[j], "%s of row %d", column.Name, j)
+		}
+	}
+}
@@ -0,0 +1,636 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hcnet/go/protocols/aurora/effects"
+	"github.com/hcnet/go/protocols/aurora/operations"
+	"github.com/hcnet/go/services/aurora/internal/db2"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/toid"
+	"github.com/hcnet/go/support/errors"
+)
+
+// ColumnType is the type of the values of an exported column.
+type ColumnType int
+
+const (
+	Int32Column ColumnType = iota
+	Int64Column
+	BoolColumn
+	StringColumn
+	// TimestampColumn values are stored with a millisecond precision.
+	TimestampColumn
+)
+
+// Column is a column of an exported table.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool
+}
+
+// Table is a history table which can be exported.
+type Table string
+
+const (
+	LedgersTable      Table = "ledgers"
+	TransactionsTable Table = "transactions"
+	OperationsTable   Table = "operations"
+	EffectsTable      Table = "effects"
+	TradesTable       Table = "trades"
+)
+
+// Tables lists the tables which can be exported.
+var Tables = []Table{
+	LedgersTable,
+	TransactionsTable,
+	OperationsTable,
+	EffectsTable,
+	TradesTable,
+}
+
+// ParseTables parses a comma separated list of tables.
+func ParseTables(s string) ([]Table, error) {
+	var tables []Table
+	for _, name := range strings.Split(s, ",") {
+		table := Table(strings.TrimSpace(name))
+		if _, ok := tableExporters[table]; !ok {
+			return nil, errors.Errorf("unknown history table %q", name)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// Columns returns the columns of table. The columns of a table don't depend
+// on the exported rows, details stored as JSON are flattened into a fixed
+// set of details_* columns and the keys without a column are kept as JSON in
+// details_other.
+func (t Table) Columns() []Column {
+	return tableExporters[t].columns
+}
+
+// record is an exported row.
+type record struct {
+	ledger uint32
+	// cursor is the paging cursor of the row.
+	cursor string
+	values []interface{}
+}
+
+type tableExporter struct {
+	columns []Column
+	// startCursor returns the cursor preceding the rows of ledger.
+	startCursor func(ledger uint32) string
+	// page loads up to limit rows following cursor.
+	page func(q *history.Q, cursor string, limit uint64) ([]record, error)
+}
+
+var tableExporters = map[Table]tableExporter{
+	LedgersTable: {
+		columns: []Column{
+			{Name: "id", Type: Int64Column},
+			{Name: "sequence", Type: Int32Column},
+			{Name: "ledger_hash", Type: StringColumn},
+			{Name: "previous_ledger_hash", Type: StringColumn, Nullable: true},
+			{Name: "transaction_count", Type: Int32Column},
+			{Name: "successful_transaction_count", Type: Int32Column, Nullable: true},
+			{Name: "failed_transaction_count", Type: Int32Column, Nullable: true},
+			{Name: "operation_count", Type: Int32Column},
+			{Name: "tx_set_operation_count", Type: Int32Column, Nullable: true},
+			{Name: "closed_at", Type: TimestampColumn},
+			{Name: "total_coins", Type: Int64Column},
+			{Name: "fee_pool", Type: Int64Column},
+			{Name: "base_fee", Type: Int32Column},
+			{Name: "base_reserve", Type: Int32Column},
+			{Name: "max_tx_set_size", Type: Int32Column},
+			{Name: "protocol_version", Type: Int32Column},
+			{Name: "importer_version", Type: Int32Column},
+			{Name: "ledger_header", Type: StringColumn, Nullable: true},
+		},
+		startCursor: idCursor,
+		page:        ledgersPage,
+	},
+	TransactionsTable: {
+		columns: []Column{
+			{Name: "id", Type: Int64Column},
+			{Name: "transaction_hash", Type: StringColumn},
+			{Name: "ledger_sequence", Type: Int32Column},
+			{Name: "ledger_close_time", Type: TimestampColumn},
+			{Name: "application_order", Type: Int32Column},
+			{Name: "account", Type: StringColumn},
+			{Name: "account_muxed", Type: StringColumn, Nullable: true},
+			{Name: "account_sequence", Type: Int64Column},
+			{Name: "max_fee", Type: Int64Column},
+			{Name: "fee_charged", Type: Int64Column},
+			{Name: "operation_count", Type: Int32Column},
+			{Name: "successful", Type: BoolColumn},
+			{Name: "memo_type", Type: StringColumn},
+			{Name: "memo", Type: StringColumn, Nullable: true},
+			{Name: "time_bounds_lower", Type: Int64Column, Nullable: true},
+			{Name: "time_bounds_upper", Type: Int64Column, Nullable: true},
+			{Name: "fee_account", Type: StringColumn, Nullable: true},
+			{Name: "fee_account_muxed", Type: StringColumn, Nullable: true},
+			{Name: "inner_transaction_hash", Type: StringColumn, Nullable: true},
+			{Name: "new_max_fee", Type: Int64Column, Nullable: true},
+			{Name: "signatures", Type: StringColumn},
+			{Name: "inner_signatures", Type: StringColumn, Nullable: true},
+			{Name: "tx_envelope", Type: StringColumn},
+			{Name: "tx_result", Type: StringColumn},
+			{Name: "tx_meta", Type: StringColumn},
+			{Name: "tx_fee_meta", Type: StringColumn},
+		},
+		startCursor: idCursor,
+		page:        transactionsPage,
+	},
+	OperationsTable: {
+		columns: append([]Column{
+			{Name: "id", Type: Int64Column},
+			{Name: "transaction_id", Type: Int64Column},
+			{Name: "transaction_hash", Type: StringColumn},
+			{Name: "transaction_successful", Type: BoolColumn},
+			{Name: "application_order", Type: Int32Column},
+			{Name: "type", Type: Int32Column},
+			{Name: "type_string", Type: StringColumn},
+			{Name: "source_account", Type: StringColumn},
+			{Name: "source_account_muxed", Type: StringColumn, Nullable: true},
+		}, detailsColumns(operationDetails)...),
+		startCursor: idCursor,
+		page:        operationsPage,
+	},
+	EffectsTable: {
+		columns: append([]Column{
+			{Name: "history_operation_id", Type: Int64Column},
+			{Name: "order", Type: Int32Column},
+			{Name: "type", Type: Int32Column},
+			{Name: "type_string", Type: StringColumn},
+			{Name: "account", Type: StringColumn},
+			{Name: "account_muxed", Type: StringColumn, Nullable: true},
+		}, detailsColumns(effectDetails)...),
+		startCursor: pairCursor,
+		page:        effectsPage,
+	},
+	TradesTable: {
+		columns: []Column{
+			{Name: "history_operation_id", Type: Int64Column},
+			{Name: "order", Type: Int32Column},
+			{Name: "ledger_closed_at", Type: TimestampColumn},
+			{Name: "offer_id", Type: Int64Column},
+			{Name: "base_offer_id", Type: Int64Column, Nullable: true},
+			{Name: "base_account", Type: StringColumn},
+			{Name: "base_asset_type", Type: StringColumn},
+			{Name: "base_asset_code", Type: StringColumn},
+			{Name: "base_asset_issuer", Type: StringColumn},
+			{Name: "base_amount", Type: Int64Column},
+			{Name: "counter_offer_id", Type: Int64Column, Nullable: true},
+			{Name: "counter_account", Type: StringColumn},
+			{Name: "counter_asset_type", Type: StringColumn},
+			{Name: "counter_asset_code", Type: StringColumn},
+			{Name: "counter_asset_issuer", Type: StringColumn},
+			{Name: "counter_amount", Type: Int64Column},
+			{Name: "base_is_seller", Type: BoolColumn},
+			{Name: "price_n", Type: Int64Column, Nullable: true},
+			{Name: "price_d", Type: Int64Column, Nullable: true},
+		},
+		startCursor: pairCursor,
+		page:        tradesPage,
+	},
+}
+
+// idCursor returns the cursor of tables paged by TOID.
+func idCursor(ledger uint32) string {
+	return strconv.FormatInt(toid.New(int32(ledger), 0, 0).ToInt64()-1, 10)
+}
+
+// pairCursor returns the cursor of tables paged by operation id and order.
+func pairCursor(ledger uint32) string {
+	return fmt.Sprintf("%s%s%d", idCursor(ledger), db2.DefaultPairSep, math.MaxInt32)
+}
+
+func pageQuery(cursor string, limit uint64) db2.PageQuery {
+	return db2.PageQuery{Cursor: cursor, Order: db2.OrderAscending, Limit: limit}
+}
+
+func ledgersPage(q *history.Q, cursor string, limit uint64) ([]record, error) {
+	var ledgers []history.Ledger
+	if err := q.Ledgers().Page(pageQuery(cursor, limit)).Select(&ledgers); err != nil {
+		return nil, errors.Wrap(err, "could not load ledgers")
+	}
+
+	records := make([]record, len(ledgers))
+	for i, l := range ledgers {
+		records[i] = record{
+			ledger: uint32(l.Sequence),
+			cursor: strconv.FormatInt(l.ID, 10),
+			values: []interface{}{
+				l.ID,
+				l.Sequence,
+				l.LedgerHash,
+				nullString(l.PreviousLedgerHash.Ptr()),
+				l.TransactionCount,
+				nullInt32(l.SuccessfulTransactionCount),
+				nullInt32(l.FailedTransactionCount),
+				l.OperationCount,
+				nullInt32(l.TxSetOperationCount),
+				l.ClosedAt,
+				l.TotalCoins,
+				l.FeePool,
+				l.BaseFee,
+				l.BaseReserve,
+				l.MaxTxSetSize,
+				l.ProtocolVersion,
+				l.ImporterVersion,
+				nullString(l.LedgerHeaderXDR.Ptr()),
+			},
+		}
+	}
+	return records, nil
+}
+
+func transactionsPage(q *history.Q, cursor string, limit uint64) ([]record, error) {
+	var transactions []history.Transaction
+	err := q.Transactions().IncludeFailed().Page(pageQuery(cursor, limit)).Select(&transactions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load transactions")
+	}
+
+	records := make([]record, len(transactions))
+	for i, tx := range transactions {
+		sequence, err := strconv.ParseInt(tx.AccountSequence, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid account sequence of transaction %s", tx.TransactionHash)
+		}
+		var lower, upper interface{}
+		if !tx.TimeBounds.Null {
+			lower = nullInt64(tx.TimeBounds.Lower.Ptr())
+			upper = nullInt64(tx.TimeBounds.Upper.Ptr())
+		}
+		var innerSignatures interface{}
+		if tx.InnerTransactionHash.Valid {
+			innerSignatures = strings.Join(tx.InnerSignatures, ",")
+		}
+
+		records[i] = record{
+			ledger: uint32(tx.LedgerSequence),
+			cursor: strconv.FormatInt(tx.ID, 10),
+			values: []interface{}{
+				tx.ID,
+				tx.TransactionHash,
+				tx.LedgerSequence,
+				tx.LedgerCloseTime,
+				tx.ApplicationOrder,
+				tx.Account,
+				nullString(tx.AccountMuxed.Ptr()),
+				sequence,
+				tx.MaxFee,
+				tx.FeeCharged,
+				tx.OperationCount,
+				tx.Successful,
+				tx.MemoType,
+				nullString(tx.Memo.Ptr()),
+				lower,
+				upper,
+				nullString(tx.FeeAccount.Ptr()),
+				nullString(tx.FeeAccountMuxed.Ptr()),
+				nullString(tx.InnerTransactionHash.Ptr()),
+				nullInt64(tx.NewMaxFee.Ptr()),
+				strings.Join(tx.Signatures, ","),
+				innerSignatures,
+				tx.TxEnvelope,
+				tx.TxResult,
+				tx.TxMeta,
+				tx.TxFeeMeta,
+			},
+		}
+	}
+	return records, nil
+}
+
+func operationsPage(q *history.Q, cursor string, limit uint64) ([]record, error) {
+	ops, _, err := q.Operations().IncludeFailed().Page(pageQuery(cursor, limit)).Fetch()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load operations")
+	}
+
+	records := make([]record, len(ops))
+	for i, op := range ops {
+		details, err := flattenDetails(operationDetails, op.DetailsString.Ptr())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid details of operation %d", op.ID)
+		}
+		records[i] = record{
+			ledger: uint32(toid.Parse(op.ID).LedgerSequence),
+			cursor: strconv.FormatInt(op.ID, 10),
+			values: append([]interface{}{
+				op.ID,
+				op.TransactionID,
+				op.TransactionHash,
+				op.TransactionSuccessful,
+				op.ApplicationOrder,
+				int32(op.Type),
+				operations.TypeNames[op.Type],
+				op.SourceAccount,
+				nullString(op.SourceAccountMuxed.Ptr()),
+			}, details...),
+		}
+	}
+	return records, nil
+}
+
+func effectsPage(q *history.Q, cursor string, limit uint64) ([]record, error) {
+	var rows []history.Effect
+	if err := q.Effects().Page(pageQuery(cursor, limit)).Select(&rows); err != nil {
+		return nil, errors.Wrap(err, "could not load effects")
+	}
+
+	records := make([]record, len(rows))
+	for i, effect := range rows {
+		details, err := flattenDetails(effectDetails, effect.DetailsString.Ptr())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid details of effect %s", effect.PagingToken())
+		}
+		records[i] = record{
+			ledger: uint32(toid.Parse(effect.HistoryOperationID).LedgerSequence),
+			cursor: effect.PagingToken(),
+			values: append([]interface{}{
+				effect.HistoryOperationID,
+				effect.Order,
+				int32(effect.Type),
+				effects.EffectTypeNames[effects.EffectType(effect.Type)],
+				effect.Account,
+				nullString(effect.AccountMuxed.Ptr()),
+			}, details...),
+		}
+	}
+	return records, nil
+}
+
+func tradesPage(q *history.Q, cursor string, limit uint64) ([]record, error) {
+	var trades []history.Trade
+	if err := q.Trades().Page(pageQuery(cursor, limit)).Select(&trades); err != nil {
+		return nil, errors.Wrap(err, "could not load trades")
+	}
+
+	records := make([]record, len(trades))
+	for i, trade := range trades {
+		records[i] = record{
+			ledger: uint32(toid.Parse(trade.HistoryOperationID).LedgerSequence),
+			cursor: trade.PagingToken(),
+			values: []interface{}{
+				trade.HistoryOperationID,
+				trade.Order,
+				trade.LedgerCloseTime,
+				trade.OfferID,
+				nullInt64(trade.BaseOfferID),
+				trade.BaseAccount,
+				trade.BaseAssetType,
+				trade.BaseAssetCode,
+				trade.BaseAssetIssuer,
+				int64(trade.BaseAmount),
+				nullInt64(trade.CounterOfferID),
+				trade.CounterAccount,
+				trade.CounterAssetType,
+				trade.CounterAssetCode,
+				trade.CounterAssetIssuer,
+				int64(trade.CounterAmount),
+				trade.BaseIsSeller,
+				nullInt64(trade.PriceN.Ptr()),
+				nullInt64(trade.PriceD.Ptr()),
+			},
+		}
+	}
+	return records, nil
+}
+
+// nullString, nullInt32 and nullInt64 return an untyped nil for null values.
+func nullString(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func nullInt32(v *int32) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func nullInt64(v *int64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// detail is a key of the JSON details of operations or effects exported in
+// its own column. The keys of nested objects are joined with "_", for example
+// price_r.n is exported as details_price_r_n.
+type detail struct {
+	key        string
+	columnType ColumnType
+}
+
+// detailsOtherColumn holds the details without a column as a JSON object.
+const detailsOtherColumn = "details_other"
+
+func detailsColumns(details []detail) []Column {
+	columns := make([]Column, 0, len(details)+1)
+	for _, d := range details {
+		columns = append(columns, Column{Name: "details_" + d.key, Type: d.columnType, Nullable: true})
+	}
+	return append(columns, Column{Name: detailsOtherColumn, Type: StringColumn, Nullable: true})
+}
+
+// flattenDetails returns the values of the details columns for the JSON
+// details. Arrays and objects are exported as JSON in string columns. Values
+// which don't match the type of their column are kept in details_other.
+func flattenDetails(columns []detail, raw *string) ([]interface{}, error) {
+	values := make([]interface{}, len(columns)+1)
+	if raw == nil {
+		return values, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(*raw))
+	decoder.UseNumber()
+	var details map[string]interface{}
+	if err := decoder.Decode(&details); err != nil {
+		return nil, err
+	}
+	flat := map[string]interface{}{}
+	flatten(flat, columns, "", details)
+
+	for i, column := range columns {
+		value, ok := flat[column.key]
+		if !ok || value == nil {
+			continue
+		}
+		converted, ok := convertDetail(column.columnType, value)
+		if !ok {
+			// Values which can't be converted are kept in details_other.
+			continue
+		}
+		values[i] = converted
+		delete(flat, column.key)
+	}
+
+	if len(flat) > 0 {
+		other, err := json.Marshal(flat)
+		if err != nil {
+			return nil, err
+		}
+		values[len(columns)] = string(other)
+	}
+	return values, nil
+}
+
+// flatten copies details into dest, replacing the objects with columns for
+// their keys by their values.
+func flatten(dest map[string]interface{}, columns []detail, prefix string, details map[string]interface{}) {
+	for key, value := range details {
+		if nested, ok := value.(map[string]interface{}); ok && hasColumnWithPrefix(columns, prefix+key+"_") {
+			flatten(dest, columns, prefix+key+"_", nested)
+			continue
+		}
+		dest[prefix+key] = value
+	}
+}
+
+func hasColumnWithPrefix(columns []detail, prefix string) bool {
+	for _, column := range columns {
+		if strings.HasPrefix(column.key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertDetail(columnType ColumnType, value interface{}) (interface{}, bool) {
+	switch columnType {
+	case Int64Column:
+		var s string
+		switch v := value.(type) {
+		case json.Number:
+			s = v.String()
+		case string:
+			s = v
+		default:
+			return nil, false
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, err == nil
+	case BoolColumn:
+		v, ok := value.(bool)
+		return v, ok
+	case StringColumn:
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case json.Number:
+			return v.String(), true
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return nil, false
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), true
+	}
+	return nil, false
+}
+
+func stringDetails(prefix string, keys ...string) []detail {
+	details := make([]detail, len(keys))
+	for i, key := range keys {
+		details[i] = detail{key: prefix + key, columnType: StringColumn}
+	}
+	return details
+}
+
+func assetDetails(prefix string) []detail {
+	return stringDetails(prefix, "asset_type", "asset_code", "asset_issuer")
+}
+
+func joinDetails(groups ...[]detail) []detail {
+	var details []detail
+	for _, group := range groups {
+		details = append(details, group...)
+	}
+	return details
+}
+
+// operationDetails lists the details of operations exported in their own
+// column, see the Details method of the operations processor.
+var operationDetails = joinDetails(
+	stringDetails("",
+		"funder", "account", "starting_balance",
+		"from", "from_muxed", "from_muxed_id", "to", "to_muxed", "to_muxed_id",
+		"amount", "source_amount", "source_max", "destination_min", "path",
+		"price",
+	),
+	assetDetails(""),
+	assetDetails("source_"),
+	assetDetails("buying_"),
+	assetDetails("selling_"),
+	[]detail{
+		{"offer_id", Int64Column},
+		{"price_r_n", Int64Column},
+		{"price_r_d", Int64Column},
+		{"master_key_weight", Int64Column},
+		{"low_threshold", Int64Column},
+		{"med_threshold", Int64Column},
+		{"high_threshold", Int64Column},
+		{"signer_weight", Int64Column},
+		{"authorize", BoolColumn},
+		{"authorize_to_maintain_liabilities", BoolColumn},
+	},
+	stringDetails("",
+		"set_flags", "set_flags_s", "clear_flags", "clear_flags_s",
+		"inflation_dest", "home_domain", "signer_key", "signer_account_id",
+		"trustee", "trustor", "limit",
+		"into", "into_muxed", "into_muxed_id", "account_muxed", "account_muxed_id",
+		"name", "value", "bump_to",
+		"balance_id", "claimant", "claimants", "asset",
+		"sponsored_id", "begin_sponsor", "sponsor",
+		"account_id", "claimable_balance_id", "data_account_id", "data_name",
+		"trustline_account_id", "trustline_asset",
+	),
+)
+
+// effectDetails lists the details of effects exported in their own column,
+// see the effects processor.
+var effectDetails = joinDetails(
+	stringDetails("",
+		"starting_balance", "amount", "asset",
+	),
+	assetDetails(""),
+	[]detail{
+		{"weight", Int64Column},
+		{"low_threshold", Int64Column},
+		{"med_threshold", Int64Column},
+		{"high_threshold", Int64Column},
+		{"offer_id", Int64Column},
+		{"new_seq", Int64Column},
+		{"auth_required_flag", BoolColumn},
+		{"auth_revocable_flag", BoolColumn},
+		{"auth_immutable_flag", BoolColumn},
+	},
+	stringDetails("",
+		"public_key", "home_domain", "inflation_destination", "limit", "trustor",
+		"seller", "sold_amount", "bought_amount",
+	),
+	assetDetails("sold_"),
+	assetDetails("bought_"),
+	stringDetails("",
+		"name", "value", "balance_id", "predicate",
+		"sponsor", "former_sponsor", "new_sponsor", "signer", "data_name",
+	),
+)
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTables(t *testing.T) {
+	tables, err := ParseTables("ledgers, effects")
+	assert.NoError(t, err)
+	assert.Equal(t, []Table{LedgersTable, EffectsTable}, tables)
+
+	_, err = ParseTables("ledgers,history_effects")
+	assert.EqualError(t, err, `unknown history table "history_effects"`)
+}
+
+func TestTableColumns(t *testing.T) {
+	for _, table := range Tables {
+		names := map[string]bool{}
+		for _, column := range table.Columns() {
+			assert.False(t, names[column.Name], "duplicate column %s in %s", column.Name, table)
+			names[column.Name] = true
+		}
+	}
+}
+
+func TestFlattenDetails(t *testing.T) {
+	columns := []detail{
+		{"amount", StringColumn},
+		{"offer_id", Int64Column},
+		{"price_r_n", Int64Column},
+		{"price_r_d", Int64Column},
+		{"authorize", BoolColumn},
+		{"path", StringColumn},
+		{"predicate", StringColumn},
+	}
+
+	values, err := flattenDetails(columns, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, make([]interface{}, len(columns)+1), values)
+
+	raw := `{
+		"amount": "10.0000000",
+		"offer_id": "12",
+		"price_r": {"n": 1, "d": 2},
+		"authorize": "yes",
+		"path": [{"asset_type": "native"}],
+		"predicate": {"unconditional": true},
+		"seller": "GA"
+	}`
+	values, err = flattenDetails(columns, &raw)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]interface{}{
+			"10.0000000",
+			int64(12),
+			int64(1),
+			int64(2),
+			nil,
+			`[{"asset_type":"native"}]`,
+			`{"unconditional":true}`,
+			`{"authorize":"yes","seller":"GA"}`,
+		},
+		values,
+	)
+
+	raw = `{"amount":`
+	_, err = flattenDetails(columns, &raw)
+	assert.Error(t, err)
+}
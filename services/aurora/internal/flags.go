@@ -5,14 +5,19 @@ import (
 	stdLog "log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/hcnet/go/services/aurora/internal/db2/schema"
+	"github.com/hcnet/go/services/aurora/internal/httpx"
+	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/services/aurora/internal/reap"
 	apkg "github.com/hcnet/go/support/app"
 	support "github.com/hcnet/go/support/config"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/xdr"
 	"github.com/stellar/throttled"
 )
 
@@ -68,6 +73,41 @@ func checkMigrations(config Config) {
 	}
 }
 
+// applyLiteConfig validates the options of lite mode and creates the tables of
+// the SQLite database. Lite mode databases don't have the tables of the
+// disabled history processors, of asset stats and of claimable balances.
+func applyLiteConfig(config *Config) {
+	if config.RODatabaseURL != "" {
+		stdLog.Fatalf("Invalid config: --ro-database-url is not supported when --lite is set")
+	}
+	if len(config.StatementTimeouts) > 0 || len(config.MaxQueryCosts) > 0 {
+		stdLog.Fatalf("Invalid config: --statement-timeouts and --max-query-costs are not supported when --lite is set")
+	}
+	if config.HistoryRetentionCount > 0 || len(config.HistoryTableRetentionCounts) > 0 {
+		stdLog.Fatalf("Invalid config: history retention is not supported when --lite is set")
+	}
+	if config.Ingest && !config.EnableCaptiveCoreIngestion {
+		stdLog.Fatalf("Invalid config: --enable-captive-core-ingestion must be set when --lite and --ingest are set")
+	}
+
+	config.IngestDisableStateVerification = true
+	for _, processor := range ingest.LiteDisabledProcessors {
+		if config.History.Enabled(processor) {
+			config.History.DisabledProcessors = append(config.History.DisabledProcessors, processor)
+		}
+	}
+
+	dbConn, err := db.Open("sqlite3", sqliteDSN(config.DatabaseURL, false))
+	if err != nil {
+		stdLog.Fatalf("could not open aurora db: %v", err)
+	}
+	defer dbConn.Close()
+
+	if err := schema.MigrateSqlite(dbConn.DB.DB); err != nil {
+		stdLog.Fatalf("could not create aurora db tables: %v", err)
+	}
+}
+
 // Flags returns a Config instance and a list of commandline flags which modify the Config instance
 func Flags() (*Config, support.ConfigOptions) {
 	config := &Config{}
@@ -77,7 +117,7 @@ func Flags() (*Config, support.ConfigOptions) {
 		ConfigKey: &config.DatabaseURL,
 		OptType:   types.String,
 		Required:  true,
-		Usage:     "aurora postgres database to connect with",
+		Usage:     "aurora postgres database to connect with, or the path of the SQLite database file when --lite is set",
 	}
 
 	// flags defines the complete flag configuration for aurora.
@@ -219,11 +259,27 @@ func Flags() (*Config, support.ConfigOptions) {
 			OptType: types.String,
 			Usage:   "deprecated, do not use",
 		},
-		&support.ConfigOption{ // Action needed in release: aurora-v2.0.0
-			// remove deprecated flag
-			Name:    "redis-url",
-			OptType: types.String,
-			Usage:   "deprecated, do not use",
+		&support.ConfigOption{
+			Name:        "redis-url",
+			ConfigKey:   &config.RedisURL,
+			OptType:     types.String,
+			FlagDefault: "",
+			Usage:       "Redis server storing the response cache of immutable history resources (ledgers, transactions and operations) instead of memory, for example redis://localhost:6379/0",
+		},
+		&support.ConfigOption{
+			Name:        "response-cache-size",
+			ConfigKey:   &config.ResponseCacheSize,
+			OptType:     types.Uint,
+			FlagDefault: uint(0),
+			Usage:       "the number of responses of immutable history resources (ledgers, transactions and operations) cached in memory, 0 disables the cache unless --redis-url is set",
+		},
+		&support.ConfigOption{
+			Name:           "response-cache-max-age",
+			ConfigKey:      &config.ResponseCacheMaxAge,
+			OptType:        types.Int,
+			FlagDefault:    3600,
+			CustomSetValue: support.SetDuration,
+			Usage:          "the number of seconds cached responses may be kept by clients and Redis",
 		},
 		&support.ConfigOption{
 			Name:           "friendbot-url",
@@ -312,6 +368,48 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: "HORIZON",
 			Usage:       "ingestor cursor used by aurora to ingest from hcnet core. must be uppercase and unique for each aurora instance ingesting from that core instance.",
 		},
+		&support.ConfigOption{
+			Name:        "disable-history-processors",
+			ConfigKey:   &config.History.DisabledProcessors,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				processors, err := ingest.ParseHistoryProcessors(viper.GetString(co.Name))
+				if err != nil {
+					stdLog.Fatalf("Invalid config: %s", err)
+				}
+				*(co.ConfigKey.(*[]ingest.HistoryProcessor)) = processors
+			},
+			Usage: "comma-separated list of history processors not run during ingestion (effects, operations, participants, trades, transactions), the matching endpoints respond with a not_ingested error",
+		},
+		&support.ConfigOption{
+			Name:        "history-accounts",
+			ConfigKey:   &config.History.Accounts,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				var accounts []string
+				if value := viper.GetString(co.Name); value != "" {
+					accounts = strings.Split(value, ",")
+				}
+				*(co.ConfigKey.(*[]string)) = accounts
+			},
+			Usage: "comma-separated list of accounts, when set only the history of transactions with one of these accounts as participant (or involving one of --history-assets) is stored",
+		},
+		&support.ConfigOption{
+			Name:        "history-assets",
+			ConfigKey:   &config.History.Assets,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				assets, err := xdr.BuildAssets(viper.GetString(co.Name))
+				if err != nil {
+					stdLog.Fatalf("Invalid config: %s", err)
+				}
+				*(co.ConfigKey.(*[]xdr.Asset)) = assets
+			},
+			Usage: "comma-separated list of assets (CODE:ISSUER or native), when set only the history of transactions involving one of these assets (or with one of --history-accounts as participant) is stored",
+		},
 		&support.ConfigOption{
 			Name:        "history-retention-count",
 			ConfigKey:   &config.HistoryRetentionCount,
@@ -319,6 +417,66 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: uint(0),
 			Usage:       "the minimum number of ledgers to maintain within aurora's history tables.  0 signifies an unlimited number of ledgers will be retained",
 		},
+		&support.ConfigOption{
+			Name:        "history-retention-counts",
+			ConfigKey:   &config.HistoryTableRetentionCounts,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				counts, err := reap.ParseRetentionCounts(viper.GetString(co.Name))
+				if err != nil {
+					stdLog.Fatalf("Invalid config: %s", err)
+				}
+				*(co.ConfigKey.(*map[reap.Table]uint)) = counts
+			},
+			Usage: "comma-separated list of table=count pairs overriding --history-retention-count for some history tables (ledgers, transactions, operations, effects, trades, participants), for example effects=518400,ledgers=0",
+		},
+		&support.ConfigOption{
+			Name:        "history-retention-batch-size",
+			ConfigKey:   &config.HistoryRetentionBatchSize,
+			OptType:     types.Uint,
+			FlagDefault: uint(1000),
+			Usage:       "the maximum number of ledgers removed from a history table by a single statement of the reaper. 0 removes all unretained ledgers at once",
+		},
+		&support.ConfigOption{
+			Name:        "history-retention-batch-pause",
+			ConfigKey:   &config.HistoryRetentionBatchPause,
+			OptType:     types.Int,
+			FlagDefault: 0,
+			CustomSetValue: func(co *support.ConfigOption) {
+				*(co.ConfigKey.(*time.Duration)) = time.Duration(viper.GetInt(co.Name)) * time.Millisecond
+			},
+			Usage: "the time (in milliseconds) the reaper waits between two batches",
+		},
+		&support.ConfigOption{
+			Name:        "history-retention-dry-run",
+			ConfigKey:   &config.HistoryRetentionDryRun,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage:       "causes the reaper to log the history it would remove instead of removing it",
+		},
+		&support.ConfigOption{
+			Name:      "ro-database-url",
+			EnvVar:    "RO_DATABASE_URL",
+			ConfigKey: &config.RODatabaseURL,
+			OptType:   types.String,
+			Required:  false,
+			Usage:     "read replica of the aurora postgres database, serving the read queries of API requests when set",
+		},
+		&support.ConfigOption{
+			Name:        "replica-lag-threshold",
+			ConfigKey:   &config.ReplicaLagThreshold,
+			OptType:     types.Uint,
+			FlagDefault: uint(10),
+			Usage:       "the maximum number of ledgers the read replica is allowed to be behind the aurora db before requests are served by the aurora db",
+		},
+		&support.ConfigOption{
+			Name:        "reject-lagging-replica",
+			ConfigKey:   &config.RejectLaggingReplica,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage:       "respond with 503 Service Unavailable instead of querying the aurora db when the read replica is lagging",
+		},
 		&support.ConfigOption{
 			Name:        "history-stale-threshold",
 			ConfigKey:   &config.StaleThreshold,
@@ -326,6 +484,34 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: uint(0),
 			Usage:       "the maximum number of ledgers the history db is allowed to be out of date from the connected hcnet-core db before aurora considers history stale",
 		},
+		&support.ConfigOption{
+			Name:        "statement-timeouts",
+			ConfigKey:   &config.StatementTimeouts,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				timeouts, err := httpx.ParseStatementTimeouts(viper.GetString(co.Name))
+				if err != nil {
+					stdLog.Fatalf("Invalid config: %s", err)
+				}
+				*(co.ConfigKey.(*map[string]time.Duration)) = timeouts
+			},
+			Usage: "comma-separated list of route=duration pairs setting the statement timeout of the db queries of history requests, keyed by the route reported in request logs or * for all other routes, for example /effects=5s,*=30s",
+		},
+		&support.ConfigOption{
+			Name:        "max-query-costs",
+			ConfigKey:   &config.MaxQueryCosts,
+			OptType:     types.String,
+			FlagDefault: "",
+			CustomSetValue: func(co *support.ConfigOption) {
+				costs, err := httpx.ParseMaxQueryCosts(viper.GetString(co.Name))
+				if err != nil {
+					stdLog.Fatalf("Invalid config: %s", err)
+				}
+				*(co.ConfigKey.(*map[string]float64)) = costs
+			},
+			Usage: "comma-separated list of route=cost pairs setting the maximum cost, as estimated by EXPLAIN, of the db queries of history requests, keyed by the route reported in request logs or * for all other routes, for example /effects=100000",
+		},
 		&support.ConfigOption{
 			Name:        "skip-cursor-update",
 			ConfigKey:   &config.SkipCursorUpdate,
@@ -340,6 +526,20 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: false,
 			Usage:       "ingestion system runs a verification routing to compare state in local database with history buckets, this can be disabled however it's not recommended",
 		},
+		&support.ConfigOption{
+			Name:        "history-archive-prefetch-dir",
+			ConfigKey:   &config.HistoryArchivePrefetchDir,
+			OptType:     types.String,
+			FlagDefault: "",
+			Usage:       "local directory to download history archive buckets to in parallel before building state, files are kept in its history-archive-prefetch subdirectory, buckets are streamed from the archive when empty",
+		},
+		&support.ConfigOption{
+			Name:        "history-archive-prefetch-concurrency",
+			ConfigKey:   &config.HistoryArchivePrefetchConcurrency,
+			OptType:     types.Uint,
+			FlagDefault: uint(8),
+			Usage:       "number of parallel bucket downloads when history-archive-prefetch-dir is set",
+		},
 		&support.ConfigOption{
 			Name:        "apply-migrations",
 			ConfigKey:   &config.ApplyMigrations,
@@ -348,6 +548,13 @@ func Flags() (*Config, support.ConfigOptions) {
 			Required:    false,
 			Usage:       "applies pending migrations before starting aurora",
 		},
+		&support.ConfigOption{
+			Name:        "lite",
+			ConfigKey:   &config.Lite,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage:       "runs aurora on the SQLite database file at --db-url, storing and serving only accounts, offers, trust lines, ledgers and transactions, requires captive core when ingesting",
+		},
 	}
 
 	return config, flags
@@ -376,16 +583,29 @@ func ApplyFlags(config *Config, flags support.ConfigOptions) {
 	flags.Require()
 	flags.SetValues()
 
-	if config.ApplyMigrations {
-		applyMigrations(*config)
-	}
+	if config.Lite {
+		applyLiteConfig(config)
+	} else {
+		if config.ApplyMigrations {
+			applyMigrations(*config)
+		}
 
-	// Migrations should be checked as early as possible
-	checkMigrations(*config)
+		// Migrations should be checked as early as possible
+		checkMigrations(*config)
+	}
 
 	// Validate options that should be provided together
 	validateBothOrNeither("tls-cert", "tls-key")
 
+	if err := config.History.Validate(); err != nil {
+		stdLog.Fatalf("Invalid config: %s", err)
+	}
+
+	err := reap.ValidateRetentionCounts(config.HistoryRetentionCount, config.HistoryTableRetentionCounts)
+	if err != nil {
+		stdLog.Fatalf("Invalid config: %s", err)
+	}
+
 	// config.HistoryArchiveURLs contains a single empty value when empty so using
 	// viper.GetString is easier.
 	if config.Ingest && viper.GetString("history-archive-urls") == "" {
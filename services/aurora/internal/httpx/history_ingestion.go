@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/ingest"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+// NewHistoryIngestedMiddleware responds with a not_ingested problem when one
+// of the history processors storing the data of the request is disabled.
+func NewHistoryIngestedMiddleware(history ingest.HistoryConfig, processors ...ingest.HistoryProcessor) func(http.Handler) http.Handler {
+	var disabled []ingest.HistoryProcessor
+	for _, processor := range processors {
+		if !history.Enabled(processor) {
+			disabled = append(disabled, processor)
+		}
+	}
+
+	return func(h http.Handler) http.Handler {
+		if len(disabled) == 0 {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := hProblem.NotIngested
+			err.Extras = map[string]interface{}{
+				"disabled_processors": disabled,
+			}
+			problem.Render(r.Context(), w, err)
+		})
+	}
+}
+
+func historyIngestionResource(history ingest.HistoryConfig) aurora.HistoryIngestion {
+	resource := aurora.HistoryIngestion{
+		Processors: []string{"ledgers"},
+		Accounts:   history.Accounts,
+	}
+	for _, processor := range history.EnabledProcessors() {
+		resource.Processors = append(resource.Processors, string(processor))
+	}
+	for _, asset := range history.Assets {
+		resource.Assets = append(resource.Assets, asset.StringCanonical())
+	}
+	return resource
+}
@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/ingest"
+	"github.com/hcnet/go/xdr"
+)
+
+func TestHistoryIngestedMiddleware(t *testing.T) {
+	history := ingest.HistoryConfig{
+		DisabledProcessors: []ingest.HistoryProcessor{ingest.EffectsProcessor, ingest.TradesProcessor},
+	}
+	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	NewHistoryIngestedMiddleware(history, ingest.OperationsProcessor)(endpoint).
+		ServeHTTP(w, httptest.NewRequest("GET", "/operations", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	NewHistoryIngestedMiddleware(history, ingest.OperationsProcessor, ingest.EffectsProcessor)(endpoint).
+		ServeHTTP(w, httptest.NewRequest("GET", "/operations/1/effects", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "https://hcnet.org/aurora-errors/not_ingested", body["type"])
+	assert.Equal(
+		t,
+		map[string]interface{}{"disabled_processors": []interface{}{"effects"}},
+		body["extras"],
+	)
+}
+
+func TestHistoryIngestionResource(t *testing.T) {
+	assert.Equal(
+		t,
+		aurora.HistoryIngestion{
+			Processors: []string{"ledgers", "effects", "operations", "participants", "trades", "transactions"},
+		},
+		historyIngestionResource(ingest.HistoryConfig{}),
+	)
+
+	issuer := "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	assert.Equal(
+		t,
+		aurora.HistoryIngestion{
+			Processors: []string{"ledgers", "operations", "participants", "transactions"},
+			Accounts:   []string{issuer},
+			Assets:     []string{"native", "USD:" + issuer},
+		},
+		historyIngestionResource(ingest.HistoryConfig{
+			DisabledProcessors: []ingest.HistoryProcessor{ingest.EffectsProcessor, ingest.TradesProcessor},
+			Accounts:           []string{issuer},
+			Assets:             []xdr.Asset{xdr.MustNewNativeAsset(), xdr.MustNewCreditAsset("USD", issuer)},
+		}),
+	)
+}
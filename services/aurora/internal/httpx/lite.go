@@ -0,0 +1,23 @@
+package httpx
+
+import (
+	"net/http"
+
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+// NewLiteModeMiddleware responds with a lite_mode problem when lite is true.
+// It's used on the endpoints whose data, like asset stats or claimable
+// balances, is not stored by lite mode databases.
+func NewLiteModeMiddleware(lite bool) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if !lite {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			problem.Render(r.Context(), w, hProblem.LiteMode)
+		})
+	}
+}
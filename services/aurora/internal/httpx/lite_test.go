@@ -0,0 +1,30 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteModeMiddleware(t *testing.T) {
+	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	NewLiteModeMiddleware(false)(endpoint).
+		ServeHTTP(w, httptest.NewRequest("GET", "/assets", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	NewLiteModeMiddleware(true)(endpoint).
+		ServeHTTP(w, httptest.NewRequest("GET", "/assets", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "https://hcnet.org/aurora-errors/lite_mode", body["type"])
+}
@@ -200,12 +200,16 @@ func firstXForwardedFor(r *http.Request) string {
 
 // recoverMiddleware helps the server recover from panics. It ensures that
 // no request can fully bring down the aurora server, and it also logs the
-// panics to the logging subsystem.
+// panics to the logging subsystem. http.ErrAbortHandler panics are propagated
+// so the server aborts the response of handlers which can't render an error.
 func recoverMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		defer func() {
 			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
 				err := errors.FromPanic(rec)
 				errors.ReportToSentry(err, r)
 				problem.Render(ctx, w, err)
@@ -218,8 +222,10 @@ func recoverMiddleware(h http.Handler) http.Handler {
 
 // NewHistoryMiddleware adds session to the request context and ensures Aurora
 // is not in a stale state, which is when the difference between latest core
-// ledger and latest history ledger is higher than the given threshold
-func NewHistoryMiddleware(staleThreshold int32, session *db.Session) func(http.Handler) http.Handler {
+// ledger and latest history ledger is higher than the given threshold. When
+// replica is not nil, the replica session is added instead unless it's
+// lagging.
+func NewHistoryMiddleware(staleThreshold int32, session *db.Session, replica *ReplicaSession) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -237,7 +243,11 @@ func NewHistoryMiddleware(staleThreshold int32, session *db.Session) func(http.H
 				}
 			}
 
-			requestSession := session.Clone()
+			requestSession, err := replica.readSession(session)
+			if err != nil {
+				problem.Render(r.Context(), w, err)
+				return
+			}
 			requestSession.Ctx = r.Context()
 			h.ServeHTTP(w, r.WithContext(
 				context.WithValue(
@@ -258,6 +268,8 @@ func NewHistoryMiddleware(staleThreshold int32, session *db.Session) func(http.H
 type StateMiddleware struct {
 	AuroraSession      *db.Session
 	NoStateVerification bool
+	// Replica, when not nil, serves the requests unless it's lagging.
+	Replica *ReplicaSession
 }
 
 func ingestionStatus(q *history.Q) (uint32, bool, error) {
@@ -291,7 +303,11 @@ func ingestionStatus(q *history.Q) (uint32, bool, error) {
 // WrapFunc executes the middleware on a given HTTP handler function
 func (m *StateMiddleware) WrapFunc(h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		session := m.AuroraSession.Clone()
+		session, err := m.Replica.readSession(m.AuroraSession)
+		if err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
 		q := &history.Q{session}
 		sseRequest := render.Negotiate(r) == render.MimeEventStream
 
@@ -301,7 +317,7 @@ func (m *StateMiddleware) WrapFunc(h http.HandlerFunc) http.HandlerFunc {
 		// it is possible to have one read fetch data from ledger N and another read
 		// fetch data from ledger N+1 .
 		session.Ctx = r.Context()
-		err := session.BeginTx(&sql.TxOptions{
+		err = session.BeginTx(&sql.TxOptions{
 			Isolation: sql.LevelRepeatableRead,
 			ReadOnly:  true,
 		})
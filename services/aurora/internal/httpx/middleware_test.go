@@ -0,0 +1,29 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	request := httptest.NewRequest("GET", "/assets/USD/holders.csv", nil)
+
+	w := httptest.NewRecorder()
+	recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})).ServeHTTP(w, request)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Aborted responses are left to the server, which closes the connection.
+	w = httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		recoverMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("account_id\n"))
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(w, request)
+	})
+	assert.Equal(t, "account_id\n", w.Body.String())
+}
@@ -0,0 +1,174 @@
+package httpx
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/render"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+// defaultQueryLimitsRoute is the key of the limits applied to the routes
+// without limits of their own.
+const defaultQueryLimitsRoute = "*"
+
+// QueryLimits are the limits of the database queries of history requests,
+// keyed by route pattern, as reported in the route field of request logs (for
+// example /accounts/{account_id:\w+}/effects), or by "*" for the limits of
+// all other routes.
+type QueryLimits struct {
+	// StatementTimeouts are set with SET LOCAL statement_timeout in a read-only
+	// transaction wrapping the request. They are not applied to streaming
+	// requests, which use a transaction per event.
+	StatementTimeouts map[string]time.Duration
+	// MaxQueryCosts are the maximum total costs of queries, as estimated by
+	// EXPLAIN, above which queries are rejected without being run.
+	MaxQueryCosts map[string]float64
+}
+
+func (l QueryLimits) statementTimeout(route string) time.Duration {
+	if timeout, ok := l.StatementTimeouts[route]; ok {
+		return timeout
+	}
+	return l.StatementTimeouts[defaultQueryLimitsRoute]
+}
+
+func (l QueryLimits) maxQueryCost(route string) float64 {
+	if cost, ok := l.MaxQueryCosts[route]; ok {
+		return cost
+	}
+	return l.MaxQueryCosts[defaultQueryLimitsRoute]
+}
+
+func (l QueryLimits) empty() bool {
+	return len(l.StatementTimeouts) == 0 && len(l.MaxQueryCosts) == 0
+}
+
+// ParseStatementTimeouts parses a comma-separated list of route=duration
+// pairs, for example "/effects=5s,*=30s".
+func ParseStatementTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	err := parseRouteLimits(s, func(route, value string) error {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return errors.Errorf("invalid statement timeout %q for route %s", value, route)
+		}
+		timeouts[route] = timeout
+		return nil
+	})
+	return timeouts, err
+}
+
+// ParseMaxQueryCosts parses a comma-separated list of route=cost pairs, for
+// example "/effects=100000,*=1000000".
+func ParseMaxQueryCosts(s string) (map[string]float64, error) {
+	costs := map[string]float64{}
+	err := parseRouteLimits(s, func(route, value string) error {
+		cost, err := strconv.ParseFloat(value, 64)
+		if err != nil || cost < 0 {
+			return errors.Errorf("invalid maximum query cost %q for route %s", value, route)
+		}
+		costs[route] = cost
+		return nil
+	})
+	return costs, err
+}
+
+func parseRouteLimits(s string, set func(route, value string) error) error {
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return errors.Errorf("invalid route limit %q, expected route=value", pair)
+		}
+		if err := set(strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewQueryLimitsMiddleware applies the query limits of the route of the
+// request to the session added to the request context by the history
+// middleware, so it must be used after it. The route is resolved on mux
+// because the route pattern is incomplete until the request reaches its
+// handler.
+func NewQueryLimitsMiddleware(mux *chi.Mux, limits QueryLimits) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if limits.empty() {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, ok := r.Context().Value(&auroraContext.SessionContextKey).(*db.Session)
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			route := routePattern(mux, r)
+			if route == "" {
+				route = "undefined"
+			}
+			session.MaxQueryCost = limits.maxQueryCost(route)
+			// The route field is used to label the query limit metrics.
+			ctx := log.Set(r.Context(), log.Ctx(r.Context()).WithField("route", route))
+			session.Ctx = ctx
+
+			timeout := limits.statementTimeout(route)
+			streaming := strings.Contains(r.Header.Get("Accept"), render.MimeEventStream)
+			if timeout > 0 && !streaming {
+				if err := beginWithStatementTimeout(session, timeout); err != nil {
+					problem.Render(ctx, w, err)
+					return
+				}
+				defer session.Rollback()
+			}
+
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func beginWithStatementTimeout(session *db.Session, timeout time.Duration) error {
+	if err := session.BeginTx(&sql.TxOptions{ReadOnly: true}); err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	if err := session.SetStatementTimeout(timeout); err != nil {
+		session.Rollback()
+		return errors.Wrap(err, "could not set statement timeout")
+	}
+	return nil
+}
+
+// routePattern returns the pattern of the route of r on mux, or an empty
+// string when r matches no route.
+func routePattern(mux *chi.Mux, r *http.Request) string {
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	// StripSlashes is only applied to the routing context of the request.
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	rctx := chi.NewRouteContext()
+	if !mux.Match(rctx, r.Method, path) {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/support/db"
+)
+
+func TestParseStatementTimeouts(t *testing.T) {
+	timeouts, err := ParseStatementTimeouts("")
+	assert.NoError(t, err)
+	assert.Empty(t, timeouts)
+
+	timeouts, err = ParseStatementTimeouts(" /effects=5s, /accounts/{account_id:\\w+}/transactions=1m,*=30s ")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"/effects": 5 * time.Second,
+		"/accounts/{account_id:\\w+}/transactions": time.Minute,
+		"*": 30 * time.Second,
+	}, timeouts)
+
+	_, err = ParseStatementTimeouts("/effects=5")
+	assert.EqualError(t, err, `invalid statement timeout "5" for route /effects`)
+	_, err = ParseStatementTimeouts("/effects")
+	assert.EqualError(t, err, `invalid route limit "/effects", expected route=value`)
+}
+
+func TestParseMaxQueryCosts(t *testing.T) {
+	costs, err := ParseMaxQueryCosts("/effects=100000,*=1e6")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		"/effects": 100000,
+		"*":        1000000,
+	}, costs)
+
+	_, err = ParseMaxQueryCosts("/effects=-1")
+	assert.EqualError(t, err, `invalid maximum query cost "-1" for route /effects`)
+	_, err = ParseMaxQueryCosts("=1")
+	assert.EqualError(t, err, `invalid route limit "=1", expected route=value`)
+}
+
+func TestRoutePattern(t *testing.T) {
+	mux := chi.NewMux()
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux.Group(func(r chi.Router) {
+		r.Get("/effects", noop)
+		r.Get("/accounts/{account_id:\\w+}/effects", noop)
+	})
+	mux.Route("/ledgers", func(r chi.Router) {
+		r.Get("/", noop)
+		r.Route("/{ledger_id}", func(r chi.Router) {
+			r.Get("/effects", noop)
+		})
+	})
+
+	for path, expected := range map[string]string{
+		"/effects":             "/effects",
+		"/accounts/GA/effects": "/accounts/{account_id:\\w+}/effects",
+		"/ledgers":             "/ledgers",
+		"/ledgers/":            "/ledgers",
+		"/ledgers/1/effects":   "/ledgers/{ledger_id}/effects",
+		"/ledgers/1/trades":    "",
+		"/unknown":             "",
+	} {
+		assert.Equal(t, expected, routePattern(mux, httptest.NewRequest("GET", path, nil)), path)
+	}
+}
+
+func TestQueryLimitsMiddleware(t *testing.T) {
+	mux := chi.NewMux()
+	limits := QueryLimits{
+		MaxQueryCosts: map[string]float64{
+			"/effects": 100,
+			"*":        1000,
+		},
+	}
+
+	var session *db.Session
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session = r.Context().Value(&auroraContext.SessionContextKey).(*db.Session)
+	})
+	mux.With(NewQueryLimitsMiddleware(mux, limits)).Get("/effects", handler)
+	mux.With(NewQueryLimitsMiddleware(mux, limits)).Get("/trades", handler)
+
+	for path, expected := range map[string]float64{
+		"/effects": 100,
+		"/trades":  1000,
+	} {
+		r := httptest.NewRequest("GET", path, nil)
+		r = r.WithContext(context.WithValue(
+			r.Context(),
+			&auroraContext.SessionContextKey,
+			&db.Session{DB: &sqlx.DB{}},
+		))
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+		assert.Equal(t, expected, session.MaxQueryCost, path)
+	}
+}
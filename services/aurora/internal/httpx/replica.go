@@ -0,0 +1,44 @@
+package httpx
+
+import (
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	hProblem "github.com/hcnet/go/services/aurora/internal/render/problem"
+	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/log"
+)
+
+// ReplicaSession routes the read queries of API requests to a read replica of
+// the aurora database while ingestion keeps writing to the primary.
+type ReplicaSession struct {
+	Session *db.Session
+	// LagThreshold is the maximum number of ledgers the replica may be
+	// behind the primary, as reported by ledger.CurrentState, before requests
+	// stop being served by the replica.
+	LagThreshold uint32
+	// RejectLagging makes requests fail with a replica_lagging problem when
+	// the replica lags, instead of being served by the primary.
+	RejectLagging bool
+}
+
+// readSession returns a clone of the session which should serve the read
+// queries of a request: the replica when configured and up to date, primary
+// otherwise. It returns an error when the replica lags and RejectLagging is
+// set.
+func (r *ReplicaSession) readSession(primary *db.Session) (*db.Session, error) {
+	if r == nil || r.Session == nil {
+		return primary.Clone(), nil
+	}
+
+	if lag := ledger.CurrentState().ReplicaLag(); lag > r.LagThreshold {
+		if r.RejectLagging {
+			err := hProblem.ReplicaLagging
+			err.Extras = map[string]interface{}{
+				"replica_lag": lag,
+			}
+			return nil, err
+		}
+		log.WithField("replica_lag", lag).Debug("Replica is lagging, using primary session")
+		return primary.Clone(), nil
+	}
+	return r.Session.Clone(), nil
+}
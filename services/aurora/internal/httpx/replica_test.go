@@ -0,0 +1,93 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/ledger"
+	"github.com/hcnet/go/support/db"
+)
+
+func TestHistoryMiddlewareReplica(t *testing.T) {
+	primary := &db.Session{DB: &sqlx.DB{}}
+	replicaSession := &db.Session{DB: &sqlx.DB{}}
+	defer ledger.SetState(ledger.State{})
+
+	for _, testCase := range []struct {
+		name            string
+		replica         *ReplicaSession
+		primaryLatest   uint32
+		replicaLatest   uint32
+		expectedStatus  int
+		expectedSession *db.Session
+	}{
+		{
+			name:            "no replica",
+			replica:         nil,
+			primaryLatest:   10,
+			expectedStatus:  http.StatusOK,
+			expectedSession: primary,
+		},
+		{
+			name:            "replica up to date",
+			replica:         &ReplicaSession{Session: replicaSession, LagThreshold: 2},
+			primaryLatest:   10,
+			replicaLatest:   10,
+			expectedStatus:  http.StatusOK,
+			expectedSession: replicaSession,
+		},
+		{
+			name:            "replica lagging within threshold",
+			replica:         &ReplicaSession{Session: replicaSession, LagThreshold: 2},
+			primaryLatest:   10,
+			replicaLatest:   8,
+			expectedStatus:  http.StatusOK,
+			expectedSession: replicaSession,
+		},
+		{
+			name:            "replica lagging falls back to primary",
+			replica:         &ReplicaSession{Session: replicaSession, LagThreshold: 2},
+			primaryLatest:   10,
+			replicaLatest:   7,
+			expectedStatus:  http.StatusOK,
+			expectedSession: primary,
+		},
+		{
+			name:           "replica lagging is rejected",
+			replica:        &ReplicaSession{Session: replicaSession, LagThreshold: 2, RejectLagging: true},
+			primaryLatest:  10,
+			replicaLatest:  7,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			ledger.SetState(ledger.State{
+				ExpHistoryLatest:        testCase.primaryLatest,
+				ReplicaExpHistoryLatest: testCase.replicaLatest,
+			})
+
+			var session *db.Session
+			handler := NewHistoryMiddleware(0, primary, testCase.replica)(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					session = r.Context().Value(&auroraContext.SessionContextKey).(*db.Session)
+				},
+			))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/ledgers", nil))
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			if testCase.expectedSession == nil {
+				assert.Nil(t, session)
+				return
+			}
+			assert.Same(t, testCase.expectedSession.DB, session.DB)
+			// Every request gets its own session.
+			assert.NotSame(t, testCase.expectedSession, session)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcnet/go/services/aurora/internal/cache"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/reap"
+	"github.com/hcnet/go/services/aurora/internal/render"
+	"github.com/hcnet/go/services/aurora/internal/toid"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/log"
+)
+
+// ResponseCache caches the responses of history resources which never change
+// once ingested.
+type ResponseCache struct {
+	Store cache.Store
+	// MaxAge is the max-age of the Cache-Control header of cached
+	// responses.
+	MaxAge time.Duration
+}
+
+// middleware returns a middleware serving the responses of resources loaded
+// from table from the cache. Responses are cached when successful. The ledger
+// of a resource is read from its paging token, which is a TOID for ledgers,
+// transactions and operations.
+//
+// It must be used before the middlewares querying the database, so cached
+// responses are served without them.
+func (c *ResponseCache) middleware(table reap.Table) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if c == nil || c.Store == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch render.Negotiate(r) {
+			case render.MimeHal, render.MimeJSON:
+			default:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			key := responseCacheKey(r)
+			entry, ok, err := c.Store.Get(key)
+			if err != nil {
+				// The request is still served when the cache is unavailable.
+				log.Ctx(r.Context()).WithField("key", key).Warnf("Could not get cached response: %s", err)
+			}
+			if ok {
+				c.write(w, r, entry)
+				return
+			}
+
+			recorder := newResponseRecorder()
+			h.ServeHTTP(recorder, r)
+
+			if recorder.status != http.StatusOK {
+				recorder.writeTo(w)
+				return
+			}
+
+			entry = cache.Entry{
+				Table:       table,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			}
+			if entry.Ledger, err = resourceLedger(entry.Body); err != nil {
+				log.Ctx(r.Context()).WithField("key", key).Warnf("Could not cache response: %s", err)
+				recorder.writeTo(w)
+				return
+			}
+			if err = c.Store.Set(key, entry); err != nil {
+				log.Ctx(r.Context()).WithField("key", key).Warnf("Could not cache response: %s", err)
+			}
+			recorder.copyHeader(w)
+			c.write(w, r, entry)
+		})
+	}
+}
+
+// write writes a cached response, or 304 Not Modified if the client already
+// has it.
+func (c *ResponseCache) write(w http.ResponseWriter, r *http.Request, entry cache.Entry) {
+	etag := entry.ETag()
+	w.Header().Set("ETag", etag)
+	w.Header().Set(
+		"Cache-Control",
+		fmt.Sprintf("public, max-age=%d", int64(c.MaxAge/time.Second)),
+	)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Content-Type", entry.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.Body)
+}
+
+// responseCacheKey returns the cache key of r: its base URL, path and query.
+// The base URL is part of the key because responses embed absolute links
+// built from it.
+func responseCacheKey(r *http.Request) string {
+	path := r.URL.Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	key := auroraContext.RequestBaseURL(r).String() + path
+	if r.URL.RawQuery == "" {
+		return key
+	}
+	return key + "?" + r.URL.RawQuery
+}
+
+// etagMatches returns true if the If-None-Match header ifNoneMatch contains
+// etag. Weak comparison is used, as required for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceLedger returns the ledger of a rendered resource from its paging
+// token.
+func resourceLedger(body []byte) (uint32, error) {
+	var resource struct {
+		PagingToken string `json:"paging_token"`
+	}
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return 0, errors.Wrap(err, "could not decode resource")
+	}
+
+	id, err := strconv.ParseInt(resource.PagingToken, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid paging token %q", resource.PagingToken)
+	}
+	return uint32(toid.Parse(id).LedgerSequence), nil
+}
+
+// responseRecorder buffers a response so it can be cached before it is
+// written.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) copyHeader(w http.ResponseWriter) {
+	for key, values := range r.header {
+		w.Header()[key] = values
+	}
+}
+
+// writeTo writes the recorded response to w.
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	r.copyHeader(w)
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+	w.Write(r.body.Bytes())
+}
@@ -0,0 +1,119 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/services/aurora/internal/cache"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/reap"
+	"github.com/hcnet/go/services/aurora/internal/toid"
+	"github.com/hcnet/go/support/render/httpjson"
+	"github.com/hcnet/go/support/render/problem"
+)
+
+func TestResponseCacheMiddleware(t *testing.T) {
+	store, err := cache.NewLRUStore(10)
+	require.NoError(t, err)
+	responseCache := &ResponseCache{Store: store, MaxAge: time.Hour}
+
+	calls := 0
+	handler := responseCache.middleware(reap.TransactionsTable)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Path == "/transactions/missing" {
+				problem.Render(r.Context(), w, problem.NotFound)
+				return
+			}
+			httpjson.Render(w, map[string]string{
+				"hash":         r.URL.Path,
+				"self":         auroraContext.RequestBaseURL(r).String() + r.URL.Path,
+				"paging_token": toid.New(7, 1, 0).String(),
+			}, httpjson.HALJSON)
+		},
+	))
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+		if host := header.Get("Host"); host != "" {
+			r.Host = host
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/transactions/abc", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "application/hal+json; charset=utf-8", w.Header().Get("Content-Type"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	body := w.Body.String()
+	assert.Contains(t, body, `"hash": "/transactions/abc"`)
+
+	entry, ok, err := store.Get("http://example.com/transactions/abc")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, reap.TransactionsTable, entry.Table)
+	assert.Equal(t, uint32(7), entry.Ledger)
+
+	// cached responses don't reach the handler
+	w = serve("/transactions/abc/", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, body, w.Body.String())
+
+	w = serve("/transactions/abc", http.Header{"If-None-Match": {`"other", W/` + etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// streams and errors are not cached
+	w = serve("/transactions/abc", http.Header{"Accept": {"text/event-stream"}})
+	assert.Equal(t, 2, calls)
+	for i := 0; i < 2; i++ {
+		w = serve("/transactions/missing", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+	}
+	assert.Equal(t, 4, calls)
+
+	// responses embed links to the requested host and scheme, which are
+	// cached separately
+	w = serve("/transactions/abc", http.Header{"Host": {"aurora.example.org"}})
+	assert.Equal(t, 5, calls)
+	assert.Contains(t, w.Body.String(), `"self": "http://aurora.example.org/transactions/abc"`)
+	w = serve("/transactions/abc", http.Header{"Host": {"aurora.example.org"}, "X-Forwarded-Proto": {"https"}})
+	assert.Equal(t, 6, calls)
+	assert.Contains(t, w.Body.String(), `"self": "https://aurora.example.org/transactions/abc"`)
+	w = serve("/transactions/abc", http.Header{"Host": {"aurora.example.org"}})
+	assert.Equal(t, 6, calls)
+	assert.Contains(t, w.Body.String(), `"self": "http://aurora.example.org/transactions/abc"`)
+	w = serve("/transactions/abc", nil)
+	assert.Equal(t, 6, calls)
+	assert.Contains(t, w.Body.String(), `"self": "http://example.com/transactions/abc"`)
+
+	// entries of reaped ledgers are invalidated
+	require.NoError(t, store.DeleteBefore(reap.TransactionsTable, 8))
+	serve("/transactions/abc", nil)
+	assert.Equal(t, 7, calls)
+}
+
+func TestResourceLedger(t *testing.T) {
+	ledger, err := resourceLedger([]byte(`{"paging_token": "` + toid.New(123, 4, 5).String() + `"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(123), ledger)
+
+	_, err = resourceLedger([]byte(`{"id": "1"}`))
+	assert.EqualError(t, err, `invalid paging token ""`)
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/stellar/throttled"
 
 	"github.com/hcnet/go/services/aurora/internal/actions"
+	"github.com/hcnet/go/services/aurora/internal/ingest"
 	"github.com/hcnet/go/services/aurora/internal/paths"
+	"github.com/hcnet/go/services/aurora/internal/reap"
 	"github.com/hcnet/go/services/aurora/internal/render/sse"
 	"github.com/hcnet/go/services/aurora/internal/txsub"
 	"github.com/hcnet/go/support/db"
@@ -28,11 +30,15 @@ const maxAssetsForPathFinding = 15
 
 type RouterConfig struct {
 	DBSession   *db.Session
+	Replica     *ReplicaSession
+	History     ingest.HistoryConfig
 	TxSubmitter *txsub.System
 	RateQuota   *throttled.RateQuota
 
 	SSEUpdateFrequency time.Duration
 	StaleThreshold     uint
+	QueryLimits        QueryLimits
+	ResponseCache      *ResponseCache
 	ConnectionTimeout  time.Duration
 	NetworkPassphrase  string
 	MaxPathLength      uint
@@ -41,6 +47,9 @@ type RouterConfig struct {
 	CoreGetter         actions.CoreSettingsGetter
 	AuroraVersion     string
 	FriendbotURL       *url.URL
+	// Lite disables the endpoints whose data is not stored by lite mode
+	// databases.
+	Lite bool
 }
 
 type Router struct {
@@ -101,6 +110,7 @@ func (r *Router) addMiddleware(config *RouterConfig,
 func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter) {
 	stateMiddleware := StateMiddleware{
 		AuroraSession: config.DBSession,
+		Replica:       config.Replica,
 	}
 
 	r.Method(http.MethodGet, "/", ObjectActionHandler{Action: actions.GetRootHandler{
@@ -108,6 +118,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		NetworkPassphrase:  config.NetworkPassphrase,
 		FriendbotURL:       config.FriendbotURL,
 		AuroraVersion:     config.AuroraVersion,
+		HistoryIngestion:   historyIngestionResource(config.History),
 	}})
 
 	streamHandler := sse.StreamHandler{
@@ -115,7 +126,15 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		LedgerSourceFactory: historyLedgerSourceFactory{updateFrequency: config.SSEUpdateFrequency},
 	}
 
-	historyMiddleware := NewHistoryMiddleware(int32(config.StaleThreshold), config.DBSession)
+	sessionMiddleware := NewHistoryMiddleware(int32(config.StaleThreshold), config.DBSession, config.Replica)
+	queryLimitsMiddleware := NewQueryLimitsMiddleware(r.Mux, config.QueryLimits)
+	historyMiddleware := func(h http.Handler) http.Handler {
+		return sessionMiddleware(queryLimitsMiddleware(h))
+	}
+	ingested := func(processors ...ingest.HistoryProcessor) func(http.Handler) http.Handler {
+		return NewHistoryIngestedMiddleware(config.History, processors...)
+	}
+	notLite := NewLiteModeMiddleware(config.Lite)
 
 	// State endpoints behind stateMiddleware
 	r.Group(func(r chi.Router) {
@@ -142,6 +161,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		})
 
 		r.Route("/claimable_balances", func(r chi.Router) {
+			r.Use(notLite)
 			r.Method(http.MethodGet, "/", restPageHandler(actions.GetClaimableBalancesHandler{}))
 			r.Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetClaimableBalanceByIDHandler{}})
 		})
@@ -151,7 +171,8 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			r.Method(http.MethodGet, "/{offer_id}", ObjectActionHandler{actions.GetOfferByID{}})
 		})
 
-		r.Method(http.MethodGet, "/assets", restPageHandler(actions.AssetStatsHandler{}))
+		r.With(notLite).Method(http.MethodGet, "/assets", restPageHandler(actions.AssetStatsHandler{}))
+		r.Method(http.MethodGet, "/assets/{asset}/holders", restPageHandler(actions.GetAssetHoldersHandler{}))
 
 		findPaths := ObjectActionHandler{actions.FindPathsHandler{
 			StaleThreshold:       config.StaleThreshold,
@@ -171,7 +192,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.Method(http.MethodGet, "/paths/strict-receive", findPaths)
 		r.Method(http.MethodGet, "/paths/strict-send", findFixedPaths)
 
-		r.Method(
+		r.With(notLite).Method(
 			http.MethodGet,
 			"/order_book",
 			streamableObjectActionHandler{
@@ -186,29 +207,29 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	// emptiness. Without it, requesting `/accounts//payments` return all payments!
 	r.Group(func(r chi.Router) {
 		r.Use(historyMiddleware)
-		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
-		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
+		r.With(ingested(ingest.EffectsProcessor)).Method(http.MethodGet, "/accounts/{account_id:\\w+}/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+		r.With(ingested(ingest.OperationsProcessor, ingest.ParticipantsProcessor)).Method(http.MethodGet, "/accounts/{account_id:\\w+}/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
 			OnlyPayments: false,
 		}, streamHandler))
-		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
+		r.With(ingested(ingest.OperationsProcessor, ingest.ParticipantsProcessor)).Method(http.MethodGet, "/accounts/{account_id:\\w+}/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
 			OnlyPayments: true,
 		}, streamHandler))
-		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
-		r.Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
+		r.With(ingested(ingest.TradesProcessor)).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
+		r.With(ingested(ingest.TransactionsProcessor, ingest.ParticipantsProcessor)).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
 	})
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
-		r.Use(historyMiddleware)
-		r.Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetLedgersHandler{}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetLedgersHandler{}, streamHandler))
 		r.Route("/{ledger_id}", func(r chi.Router) {
-			r.Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLedgerByIDHandler{}})
-			r.Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
+			r.With(config.ResponseCache.middleware(reap.LedgersTable), historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLedgerByIDHandler{}})
+			r.With(historyMiddleware, ingested(ingest.TransactionsProcessor)).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
 			r.Group(func(r chi.Router) {
-				r.Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
-				r.Method(http.MethodGet, "/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
+				r.Use(historyMiddleware)
+				r.With(ingested(ingest.EffectsProcessor)).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+				r.With(ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
 					OnlyPayments: false,
 				}, streamHandler))
-				r.Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
+				r.With(ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
 					OnlyPayments: true,
 				}, streamHandler))
 			})
@@ -217,46 +238,52 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 	// transaction history actions
 	r.Route("/transactions", func(r chi.Router) {
-		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
+		r.With(historyMiddleware, ingested(ingest.TransactionsProcessor)).Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetTransactionsHandler{}, streamHandler))
 		r.Route("/{tx_id}", func(r chi.Router) {
-			r.Use(historyMiddleware)
-			r.Method(http.MethodGet, "/", ObjectActionHandler{actions.GetTransactionByHashHandler{}})
-			r.Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
-			r.Method(http.MethodGet, "/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
-				OnlyPayments: false,
-			}, streamHandler))
-			r.Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
-				OnlyPayments: true,
-			}, streamHandler))
+			r.With(config.ResponseCache.middleware(reap.TransactionsTable), historyMiddleware, ingested(ingest.TransactionsProcessor)).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetTransactionByHashHandler{}})
+			r.Group(func(r chi.Router) {
+				r.Use(historyMiddleware)
+				r.Use(ingested(ingest.TransactionsProcessor))
+				r.With(ingested(ingest.EffectsProcessor)).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+				r.With(ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(actions.GetOperationsHandler{
+					OnlyPayments: false,
+				}, streamHandler))
+				r.With(ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
+					OnlyPayments: true,
+				}, streamHandler))
+			})
 		})
 	})
 
 	// operation actions
 	r.Route("/operations", func(r chi.Router) {
-		r.Use(historyMiddleware)
-		r.Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetOperationsHandler{
-			OnlyPayments: false,
-		}, streamHandler))
-		r.Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetOperationByIDHandler{}})
-		r.Method(http.MethodGet, "/{op_id}/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+		r.With(config.ResponseCache.middleware(reap.OperationsTable), historyMiddleware, ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetOperationByIDHandler{}})
+		r.Group(func(r chi.Router) {
+			r.Use(historyMiddleware)
+			r.Use(ingested(ingest.OperationsProcessor))
+			r.Method(http.MethodGet, "/", streamableHistoryPageHandler(actions.GetOperationsHandler{
+				OnlyPayments: false,
+			}, streamHandler))
+			r.With(ingested(ingest.EffectsProcessor)).Method(http.MethodGet, "/{op_id}/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+		})
 	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(historyMiddleware)
 		// payment actions
-		r.Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
+		r.With(ingested(ingest.OperationsProcessor)).Method(http.MethodGet, "/payments", streamableHistoryPageHandler(actions.GetOperationsHandler{
 			OnlyPayments: true,
 		}, streamHandler))
 
 		// effect actions
-		r.Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
+		r.With(ingested(ingest.EffectsProcessor)).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(actions.GetEffectsHandler{}, streamHandler))
 
 		// trading related endpoints
-		r.Method(http.MethodGet, "/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
-		r.Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{actions.GetTradeAggregationsHandler{}})
+		r.With(ingested(ingest.TradesProcessor)).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
+		r.With(ingested(ingest.TradesProcessor)).Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{actions.GetTradeAggregationsHandler{}})
 		// /offers/{offer_id} has been created above so we need to use absolute
 		// routes here.
-		r.Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
+		r.With(ingested(ingest.TradesProcessor)).Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(actions.GetTradesHandler{}, streamHandler))
 	})
 
 	// Transaction submission API
@@ -266,7 +293,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	}})
 
 	// Network state related endpoints
-	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
+	r.With(notLite).Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
 
 	// friendbot
 	if config.FriendbotURL != nil {
@@ -286,4 +313,5 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	r.Internal.Get("/metrics", promhttp.HandlerFor(config.PrometheusRegistry, promhttp.HandlerOpts{}).ServeHTTP)
 	r.Internal.Get("/debug/pprof/heap", pprof.Index)
 	r.Internal.Get("/debug/pprof/profile", pprof.Profile)
+	r.Internal.With(stateMiddleware.Wrap).Get("/assets/{asset}/holders.csv", HandleRaw(actions.GetAssetHoldersHandler{}))
 }
@@ -54,6 +54,8 @@ func init() {
 	problem.RegisterError(context.DeadlineExceeded, hProblem.Timeout)
 	problem.RegisterError(context.Canceled, hProblem.ServiceUnavailable)
 	problem.RegisterError(db.ErrCancelled, hProblem.ServiceUnavailable)
+	problem.RegisterError(db.ErrStatementTimeout, hProblem.StatementTimeout)
+	problem.RegisterError(db.ErrQueryCostExceeded, hProblem.QueryTooExpensive)
 }
 
 func NewServer(serverConfig ServerConfig, routerConfig RouterConfig) (*Server, error) {
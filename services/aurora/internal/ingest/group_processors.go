@@ -2,6 +2,7 @@ package ingest
 
 import (
 	"github.com/hcnet/go/ingest/io"
+	"github.com/hcnet/go/services/aurora/internal/ingest/processors"
 	"github.com/hcnet/go/support/errors"
 )
 
@@ -50,3 +51,26 @@ func (g groupTransactionProcessors) Commit() error {
 	}
 	return nil
 }
+
+// filteredTransactionProcessor only passes the transactions matching filter
+// to processor.
+type filteredTransactionProcessor struct {
+	filter    *processors.TransactionFilter
+	sequence  uint32
+	processor auroraTransactionProcessor
+}
+
+func (f filteredTransactionProcessor) ProcessTransaction(tx io.LedgerTransaction) error {
+	matches, err := f.filter.Matches(f.sequence, tx)
+	if err != nil {
+		return errors.Wrap(err, "error filtering transaction")
+	}
+	if !matches {
+		return nil
+	}
+	return f.processor.ProcessTransaction(tx)
+}
+
+func (f filteredTransactionProcessor) Commit() error {
+	return f.processor.Commit()
+}
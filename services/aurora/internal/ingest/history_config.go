@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"strings"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// HistoryProcessor is the name of a history processor which can be disabled.
+// Ledgers are always ingested, and state processors are always run because
+// state verification and path finding depend on them.
+type HistoryProcessor string
+
+const (
+	EffectsProcessor      HistoryProcessor = "effects"
+	OperationsProcessor   HistoryProcessor = "operations"
+	ParticipantsProcessor HistoryProcessor = "participants"
+	TradesProcessor       HistoryProcessor = "trades"
+	TransactionsProcessor HistoryProcessor = "transactions"
+)
+
+// HistoryProcessors lists the history processors which can be disabled.
+var HistoryProcessors = []HistoryProcessor{
+	EffectsProcessor,
+	OperationsProcessor,
+	ParticipantsProcessor,
+	TradesProcessor,
+	TransactionsProcessor,
+}
+
+// LiteDisabledProcessors lists the history processors which are not run in
+// lite mode, because lite mode databases don't have their tables.
+var LiteDisabledProcessors = []HistoryProcessor{
+	EffectsProcessor,
+	OperationsProcessor,
+	ParticipantsProcessor,
+	TradesProcessor,
+}
+
+// historyProcessorDependencies lists the processors whose rows are needed to
+// serve the rows of a processor.
+var historyProcessorDependencies = map[HistoryProcessor][]HistoryProcessor{
+	// Operations are rendered with fields of their transaction.
+	OperationsProcessor: {TransactionsProcessor},
+	// Participants are only used to find the transactions and operations of
+	// an account.
+	ParticipantsProcessor: {OperationsProcessor, TransactionsProcessor},
+}
+
+// HistoryConfig selects the history stored during ingestion. The zero value
+// stores the history of all transactions.
+type HistoryConfig struct {
+	// DisabledProcessors lists the history processors which are not run.
+	DisabledProcessors []HistoryProcessor
+	// Accounts and Assets filter the transactions stored by history
+	// processors other than the ledgers one. When any of them is set, only
+	// transactions with one of Accounts as participant or involving one of
+	// Assets are stored.
+	Accounts []string
+	Assets   []xdr.Asset
+}
+
+// ParseHistoryProcessors parses a comma separated list of history processors.
+func ParseHistoryProcessors(s string) ([]HistoryProcessor, error) {
+	var result []HistoryProcessor
+	if s == "" {
+		return result, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		processor := HistoryProcessor(strings.TrimSpace(name))
+		if !processor.valid() {
+			return nil, errors.Errorf("unknown history processor %q", name)
+		}
+		result = append(result, processor)
+	}
+	return result, nil
+}
+
+func (p HistoryProcessor) valid() bool {
+	for _, processor := range HistoryProcessors {
+		if p == processor {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled returns true when processor is run during ingestion.
+func (c HistoryConfig) Enabled(processor HistoryProcessor) bool {
+	for _, disabled := range c.DisabledProcessors {
+		if disabled == processor {
+			return false
+		}
+	}
+	return true
+}
+
+// EnabledProcessors returns the history processors run during ingestion.
+func (c HistoryConfig) EnabledProcessors() []HistoryProcessor {
+	var enabled []HistoryProcessor
+	for _, processor := range HistoryProcessors {
+		if c.Enabled(processor) {
+			enabled = append(enabled, processor)
+		}
+	}
+	return enabled
+}
+
+// Filtered returns true when only the transactions of some accounts or assets
+// are stored.
+func (c HistoryConfig) Filtered() bool {
+	return len(c.Accounts) > 0 || len(c.Assets) > 0
+}
+
+// Validate returns an error when a processor is enabled but one it depends
+// on is not.
+func (c HistoryConfig) Validate() error {
+	for _, processor := range c.DisabledProcessors {
+		if !processor.valid() {
+			return errors.Errorf("unknown history processor %q", processor)
+		}
+	}
+	for _, processor := range HistoryProcessors {
+		if !c.Enabled(processor) {
+			continue
+		}
+		for _, dependency := range historyProcessorDependencies[processor] {
+			if !c.Enabled(dependency) {
+				return errors.Errorf(
+					"history processor %s requires %s which is disabled",
+					processor, dependency,
+				)
+			}
+		}
+	}
+	for _, account := range c.Accounts {
+		if _, err := xdr.AddressToAccountId(account); err != nil {
+			return errors.Wrapf(err, "invalid history account %s", account)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHistoryProcessors(t *testing.T) {
+	processors, err := ParseHistoryProcessors("")
+	assert.NoError(t, err)
+	assert.Empty(t, processors)
+
+	processors, err = ParseHistoryProcessors("effects, trades")
+	assert.NoError(t, err)
+	assert.Equal(t, []HistoryProcessor{EffectsProcessor, TradesProcessor}, processors)
+
+	_, err = ParseHistoryProcessors("effects,ledgers")
+	assert.EqualError(t, err, `unknown history processor "ledgers"`)
+}
+
+func TestHistoryConfig(t *testing.T) {
+	config := HistoryConfig{}
+	assert.NoError(t, config.Validate())
+	assert.False(t, config.Filtered())
+	assert.Equal(t, HistoryProcessors, config.EnabledProcessors())
+
+	config = HistoryConfig{
+		DisabledProcessors: []HistoryProcessor{EffectsProcessor, TradesProcessor},
+		Accounts:           []string{"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"},
+	}
+	assert.NoError(t, config.Validate())
+	assert.True(t, config.Filtered())
+	assert.False(t, config.Enabled(EffectsProcessor))
+	assert.True(t, config.Enabled(OperationsProcessor))
+	assert.Equal(
+		t,
+		[]HistoryProcessor{OperationsProcessor, ParticipantsProcessor, TransactionsProcessor},
+		config.EnabledProcessors(),
+	)
+
+	config = HistoryConfig{
+		DisabledProcessors: []HistoryProcessor{TransactionsProcessor},
+	}
+	assert.EqualError(t, config.Validate(), "history processor operations requires transactions which is disabled")
+
+	config = HistoryConfig{
+		DisabledProcessors: []HistoryProcessor{OperationsProcessor, TransactionsProcessor},
+	}
+	assert.EqualError(t, config.Validate(), "history processor participants requires operations which is disabled")
+
+	config = HistoryConfig{
+		DisabledProcessors: []HistoryProcessor{OperationsProcessor, ParticipantsProcessor, TransactionsProcessor},
+	}
+	assert.NoError(t, config.Validate())
+
+	config = HistoryConfig{Accounts: []string{"GABC"}}
+	assert.Error(t, config.Validate())
+}
@@ -48,7 +48,9 @@ const (
 	// - 11: Protocol 14: CAP-23 and CAP-33.
 	// - 12: Trigger state rebuild due to `absTime` -> `abs_time` rename
 	//       in ClaimableBalances predicates.
-	CurrentVersion = 12
+	// - 13: Asset stats include unauthorized trust lines, claimable balances
+	//       and liabilities.
+	CurrentVersion = 13
 
 	// MaxDBConnections is the size of the postgres connection pool dedicated to Aurora ingestion:
 	//  * Ledger ingestion,
@@ -58,6 +60,10 @@ const (
 
 	defaultCoreCursorName           = "HORIZON"
 	stateVerificationErrorThreshold = 3
+
+	// prefetchMaxRetries is the number of times a failed bucket download is
+	// retried when HistoryArchivePrefetchDir is set.
+	prefetchMaxRetries = 5
 )
 
 var log = logpkg.DefaultLogger.WithField("service", "ingest")
@@ -76,6 +82,19 @@ type Config struct {
 	HistoryArchiveURL        string
 	DisableStateVerification bool
 
+	// HistoryArchivePrefetchDir enables downloading buckets in parallel to
+	// a local directory before building state.
+	HistoryArchivePrefetchDir         string
+	HistoryArchivePrefetchConcurrency int
+
+	// History selects the history stored by history processors.
+	History HistoryConfig
+
+	// Lite ingests into a lite mode SQLite database. Asset stats and
+	// claimable balances are not ingested and state is not verified.
+	// History.DisabledProcessors must include LiteDisabledProcessors.
+	Lite bool
+
 	MaxReingestRetries          int
 	ReingestRetryBackoffSeconds int
 }
@@ -148,6 +167,10 @@ type system struct {
 }
 
 func NewSystem(config Config) (System, error) {
+	if err := config.History.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid history configuration")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	archive, err := historyarchive.Connect(
@@ -198,11 +221,39 @@ func NewSystem(config Config) (System, error) {
 
 	historyAdapter := adapters.MakeHistoryArchiveAdapter(archive)
 
+	// State is built from prefetched buckets when configured. Verification
+	// keeps streaming from the archive to avoid storing a copy of all
+	// buckets on every checkpoint.
+	stateAdapter := historyAdapter
+	if config.HistoryArchivePrefetchDir != "" {
+		stateAdapter, err = adapters.MakePrefetchingHistoryArchiveAdapter(
+			archive,
+			config.HistoryArchivePrefetchDir,
+			config.NetworkPassphrase,
+			historyarchive.PrefetchOptions{
+				Concurrency: config.HistoryArchivePrefetchConcurrency,
+				MaxRetries:  prefetchMaxRetries,
+				Progress: func(p historyarchive.PrefetchProgress) {
+					log.WithFields(logpkg.F{
+						"done":   p.DoneFiles,
+						"total":  p.TotalFiles,
+						"failed": p.FailedFiles,
+						"bytes":  p.BytesDownloaded,
+					}).Debug("Prefetching buckets")
+				},
+			},
+		)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "error creating prefetching history archive adapter")
+		}
+	}
+
 	system := &system{
 		cancel:                      cancel,
 		config:                      config,
 		ctx:                         ctx,
-		disableStateVerification:    config.DisableStateVerification,
+		disableStateVerification:    config.DisableStateVerification || config.Lite,
 		historyAdapter:              historyAdapter,
 		historyQ:                    historyQ,
 		ledgerBackend:               ledgerBackend,
@@ -215,7 +266,7 @@ func NewSystem(config Config) (System, error) {
 			ctx:            ctx,
 			config:         config,
 			historyQ:       historyQ,
-			historyAdapter: historyAdapter,
+			historyAdapter: stateAdapter,
 			ledgerBackend:  ledgerBackend,
 		},
 	}
@@ -335,6 +335,11 @@ func (m *mockDBQ) DeleteRangeAll(start, end int64) error {
 	return args.Error(0)
 }
 
+func (m *mockDBQ) EnsureHistoryPartitions(ledger uint32) error {
+	args := m.Called(ledger)
+	return args.Error(0)
+}
+
 // Methods from interfaces duplicating methods:
 
 func (m *mockDBQ) NewTransactionParticipantsBatchInsertBuilder(maxBatchSize int) history.TransactionParticipantsBatchInsertBuilder {
@@ -97,6 +97,19 @@ func (s *ProcessorRunner) buildChangeProcessor(
 	}
 
 	useLedgerCache := source == ledgerSource
+	if s.config.Lite {
+		// Lite mode databases don't have the asset stats and claimable
+		// balances tables.
+		return groupChangeProcessors{
+			statsChangeProcessor,
+			processors.NewAccountDataProcessor(s.historyQ),
+			processors.NewAccountsProcessor(s.historyQ),
+			processors.NewOffersProcessor(s.historyQ, sequence),
+			processors.NewSignersProcessor(s.historyQ, useLedgerCache),
+			processors.NewTrustLinesProcessor(s.historyQ),
+		}
+	}
+
 	return groupChangeProcessors{
 		statsChangeProcessor,
 		processors.NewAccountDataProcessor(s.historyQ),
@@ -118,15 +131,50 @@ func (s *ProcessorRunner) buildTransactionProcessor(
 	}
 
 	sequence := uint32(ledger.Header.LedgerSeq)
-	return groupTransactionProcessors{
+	history := s.config.History
+	if !history.Filtered() && len(history.DisabledProcessors) == 0 {
+		return groupTransactionProcessors{
+			statsLedgerTransactionProcessor,
+			processors.NewEffectProcessor(s.historyQ, sequence),
+			processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion),
+			processors.NewOperationProcessor(s.historyQ, sequence),
+			processors.NewTradeProcessor(s.historyQ, ledger),
+			processors.NewParticipantsProcessor(s.historyQ, sequence),
+			processors.NewTransactionProcessor(s.historyQ, sequence),
+		}
+	}
+
+	var historyProcessors groupTransactionProcessors
+	if history.Enabled(EffectsProcessor) {
+		historyProcessors = append(historyProcessors, processors.NewEffectProcessor(s.historyQ, sequence))
+	}
+	if history.Enabled(OperationsProcessor) {
+		historyProcessors = append(historyProcessors, processors.NewOperationProcessor(s.historyQ, sequence))
+	}
+	if history.Enabled(TradesProcessor) {
+		historyProcessors = append(historyProcessors, processors.NewTradeProcessor(s.historyQ, ledger))
+	}
+	if history.Enabled(ParticipantsProcessor) {
+		historyProcessors = append(historyProcessors, processors.NewParticipantsProcessor(s.historyQ, sequence))
+	}
+	if history.Enabled(TransactionsProcessor) {
+		historyProcessors = append(historyProcessors, processors.NewTransactionProcessor(s.historyQ, sequence))
+	}
+
+	// Ledgers are never filtered, their transaction and operation counts
+	// include all transactions.
+	group := groupTransactionProcessors{
 		statsLedgerTransactionProcessor,
-		processors.NewEffectProcessor(s.historyQ, sequence),
 		processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion),
-		processors.NewOperationProcessor(s.historyQ, sequence),
-		processors.NewTradeProcessor(s.historyQ, ledger),
-		processors.NewParticipantsProcessor(s.historyQ, sequence),
-		processors.NewTransactionProcessor(s.historyQ, sequence),
 	}
+	if history.Filtered() {
+		return append(group, filteredTransactionProcessor{
+			filter:    processors.NewTransactionFilter(history.Accounts, history.Assets),
+			sequence:  sequence,
+			processor: historyProcessors,
+		})
+	}
+	return append(group, historyProcessors...)
 }
 
 // checkIfProtocolVersionSupported checks if this Aurora version supports the
@@ -282,6 +330,10 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger uint32) (io.St
 		return ledgerTransactionStats.GetResults(), errors.Wrap(err, "Error while checking for supported protocol version")
 	}
 
+	if err = s.historyQ.EnsureHistoryPartitions(ledger); err != nil {
+		return ledgerTransactionStats.GetResults(), errors.Wrap(err, "Error creating history partitions")
+	}
+
 	txProcessor := s.buildTransactionProcessor(&ledgerTransactionStats, transactionReader.GetHeader())
 	err = io.StreamLedgerTransactions(txProcessor, transactionReader)
 	if err != nil {
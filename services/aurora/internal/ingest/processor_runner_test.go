@@ -306,6 +306,37 @@ func TestProcessorRunnerBuildChangeProcessor(t *testing.T) {
 	assert.IsType(t, &processors.TrustLinesProcessor{}, processor.(groupChangeProcessors)[6])
 }
 
+func TestProcessorRunnerBuildChangeProcessorLite(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQOffers.On("NewOffersBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOffersBatchInsertBuilder{}).Once()
+	q.MockQData.On("NewAccountDataBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountDataBatchInsertBuilder{}).Once()
+	q.MockQSigners.On("NewAccountSignersBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockAccountSignersBatchInsertBuilder{}).Once()
+
+	runner := ProcessorRunner{
+		config:   Config{Lite: true},
+		historyQ: q,
+	}
+
+	stats := &io.StatsChangeProcessor{}
+	processor := runner.buildChangeProcessor(stats, historyArchiveSource, 123)
+	assert.IsType(t, groupChangeProcessors{}, processor)
+	assert.Len(t, processor.(groupChangeProcessors), 6)
+
+	assert.IsType(t, &statsChangeProcessor{}, processor.(groupChangeProcessors)[0])
+	assert.IsType(t, &processors.AccountDataProcessor{}, processor.(groupChangeProcessors)[1])
+	assert.IsType(t, &processors.AccountsProcessor{}, processor.(groupChangeProcessors)[2])
+	assert.IsType(t, &processors.OffersProcessor{}, processor.(groupChangeProcessors)[3])
+	assert.IsType(t, &processors.SignersProcessor{}, processor.(groupChangeProcessors)[4])
+	assert.IsType(t, &processors.TrustLinesProcessor{}, processor.(groupChangeProcessors)[5])
+}
+
 func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	maxBatchSize := 100000
 
@@ -336,6 +367,53 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	assert.IsType(t, &processors.TransactionProcessor{}, processor.(groupTransactionProcessors)[6])
 }
 
+func TestProcessorRunnerBuildTransactionProcessorHistoryConfig(t *testing.T) {
+	maxBatchSize := 100000
+
+	q := &mockDBQ{}
+	defer mock.AssertExpectationsForObjects(t, q)
+
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Once()
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Once()
+
+	runner := ProcessorRunner{
+		config: Config{
+			History: HistoryConfig{
+				DisabledProcessors: []HistoryProcessor{EffectsProcessor, TradesProcessor},
+			},
+		},
+		historyQ: q,
+	}
+
+	stats := &io.StatsLedgerTransactionProcessor{}
+	ledger := xdr.LedgerHeaderHistoryEntry{}
+	processor := runner.buildTransactionProcessor(stats, ledger)
+	assert.IsType(t, groupTransactionProcessors{}, processor)
+	group := processor.(groupTransactionProcessors)
+	assert.Len(t, group, 5)
+	assert.IsType(t, &statsLedgerTransactionProcessor{}, group[0])
+	assert.IsType(t, &processors.LedgersProcessor{}, group[1])
+	assert.IsType(t, &processors.OperationProcessor{}, group[2])
+	assert.IsType(t, &processors.ParticipantsProcessor{}, group[3])
+	assert.IsType(t, &processors.TransactionProcessor{}, group[4])
+
+	runner.config.History.Accounts = []string{"GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"}
+	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockTransactionsBatchInsertBuilder{}).Once()
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", maxBatchSize).
+		Return(&history.MockOperationsBatchInsertBuilder{}).Once()
+
+	processor = runner.buildTransactionProcessor(stats, ledger)
+	group = processor.(groupTransactionProcessors)
+	assert.Len(t, group, 3)
+	assert.IsType(t, &statsLedgerTransactionProcessor{}, group[0])
+	assert.IsType(t, &processors.LedgersProcessor{}, group[1])
+	assert.IsType(t, filteredTransactionProcessor{}, group[2])
+	assert.Len(t, group[2].(filteredTransactionProcessor).processor, 3)
+}
+
 func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
 	maxBatchSize := 100000
 
@@ -408,6 +486,8 @@ func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
 	q.MockQLedgers.On("InsertLedger", ledger, 0, 0, 0, 0, CurrentVersion).
 		Return(int64(1), nil).Once()
 
+	q.On("EnsureHistoryPartitions", uint32(63)).Return(nil).Once()
+
 	runner := ProcessorRunner{
 		ctx:           context.Background(),
 		config:        config,
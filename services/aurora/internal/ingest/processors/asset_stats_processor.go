@@ -2,7 +2,6 @@ package processors
 
 import (
 	"database/sql"
-	"math/big"
 
 	ingesterrors "github.com/hcnet/go/ingest/errors"
 	"github.com/hcnet/go/ingest/io"
@@ -42,7 +41,8 @@ func (p *AssetStatsProcessor) reset() {
 }
 
 func (p *AssetStatsProcessor) ProcessChange(change io.Change) error {
-	if change.Type != xdr.LedgerEntryTypeTrustline {
+	if change.Type != xdr.LedgerEntryTypeTrustline &&
+		change.Type != xdr.LedgerEntryTypeClaimableBalance {
 		return nil
 	}
 
@@ -66,8 +66,7 @@ func (p *AssetStatsProcessor) ProcessChange(change io.Change) error {
 		return errors.New("AssetStatsProcessor is in insert only mode")
 	}
 
-	postTrustLine := change.Post.Data.MustTrustLine()
-	err := p.adjustAssetStat(nil, &postTrustLine)
+	err := p.adjustAssetStat(change)
 	if err != nil {
 		return errors.Wrap(err, "Error adjusting asset stat")
 	}
@@ -82,39 +81,22 @@ func (p *AssetStatsProcessor) Commit() error {
 
 	changes := p.cache.GetChanges()
 	for _, change := range changes {
-		var err error
-
-		switch {
-		case change.Pre == nil && change.Post != nil:
-			// Created
-			postTrustLine := change.Post.Data.MustTrustLine()
-			err = p.adjustAssetStat(nil, &postTrustLine)
-		case change.Pre != nil && change.Post != nil:
-			// Updated
-			preTrustLine := change.Pre.Data.MustTrustLine()
-			postTrustLine := change.Post.Data.MustTrustLine()
-			err = p.adjustAssetStat(&preTrustLine, &postTrustLine)
-		case change.Pre != nil && change.Post == nil:
-			// Removed
-			preTrustLine := change.Pre.Data.MustTrustLine()
-			err = p.adjustAssetStat(&preTrustLine, nil)
-		default:
+		if change.Pre == nil && change.Post == nil {
 			return errors.New("Invalid io.Change: change.Pre == nil && change.Post == nil")
 		}
 
-		if err != nil {
+		if err := p.adjustAssetStat(change); err != nil {
 			return errors.Wrap(err, "Error adjusting asset stat")
 		}
 	}
 
-	assetStatsDeltas := p.assetStatSet.All()
-	for _, delta := range assetStatsDeltas {
+	for key, delta := range p.assetStatSet {
 		var rowsAffected int64
 
 		stat, err := p.assetStatsQ.GetAssetStat(
-			delta.AssetType,
-			delta.AssetCode,
-			delta.AssetIssuer,
+			key.assetType,
+			key.assetCode,
+			key.assetIssuer,
 		)
 		assetStatNotFound := err == sql.ErrNoRows
 		if !assetStatNotFound && err != nil {
@@ -123,62 +105,60 @@ func (p *AssetStatsProcessor) Commit() error {
 
 		if assetStatNotFound {
 			// Insert
-			if delta.NumAccounts < 0 {
+			if delta.hasNegativeAccounts() {
 				return ingesterrors.NewStateError(errors.Errorf(
-					"NumAccounts negative but DB entry does not exist for asset: %s %s %s",
-					delta.AssetType,
-					delta.AssetCode,
-					delta.AssetIssuer,
+					"Accounts negative but DB entry does not exist for asset: %s %s %s",
+					key.assetType,
+					key.assetCode,
+					key.assetIssuer,
 				))
 			}
 
 			var errInsert error
-			rowsAffected, errInsert = p.assetStatsQ.InsertAssetStat(delta)
+			rowsAffected, errInsert = p.assetStatsQ.InsertAssetStat(delta.toHistory(key))
 			if errInsert != nil {
 				return errors.Wrap(errInsert, "could not insert asset stat")
 			}
 		} else {
-			statBalance, ok := new(big.Int).SetString(stat.Amount, 10)
-			if !ok {
-				return errors.New("Error parsing: " + stat.Amount)
+			var current *assetStatValue
+			current, err = assetStatValueFromHistory(stat)
+			if err != nil {
+				return errors.Wrap(err, "could not parse asset stat from db")
 			}
 
-			deltaBalance, ok := new(big.Int).SetString(delta.Amount, 10)
-			if !ok {
-				return errors.New("Error parsing: " + stat.Amount)
-			}
+			// current = current + delta
+			current.add(delta)
 
-			// statBalance = statBalance + deltaBalance
-			statBalance.Add(statBalance, deltaBalance)
-			statAccounts := stat.NumAccounts + delta.NumAccounts
+			if current.hasNegativeAccounts() {
+				return ingesterrors.NewStateError(errors.Errorf(
+					"Accounts negative after adjusting asset stat for: %s %s %s",
+					key.assetType,
+					key.assetCode,
+					key.assetIssuer,
+				))
+			}
 
-			if statAccounts == 0 {
+			if !current.hasAccounts() {
 				// Remove stats
-				if statBalance.Cmp(big.NewInt(0)) != 0 {
+				if current.hasAmounts() {
 					return ingesterrors.NewStateError(errors.Errorf(
 						"Removing asset stat by final amount non-zero for: %s %s %s",
-						delta.AssetType,
-						delta.AssetCode,
-						delta.AssetIssuer,
+						key.assetType,
+						key.assetCode,
+						key.assetIssuer,
 					))
 				}
 				rowsAffected, err = p.assetStatsQ.RemoveAssetStat(
-					delta.AssetType,
-					delta.AssetCode,
-					delta.AssetIssuer,
+					key.assetType,
+					key.assetCode,
+					key.assetIssuer,
 				)
 				if err != nil {
 					return errors.Wrap(err, "could not remove asset stat")
 				}
 			} else {
 				// Update
-				rowsAffected, err = p.assetStatsQ.UpdateAssetStat(history.ExpAssetStat{
-					AssetType:   delta.AssetType,
-					AssetCode:   delta.AssetCode,
-					AssetIssuer: delta.AssetIssuer,
-					Amount:      statBalance.String(),
-					NumAccounts: statAccounts,
-				})
+				rowsAffected, err = p.assetStatsQ.UpdateAssetStat(current.toHistory(key))
 				if err != nil {
 					return errors.Wrap(err, "could not update asset stat")
 				}
@@ -189,9 +169,9 @@ func (p *AssetStatsProcessor) Commit() error {
 			return ingesterrors.NewStateError(errors.Errorf(
 				"%d rows affected when adjusting asset stat for asset: %s %s %s",
 				rowsAffected,
-				delta.AssetType,
-				delta.AssetCode,
-				delta.AssetIssuer,
+				key.assetType,
+				key.assetCode,
+				key.assetIssuer,
 			))
 		}
 	}
@@ -199,56 +179,35 @@ func (p *AssetStatsProcessor) Commit() error {
 	return nil
 }
 
-func (p *AssetStatsProcessor) adjustAssetStat(
-	preTrustline *xdr.TrustLineEntry,
-	postTrustline *xdr.TrustLineEntry,
-) error {
-	var deltaBalance xdr.Int64
-	var deltaAccounts int32
-	var trustline xdr.TrustLineEntry
-
-	if preTrustline != nil && postTrustline == nil {
-		trustline = *preTrustline
-		// removing a trustline
-		if xdr.TrustLineFlags(preTrustline.Flags).IsAuthorized() {
-			deltaAccounts = -1
-			deltaBalance = -preTrustline.Balance
+// adjustAssetStat adds the difference between the pre and post state of a
+// trustline or claimable balance change to the asset stats.
+func (p *AssetStatsProcessor) adjustAssetStat(change io.Change) error {
+	var err error
+	switch change.Type {
+	case xdr.LedgerEntryTypeTrustline:
+		var pre, post *xdr.TrustLineEntry
+		if change.Pre != nil {
+			pre = change.Pre.Data.TrustLine
+		}
+		if change.Post != nil {
+			post = change.Post.Data.TrustLine
 		}
-	} else if preTrustline == nil && postTrustline != nil {
-		trustline = *postTrustline
-		// adding a trustline
-		if xdr.TrustLineFlags(postTrustline.Flags).IsAuthorized() {
-			deltaAccounts = 1
-			deltaBalance = postTrustline.Balance
+		err = p.assetStatSet.AddTrustLineChange(pre, post)
+	case xdr.LedgerEntryTypeClaimableBalance:
+		var pre, post *xdr.ClaimableBalanceEntry
+		if change.Pre != nil {
+			pre = change.Pre.Data.ClaimableBalance
 		}
-	} else if preTrustline != nil && postTrustline != nil {
-		trustline = *postTrustline
-		// updating a trustline
-		if xdr.TrustLineFlags(preTrustline.Flags).IsAuthorized() &&
-			xdr.TrustLineFlags(postTrustline.Flags).IsAuthorized() {
-			// trustline remains authorized
-			deltaAccounts = 0
-			deltaBalance = postTrustline.Balance - preTrustline.Balance
-		} else if xdr.TrustLineFlags(preTrustline.Flags).IsAuthorized() &&
-			!xdr.TrustLineFlags(postTrustline.Flags).IsAuthorized() {
-			// trustline was authorized and became unauthorized
-			deltaAccounts = -1
-			deltaBalance = -preTrustline.Balance
-		} else if !xdr.TrustLineFlags(preTrustline.Flags).IsAuthorized() &&
-			xdr.TrustLineFlags(postTrustline.Flags).IsAuthorized() {
-			// trustline was unauthorized and became authorized
-			deltaAccounts = 1
-			deltaBalance = postTrustline.Balance
+		if change.Post != nil {
+			post = change.Post.Data.ClaimableBalance
 		}
-		// else, trustline was unauthorized and remains unauthorized
-		// so there is no change to accounts or balances
-	} else {
-		return ingesterrors.NewStateError(errors.New("both pre and post trustlines cannot be nil"))
+		err = p.assetStatSet.AddClaimableBalanceChange(pre, post)
+	default:
+		return errors.Errorf("unexpected change type %s", change.Type)
 	}
 
-	err := p.assetStatSet.AddDelta(trustline.Asset, int64(deltaBalance), deltaAccounts)
 	if err != nil {
-		return errors.Wrap(err, "error running AssetStatSet.AddDelta")
+		return errors.Wrap(err, "error running AssetStatSet")
 	}
 	return nil
 }
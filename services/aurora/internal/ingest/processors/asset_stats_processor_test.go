@@ -53,13 +53,11 @@ func (s *AssetStatsProcessorTestSuiteState) TestCreateTrustLine() {
 	s.Assert().NoError(err)
 
 	s.mockQ.On("InsertAssetStats", []history.ExpAssetStat{
-		{
-			AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-			AssetIssuer: trustLineIssuer.Address(),
-			AssetCode:   "EUR",
-			Amount:      "0",
-			NumAccounts: 1,
-		},
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{Authorized: 1},
+			history.ExpAssetStatBalances{},
+			history.ExpAssetStatLiabilities{},
+		),
 	}, maxBatchSize).Return(nil).Once()
 }
 
@@ -82,8 +80,54 @@ func (s *AssetStatsProcessorTestSuiteState) TestCreateTrustLineUnauthorized() {
 	})
 	s.Assert().NoError(err)
 
-	s.mockQ.
-		On("InsertAssetStats", []history.ExpAssetStat{}, maxBatchSize).Return(nil).Once()
+	s.mockQ.On("InsertAssetStats", []history.ExpAssetStat{
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{Unauthorized: 1},
+			history.ExpAssetStatBalances{},
+			history.ExpAssetStatLiabilities{},
+		),
+	}, maxBatchSize).Return(nil).Once()
+}
+
+func (s *AssetStatsProcessorTestSuiteState) TestCreateClaimableBalance() {
+	err := s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		Pre:  nil,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &xdr.ClaimableBalanceEntry{
+					Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+					Amount: 12,
+				},
+			},
+		},
+	})
+	s.Assert().NoError(err)
+
+	// claimable balances of native assets are ignored
+	err = s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		Pre:  nil,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &xdr.ClaimableBalanceEntry{
+					Asset:  xdr.MustNewNativeAsset(),
+					Amount: 12,
+				},
+			},
+		},
+	})
+	s.Assert().NoError(err)
+
+	s.mockQ.On("InsertAssetStats", []history.ExpAssetStat{
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{ClaimableBalances: 1},
+			history.ExpAssetStatBalances{ClaimableBalances: "12"},
+			history.ExpAssetStatLiabilities{},
+		),
+	}, maxBatchSize).Return(nil).Once()
 }
 
 func TestAssetStatsProcessorTestSuiteLedger(t *testing.T) {
@@ -218,13 +262,22 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestInsertTrustLine() {
 		"EUR",
 		trustLineIssuer.Address(),
 	).Return(history.ExpAssetStat{}, sql.ErrNoRows).Once()
-	s.mockQ.On("InsertAssetStat", history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "10",
-		NumAccounts: 1,
-	}).Return(int64(1), nil).Once()
+	s.mockQ.On("InsertAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
+
+	s.mockQ.On("GetAssetStat",
+		xdr.AssetTypeAssetTypeCreditAlphanum4,
+		"USD",
+		trustLineIssuer.Address(),
+	).Return(history.ExpAssetStat{}, sql.ErrNoRows).Once()
+	s.mockQ.On("InsertAssetStat", newAssetStat("USD",
+		history.ExpAssetStatAccounts{Unauthorized: 1},
+		history.ExpAssetStatBalances{Unauthorized: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
 
 	s.Assert().NoError(s.processor.Commit())
 }
@@ -268,20 +321,16 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateTrustLine() {
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
 		"EUR",
 		trustLineIssuer.Address(),
-	).Return(history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "100",
-		NumAccounts: 1,
-	}, nil).Once()
-	s.mockQ.On("UpdateAssetStat", history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "110",
-		NumAccounts: 1,
-	}).Return(int64(1), nil).Once()
+	).Return(newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "100"},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
+	s.mockQ.On("UpdateAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "110"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
 
 	s.Assert().NoError(s.processor.Commit())
 }
@@ -355,31 +404,93 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateTrustLineAuthorization()
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
 		"EUR",
 		trustLineIssuer.Address(),
-	).Return(history.ExpAssetStat{}, sql.ErrNoRows).Once()
-	s.mockQ.On("InsertAssetStat", history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "10",
-		NumAccounts: 1,
-	}).Return(int64(1), nil).Once()
+	).Return(newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Unauthorized: 1},
+		history.ExpAssetStatBalances{Unauthorized: "100"},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
+	s.mockQ.On("UpdateAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
 
 	s.mockQ.On("GetAssetStat",
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
 		"USD",
 		trustLineIssuer.Address(),
-	).Return(history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "USD",
-		Amount:      "100",
-		NumAccounts: 1,
-	}, nil).Once()
-	s.mockQ.On("RemoveAssetStat",
+	).Return(newAssetStat("USD",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "100"},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
+	s.mockQ.On("UpdateAssetStat", newAssetStat("USD",
+		history.ExpAssetStatAccounts{Unauthorized: 1},
+		history.ExpAssetStatBalances{Unauthorized: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *AssetStatsProcessorTestSuiteLedger) TestUpdateTrustLineMaintainLiabilities() {
+	trustLine := xdr.TrustLineEntry{
+		AccountId: xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
+		Asset:     xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+		Balance:   100,
+		Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		Ext: xdr.TrustLineEntryExt{
+			V: 1,
+			V1: &xdr.TrustLineEntryV1{
+				Liabilities: xdr.Liabilities{
+					Buying:  20,
+					Selling: 30,
+				},
+			},
+		},
+	}
+	updatedTrustLine := trustLine
+	updatedTrustLine.Flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag)
+	updatedTrustLine.Ext = xdr.TrustLineEntryExt{
+		V: 1,
+		V1: &xdr.TrustLineEntryV1{
+			Liabilities: xdr.Liabilities{
+				Buying:  5,
+				Selling: 10,
+			},
+		},
+	}
+
+	err := s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeTrustline,
+		Pre: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:      xdr.LedgerEntryTypeTrustline,
+				TrustLine: &trustLine,
+			},
+		},
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:      xdr.LedgerEntryTypeTrustline,
+				TrustLine: &updatedTrustLine,
+			},
+		},
+	})
+	s.Assert().NoError(err)
+
+	s.mockQ.On("GetAssetStat",
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
-		"USD",
+		"EUR",
 		trustLineIssuer.Address(),
-	).Return(int64(1), nil).Once()
+	).Return(newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 2},
+		history.ExpAssetStatBalances{Authorized: "150"},
+		history.ExpAssetStatLiabilities{Buying: "20", Selling: "40"},
+	), nil).Once()
+	s.mockQ.On("UpdateAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1, AuthorizedToMaintainLiabilities: 1},
+		history.ExpAssetStatBalances{Authorized: "50", AuthorizedToMaintainLiabilities: "100"},
+		history.ExpAssetStatLiabilities{Buying: "5", Selling: "20"},
+	)).Return(int64(1), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
 
@@ -423,21 +534,126 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestRemoveTrustLine() {
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
 		"EUR",
 		trustLineIssuer.Address(),
-	).Return(history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "0",
-		NumAccounts: 1,
-	}, nil).Once()
+	).Return(newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
 	s.mockQ.On("RemoveAssetStat",
 		xdr.AssetTypeAssetTypeCreditAlphanum4,
 		"EUR",
 		trustLineIssuer.Address(),
 	).Return(int64(1), nil).Once()
+
+	s.mockQ.On("GetAssetStat",
+		xdr.AssetTypeAssetTypeCreditAlphanum4,
+		"USD",
+		trustLineIssuer.Address(),
+	).Return(newAssetStat("USD",
+		history.ExpAssetStatAccounts{Unauthorized: 1},
+		history.ExpAssetStatBalances{},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
+	s.mockQ.On("RemoveAssetStat",
+		xdr.AssetTypeAssetTypeCreditAlphanum4,
+		"USD",
+		trustLineIssuer.Address(),
+	).Return(int64(1), nil).Once()
+	s.Assert().NoError(s.processor.Commit())
+}
+
+func (s *AssetStatsProcessorTestSuiteLedger) TestClaimableBalances() {
+	cBalance := xdr.ClaimableBalanceEntry{
+		BalanceId: xdr.ClaimableBalanceId{
+			Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0,
+			V0:   &xdr.Hash{1, 2, 3},
+		},
+		Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+		Amount: 10,
+	}
+	otherCBalance := xdr.ClaimableBalanceEntry{
+		BalanceId: xdr.ClaimableBalanceId{
+			Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0,
+			V0:   &xdr.Hash{4, 5, 6},
+		},
+		Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+		Amount: 20,
+	}
+
+	// one claimable balance is created and another one is claimed
+	err := s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		Pre:  nil,
+		Post: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:             xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &cBalance,
+			},
+		},
+	})
+	s.Assert().NoError(err)
+
+	err = s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		Pre: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:             xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &otherCBalance,
+			},
+		},
+		Post: nil,
+	})
+	s.Assert().NoError(err)
+
+	s.mockQ.On("GetAssetStat",
+		xdr.AssetTypeAssetTypeCreditAlphanum4,
+		"EUR",
+		trustLineIssuer.Address(),
+	).Return(newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1, ClaimableBalances: 1},
+		history.ExpAssetStatBalances{Authorized: "5", ClaimableBalances: "20"},
+		history.ExpAssetStatLiabilities{},
+	), nil).Once()
+	s.mockQ.On("UpdateAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1, ClaimableBalances: 1},
+		history.ExpAssetStatBalances{Authorized: "5", ClaimableBalances: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
 
+func (s *AssetStatsProcessorTestSuiteLedger) TestRemoveClaimableBalanceWithoutAssetStat() {
+	err := s.processor.ProcessChange(io.Change{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		Pre: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeClaimableBalance,
+				ClaimableBalance: &xdr.ClaimableBalanceEntry{
+					BalanceId: xdr.ClaimableBalanceId{
+						Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0,
+						V0:   &xdr.Hash{1, 2, 3},
+					},
+					Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+					Amount: 10,
+				},
+			},
+		},
+		Post: nil,
+	})
+	s.Assert().NoError(err)
+
+	s.mockQ.On("GetAssetStat",
+		xdr.AssetTypeAssetTypeCreditAlphanum4,
+		"EUR",
+		trustLineIssuer.Address(),
+	).Return(history.ExpAssetStat{}, sql.ErrNoRows).Once()
+	s.Assert().EqualError(
+		s.processor.Commit(),
+		"Accounts negative but DB entry does not exist for asset: AssetTypeAssetTypeCreditAlphanum4 EUR "+
+			trustLineIssuer.Address(),
+	)
+}
+
 func (s *AssetStatsProcessorTestSuiteLedger) TestProcessUpgradeChange() {
 	// add trust line
 	lastModifiedLedgerSeq := xdr.Uint32(1234)
@@ -491,12 +707,10 @@ func (s *AssetStatsProcessorTestSuiteLedger) TestProcessUpgradeChange() {
 		"EUR",
 		trustLineIssuer.Address(),
 	).Return(history.ExpAssetStat{}, sql.ErrNoRows).Once()
-	s.mockQ.On("InsertAssetStat", history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetIssuer: trustLineIssuer.Address(),
-		AssetCode:   "EUR",
-		Amount:      "10",
-		NumAccounts: 1,
-	}).Return(int64(1), nil).Once()
+	s.mockQ.On("InsertAssetStat", newAssetStat("EUR",
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "10"},
+		history.ExpAssetStatLiabilities{},
+	)).Return(int64(1), nil).Once()
 	s.Assert().NoError(s.processor.Commit())
 }
@@ -13,27 +13,232 @@ type assetStatKey struct {
 	assetCode   string
 	assetIssuer string
 }
+
+// assetStatValue holds the stats of a single asset. It is used both for the
+// absolute stats of an asset and for the deltas applied to them, so any of
+// its fields can be negative.
 type assetStatValue struct {
-	amount      *big.Int
-	numAccounts int32
+	accounts           history.ExpAssetStatAccounts
+	balances           assetStatBalances
+	buyingLiabilities  *big.Int
+	sellingLiabilities *big.Int
+}
+
+type assetStatBalances struct {
+	authorized                      *big.Int
+	authorizedToMaintainLiabilities *big.Int
+	unauthorized                    *big.Int
+	claimableBalances               *big.Int
+}
+
+func newAssetStatValue() *assetStatValue {
+	return &assetStatValue{
+		balances: assetStatBalances{
+			authorized:                      big.NewInt(0),
+			authorizedToMaintainLiabilities: big.NewInt(0),
+			unauthorized:                    big.NewInt(0),
+			claimableBalances:               big.NewInt(0),
+		},
+		buyingLiabilities:  big.NewInt(0),
+		sellingLiabilities: big.NewInt(0),
+	}
+}
+
+// assetStatValueFromHistory parses the amounts of a history.ExpAssetStat.
+func assetStatValueFromHistory(stat history.ExpAssetStat) (*assetStatValue, error) {
+	value := newAssetStatValue()
+	value.accounts = stat.Accounts
+
+	amounts := []struct {
+		dest   *big.Int
+		amount string
+	}{
+		{value.balances.authorized, stat.Balances.Authorized},
+		{value.balances.authorizedToMaintainLiabilities, stat.Balances.AuthorizedToMaintainLiabilities},
+		{value.balances.unauthorized, stat.Balances.Unauthorized},
+		{value.balances.claimableBalances, stat.Balances.ClaimableBalances},
+		{value.buyingLiabilities, stat.Liabilities.Buying},
+		{value.sellingLiabilities, stat.Liabilities.Selling},
+	}
+	for _, a := range amounts {
+		if _, ok := a.dest.SetString(a.amount, 10); !ok {
+			return nil, errors.New("Error parsing: " + a.amount)
+		}
+	}
+
+	return value, nil
+}
+
+// trustLineDelta returns the stats of a single trust line multiplied by sign.
+func trustLineDelta(trustLine xdr.TrustLineEntry, sign int64) *assetStatValue {
+	value := newAssetStatValue()
+	balance := big.NewInt(sign * int64(trustLine.Balance))
+	flags := xdr.TrustLineFlags(trustLine.Flags)
+	switch {
+	case flags.IsAuthorized():
+		value.accounts.Authorized = int32(sign)
+		value.balances.authorized = balance
+	case flags.IsAuthorizedToMaintainLiabilitiesFlag():
+		value.accounts.AuthorizedToMaintainLiabilities = int32(sign)
+		value.balances.authorizedToMaintainLiabilities = balance
+	default:
+		value.accounts.Unauthorized = int32(sign)
+		value.balances.unauthorized = balance
+	}
+
+	liabilities := trustLine.Liabilities()
+	value.buyingLiabilities = big.NewInt(sign * int64(liabilities.Buying))
+	value.sellingLiabilities = big.NewInt(sign * int64(liabilities.Selling))
+	return value
+}
+
+// claimableBalanceDelta returns the stats of a single claimable balance
+// multiplied by sign.
+func claimableBalanceDelta(cBalance xdr.ClaimableBalanceEntry, sign int64) *assetStatValue {
+	value := newAssetStatValue()
+	value.accounts.ClaimableBalances = int32(sign)
+	value.balances.claimableBalances = big.NewInt(sign * int64(cBalance.Amount))
+	return value
+}
+
+func (v *assetStatValue) add(delta *assetStatValue) {
+	v.accounts.Authorized += delta.accounts.Authorized
+	v.accounts.AuthorizedToMaintainLiabilities += delta.accounts.AuthorizedToMaintainLiabilities
+	v.accounts.Unauthorized += delta.accounts.Unauthorized
+	v.accounts.ClaimableBalances += delta.accounts.ClaimableBalances
+
+	v.balances.authorized.Add(v.balances.authorized, delta.balances.authorized)
+	v.balances.authorizedToMaintainLiabilities.Add(
+		v.balances.authorizedToMaintainLiabilities,
+		delta.balances.authorizedToMaintainLiabilities,
+	)
+	v.balances.unauthorized.Add(v.balances.unauthorized, delta.balances.unauthorized)
+	v.balances.claimableBalances.Add(v.balances.claimableBalances, delta.balances.claimableBalances)
+
+	v.buyingLiabilities.Add(v.buyingLiabilities, delta.buyingLiabilities)
+	v.sellingLiabilities.Add(v.sellingLiabilities, delta.sellingLiabilities)
+}
+
+// hasAccounts returns true if any trust line or claimable balance is counted.
+func (v *assetStatValue) hasAccounts() bool {
+	return v.accounts != history.ExpAssetStatAccounts{}
+}
+
+// hasNegativeAccounts returns true if any of the counts is negative.
+func (v *assetStatValue) hasNegativeAccounts() bool {
+	return v.accounts.Authorized < 0 ||
+		v.accounts.AuthorizedToMaintainLiabilities < 0 ||
+		v.accounts.Unauthorized < 0 ||
+		v.accounts.ClaimableBalances < 0
+}
+
+// hasAmounts returns true if any of the balances or liabilities is non-zero.
+func (v *assetStatValue) hasAmounts() bool {
+	for _, amount := range []*big.Int{
+		v.balances.authorized,
+		v.balances.authorizedToMaintainLiabilities,
+		v.balances.unauthorized,
+		v.balances.claimableBalances,
+		v.buyingLiabilities,
+		v.sellingLiabilities,
+	} {
+		if amount.Sign() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *assetStatValue) isZero() bool {
+	return !v.hasAccounts() && !v.hasAmounts()
+}
+
+func (v *assetStatValue) toHistory(key assetStatKey) history.ExpAssetStat {
+	return history.ExpAssetStat{
+		AssetType:   key.assetType,
+		AssetCode:   key.assetCode,
+		AssetIssuer: key.assetIssuer,
+		Accounts:    v.accounts,
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      v.balances.authorized.String(),
+			AuthorizedToMaintainLiabilities: v.balances.authorizedToMaintainLiabilities.String(),
+			Unauthorized:                    v.balances.unauthorized.String(),
+			ClaimableBalances:               v.balances.claimableBalances.String(),
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  v.buyingLiabilities.String(),
+			Selling: v.sellingLiabilities.String(),
+		},
+		Amount:      v.balances.authorized.String(),
+		NumAccounts: v.accounts.Authorized,
+	}
 }
 
 // AssetStatSet represents a collection of asset stats
 type AssetStatSet map[assetStatKey]*assetStatValue
 
-// Add updates the set with a trustline entry from a history archive snapshot
-// if the trustline is authorized.
+// Add updates the set with a trustline entry from a history archive snapshot.
 func (s AssetStatSet) Add(trustLine xdr.TrustLineEntry) error {
-	if !xdr.TrustLineFlags(trustLine.Flags).IsAuthorized() {
+	return s.addDelta(trustLine.Asset, trustLineDelta(trustLine, 1))
+}
+
+// AddClaimableBalance updates the set with a claimable balance entry from a
+// history archive snapshot. Claimable balances of native assets are ignored.
+func (s AssetStatSet) AddClaimableBalance(cBalance xdr.ClaimableBalanceEntry) error {
+	if cBalance.Asset.Type == xdr.AssetTypeAssetTypeNative {
 		return nil
 	}
+	return s.addDelta(cBalance.Asset, claimableBalanceDelta(cBalance, 1))
+}
+
+// AddTrustLineChange adds the difference between the pre and post state of a
+// trustline to the set. pre is nil when the trustline was created and post is
+// nil when it was removed.
+func (s AssetStatSet) AddTrustLineChange(pre, post *xdr.TrustLineEntry) error {
+	if pre == nil && post == nil {
+		return errors.New("both pre and post trustlines cannot be nil")
+	}
 
-	return s.AddDelta(trustLine.Asset, int64(trustLine.Balance), 1)
+	var asset xdr.Asset
+	delta := newAssetStatValue()
+	if pre != nil {
+		asset = pre.Asset
+		delta.add(trustLineDelta(*pre, -1))
+	}
+	if post != nil {
+		asset = post.Asset
+		delta.add(trustLineDelta(*post, 1))
+	}
+	return s.addDelta(asset, delta)
 }
 
-// AddDelta adds a delta balance and delta accounts to a given asset.
-func (s AssetStatSet) AddDelta(asset xdr.Asset, deltaBalance int64, deltaAccounts int32) error {
-	if deltaBalance == 0 && deltaAccounts == 0 {
+// AddClaimableBalanceChange adds the difference between the pre and post state
+// of a claimable balance to the set. Claimable balances of native assets are
+// ignored.
+func (s AssetStatSet) AddClaimableBalanceChange(pre, post *xdr.ClaimableBalanceEntry) error {
+	if pre == nil && post == nil {
+		return errors.New("both pre and post claimable balances cannot be nil")
+	}
+
+	var asset xdr.Asset
+	delta := newAssetStatValue()
+	if pre != nil {
+		asset = pre.Asset
+		delta.add(claimableBalanceDelta(*pre, -1))
+	}
+	if post != nil {
+		asset = post.Asset
+		delta.add(claimableBalanceDelta(*post, 1))
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return nil
+	}
+	return s.addDelta(asset, delta)
+}
+
+// addDelta adds delta to the stats of a given asset.
+func (s AssetStatSet) addDelta(asset xdr.Asset, delta *assetStatValue) error {
+	if delta.isZero() {
 		return nil
 	}
 
@@ -44,20 +249,15 @@ func (s AssetStatSet) AddDelta(asset xdr.Asset, deltaBalance int64, deltaAccount
 
 	current, ok := s[key]
 	if !ok {
-		s[key] = &assetStatValue{
-			amount:      big.NewInt(int64(deltaBalance)),
-			numAccounts: deltaAccounts,
-		}
-	} else {
-		current.amount.Add(current.amount, big.NewInt(int64(deltaBalance)))
-		current.numAccounts += deltaAccounts
-		// Note: it's possible that after operations above:
-		// numAccounts != 0 && amount == 0 (ex. two accounts send some of their assets to third account)
-		//  OR
-		// numAccounts == 0 && amount != 0 (ex. issuer issued an asset)
-		if current.numAccounts == 0 && current.amount.Cmp(big.NewInt(0)) == 0 {
-			delete(s, key)
-		}
+		current = newAssetStatValue()
+		s[key] = current
+	}
+	current.add(delta)
+	// Note: it's possible that after operations above the accounts are non-zero
+	// while the amounts are zero (ex. two accounts send some of their assets to
+	// third account) or the other way around (ex. issuer issued an asset).
+	if current.isZero() {
+		delete(s, key)
 	}
 
 	return nil
@@ -73,26 +273,14 @@ func (s AssetStatSet) Remove(assetType xdr.AssetType, assetCode string, assetIss
 
 	delete(s, key)
 
-	return history.ExpAssetStat{
-		AssetType:   key.assetType,
-		AssetCode:   key.assetCode,
-		AssetIssuer: key.assetIssuer,
-		Amount:      value.amount.String(),
-		NumAccounts: value.numAccounts,
-	}, true
+	return value.toHistory(key), true
 }
 
 // All returns a list of all `history.ExpAssetStat` contained within the set
 func (s AssetStatSet) All() []history.ExpAssetStat {
 	assetStats := make([]history.ExpAssetStat, 0, len(s))
 	for key, value := range s {
-		assetStats = append(assetStats, history.ExpAssetStat{
-			AssetType:   key.assetType,
-			AssetCode:   key.assetCode,
-			AssetIssuer: key.assetIssuer,
-			Amount:      value.amount.String(),
-			NumAccounts: value.numAccounts,
-		})
+		assetStats = append(assetStats, value.toHistory(key))
 	}
 	return assetStats
 }
@@ -25,6 +25,44 @@ func TestEmptyAssetStatSet(t *testing.T) {
 	}
 }
 
+// newAssetStat returns the EUR or USD asset stat of trustLineIssuer with the
+// given accounts, balances and liabilities. Empty amounts are set to "0".
+func newAssetStat(
+	code string,
+	accounts history.ExpAssetStatAccounts,
+	balances history.ExpAssetStatBalances,
+	liabilities history.ExpAssetStatLiabilities,
+) history.ExpAssetStat {
+	for _, amount := range []*string{
+		&balances.Authorized,
+		&balances.AuthorizedToMaintainLiabilities,
+		&balances.Unauthorized,
+		&balances.ClaimableBalances,
+		&liabilities.Buying,
+		&liabilities.Selling,
+	} {
+		if *amount == "" {
+			*amount = "0"
+		}
+	}
+
+	assetType := xdr.AssetTypeAssetTypeCreditAlphanum4
+	if len(code) > 4 {
+		assetType = xdr.AssetTypeAssetTypeCreditAlphanum12
+	}
+
+	return history.ExpAssetStat{
+		AssetType:   assetType,
+		AssetCode:   code,
+		AssetIssuer: trustLineIssuer.Address(),
+		Accounts:    accounts,
+		Balances:    balances,
+		Liabilities: liabilities,
+		Amount:      balances.Authorized,
+		NumAccounts: accounts.Authorized,
+	}
+}
+
 func assertAllEquals(t *testing.T, set AssetStatSet, expected []history.ExpAssetStat) {
 	all := set.All()
 	if len(all) != len(expected) {
@@ -40,31 +78,131 @@ func assertAllEquals(t *testing.T, set AssetStatSet, expected []history.ExpAsset
 	}
 }
 
-func TestAssetStatSetIgnoresUnauthorizedTrustlines(t *testing.T) {
+func TestAssetStatSetAuthorizationStates(t *testing.T) {
 	set := AssetStatSet{}
-	err := set.Add(xdr.TrustLineEntry{
+	for _, trustLine := range []xdr.TrustLineEntry{
+		{
+			AccountId: xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
+			Asset:     xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+			Balance:   1,
+		},
+		{
+			AccountId: xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"),
+			Asset:     xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+			Balance:   2,
+			Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag),
+			Ext: xdr.TrustLineEntryExt{
+				V: 1,
+				V1: &xdr.TrustLineEntryV1{
+					Liabilities: xdr.Liabilities{Buying: 3, Selling: 1},
+				},
+			},
+		},
+		{
+			AccountId: xdr.MustAddress("GCYLTPOU7IVYHHA3XKQF4YB4W4ZWHFERMOQ7K47IWANKNBFBNJJNEOG5"),
+			Asset:     xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+			Balance:   4,
+			Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		},
+	} {
+		if err := set.Add(trustLine); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	err := set.AddClaimableBalance(xdr.ClaimableBalanceEntry{
+		Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+		Amount: 8,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	err = set.AddClaimableBalance(xdr.ClaimableBalanceEntry{
+		Asset:  xdr.MustNewNativeAsset(),
+		Amount: 16,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	assertAllEquals(t, set, []history.ExpAssetStat{
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{
+				Authorized:                      1,
+				AuthorizedToMaintainLiabilities: 1,
+				Unauthorized:                    1,
+				ClaimableBalances:               1,
+			},
+			history.ExpAssetStatBalances{
+				Authorized:                      "4",
+				AuthorizedToMaintainLiabilities: "2",
+				Unauthorized:                    "1",
+				ClaimableBalances:               "8",
+			},
+			history.ExpAssetStatLiabilities{Buying: "3", Selling: "1"},
+		),
+	})
+}
+
+func TestAssetStatSetChanges(t *testing.T) {
+	set := AssetStatSet{}
+	trustLine := xdr.TrustLineEntry{
 		AccountId: xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
 		Asset:     xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
-		Balance:   1,
+		Balance:   10,
+		Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+	}
+	revoked := trustLine
+	revoked.Flags = 0
+
+	if err := set.AddTrustLineChange(&trustLine, &revoked); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertAllEquals(t, set, []history.ExpAssetStat{
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{Authorized: -1, Unauthorized: 1},
+			history.ExpAssetStatBalances{Authorized: "-10", Unauthorized: "10"},
+			history.ExpAssetStatLiabilities{},
+		),
 	})
-	if err != nil {
+
+	// the changes cancel each other out so the asset stat is removed
+	if err := set.AddTrustLineChange(&revoked, &trustLine); err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	if all := set.All(); len(all) != 0 {
-		t.Fatalf("expected empty list but got %v", all)
+	assertAllEquals(t, set, []history.ExpAssetStat{})
+
+	cBalance := xdr.ClaimableBalanceEntry{
+		Asset:  xdr.MustNewCreditAsset("EUR", trustLineIssuer.Address()),
+		Amount: 5,
+	}
+	if err := set.AddClaimableBalanceChange(&cBalance, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertAllEquals(t, set, []history.ExpAssetStat{
+		newAssetStat("EUR",
+			history.ExpAssetStatAccounts{ClaimableBalances: -1},
+			history.ExpAssetStatBalances{ClaimableBalances: "-5"},
+			history.ExpAssetStatLiabilities{},
+		),
+	})
+
+	if err := set.AddTrustLineChange(nil, nil); err == nil {
+		t.Fatal("expected error when both pre and post trustlines are nil")
+	}
+	if err := set.AddClaimableBalanceChange(nil, nil); err == nil {
+		t.Fatal("expected error when both pre and post claimable balances are nil")
 	}
 }
 
 func TestAddAndRemoveAssetStats(t *testing.T) {
 	set := AssetStatSet{}
 	eur := "EUR"
-	eurAssetStat := history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetCode:   eur,
-		AssetIssuer: trustLineIssuer.Address(),
-		Amount:      "1",
-		NumAccounts: 1,
-	}
+	eurAssetStat := newAssetStat(eur,
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "1"},
+		history.ExpAssetStatLiabilities{},
+	)
 
 	err := set.Add(xdr.TrustLineEntry{
 		AccountId: xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
@@ -87,8 +225,11 @@ func TestAddAndRemoveAssetStats(t *testing.T) {
 		t.Fatalf("unexpected error %v", err)
 	}
 
-	eurAssetStat.Amount = "25"
-	eurAssetStat.NumAccounts++
+	eurAssetStat = newAssetStat(eur,
+		history.ExpAssetStatAccounts{Authorized: 2},
+		history.ExpAssetStatBalances{Authorized: "25"},
+		history.ExpAssetStatLiabilities{},
+	)
 	assertAllEquals(t, set, []history.ExpAssetStat{eurAssetStat})
 
 	usd := "USD"
@@ -114,21 +255,17 @@ func TestAddAndRemoveAssetStats(t *testing.T) {
 	}
 
 	expected := []history.ExpAssetStat{
-		history.ExpAssetStat{
-			AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum12,
-			AssetCode:   ether,
-			AssetIssuer: trustLineIssuer.Address(),
-			Amount:      "3",
-			NumAccounts: 1,
-		},
+		newAssetStat(ether,
+			history.ExpAssetStatAccounts{Authorized: 1},
+			history.ExpAssetStatBalances{Authorized: "3"},
+			history.ExpAssetStatLiabilities{},
+		),
 		eurAssetStat,
-		history.ExpAssetStat{
-			AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-			AssetCode:   usd,
-			AssetIssuer: trustLineIssuer.Address(),
-			Amount:      "10",
-			NumAccounts: 1,
-		},
+		newAssetStat(usd,
+			history.ExpAssetStatAccounts{Authorized: 1},
+			history.ExpAssetStatBalances{Authorized: "10"},
+			history.ExpAssetStatLiabilities{},
+		),
 	}
 	assertAllEquals(t, set, expected)
 
@@ -162,13 +299,11 @@ func TestOverflowAssetStatSet(t *testing.T) {
 		t.Fatalf("expected list of 1 asset stat but got %v", all)
 	}
 
-	eurAssetStat := history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetCode:   eur,
-		AssetIssuer: trustLineIssuer.Address(),
-		Amount:      "9223372036854775807",
-		NumAccounts: 1,
-	}
+	eurAssetStat := newAssetStat(eur,
+		history.ExpAssetStatAccounts{Authorized: 1},
+		history.ExpAssetStatBalances{Authorized: "9223372036854775807"},
+		history.ExpAssetStatLiabilities{},
+	)
 	if all[0] != eurAssetStat {
 		t.Fatalf("expected asset stat to be %v but got %v", eurAssetStat, all[0])
 	}
@@ -187,13 +322,11 @@ func TestOverflowAssetStatSet(t *testing.T) {
 		t.Fatalf("expected list of 1 asset stat but got %v", all)
 	}
 
-	eurAssetStat = history.ExpAssetStat{
-		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
-		AssetCode:   eur,
-		AssetIssuer: trustLineIssuer.Address(),
-		Amount:      "18446744073709551614",
-		NumAccounts: 2,
-	}
+	eurAssetStat = newAssetStat(eur,
+		history.ExpAssetStatAccounts{Authorized: 2},
+		history.ExpAssetStatBalances{Authorized: "18446744073709551614"},
+		history.ExpAssetStatLiabilities{},
+	)
 	if all[0] != eurAssetStat {
 		t.Fatalf("expected asset stat to be %v but got %v", eurAssetStat, all[0])
 	}
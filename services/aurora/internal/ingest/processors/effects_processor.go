@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 
+	"github.com/guregu/null"
 	"github.com/hcnet/go/amount"
 	"github.com/hcnet/go/ingest/io"
 	"github.com/hcnet/go/keypair"
@@ -92,6 +93,7 @@ func (p *EffectProcessor) insertDBOperationsEffects(effects []effect, accountSet
 
 		if err := batch.Add(
 			accountID,
+			effect.addressMuxed,
 			effect.operationID,
 			effect.order,
 			effect.effectType,
@@ -144,11 +146,12 @@ func (p *EffectProcessor) Commit() (err error) {
 }
 
 type effect struct {
-	address     string
-	operationID int64
-	details     map[string]interface{}
-	effectType  history.EffectType
-	order       uint32
+	address      string
+	addressMuxed null.String
+	operationID  int64
+	details      map[string]interface{}
+	effectType   history.EffectType
+	order        uint32
 }
 
 // Effects returns the operation effects
@@ -237,6 +240,18 @@ func (e *effectsWrapper) add(address string, effectType history.EffectType, deta
 	})
 }
 
+func (e *effectsWrapper) addMuxed(account xdr.MuxedAccount, effectType history.EffectType, details map[string]interface{}) {
+	aid := account.ToAccountId()
+	e.effects = append(e.effects, effect{
+		address:      aid.Address(),
+		addressMuxed: history.MuxedAddress(account),
+		operationID:  e.operation.ID(),
+		effectType:   effectType,
+		order:        uint32(len(e.effects) + 1),
+		details:      details,
+	})
+}
+
 var sponsoringEffectsTable = map[xdr.LedgerEntryType]struct {
 	created, updated, removed history.EffectType
 }{
@@ -427,14 +442,13 @@ func (e *effectsWrapper) addPaymentEffects() {
 	details := map[string]interface{}{"amount": amount.String(op.Amount)}
 	addAssetDetails(details, op.Asset, "")
 
-	aid := op.Destination.ToAccountId()
-	e.add(
-		aid.Address(),
+	e.addMuxed(
+		op.Destination,
 		history.EffectAccountCredited,
 		details,
 	)
-	e.add(
-		e.operation.SourceAccount().Address(),
+	e.addMuxed(
+		e.operation.MuxedSourceAccount(),
 		history.EffectAccountDebited,
 		details,
 	)
@@ -448,9 +462,8 @@ func (e *effectsWrapper) pathPaymentStrictReceiveEffects() {
 	details := map[string]interface{}{"amount": amount.String(op.DestAmount)}
 	addAssetDetails(details, op.DestAsset, "")
 
-	aid := op.Destination.ToAccountId()
-	e.add(
-		aid.Address(),
+	e.addMuxed(
+		op.Destination,
 		history.EffectAccountCredited,
 		details,
 	)
@@ -459,8 +472,8 @@ func (e *effectsWrapper) pathPaymentStrictReceiveEffects() {
 	details = map[string]interface{}{"amount": amount.String(result.SendAmount())}
 	addAssetDetails(details, op.SendAsset, "")
 
-	e.add(
-		source.Address(),
+	e.addMuxed(
+		e.operation.MuxedSourceAccount(),
 		history.EffectAccountDebited,
 		details,
 	)
@@ -476,12 +489,11 @@ func (e *effectsWrapper) addPathPaymentStrictSendEffects() {
 
 	details := map[string]interface{}{"amount": amount.String(result.DestAmount())}
 	addAssetDetails(details, op.DestAsset, "")
-	aid := op.Destination.ToAccountId()
-	e.add(aid.Address(), history.EffectAccountCredited, details)
+	e.addMuxed(op.Destination, history.EffectAccountCredited, details)
 
 	details = map[string]interface{}{"amount": amount.String(op.SendAmount)}
 	addAssetDetails(details, op.SendAsset, "")
-	e.add(source.Address(), history.EffectAccountDebited, details)
+	e.addMuxed(e.operation.MuxedSourceAccount(), history.EffectAccountDebited, details)
 
 	e.addIngestTradeEffects(*source, resultSuccess.Offers)
 }
@@ -692,7 +704,7 @@ func (e *effectsWrapper) addAllowTrustEffects() {
 }
 
 func (e *effectsWrapper) addAccountMergeEffects() {
-	source := e.operation.SourceAccount()
+	source := e.operation.MuxedSourceAccount()
 
 	dest := e.operation.operation.Body.MustDestination()
 	result := e.operation.OperationResult().MustAccountMergeResult()
@@ -701,10 +713,9 @@ func (e *effectsWrapper) addAccountMergeEffects() {
 		"asset_type": "native",
 	}
 
-	e.add(source.Address(), history.EffectAccountDebited, details)
-	aid := dest.ToAccountId()
-	e.add(aid.Address(), history.EffectAccountCredited, details)
-	e.add(source.Address(), history.EffectAccountRemoved, map[string]interface{}{})
+	e.addMuxed(source, history.EffectAccountDebited, details)
+	e.addMuxed(dest, history.EffectAccountCredited, details)
+	e.addMuxed(source, history.EffectAccountRemoved, map[string]interface{}{})
 }
 
 func (e *effectsWrapper) addInflationEffects() {
@@ -4,6 +4,7 @@ package processors
 import (
 	"testing"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -131,6 +132,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[2]],
+		null.String{},
 		toid.New(int32(s.sequence), 1, 1).ToInt64(),
 		uint32(1),
 		history.EffectSequenceBumped,
@@ -139,6 +141,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[2]],
+		null.String{},
 		toid.New(int32(s.sequence), 2, 1).ToInt64(),
 		uint32(1),
 		history.EffectAccountCreated,
@@ -147,6 +150,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[1]],
+		null.String{},
 		toid.New(int32(s.sequence), 2, 1).ToInt64(),
 		uint32(2),
 		history.EffectAccountDebited,
@@ -155,6 +159,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[2]],
+		null.String{},
 		toid.New(int32(s.sequence), 2, 1).ToInt64(),
 		uint32(3),
 		history.EffectSignerCreated,
@@ -164,6 +169,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[0]],
+		null.String{},
 		toid.New(int32(s.sequence), 3, 1).ToInt64(),
 		uint32(1),
 		history.EffectAccountCredited,
@@ -173,6 +179,7 @@ func (s *EffectsProcessorTestSuiteLedger) mockSuccessfulEffectBatchAdds() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[0]],
+		null.String{},
 		toid.New(int32(s.sequence), 3, 1).ToInt64(),
 		uint32(2),
 		history.EffectAccountDebited,
@@ -233,6 +240,7 @@ func (s *EffectsProcessorTestSuiteLedger) TestBatchAddFails() {
 	s.mockBatchInsertBuilder.On(
 		"Add",
 		s.addressToID[s.addresses[2]],
+		null.String{},
 		toid.New(int32(s.sequence), 1, 1).ToInt64(),
 		uint32(1),
 		history.EffectSequenceBumped,
@@ -878,7 +886,8 @@ func TestOperationEffects(t *testing.T) {
 			sequence:      20,
 			expected: []effect{
 				{
-					address: "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
+					address:      "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
+					addressMuxed: null.StringFrom(dest.Address()),
 					details: map[string]interface{}{
 						"amount":       "1.0000000",
 						"asset_code":   "ARS",
@@ -890,7 +899,8 @@ func TestOperationEffects(t *testing.T) {
 					order:       uint32(1),
 				},
 				{
-					address: "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
+					address:      "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
+					addressMuxed: null.StringFrom(sourceAccount.Address()),
 					details: map[string]interface{}{
 						"amount":       "0.0300000",
 						"asset_code":   "BRL",
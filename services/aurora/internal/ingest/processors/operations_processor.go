@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/hcnet/go/amount"
 	"github.com/hcnet/go/ingest/io"
@@ -55,6 +56,7 @@ func (p *OperationProcessor) ProcessTransaction(transaction io.LedgerTransaction
 			operation.OperationType(),
 			detailsJSON,
 			operation.SourceAccount().Address(),
+			history.MuxedAddress(operation.MuxedSourceAccount()),
 		); err != nil {
 			return errors.Wrap(err, "Error batch inserting operation rows")
 		}
@@ -107,6 +109,15 @@ func (operation *transactionOperationWrapper) SourceAccount() *xdr.AccountId {
 	return &sa
 }
 
+// MuxedSourceAccount returns the operation's source account without
+// dropping the muxed account id.
+func (operation *transactionOperationWrapper) MuxedSourceAccount() xdr.MuxedAccount {
+	if sourceAccount := operation.operation.SourceAccount; sourceAccount != nil {
+		return *sourceAccount
+	}
+	return operation.transaction.Envelope.SourceAccount()
+}
+
 // OperationType returns the operation type.
 func (operation *transactionOperationWrapper) OperationType() xdr.OperationType {
 	return operation.operation.Body.Type
@@ -219,6 +230,8 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		details["from"] = source.Address()
 		accid := op.Destination.ToAccountId()
 		details["to"] = accid.Address()
+		addMuxedAccountDetails(details, operation.MuxedSourceAccount(), "from_")
+		addMuxedAccountDetails(details, op.Destination, "to_")
 		details["amount"] = amount.String(op.Amount)
 		addAssetDetails(details, op.Asset, "")
 	case xdr.OperationTypePathPaymentStrictReceive:
@@ -226,6 +239,8 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		details["from"] = source.Address()
 		accid := op.Destination.ToAccountId()
 		details["to"] = accid.Address()
+		addMuxedAccountDetails(details, operation.MuxedSourceAccount(), "from_")
+		addMuxedAccountDetails(details, op.Destination, "to_")
 
 		details["amount"] = amount.String(op.DestAmount)
 		details["source_amount"] = amount.String(0)
@@ -250,6 +265,8 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		details["from"] = source.Address()
 		accid := op.Destination.ToAccountId()
 		details["to"] = accid.Address()
+		addMuxedAccountDetails(details, operation.MuxedSourceAccount(), "from_")
+		addMuxedAccountDetails(details, op.Destination, "to_")
 
 		details["amount"] = amount.String(0)
 		details["source_amount"] = amount.String(op.SendAmount)
@@ -358,6 +375,8 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		aid := operation.operation.Body.MustDestination().ToAccountId()
 		details["account"] = source.Address()
 		details["into"] = aid.Address()
+		addMuxedAccountDetails(details, operation.MuxedSourceAccount(), "account_")
+		addMuxedAccountDetails(details, operation.operation.Body.MustDestination(), "into_")
 	case xdr.OperationTypeInflation:
 		// no inflation details, presently
 	case xdr.OperationTypeManageData:
@@ -426,6 +445,17 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 	return details, nil
 }
 
+// addMuxedAccountDetails sets the M... address and the id of `account` on
+// `result` using keys with `prefix` when `account` is a muxed account.
+func addMuxedAccountDetails(result map[string]interface{}, account xdr.MuxedAccount, prefix string) {
+	id, ok := account.ID()
+	if !ok {
+		return
+	}
+	result[prefix+"muxed"] = account.Address()
+	result[prefix+"muxed_id"] = strconv.FormatUint(id, 10)
+}
+
 // addAssetDetails sets the details for `a` on `result` using keys with `prefix`
 func addAssetDetails(result map[string]interface{}, a xdr.Asset, prefix string) error {
 	var (
@@ -10,6 +10,7 @@ import (
 	"github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/xdr"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -69,6 +70,7 @@ func (s *OperationsProcessorTestSuiteLedger) mockBatchInsertAdds(txs []io.Ledger
 				expected.OperationType(),
 				detailsJSON,
 				expected.SourceAccount().Address(),
+				history.MuxedAddress(expected.MuxedSourceAccount()),
 			).Return(nil).Once()
 		}
 	}
@@ -130,6 +132,7 @@ func (s *OperationsProcessorTestSuiteLedger) TestAddOperationFails() {
 			mock.Anything,
 			mock.Anything,
 			mock.Anything,
+			mock.Anything,
 		).Return(errors.New("transient error")).Once()
 
 	err := s.processor.ProcessTransaction(tx)
@@ -143,3 +146,34 @@ func (s *OperationsProcessorTestSuiteLedger) TestExecFails() {
 	s.Assert().Error(err)
 	s.Assert().EqualError(err, "transient error")
 }
+
+func TestOperationDetailsMuxedAccounts(t *testing.T) {
+	source := xdr.MustMuxedAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+	tx := createTransaction(true, 1)
+	tx.Envelope.V1.Tx.SourceAccount = source
+	tx.Envelope.Operations()[0].Body = xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: xdr.MustMuxedAddress("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"),
+			Asset:       xdr.Asset{Type: xdr.AssetTypeAssetTypeNative},
+			Amount:      100,
+		},
+	}
+
+	operation := transactionOperationWrapper{
+		index:          0,
+		transaction:    tx,
+		operation:      tx.Envelope.Operations()[0],
+		ledgerSequence: 56,
+	}
+	details, err := operation.Details()
+	assert.NoError(t, err)
+	assert.Equal(t, "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ", details["from"])
+	assert.Equal(t, "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK", details["from_muxed"])
+	assert.Equal(t, "9223372036854775808", details["from_muxed_id"])
+	assert.Equal(t, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", details["to"])
+	assert.NotContains(t, details, "to_muxed")
+	assert.NotContains(t, details, "to_muxed_id")
+
+	assert.Equal(t, "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK", history.MuxedAddress(operation.MuxedSourceAccount()).String)
+}
@@ -0,0 +1,131 @@
+package processors
+
+import (
+	"github.com/hcnet/go/ingest/io"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// TransactionFilter selects the transactions stored in history tables by the
+// accounts participating in them or the assets they involve.
+type TransactionFilter struct {
+	accounts map[string]bool
+	assets   map[string]bool
+}
+
+// NewTransactionFilter returns a filter matching transactions with one of
+// accounts as participant or involving one of assets.
+func NewTransactionFilter(accounts []string, assets []xdr.Asset) *TransactionFilter {
+	f := &TransactionFilter{
+		accounts: map[string]bool{},
+		assets:   map[string]bool{},
+	}
+	for _, account := range accounts {
+		f.accounts[account] = true
+	}
+	for _, asset := range assets {
+		f.assets[asset.String()] = true
+	}
+	return f
+}
+
+// Matches returns true when transaction should be stored. An asset is
+// involved in a transaction when one of its operations refers to it or when
+// the transaction changes a trust line, offer or claimable balance of the
+// asset, for example when a path payment crosses offers.
+func (f *TransactionFilter) Matches(sequence uint32, transaction io.LedgerTransaction) (bool, error) {
+	if len(f.accounts) > 0 {
+		participants, err := participantsForTransaction(sequence, transaction)
+		if err != nil {
+			return false, errors.Wrap(err, "could not determine transaction participants")
+		}
+		for _, participant := range participants {
+			if f.accounts[participant.Address()] {
+				return true, nil
+			}
+		}
+	}
+
+	if len(f.assets) == 0 {
+		return false, nil
+	}
+
+	txSource := transaction.Envelope.SourceAccount().ToAccountId()
+	for _, op := range transaction.Envelope.Operations() {
+		source := txSource
+		if op.SourceAccount != nil {
+			source = op.SourceAccount.ToAccountId()
+		}
+		if f.matchesAssets(operationAssets(source, op)) {
+			return true, nil
+		}
+	}
+
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return false, errors.Wrap(err, "could not read transaction changes")
+	}
+	for _, change := range changes {
+		for _, entry := range []*xdr.LedgerEntry{change.Pre, change.Post} {
+			if entry != nil && f.matchesAssets(ledgerEntryAssets(*entry)) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (f *TransactionFilter) matchesAssets(assets []xdr.Asset) bool {
+	for _, asset := range assets {
+		if f.assets[asset.String()] {
+			return true
+		}
+	}
+	return false
+}
+
+func operationAssets(source xdr.AccountId, op xdr.Operation) []xdr.Asset {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount, xdr.OperationTypeAccountMerge:
+		return []xdr.Asset{xdr.MustNewNativeAsset()}
+	case xdr.OperationTypePayment:
+		return []xdr.Asset{op.Body.MustPaymentOp().Asset}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		body := op.Body.MustPathPaymentStrictReceiveOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypePathPaymentStrictSend:
+		body := op.Body.MustPathPaymentStrictSendOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypeManageSellOffer:
+		body := op.Body.MustManageSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeManageBuyOffer:
+		body := op.Body.MustManageBuyOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		body := op.Body.MustCreatePassiveSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeChangeTrust:
+		return []xdr.Asset{op.Body.MustChangeTrustOp().Line}
+	case xdr.OperationTypeAllowTrust:
+		return []xdr.Asset{op.Body.MustAllowTrustOp().Asset.ToAsset(source)}
+	case xdr.OperationTypeCreateClaimableBalance:
+		return []xdr.Asset{op.Body.MustCreateClaimableBalanceOp().Asset}
+	default:
+		return nil
+	}
+}
+
+func ledgerEntryAssets(entry xdr.LedgerEntry) []xdr.Asset {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeTrustline:
+		return []xdr.Asset{entry.Data.MustTrustLine().Asset}
+	case xdr.LedgerEntryTypeOffer:
+		offer := entry.Data.MustOffer()
+		return []xdr.Asset{offer.Selling, offer.Buying}
+	case xdr.LedgerEntryTypeClaimableBalance:
+		return []xdr.Asset{entry.Data.MustClaimableBalance().Asset}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package processors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hcnet/go/xdr"
+)
+
+func TestTransactionFilter(t *testing.T) {
+	source := "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	other := "GACMZD5VJXTRLKVET72CETCYKELPNCOTTBDC6DHFEUPLG5DHEK534JQX"
+	issuer := xdr.MustAddress(other)
+	usd := xdr.MustNewCreditAsset("USD", other)
+	eur := xdr.MustNewCreditAsset("EUR", other)
+
+	payment := createTransaction(true, 1)
+	payment.Envelope.V1.Tx.Operations[0].Body = xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: issuer.ToMuxedAccount(),
+			Asset:       usd,
+			Amount:      100,
+		},
+	}
+
+	trustLineChange := createTransaction(true, 1)
+	trustLineChange.Meta.V2.Operations[0].Changes = xdr.LedgerEntryChanges{
+		{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+			Created: &xdr.LedgerEntry{
+				Data: xdr.LedgerEntryData{
+					Type: xdr.LedgerEntryTypeTrustline,
+					TrustLine: &xdr.TrustLineEntry{
+						AccountId: xdr.MustAddress(source),
+						Asset:     eur,
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		filter   *TransactionFilter
+		expected bool
+	}{
+		{
+			name:     "source account",
+			filter:   NewTransactionFilter([]string{source}, nil),
+			expected: true,
+		},
+		{
+			name:     "other account",
+			filter:   NewTransactionFilter([]string{"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"}, nil),
+			expected: false,
+		},
+		{
+			name:     "payment destination",
+			filter:   NewTransactionFilter([]string{other}, nil),
+			expected: true,
+		},
+		{
+			name:     "payment asset",
+			filter:   NewTransactionFilter(nil, []xdr.Asset{usd}),
+			expected: true,
+		},
+		{
+			name:     "other asset",
+			filter:   NewTransactionFilter(nil, []xdr.Asset{eur}),
+			expected: false,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			matches, err := testCase.filter.Matches(20, payment)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, matches)
+		})
+	}
+
+	t.Run("trust line change", func(t *testing.T) {
+		matches, err := NewTransactionFilter(nil, []xdr.Asset{eur}).Matches(20, trustLineChange)
+		assert.NoError(t, err)
+		assert.True(t, matches)
+
+		matches, err = NewTransactionFilter(nil, []xdr.Asset{usd}).Matches(20, trustLineChange)
+		assert.NoError(t, err)
+		assert.False(t, matches)
+	})
+}
+
+func TestOperationAssets(t *testing.T) {
+	source := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	other := xdr.MustAddress("GACMZD5VJXTRLKVET72CETCYKELPNCOTTBDC6DHFEUPLG5DHEK534JQX")
+	native := xdr.MustNewNativeAsset()
+	usd := xdr.MustNewCreditAsset("USD", other.Address())
+	destination := other.ToMuxedAccount()
+
+	for _, testCase := range []struct {
+		name     string
+		body     xdr.OperationBody
+		expected []xdr.Asset
+	}{
+		{
+			name: "create account",
+			body: xdr.OperationBody{
+				Type: xdr.OperationTypeCreateAccount,
+				CreateAccountOp: &xdr.CreateAccountOp{
+					Destination:     other,
+					StartingBalance: 100,
+				},
+			},
+			expected: []xdr.Asset{native},
+		},
+		{
+			name: "account merge",
+			body: xdr.OperationBody{
+				Type:        xdr.OperationTypeAccountMerge,
+				Destination: &destination,
+			},
+			expected: []xdr.Asset{native},
+		},
+		{
+			name: "payment",
+			body: xdr.OperationBody{
+				Type: xdr.OperationTypePayment,
+				PaymentOp: &xdr.PaymentOp{
+					Destination: other.ToMuxedAccount(),
+					Asset:       usd,
+					Amount:      100,
+				},
+			},
+			expected: []xdr.Asset{usd},
+		},
+		{
+			name:     "bump sequence",
+			body:     xdr.OperationBody{Type: xdr.OperationTypeBumpSequence},
+			expected: nil,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			assets := operationAssets(source, xdr.Operation{Body: testCase.body})
+			assert.Equal(t, testCase.expected, assets)
+		})
+	}
+}
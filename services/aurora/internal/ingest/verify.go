@@ -27,7 +27,7 @@ const assetStatsBatchSize = 500
 // check them.
 // There is a test that checks it, to fix it: update the actual `verifyState`
 // method instead of just updating this value!
-const stateVerifierExpectedIngestionVersion = 12
+const stateVerifierExpectedIngestionVersion = 13
 
 // verifyState is called as a go routine from pipeline post hook every 64
 // ledgers. It checks if the state is correct. If another go routine is already
@@ -578,6 +578,11 @@ func addClaimableBalanceToStateVerifier(
 		if err := verifier.Write(entry); err != nil {
 			return err
 		}
+		if err := assetStats.AddClaimableBalance(cBalance); err != nil {
+			return ingesterrors.NewStateError(
+				errors.Wrap(err, "could not add claimable balance to asset stats"),
+			)
+		}
 	}
 
 	return nil
@@ -6,9 +6,10 @@ import (
 	"runtime"
 
 	"github.com/getsentry/raven-go"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/hcnet/go/exp/orderbook"
+	"github.com/hcnet/go/services/aurora/internal/cache"
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/services/aurora/internal/httpx"
 	"github.com/hcnet/go/services/aurora/internal/ingest"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/simplepath"
@@ -16,6 +17,10 @@ import (
 	"github.com/hcnet/go/services/aurora/internal/txsub/sequence"
 	"github.com/hcnet/go/support/db"
 	"github.com/hcnet/go/support/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	// Enable sqlite, used in lite mode
+	_ "github.com/hcnet/go/support/db/sqlite"
 )
 
 func mustNewDBSession(databaseURL string, maxIdle, maxOpen int) *db.Session {
@@ -29,6 +34,36 @@ func mustNewDBSession(databaseURL string, maxIdle, maxOpen int) *db.Session {
 	return session
 }
 
+// sqliteDSN returns the DSN of the SQLite database file at path. Connections
+// wait for locks instead of failing and the WAL journal lets requests read
+// while ingestion writes. Transactions of the ingestion session take the write
+// lock when they begin, which replaces the SELECT ... FOR UPDATE of the last
+// ingested ledger used on postgres.
+func sqliteDSN(path string, ingestion bool) string {
+	dsn := path + "?_busy_timeout=5000&_journal_mode=WAL"
+	if ingestion {
+		dsn += "&_txlock=immediate"
+	}
+	return dsn
+}
+
+// mustNewAuroraDBSession opens a session of the aurora database, which is a
+// SQLite database file in lite mode.
+func mustNewAuroraDBSession(config Config, ingestion bool, maxIdle, maxOpen int) *db.Session {
+	if !config.Lite {
+		return mustNewDBSession(config.DatabaseURL, maxIdle, maxOpen)
+	}
+
+	session, err := db.Open("sqlite3", sqliteDSN(config.DatabaseURL, ingestion))
+	if err != nil {
+		log.Fatalf("cannot open Aurora DB: %v", err)
+	}
+
+	session.DB.SetMaxIdleConns(maxIdle)
+	session.DB.SetMaxOpenConns(maxOpen)
+	return session
+}
+
 func mustInitAuroraDB(app *App) {
 	maxIdle := app.config.AuroraDBMaxIdleConnections
 	maxOpen := app.config.AuroraDBMaxOpenConnections
@@ -43,11 +78,22 @@ func mustInitAuroraDB(app *App) {
 		}
 	}
 
-	app.historyQ = &history.Q{mustNewDBSession(
-		app.config.DatabaseURL,
+	app.historyQ = &history.Q{mustNewAuroraDBSession(
+		app.config,
+		false,
 		maxIdle,
 		maxOpen,
 	)}
+
+	if app.config.RODatabaseURL != "" {
+		// Ingestion doesn't use the replica so all connections serve
+		// requests.
+		app.replicaQ = &history.Q{mustNewDBSession(
+			app.config.RODatabaseURL,
+			app.config.AuroraDBMaxIdleConnections,
+			app.config.AuroraDBMaxOpenConnections,
+		)}
+	}
 }
 
 func initExpIngester(app *App) {
@@ -58,21 +104,25 @@ func initExpIngester(app *App) {
 	}
 	app.ingester, err = ingest.NewSystem(ingest.Config{
 		CoreSession: coreSession,
-		HistorySession: mustNewDBSession(
-			app.config.DatabaseURL, ingest.MaxDBConnections, ingest.MaxDBConnections,
+		HistorySession: mustNewAuroraDBSession(
+			app.config, true, ingest.MaxDBConnections, ingest.MaxDBConnections,
 		),
 		NetworkPassphrase: app.config.NetworkPassphrase,
 		// TODO:
 		// Use the first archive for now. We don't have a mechanism to
 		// use multiple archives at the same time currently.
-		HistoryArchiveURL:        app.config.HistoryArchiveURLs[0],
-		HcnetCoreURL:           app.config.HcnetCoreURL,
-		HcnetCoreCursor:        app.config.CursorName,
-		HcnetCoreBinaryPath:    app.config.HcnetCoreBinaryPath,
-		HcnetCoreConfigPath:    app.config.HcnetCoreConfigPath,
-		RemoteCaptiveCoreURL:     app.config.RemoteCaptiveCoreURL,
-		EnableCaptiveCore:        app.config.EnableCaptiveCoreIngestion,
-		DisableStateVerification: app.config.IngestDisableStateVerification,
+		HistoryArchiveURL:                 app.config.HistoryArchiveURLs[0],
+		HcnetCoreURL:                      app.config.HcnetCoreURL,
+		HcnetCoreCursor:                   app.config.CursorName,
+		HcnetCoreBinaryPath:               app.config.HcnetCoreBinaryPath,
+		HcnetCoreConfigPath:               app.config.HcnetCoreConfigPath,
+		RemoteCaptiveCoreURL:              app.config.RemoteCaptiveCoreURL,
+		EnableCaptiveCore:                 app.config.EnableCaptiveCoreIngestion,
+		DisableStateVerification:          app.config.IngestDisableStateVerification,
+		HistoryArchivePrefetchDir:         app.config.HistoryArchivePrefetchDir,
+		HistoryArchivePrefetchConcurrency: int(app.config.HistoryArchivePrefetchConcurrency),
+		History:                           app.config.History,
+		Lite:                              app.config.Lite,
 	})
 
 	if err != nil {
@@ -90,6 +140,31 @@ func initPathFinder(app *App) {
 	app.paths = simplepath.NewInMemoryFinder(orderBookGraph)
 }
 
+// initResponseCache creates the cache of immutable history resources, stored
+// in Redis when a Redis URL is configured or in memory otherwise. Cached
+// responses are invalidated by the reaper.
+func initResponseCache(app *App) {
+	var store cache.Store
+	switch {
+	case app.config.RedisURL != "":
+		store = cache.NewRedisStore(app.config.RedisURL, app.config.ResponseCacheMaxAge)
+	case app.config.ResponseCacheSize > 0:
+		lruStore, err := cache.NewLRUStore(int(app.config.ResponseCacheSize))
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = lruStore
+	default:
+		return
+	}
+
+	app.responseCache = &httpx.ResponseCache{
+		Store:  store,
+		MaxAge: app.config.ResponseCacheMaxAge,
+	}
+	app.reaper.Invalidator = store
+}
+
 // initSentry initialized the default sentry client with the configured DSN
 func initSentry(app *App) {
 	if app.config.SentryDSN == "" {
@@ -215,6 +290,19 @@ func initDbMetrics(app *App) {
 	)
 	app.prometheusRegistry.MustRegister(app.dbWaitDurationCounter)
 
+	if app.replicaQ != nil {
+		app.replicaLagGauge = prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "aurora", Subsystem: "db", Name: "replica_lag_ledgers",
+				Help: "number of ledgers the read replica is behind the aurora db",
+			},
+			func() float64 {
+				return float64(ledger.CurrentState().ReplicaLag())
+			},
+		)
+		app.prometheusRegistry.MustRegister(app.replicaLagGauge)
+	}
+
 	app.prometheusRegistry.MustRegister(app.orderBookStream.LatestLedgerGauge)
 }
 
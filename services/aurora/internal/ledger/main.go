@@ -16,6 +16,19 @@ type State struct {
 	HistoryLatest    int32  `db:"history_latest"`
 	HistoryElder     int32  `db:"history_elder"`
 	ExpHistoryLatest uint32 `db:"exp_history_latest"`
+	// ReplicaExpHistoryLatest is the latest ledger ingested according to the
+	// read replica. It's zero when no replica is configured or it could not
+	// be queried.
+	ReplicaExpHistoryLatest uint32 `db:"replica_exp_history_latest"`
+}
+
+// ReplicaLag returns the number of ledgers the read replica is behind the
+// primary database.
+func (s State) ReplicaLag() uint32 {
+	if s.ReplicaExpHistoryLatest >= s.ExpHistoryLatest {
+		return 0
+	}
+	return s.ExpHistoryLatest - s.ReplicaExpHistoryLatest
 }
 
 // CurrentState returns the cached snapshot of ledger state
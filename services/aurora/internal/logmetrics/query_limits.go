@@ -0,0 +1,51 @@
+package logmetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// QueryLimitMetrics is a logrus hook-compliant struct that counts the database
+// queries which exceeded a limit of their session, using the warnings logged
+// by support/db with a query_limit field. The counter is labeled with the
+// limit and the route of the request, when the log entry has a route field.
+type QueryLimitMetrics struct {
+	Counter *prometheus.CounterVec
+}
+
+var DefaultQueryLimitMetrics = NewQueryLimitMetrics()
+
+// NewQueryLimitMetrics creates a new hook for recording query limit metrics.
+func NewQueryLimitMetrics() *QueryLimitMetrics {
+	return &QueryLimitMetrics{
+		Counter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "aurora", Subsystem: "db", Name: "query_limits_exceeded_total",
+				Help: "number of queries which exceeded the statement timeout or the maximum query cost of their route",
+			},
+			[]string{"route", "limit"},
+		),
+	}
+}
+
+// Fire is triggered by logrus, in response to a logging event
+func (m *QueryLimitMetrics) Fire(e *logrus.Entry) error {
+	limit, ok := e.Data["query_limit"].(string)
+	if !ok {
+		return nil
+	}
+
+	route, ok := e.Data["route"].(string)
+	if !ok {
+		route = "undefined"
+	}
+
+	m.Counter.With(prometheus.Labels{"route": route, "limit": limit}).Inc()
+	return nil
+}
+
+// Levels returns the logging levels that will trigger this hook to run. Query
+// limits are logged as warnings.
+func (m *QueryLimitMetrics) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel}
+}
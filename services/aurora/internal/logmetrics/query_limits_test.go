@@ -0,0 +1,36 @@
+package logmetrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryLimitMetrics(t *testing.T) {
+	l, _ := New()
+	l.Logger.Out = new(bytes.Buffer)
+	m := NewQueryLimitMetrics()
+	l.Logger.Hooks.Add(m)
+
+	l.Warn("foo")
+	l.WithField("query_limit", "statement_timeout").Info("foo")
+	l.WithField("query_limit", "statement_timeout").Warn("foo")
+	l.WithField("query_limit", "statement_timeout").WithField("route", "/effects").Warn("foo")
+	l.WithField("query_limit", "max_query_cost").WithField("route", "/effects").Warn("foo")
+	l.WithField("query_limit", "max_query_cost").WithField("route", "/effects").Warn("foo")
+
+	for _, tc := range []struct {
+		route, limit string
+		expected     float64
+	}{
+		{"undefined", "statement_timeout", 1},
+		{"/effects", "statement_timeout", 1},
+		{"/effects", "max_query_cost", 2},
+		{"undefined", "max_query_cost", 0},
+	} {
+		counter := m.Counter.With(prometheus.Labels{"route": tc.route, "limit": tc.limit})
+		assert.Equal(t, tc.expected, getMetricValue(counter).GetCounter().GetValue(), tc)
+	}
+}
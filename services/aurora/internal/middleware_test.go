@@ -351,7 +351,7 @@ func TestCheckHistoryStaleMiddleware(t *testing.T) {
 				HistoryLatest: testCase.historyLatest,
 			}
 			ledger.SetState(state)
-			historyMiddleware := httpx.NewHistoryMiddleware(testCase.staleThreshold, tt.AuroraSession())
+			historyMiddleware := httpx.NewHistoryMiddleware(testCase.staleThreshold, tt.AuroraSession(), nil)
 			handler := historyMiddleware(http.HandlerFunc(endpoint))
 			w := httptest.NewRecorder()
 			handler.ServeHTTP(w, request)
@@ -1,22 +1,148 @@
 // Package reap contains the history reaping subsystem for aurora.  This system
 // is designed to remove data from the history database such that it does not
 // grow indefinitely.  The system can be configured with a number of ledgers to
-// maintain at a minimum.
+// maintain at a minimum, for all history or for some tables.
 package reap
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hcnet/go/services/aurora/internal/db2/history"
 	"github.com/hcnet/go/support/db"
+	"github.com/hcnet/go/support/errors"
 )
 
+// Table is a kind of history with its own retention.
+type Table string
+
+const (
+	EffectsTable      Table = "effects"
+	ParticipantsTable Table = "participants"
+	OperationsTable   Table = "operations"
+	TradesTable       Table = "trades"
+	TransactionsTable Table = "transactions"
+	LedgersTable      Table = "ledgers"
+)
+
+// Tables lists the kinds of history in the order they are reaped.
+var Tables = []Table{
+	EffectsTable,
+	ParticipantsTable,
+	OperationsTable,
+	TradesTable,
+	TransactionsTable,
+	LedgersTable,
+}
+
+type dbTable struct {
+	name string
+	// column is the TOID column rows are reaped by.
+	column string
+}
+
+var dbTables = map[Table][]dbTable{
+	EffectsTable: {{"history_effects", "history_operation_id"}},
+	ParticipantsTable: {
+		{"history_operation_participants", "history_operation_id"},
+		{"history_transaction_participants", "history_transaction_id"},
+	},
+	OperationsTable:   {{"history_operations", "id"}},
+	TradesTable:       {{"history_trades", "history_operation_id"}},
+	TransactionsTable: {{"history_transactions", "id"}},
+	LedgersTable:      {{"history_ledgers", "id"}},
+}
+
+// ParseRetentionCounts parses a comma separated list of table=count pairs,
+// for example "effects=518400,ledgers=0".
+func ParseRetentionCounts(s string) (map[Table]uint, error) {
+	counts := map[Table]uint{}
+	if s == "" {
+		return counts, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid retention count %q, expected table=count", pair)
+		}
+		table := Table(parts[0])
+		if _, ok := dbTables[table]; !ok {
+			return nil, errors.Errorf("unknown history table %q", parts[0])
+		}
+		count, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid retention count of %s", table)
+		}
+		counts[table] = uint(count)
+	}
+	return counts, nil
+}
+
+// ValidateRetentionCounts checks that history_ledgers is retained at least as
+// long as every other history table. The other tables are joined to
+// history_ledgers and the oldest ledger available is read from it, so their
+// rows would be unusable once their ledger is gone.
+func ValidateRetentionCounts(retention uint, counts map[Table]uint) error {
+	count := func(table Table) uint {
+		if c, ok := counts[table]; ok {
+			return c
+		}
+		return retention
+	}
+
+	ledgers := count(LedgersTable)
+	if ledgers == 0 {
+		return nil
+	}
+	for _, table := range Tables {
+		if c := count(table); c == 0 || c > ledgers {
+			return errors.Errorf(
+				"%s can't be retained for fewer ledgers than %s (ledgers=%d, %s=%s)",
+				LedgersTable, table, ledgers, table, formatRetentionCount(c),
+			)
+		}
+	}
+	return nil
+}
+
+func formatRetentionCount(count uint) string {
+	if count == 0 {
+		return "0 (all)"
+	}
+	return strconv.FormatUint(uint64(count), 10)
+}
+
+// Invalidator removes copies of history, such as cached responses, when the
+// reaper deletes it.
+type Invalidator interface {
+	// DeleteBefore removes the copies of the history of table for ledgers
+	// older than seq.
+	DeleteBefore(table Table, seq uint32) error
+}
+
 // System represents the history reaping subsystem of aurora.
 type System struct {
 	HistoryQ       *history.Q
 	RetentionCount uint
+	// TableRetentionCounts overrides RetentionCount for some tables. 0
+	// retains all ledgers of a table.
+	TableRetentionCounts map[Table]uint
+	// BatchSize is the maximum number of ledgers deleted by a single
+	// statement. 0 deletes all unretained ledgers at once.
+	BatchSize uint
+	// BatchPause is the time waited between batches, letting other queries
+	// acquire the locks held while deleting.
+	BatchPause time.Duration
+	// DryRun only reports the history which would be removed.
+	DryRun bool
+	// Invalidator, when not nil, is notified of the history removed from
+	// every table.
+	Invalidator Invalidator
 
 	nextRun time.Time
+	// running is 1 while Tick is reaping in the background.
+	running int32
 }
 
 // New initializes the reaper, causing it to begin polling the hcnet-core
@@ -30,3 +156,12 @@ func New(retention uint, dbSession *db.Session) *System {
 	r.nextRun = time.Now().Add(1 * time.Hour)
 	return r
 }
+
+// retentionCount returns the number of ledgers retained in table, 0 meaning
+// all of them.
+func (r *System) retentionCount(table Table) uint {
+	if count, ok := r.TableRetentionCounts[table]; ok {
+		return count
+	}
+	return r.RetentionCount
+}
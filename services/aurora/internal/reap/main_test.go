@@ -0,0 +1,93 @@
+package reap
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetentionCounts(t *testing.T) {
+	counts, err := ParseRetentionCounts("")
+	assert.NoError(t, err)
+	assert.Empty(t, counts)
+
+	counts, err = ParseRetentionCounts("effects=518400, ledgers=0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[Table]uint{EffectsTable: 518400, LedgersTable: 0}, counts)
+
+	_, err = ParseRetentionCounts("effects")
+	assert.EqualError(t, err, `invalid retention count "effects", expected table=count`)
+
+	_, err = ParseRetentionCounts("history_effects=10")
+	assert.EqualError(t, err, `unknown history table "history_effects"`)
+
+	_, err = ParseRetentionCounts("effects=-1")
+	assert.Error(t, err)
+}
+
+func TestValidateRetentionCounts(t *testing.T) {
+	assert.NoError(t, ValidateRetentionCounts(0, nil))
+	assert.NoError(t, ValidateRetentionCounts(100, nil))
+	assert.NoError(t, ValidateRetentionCounts(100, map[Table]uint{EffectsTable: 10}))
+	assert.NoError(t, ValidateRetentionCounts(10, map[Table]uint{LedgersTable: 0}))
+	assert.NoError(t, ValidateRetentionCounts(0, map[Table]uint{LedgersTable: 0, TradesTable: 10}))
+
+	err := ValidateRetentionCounts(100, map[Table]uint{LedgersTable: 10})
+	assert.EqualError(t, err, "ledgers can't be retained for fewer ledgers than effects (ledgers=10, effects=100)")
+
+	err = ValidateRetentionCounts(100, map[Table]uint{LedgersTable: 100, TransactionsTable: 101})
+	assert.EqualError(t, err, "ledgers can't be retained for fewer ledgers than transactions (ledgers=100, transactions=101)")
+
+	err = ValidateRetentionCounts(0, map[Table]uint{LedgersTable: 100})
+	assert.EqualError(t, err, "ledgers can't be retained for fewer ledgers than effects (ledgers=100, effects=0 (all))")
+
+	r := &System{
+		RetentionCount:       100,
+		TableRetentionCounts: map[Table]uint{LedgersTable: 10},
+	}
+	assert.EqualError(t, r.DeleteUnretainedHistory(), "ledgers can't be retained for fewer ledgers than effects (ledgers=10, effects=100)")
+}
+
+func TestRetentionCount(t *testing.T) {
+	r := &System{
+		RetentionCount:       100,
+		TableRetentionCounts: map[Table]uint{EffectsTable: 10, LedgersTable: 0},
+	}
+	assert.Equal(t, uint(10), r.retentionCount(EffectsTable))
+	assert.Equal(t, uint(0), r.retentionCount(LedgersTable))
+	assert.Equal(t, uint(100), r.retentionCount(TradesTable))
+
+	for _, table := range Tables {
+		assert.NotEmpty(t, dbTables[table], "no db tables for %s", table)
+	}
+}
+
+func TestTick(t *testing.T) {
+	// The retention counts are invalid so reaping fails before querying the
+	// database.
+	r := &System{
+		RetentionCount:       10,
+		TableRetentionCounts: map[Table]uint{LedgersTable: 5},
+	}
+
+	// Nothing is started while a previous run is in progress.
+	r.running = 1
+	r.Tick()
+	assert.True(t, r.nextRun.IsZero())
+
+	// Runs happen in the background and are scheduled an hour later.
+	r.running = 0
+	r.Tick()
+	assert.True(t, r.nextRun.After(time.Now().Add(59*time.Minute)))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&r.running) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Ticks before the next run do nothing.
+	nextRun := r.nextRun
+	r.Tick()
+	assert.Equal(t, nextRun, r.nextRun)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&r.running))
+}
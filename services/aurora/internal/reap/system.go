@@ -1,51 +1,82 @@
 package reap
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/hcnet/go/services/aurora/internal/errors"
 	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/toid"
+	supportErrors "github.com/hcnet/go/support/errors"
 	"github.com/hcnet/go/support/log"
 )
 
-// DeleteUnretainedHistory removes all data associated with unretained ledgers.
+// DeleteUnretainedHistory removes all data associated with unretained ledgers
+// from every table, according to its retention count.
 func (r *System) DeleteUnretainedHistory() error {
-	// RetentionCount of 0 indicates "keep all history"
-	if r.RetentionCount == 0 {
-		return nil
+	err := ValidateRetentionCounts(r.RetentionCount, r.TableRetentionCounts)
+	if err != nil {
+		return err
 	}
 
-	var (
-		latest      = ledger.CurrentState()
-		targetElder = (latest.HistoryLatest - int32(r.RetentionCount)) + 1
-	)
+	latest := ledger.CurrentState()
 
-	if targetElder < latest.HistoryElder {
-		return nil
-	}
-
-	err := r.clearBefore(targetElder)
+	partitioning, err := r.HistoryQ.GetHistoryPartitioning()
 	if err != nil {
 		return err
 	}
 
-	log.
-		WithField("new_elder", targetElder).
-		Info("reaper succeeded")
+	for _, table := range Tables {
+		// A retention count of 0 indicates "keep all history"
+		retention := r.retentionCount(table)
+		if retention == 0 {
+			continue
+		}
 
+		targetElder := (latest.HistoryLatest - int32(retention)) + 1
+		if targetElder <= 1 {
+			continue
+		}
+
+		if partitioning.Enabled() {
+			err = r.dropPartitionsBefore(table, targetElder)
+		} else {
+			err = r.clearBefore(table, targetElder)
+		}
+		if err != nil {
+			return supportErrors.Wrapf(err, "could not reap %s", table)
+		}
+
+		if r.Invalidator != nil && !r.DryRun {
+			// Copies of reaped history are removed on a best effort basis,
+			// failing to do so doesn't affect the database.
+			if err = r.Invalidator.DeleteBefore(table, uint32(targetElder)); err != nil {
+				log.WithField("table", table).Errorf("reaper: could not invalidate history copies: %s", err)
+			}
+		}
+	}
+
+	log.WithField("dry_run", r.DryRun).Info("reaper succeeded")
 	return nil
 }
 
 // Tick triggers the reaper system to update itself, deleted unretained history
-// if it is the appropriate time.
+// if it is the appropriate time. The history is deleted in the background so
+// batch pauses don't delay the caller, Tick does nothing while a previous run
+// is still in progress. Use DeleteUnretainedHistory to reap synchronously.
 func (r *System) Tick() {
 	if time.Now().Before(r.nextRun) {
 		return
 	}
+	if !atomic.CompareAndSwapInt32(&r.running, 0, 1) {
+		return
+	}
 
-	r.runOnce()
 	r.nextRun = time.Now().Add(1 * time.Hour)
+	go func() {
+		defer atomic.StoreInt32(&r.running, 0)
+		r.runOnce()
+	}()
 }
 
 func (r *System) runOnce() {
@@ -63,18 +94,95 @@ func (r *System) runOnce() {
 	}
 }
 
-func (r *System) clearBefore(seq int32) error {
-	log.WithField("new_elder", seq).Info("reaper: clearing")
+// clearBefore deletes the rows of table older than seq, in batches of at most
+// BatchSize ledgers.
+func (r *System) clearBefore(table Table, seq int32) error {
+	for _, t := range dbTables[table] {
+		elder, err := r.HistoryQ.ElderTOID(t.name, t.column)
+		if err != nil {
+			return err
+		}
+		from := toid.Parse(elder).LedgerSequence
+		if elder == 0 || from >= seq {
+			continue
+		}
+		if from < 1 {
+			from = 1
+		}
+
+		l := log.WithFields(log.F{
+			"table":     t.name,
+			"old_elder": from,
+			"new_elder": seq,
+		})
+
+		if r.DryRun {
+			start, end, err := toid.LedgerRangeInclusive(from, seq-1)
+			if err != nil {
+				return err
+			}
+			count, err := r.HistoryQ.CountTOIDRange(t.name, t.column, start, end)
+			if err != nil {
+				return err
+			}
+			l.WithField("rows", count).Info("reaper: dry run, rows would be deleted")
+			continue
+		}
 
-	start, end, err := toid.LedgerRangeInclusive(1, seq-1)
-	if err != nil {
-		return err
+		var deleted int64
+		for batchStart := from; batchStart < seq; {
+			batchEnd := seq - 1
+			if r.BatchSize > 0 && int64(batchEnd-batchStart) >= int64(r.BatchSize) {
+				batchEnd = batchStart + int32(r.BatchSize) - 1
+			}
+
+			start, end, err := toid.LedgerRangeInclusive(batchStart, batchEnd)
+			if err != nil {
+				return err
+			}
+			count, err := r.HistoryQ.DeleteTOIDRange(t.name, t.column, start, end)
+			if err != nil {
+				return err
+			}
+			deleted += count
+
+			batchStart = batchEnd + 1
+			if batchStart < seq && r.BatchPause > 0 {
+				time.Sleep(r.BatchPause)
+			}
+		}
+		l.WithField("rows", deleted).Info("reaper: deleted rows")
 	}
+	return nil
+}
 
-	err = r.HistoryQ.DeleteRangeAll(start, end)
-	if err != nil {
-		return err
-	}
+// dropPartitionsBefore drops the partitions of table only containing ledgers
+// older than seq instead of deleting rows, which avoids long locks and table
+// bloat. Ledgers sharing a partition with retained ledgers are kept until the
+// whole partition can be dropped.
+func (r *System) dropPartitionsBefore(table Table, seq int32) error {
+	for _, t := range dbTables[table] {
+		partitions, err := r.HistoryQ.HistoryPartitionsBefore(t.name, uint32(seq))
+		if err != nil {
+			return err
+		}
 
+		l := log.WithFields(log.F{
+			"table":      t.name,
+			"new_elder":  seq,
+			"partitions": partitions,
+		})
+		if r.DryRun {
+			l.Info("reaper: dry run, partitions would be dropped")
+			continue
+		}
+
+		for _, partition := range partitions {
+			if err = r.HistoryQ.DropHistoryPartition(partition); err != nil {
+				return err
+			}
+		}
+		l.Info("reaper: dropped partitions")
+	}
 	return nil
 }
@@ -3,7 +3,9 @@ package reap
 import (
 	"testing"
 
+	"github.com/hcnet/go/services/aurora/internal/ledger"
 	"github.com/hcnet/go/services/aurora/internal/test"
+	"github.com/hcnet/go/services/aurora/internal/toid"
 )
 
 func TestDeleteUnretainedHistory(t *testing.T) {
@@ -46,3 +48,63 @@ func TestDeleteUnretainedHistory(t *testing.T) {
 		tt.Assert.Equal(1, cur)
 	}
 }
+
+func TestDeleteUnretainedHistoryPerTable(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+
+	db := tt.AuroraSession()
+	tt.UpdateLedgerState()
+
+	count := func(table string) int {
+		var cur int
+		err := db.GetRaw(&cur, `SELECT COUNT(*) FROM `+table)
+		tt.Require.NoError(err)
+		return cur
+	}
+	ledgers := count("history_ledgers")
+	transactions := count("history_transactions")
+	effects := count("history_effects")
+	tt.Require.NotZero(effects)
+
+	invalidator := &testInvalidator{}
+	sys := New(0, db)
+	sys.TableRetentionCounts = map[Table]uint{EffectsTable: 1}
+	sys.BatchSize = 2
+	sys.DryRun = true
+	sys.Invalidator = invalidator
+
+	tt.Require.NoError(sys.DeleteUnretainedHistory())
+	tt.Assert.Equal(effects, count("history_effects"), "effects deleted in dry run")
+	tt.Assert.Empty(invalidator.elders, "history invalidated in dry run")
+
+	sys.DryRun = false
+	tt.Require.NoError(sys.DeleteUnretainedHistory())
+	tt.Assert.Equal(
+		map[Table]uint32{EffectsTable: uint32(ledger.CurrentState().HistoryLatest)},
+		invalidator.elders,
+	)
+	tt.Assert.Equal(ledgers, count("history_ledgers"))
+	tt.Assert.Equal(transactions, count("history_transactions"))
+
+	var remaining int
+	err := db.GetRaw(&remaining, `
+		SELECT COUNT(*) FROM history_effects
+		WHERE history_operation_id < $1`,
+		toid.New(ledger.CurrentState().HistoryLatest, 0, 0).ToInt64(),
+	)
+	tt.Require.NoError(err)
+	tt.Assert.Equal(0, remaining)
+}
+
+type testInvalidator struct {
+	elders map[Table]uint32
+}
+
+func (i *testInvalidator) DeleteBefore(table Table, seq uint32) error {
+	if i.elders == nil {
+		i.elders = map[Table]uint32{}
+	}
+	i.elders[table] = seq
+	return nil
+}
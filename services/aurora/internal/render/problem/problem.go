@@ -104,6 +104,51 @@ var (
 			"server, please ensure that the ingestion system is properly running.",
 	}
 
+	// ReplicaLagging is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	ReplicaLagging = problem.P{
+		Type:   "replica_lagging",
+		Title:  "Read Replica Is Lagging",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This aurora instance is configured to reject client requests " +
+			"when its read replica database is lagging too far behind the " +
+			"primary database. Please try your request again later.",
+	}
+
+	// NotIngested is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	NotIngested = problem.P{
+		Type:   "not_ingested",
+		Title:  "Not Ingested",
+		Status: http.StatusNotImplemented,
+		Detail: "This aurora instance is configured to not ingest the history " +
+			"required by this request. The history_ingestion field of the root " +
+			"resource lists the history it ingests.",
+	}
+
+	// LiteMode is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	LiteMode = problem.P{
+		Type:   "lite_mode",
+		Title:  "Not Available In Lite Mode",
+		Status: http.StatusNotImplemented,
+		Detail: "This aurora instance runs in lite mode, which only stores " +
+			"accounts, offers, trust lines, ledgers and transactions. The data " +
+			"required by this request is not available.",
+	}
+
+	// LedgerNotAvailable is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	LedgerNotAvailable = problem.P{
+		Type:   "ledger_not_available",
+		Title:  "Ledger Not Available",
+		Status: http.StatusConflict,
+		Detail: "The state of this aurora instance is not at the ledger requested " +
+			"with the at_ledger parameter. Ledger entries can only be listed at " +
+			"the latest ingested ledger, given in the Latest-Ledger header. " +
+			"Please start listing them again from the first page.",
+	}
+
 	// StillIngesting is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	StillIngesting = problem.P{
@@ -113,4 +158,34 @@ var (
 		Detail: "Data cannot be presented because it's still being ingested. Please " +
 			"wait for several minutes before trying your request again.",
 	}
+
+	// StatementTimeout is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	StatementTimeout = problem.P{
+		Type:   "statement_timeout",
+		Title:  "Statement Timeout",
+		Status: http.StatusServiceUnavailable,
+		Detail: "A database query of this request ran longer than the statement " +
+			"timeout this aurora instance allows for the endpoint. Please try a " +
+			"narrower request, for example with a more recent cursor or a smaller " +
+			"limit.",
+		Extras: map[string]interface{}{
+			"limit": "statement_timeout",
+		},
+	}
+
+	// QueryTooExpensive is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	QueryTooExpensive = problem.P{
+		Type:   "query_too_expensive",
+		Title:  "Query Too Expensive",
+		Status: http.StatusBadRequest,
+		Detail: "The estimated cost of a database query of this request is " +
+			"higher than the maximum query cost this aurora instance allows for " +
+			"the endpoint. Please try a narrower request, for example with a more " +
+			"recent cursor or without the include_failed parameter.",
+		Extras: map[string]interface{}{
+			"limit": "max_query_cost",
+		},
+	}
 )
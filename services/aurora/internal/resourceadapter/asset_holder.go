@@ -0,0 +1,31 @@
+package resourceadapter
+
+import (
+	"context"
+
+	"github.com/hcnet/go/amount"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	auroraContext "github.com/hcnet/go/services/aurora/internal/context"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/support/render/hal"
+	"github.com/hcnet/go/xdr"
+)
+
+// PopulateAssetHolder populates an AssetHolder using a trust line of the asset.
+func PopulateAssetHolder(ctx context.Context, dest *protocol.AssetHolder, row history.TrustLine) {
+	dest.PT = row.HolderPagingToken()
+	dest.AccountID = row.AccountID
+	dest.Balance = amount.String(xdr.Int64(row.Balance))
+	dest.Limit = amount.String(xdr.Int64(row.Limit))
+	dest.BuyingLiabilities = amount.String(xdr.Int64(row.BuyingLiabilities))
+	dest.SellingLiabilities = amount.String(xdr.Int64(row.SellingLiabilities))
+	dest.IsAuthorized = row.IsAuthorized()
+	dest.IsAuthorizedToMaintainLiabilities = row.IsAuthorizedToMaintainLiabilities()
+	dest.LastModifiedLedger = row.LastModifiedLedger
+	if row.Sponsor.Valid {
+		dest.Sponsor = row.Sponsor.String
+	}
+
+	lb := hal.LinkBuilder{auroraContext.BaseURL(ctx)}
+	dest.Links.Account = lb.Linkf("/accounts/%s", row.AccountID)
+}
@@ -0,0 +1,46 @@
+package resourceadapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null"
+	protocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/services/aurora/internal/db2/history"
+	"github.com/hcnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPopulateAssetHolder(t *testing.T) {
+	row := history.TrustLine{
+		AccountID:          "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM",
+		AssetType:          xdr.AssetTypeAssetTypeCreditAlphanum4,
+		AssetCode:          "XIM",
+		AssetIssuer:        "GCYVFGI3SEQJGBNQQG7YCMFWEYOHK3XPVOVPA6C566PXWN4SN7LILZSM",
+		Balance:            1000000000,
+		Limit:              9223372036854775807,
+		BuyingLiabilities:  10,
+		SellingLiabilities: 20,
+		Flags:              uint32(xdr.TrustLineFlagsAuthorizedFlag),
+		LastModifiedLedger: 1234,
+	}
+
+	var res protocol.AssetHolder
+	PopulateAssetHolder(context.Background(), &res, row)
+
+	assert.Equal(t, row.AccountID, res.AccountID)
+	assert.Equal(t, "100.0000000", res.Balance)
+	assert.Equal(t, "922337203685.4775807", res.Limit)
+	assert.Equal(t, "0.0000010", res.BuyingLiabilities)
+	assert.Equal(t, "0.0000020", res.SellingLiabilities)
+	assert.True(t, res.IsAuthorized)
+	assert.False(t, res.IsAuthorizedToMaintainLiabilities)
+	assert.Equal(t, uint32(1234), res.LastModifiedLedger)
+	assert.Equal(t, "", res.Sponsor)
+	assert.Equal(t, "1000000000_"+row.AccountID, res.PagingToken())
+	assert.Equal(t, "/accounts/"+row.AccountID, res.Links.Account.Href)
+
+	row.Sponsor = null.StringFrom("GCYVFGI3SEQJGBNQQG7YCMFWEYOHK3XPVOVPA6C566PXWN4SN7LILZSM")
+	PopulateAssetHolder(context.Background(), &res, row)
+	assert.Equal(t, row.Sponsor.String, res.Sponsor)
+}
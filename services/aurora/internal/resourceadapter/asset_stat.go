@@ -23,16 +23,38 @@ func PopulateAssetStat(
 	res.Asset.Type = xdr.AssetTypeToString[row.AssetType]
 	res.Asset.Code = row.AssetCode
 	res.Asset.Issuer = row.AssetIssuer
-	res.Amount, err = amount.IntStringToAmount(row.Amount)
-	if err != nil {
-		return errors.Wrap(err, "Invalid amount in PopulateAssetStat")
+	res.Accounts = protocol.AssetStatAccounts{
+		Authorized:                      row.Accounts.Authorized,
+		AuthorizedToMaintainLiabilities: row.Accounts.AuthorizedToMaintainLiabilities,
+		Unauthorized:                    row.Accounts.Unauthorized,
+	}
+	res.NumClaimableBalances = row.Accounts.ClaimableBalances
+
+	amounts := []struct {
+		dest   *string
+		amount string
+	}{
+		{&res.Balances.Authorized, row.Balances.Authorized},
+		{&res.Balances.AuthorizedToMaintainLiabilities, row.Balances.AuthorizedToMaintainLiabilities},
+		{&res.Balances.Unauthorized, row.Balances.Unauthorized},
+		{&res.ClaimableBalancesAmount, row.Balances.ClaimableBalances},
+		{&res.Liabilities.Buying, row.Liabilities.Buying},
+		{&res.Liabilities.Selling, row.Liabilities.Selling},
+		{&res.Amount, row.Amount},
+	}
+	for _, a := range amounts {
+		*a.dest, err = amount.IntStringToAmount(a.amount)
+		if err != nil {
+			return errors.Wrap(err, "Invalid amount in PopulateAssetStat")
+		}
 	}
 	res.NumAccounts = row.NumAccounts
-	flags := int8(issuer.Flags)
+
+	flags := xdr.AccountFlags(issuer.Flags)
 	res.Flags = protocol.AccountFlags{
-		(flags & int8(xdr.AccountFlagsAuthRequiredFlag)) != 0,
-		(flags & int8(xdr.AccountFlagsAuthRevocableFlag)) != 0,
-		(flags & int8(xdr.AccountFlagsAuthImmutableFlag)) != 0,
+		AuthRequired:  (flags & xdr.AccountFlagsAuthRequiredFlag) != 0,
+		AuthRevocable: (flags & xdr.AccountFlagsAuthRevocableFlag) != 0,
+		AuthImmutable: (flags & xdr.AccountFlagsAuthImmutableFlag) != 0,
 	}
 	res.PT = row.PagingToken()
 
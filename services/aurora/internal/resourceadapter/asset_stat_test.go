@@ -16,6 +16,22 @@ func TestPopulateExpAssetStat(t *testing.T) {
 		AssetType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
 		AssetCode:   "XIM",
 		AssetIssuer: "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM",
+		Accounts: history.ExpAssetStatAccounts{
+			Authorized:                      429,
+			AuthorizedToMaintainLiabilities: 214,
+			Unauthorized:                    107,
+			ClaimableBalances:               12,
+		},
+		Balances: history.ExpAssetStatBalances{
+			Authorized:                      "100000000000000000000",
+			AuthorizedToMaintainLiabilities: "50000000000000000000",
+			Unauthorized:                    "25000000000000000000",
+			ClaimableBalances:               "12500000000000000000",
+		},
+		Liabilities: history.ExpAssetStatLiabilities{
+			Buying:  "2000000000",
+			Selling: "1000000000",
+		},
 		Amount:      "100000000000000000000", // 10T
 		NumAccounts: 429,
 	}
@@ -34,6 +50,22 @@ func TestPopulateExpAssetStat(t *testing.T) {
 	assert.Equal(t, "GBZ35ZJRIKJGYH5PBKLKOZ5L6EXCNTO7BKIL7DAVVDFQ2ODJEEHHJXIM", res.Issuer)
 	assert.Equal(t, "10000000000000.0000000", res.Amount)
 	assert.Equal(t, int32(429), res.NumAccounts)
+	assert.Equal(t, aurora.AssetStatAccounts{
+		Authorized:                      429,
+		AuthorizedToMaintainLiabilities: 214,
+		Unauthorized:                    107,
+	}, res.Accounts)
+	assert.Equal(t, int32(12), res.NumClaimableBalances)
+	assert.Equal(t, aurora.AssetStatBalances{
+		Authorized:                      "10000000000000.0000000",
+		AuthorizedToMaintainLiabilities: "5000000000000.0000000",
+		Unauthorized:                    "2500000000000.0000000",
+	}, res.Balances)
+	assert.Equal(t, "1250000000000.0000000", res.ClaimableBalancesAmount)
+	assert.Equal(t, aurora.AssetStatLiabilities{
+		Buying:  "200.0000000",
+		Selling: "100.0000000",
+	}, res.Liabilities)
 	assert.Equal(t, aurora.AccountFlags{}, res.Flags)
 	assert.Equal(t, "https://xim.com/.well-known/hcnet.toml", res.Links.Toml.Href)
 	assert.Equal(t, row.PagingToken(), res.PagingToken())
@@ -60,4 +92,8 @@ func TestPopulateExpAssetStat(t *testing.T) {
 	)
 	assert.Equal(t, "", res.Links.Toml.Href)
 	assert.Equal(t, row.PagingToken(), res.PagingToken())
+
+	row.Balances.ClaimableBalances = "invalid"
+	err = PopulateAssetStat(context.Background(), &res, row, issuer)
+	assert.EqualError(t, err, "Invalid amount in PopulateAssetStat: invalid amount format: invalid")
 }
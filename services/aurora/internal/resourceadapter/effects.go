@@ -259,6 +259,7 @@ func PopulateBaseEffect(ctx context.Context, this *effects.Base, row history.Eff
 	this.ID = row.ID()
 	this.PT = row.PagingToken()
 	this.Account = row.Account
+	populateMuxedAccount(row.AccountMuxed, &this.AccountMuxed, &this.AccountMuxedID)
 	populateEffectType(this, row)
 	this.LedgerCloseTime = ledger.ClosedAt
 
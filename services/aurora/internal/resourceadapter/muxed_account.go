@@ -0,0 +1,23 @@
+package resourceadapter
+
+import (
+	"strconv"
+
+	"github.com/guregu/null"
+	"github.com/hcnet/go/strkey"
+)
+
+// populateMuxedAccount sets the M... address and its id when account is a
+// valid muxed account address.
+func populateMuxedAccount(account null.String, address, id *string) {
+	if !account.Valid {
+		return
+	}
+
+	_, muxedID, err := strkey.DecodeMuxedAccount(account.String)
+	if err != nil {
+		return
+	}
+	*address = account.String
+	*id = strconv.FormatUint(muxedID, 10)
+}
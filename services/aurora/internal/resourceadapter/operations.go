@@ -148,6 +148,7 @@ func PopulateBaseOperation(
 	dest.PT = operationRow.PagingToken()
 	dest.TransactionSuccessful = operationRow.TransactionSuccessful
 	dest.SourceAccount = operationRow.SourceAccount
+	populateMuxedAccount(operationRow.SourceAccountMuxed, &dest.SourceAccountMuxed, &dest.SourceAccountMuxedID)
 	populateOperationType(dest, operationRow)
 	dest.LedgerCloseTime = ledger.ClosedAt
 	dest.TransactionHash = transactionHash
@@ -30,6 +30,7 @@ func PopulateTransaction(
 	dest.Ledger = row.LedgerSequence
 	dest.LedgerCloseTime = row.LedgerCloseTime
 	dest.Account = row.Account
+	populateMuxedAccount(row.AccountMuxed, &dest.AccountMuxed, &dest.AccountMuxedID)
 	dest.AccountSequence = row.AccountSequence
 
 	dest.FeeCharged = row.FeeCharged
@@ -56,6 +57,7 @@ func PopulateTransaction(
 
 	if row.InnerTransactionHash.Valid {
 		dest.FeeAccount = row.FeeAccount.String
+		populateMuxedAccount(row.FeeAccountMuxed, &dest.FeeAccountMuxed, &dest.FeeAccountMuxedID)
 		dest.MaxFee = row.NewMaxFee.Int64
 		dest.FeeBumpTransaction = &protocol.FeeBumpTransaction{
 			Hash:       row.TransactionHash,
@@ -71,6 +73,8 @@ func PopulateTransaction(
 		}
 	} else {
 		dest.FeeAccount = row.Account
+		dest.FeeAccountMuxed = dest.AccountMuxed
+		dest.FeeAccountMuxedID = dest.AccountMuxedID
 		dest.MaxFee = row.MaxFee
 	}
 
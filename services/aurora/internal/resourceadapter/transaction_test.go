@@ -197,3 +197,32 @@ func TestFeeBumpTransaction(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c"}, dest.FeeBumpTransaction.Signatures)
 	assert.Equal(t, "/transactions/"+row.InnerTransactionHash.String, dest.Links.Transaction.Href)
 }
+
+func TestPopulateTransaction_MuxedAccounts(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	muxed := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+
+	dest := Transaction{}
+	row := history.Transaction{
+		TransactionWithoutLedger: history.TransactionWithoutLedger{
+			Account:      "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ",
+			AccountMuxed: null.StringFrom(muxed),
+		},
+	}
+	assert.NoError(t, PopulateTransaction(ctx, row.TransactionHash, &dest, row))
+	assert.Equal(t, muxed, dest.AccountMuxed)
+	assert.Equal(t, "9223372036854775808", dest.AccountMuxedID)
+	assert.Equal(t, muxed, dest.FeeAccountMuxed)
+	assert.Equal(t, "9223372036854775808", dest.FeeAccountMuxedID)
+
+	dest = Transaction{}
+	row = history.Transaction{
+		TransactionWithoutLedger: history.TransactionWithoutLedger{
+			Account: "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ",
+		},
+	}
+	assert.NoError(t, PopulateTransaction(ctx, row.TransactionHash, &dest, row))
+	assert.Empty(t, dest.AccountMuxed)
+	assert.Empty(t, dest.AccountMuxedID)
+	assert.Empty(t, dest.FeeAccountMuxed)
+}
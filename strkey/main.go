@@ -31,6 +31,10 @@ const (
 	//VersionByteHashX is the version byte used for encoded hcnet hashX
 	//signer keys.
 	VersionByteHashX = 23 << 3 // Base32-encodes to 'X...'
+
+	//VersionByteMuxedAccount is the version byte used for encoded hcnet
+	//multiplexed addresses (SEP-23).
+	VersionByteMuxedAccount = 12 << 3 // Base32-encodes to 'M...'
 )
 
 // DecodeAny decodes the provided StrKey into a raw value, checking the checksum
@@ -156,9 +160,8 @@ func Version(src string) (VersionByte, error) {
 // is not one of the defined valid version byte constants.
 func checkValidVersionByte(version VersionByte) error {
 	switch version {
-	// intentionally disallow M-strkeys  (versionByteMuxedAccount)
-	// until SEP23 leaves the Draft status.
-	case VersionByteAccountID, VersionByteSeed, VersionByteHashTx, VersionByteHashX:
+	case VersionByteAccountID, VersionByteSeed, VersionByteHashTx, VersionByteHashX,
+		VersionByteMuxedAccount:
 		return nil
 	default:
 		return ErrInvalidVersionByte
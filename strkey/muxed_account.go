@@ -0,0 +1,60 @@
+package strkey
+
+import (
+	"encoding/binary"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// muxedAccountPayloadLength is the length of a decoded M-address: a 32 byte
+// ed25519 public key followed by a big-endian 64 bit id.
+const muxedAccountPayloadLength = 32 + 8
+
+// EncodeMuxedAccount encodes an ed25519 public key and an id into a SEP-23
+// multiplexed (M...) address.
+func EncodeMuxedAccount(ed25519 []byte, id uint64) (string, error) {
+	if len(ed25519) != 32 {
+		return "", errors.Errorf("invalid ed25519 public key length: %d", len(ed25519))
+	}
+
+	payload := make([]byte, muxedAccountPayloadLength)
+	copy(payload, ed25519)
+	binary.BigEndian.PutUint64(payload[32:], id)
+	return Encode(VersionByteMuxedAccount, payload)
+}
+
+// MustEncodeMuxedAccount is like EncodeMuxedAccount, but panics on error
+func MustEncodeMuxedAccount(ed25519 []byte, id uint64) string {
+	e, err := EncodeMuxedAccount(ed25519, id)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// DecodeMuxedAccount decodes a SEP-23 multiplexed (M...) address into its
+// ed25519 public key and id.
+func DecodeMuxedAccount(address string) ([]byte, uint64, error) {
+	payload, err := Decode(VersionByteMuxedAccount, address)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(payload) != muxedAccountPayloadLength {
+		return nil, 0, errors.Errorf("invalid muxed account payload length: %d", len(payload))
+	}
+
+	return payload[:32], binary.BigEndian.Uint64(payload[32:]), nil
+}
+
+// IsValidMuxedAccount validates a hcnet multiplexed (M...) address
+func IsValidMuxedAccount(i interface{}) bool {
+	enc, ok := i.(string)
+
+	if !ok {
+		return false
+	}
+
+	_, _, err := DecodeMuxedAccount(enc)
+
+	return err == nil
+}
@@ -0,0 +1,41 @@
+package strkey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuxedAccount(t *testing.T) {
+	ed25519 := MustDecode(VersionByteAccountID, "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ")
+
+	// Test cases from SEP23
+	cases := []struct {
+		Address string
+		ID      uint64
+	}{
+		{"MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJUAAAAAAAAAAAACJUQ", 0},
+		{"MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK", 9223372036854775808},
+	}
+
+	for _, kase := range cases {
+		address, err := EncodeMuxedAccount(ed25519, kase.ID)
+		require.NoError(t, err)
+		assert.Equal(t, kase.Address, address)
+
+		key, id, err := DecodeMuxedAccount(kase.Address)
+		require.NoError(t, err)
+		assert.Equal(t, ed25519, key)
+		assert.Equal(t, kase.ID, id)
+		assert.True(t, IsValidMuxedAccount(kase.Address))
+	}
+
+	_, err := EncodeMuxedAccount(ed25519[:31], 0)
+	assert.Error(t, err)
+
+	// G addresses are not muxed accounts
+	assert.False(t, IsValidMuxedAccount("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"))
+	// invalid checksum
+	assert.False(t, IsValidMuxedAccount("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLL"))
+}
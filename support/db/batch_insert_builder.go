@@ -1,12 +1,16 @@
 package db
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hcnet/go/support/errors"
+	"github.com/lib/pq"
 )
 
 // BatchInsertBuilder works like sq.InsertBuilder but has a better support for batching
@@ -23,6 +27,14 @@ type BatchInsertBuilder struct {
 	// Suffix adds a sql expression to the end of the query (e.g. an ON CONFLICT clause)
 	Suffix string
 
+	// UseCopy makes Exec load rows with COPY FROM STDIN instead of multi-row
+	// INSERT statements when the session is a postgres session in a
+	// transaction and Suffix is empty. COPY is not limited by the number of
+	// query parameters and avoids building large statements. []byte values
+	// are sent as text, like INSERT does for non-bytea columns, so it must not
+	// be used with bytea columns.
+	UseCopy bool
+
 	columns       []string
 	rows          [][]interface{}
 	rowStructType reflect.Type
@@ -110,15 +122,21 @@ func (b *BatchInsertBuilder) insertSQL() sq.InsertBuilder {
 // Exec inserts rows in batches. In case of errors it's possible that some batches
 // were added so this should be run in a DB transaction for easy rollbacks.
 func (b *BatchInsertBuilder) Exec() error {
+	session := b.Table.Session
+	if b.UseCopy && len(b.Suffix) == 0 && session.GetTx() != nil && session.Dialect() == "postgres" {
+		return b.execCopy()
+	}
+
 	sql := b.insertSQL()
 	paramsCount := 0
+	maxParams := session.maxQueryParams()
 
 	for _, row := range b.rows {
 		sql = sql.Values(row...)
 		paramsCount += len(row)
 
-		if paramsCount > postgresQueryMaxParams-2*len(b.columns) {
-			_, err := b.Table.Session.Exec(sql)
+		if paramsCount > maxParams-2*len(b.columns) {
+			_, err := session.Exec(sql)
 			if err != nil {
 				return errors.Wrap(err, fmt.Sprintf("error adding values while inserting to %s", b.Table.Name))
 			}
@@ -129,7 +147,7 @@ func (b *BatchInsertBuilder) Exec() error {
 
 	// Insert last batch
 	if paramsCount > 0 {
-		_, err := b.Table.Session.Exec(sql)
+		_, err := session.Exec(sql)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("error adding values while inserting to %s", b.Table.Name))
 		}
@@ -139,3 +157,64 @@ func (b *BatchInsertBuilder) Exec() error {
 	b.rows = make([][]interface{}, 0)
 	return nil
 }
+
+// execCopy streams all rows to the table with COPY FROM STDIN in the session
+// transaction.
+func (b *BatchInsertBuilder) execCopy() error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+
+	ctx := b.Table.Session.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		// pq.CopyIn quotes the columns itself.
+		columns[i] = strings.Trim(column, `"`)
+	}
+
+	stmt, err := b.Table.Session.GetTx().PrepareContext(ctx, pq.CopyIn(b.Table.Name, columns...))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error starting copy to %s", b.Table.Name))
+	}
+	defer stmt.Close()
+
+	encoded := make([]interface{}, len(b.columns))
+	for _, row := range b.rows {
+		if err = encodeCopyRow(encoded, row); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error encoding row while copying to %s", b.Table.Name))
+		}
+		if _, err = stmt.ExecContext(ctx, encoded...); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("error adding values while copying to %s", b.Table.Name))
+		}
+	}
+
+	// Executing the statement without arguments flushes the buffered rows and
+	// waits for the server to complete the copy.
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("error copying to %s", b.Table.Name))
+	}
+
+	b.rows = make([][]interface{}, 0)
+	return nil
+}
+
+// encodeCopyRow converts the values of row to the types supported by
+// pq.CopyIn and stores them in dst.
+func encodeCopyRow(dst, row []interface{}) error {
+	for i, value := range row {
+		v, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			return err
+		}
+		// pq.CopyIn encodes []byte as bytea while INSERT sends it as is.
+		if bytes, ok := v.([]byte); ok {
+			v = string(bytes)
+		}
+		dst[i] = v
+	}
+	return nil
+}
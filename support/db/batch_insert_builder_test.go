@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/hcnet/go/support/db/dbtest"
@@ -149,3 +150,163 @@ func TestBatchInsertBuilder(t *testing.T) {
 		},
 	)
 }
+
+func TestBatchInsertBuilderCopy(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	insertBuilder := &BatchInsertBuilder{
+		Table:   sess.GetTable("people"),
+		UseCopy: true,
+	}
+
+	require.NoError(t, sess.Begin())
+	defer sess.Rollback()
+
+	// exec on the empty set should produce no errors
+	assert.NoError(t, insertBuilder.Exec())
+
+	err := insertBuilder.Row(map[string]interface{}{
+		"name":         "bubba\ttab",
+		"hunger_level": 120,
+	})
+	assert.NoError(t, err)
+
+	err = insertBuilder.Row(map[string]interface{}{
+		"name":         []byte("bubba2"),
+		"hunger_level": uint32(1202),
+	})
+	assert.NoError(t, err)
+
+	err = insertBuilder.Exec()
+	assert.NoError(t, err)
+
+	var found []person
+	err = sess.SelectRaw(&found, `SELECT * FROM people WHERE name like 'bubba%' ORDER BY name`)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]person{
+			person{Name: "bubba\ttab", HungerLevel: "120"},
+			person{Name: "bubba2", HungerLevel: "1202"},
+		},
+		found,
+	)
+
+	err = insertBuilder.Row(map[string]interface{}{
+		"name":         "bubba2",
+		"hunger_level": 1,
+	})
+	assert.NoError(t, err)
+
+	err = insertBuilder.Exec()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate key value violates unique constraint")
+}
+
+func TestBatchInsertBuilderCopyOutsideTransaction(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	// COPY requires a transaction, rows are inserted with INSERT statements
+	// outside of it.
+	insertBuilder := &BatchInsertBuilder{
+		Table:   sess.GetTable("people"),
+		UseCopy: true,
+	}
+	err := insertBuilder.Row(map[string]interface{}{
+		"name":         "bubba",
+		"hunger_level": 120,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, insertBuilder.Exec())
+
+	var count int
+	require.NoError(t, sess.GetRaw(&count, `SELECT COUNT(*) FROM people WHERE name = 'bubba'`))
+	assert.Equal(t, 1, count)
+}
+
+func BenchmarkBatchInsertBuilder(b *testing.B) {
+	db := dbtest.Postgres(b).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	for _, mode := range []struct {
+		name    string
+		useCopy bool
+	}{
+		{"insert", false},
+		{"copy", true},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			const rows = 10000
+			for i := 0; i < b.N; i++ {
+				require.NoError(b, sess.Begin())
+				insertBuilder := &BatchInsertBuilder{
+					Table:   sess.GetTable("people"),
+					UseCopy: mode.useCopy,
+				}
+				for j := 0; j < rows; j++ {
+					err := insertBuilder.Row(map[string]interface{}{
+						"name":         fmt.Sprintf("person%d", j),
+						"hunger_level": j,
+					})
+					require.NoError(b, err)
+				}
+				require.NoError(b, insertBuilder.Exec())
+				require.NoError(b, sess.Rollback())
+			}
+		})
+	}
+}
+
+func TestBatchInsertBuilderSqlite(t *testing.T) {
+	db := dbtest.Sqlite(t).Load(testSchema)
+	defer db.Close()
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	// COPY is not supported by SQLite, rows are inserted with INSERT
+	// statements instead.
+	insertBuilder := &BatchInsertBuilder{
+		Table:   sess.GetTable("people"),
+		UseCopy: true,
+	}
+
+	require.NoError(t, sess.Begin())
+	defer sess.Rollback()
+
+	// more parameters than allowed in a single query on SQLite
+	for i := 0; i < sqliteQueryMaxParams; i++ {
+		err := insertBuilder.Row(map[string]interface{}{
+			"name":         fmt.Sprintf("bubba%d", i),
+			"hunger_level": i,
+		})
+		require.NoError(t, err)
+	}
+	assert.NoError(t, insertBuilder.Exec())
+
+	var count int
+	require.NoError(t, sess.GetRaw(&count, `SELECT COUNT(*) FROM people WHERE name like 'bubba%'`))
+	assert.Equal(t, sqliteQueryMaxParams, count)
+
+	insertBuilder = &BatchInsertBuilder{
+		Table:  sess.GetTable("people"),
+		Suffix: "ON CONFLICT (name) DO UPDATE SET hunger_level = excluded.hunger_level",
+	}
+	err := insertBuilder.Row(map[string]interface{}{
+		"name":         "bubba1",
+		"hunger_level": 1202,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, insertBuilder.Exec())
+
+	var found person
+	require.NoError(t, sess.GetRaw(&found, `SELECT * FROM people WHERE name = ?`, "bubba1"))
+	assert.Equal(t, person{Name: "bubba1", HungerLevel: "1202"}, found)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +15,9 @@ import (
 	"github.com/hcnet/go/support/db/sqlutils"
 	"github.com/hcnet/go/support/errors"
 	"github.com/stretchr/testify/require"
+
+	// Enable sqlite
+	_ "github.com/hcnet/go/support/db/sqlite"
 )
 
 // DB represents an ephemeral database that starts blank and can be used
@@ -22,7 +26,7 @@ type DB struct {
 	Dialect string
 	DSN     string
 	dbName  string
-	t       *testing.T
+	t       testing.TB
 	closer  func()
 	closed  bool
 }
@@ -101,7 +105,7 @@ func (db *DB) Version() (major int) {
 	return major
 }
 
-func execStatement(t *testing.T, pguser, query string) {
+func execStatement(t testing.TB, pguser, query string) {
 	db, err := sqlx.Open("postgres", fmt.Sprintf("postgres://%s@localhost/?sslmode=disable", pguser))
 	require.NoError(t, err)
 	_, err = db.Exec(query)
@@ -113,7 +117,7 @@ func execStatement(t *testing.T, pguser, query string) {
 // of the running process.  It assumes that you have postgres running on the
 // default port, have the command line postgres tools installed, and that the
 // current user has access to the server.  It panics on the event of a failure.
-func Postgres(t *testing.T) *DB {
+func Postgres(t testing.TB) *DB {
 	var result DB
 	result.dbName = randomName()
 	result.Dialect = "postgres"
@@ -137,3 +141,46 @@ func Postgres(t *testing.T) *DB {
 
 	return &result
 }
+
+// Sqlite provisions a new, blank database in a temporary file. It panics on
+// the event of a failure.
+func Sqlite(t testing.TB) *DB {
+	var result DB
+
+	tmpfile, err := ioutil.TempFile("", "test-sqlite")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	result.dbName = tmpfile.Name()
+	result.Dialect = "sqlite3"
+	result.DSN = tmpfile.Name()
+	result.t = t
+
+	t.Log("Test Database:", result.dbName)
+
+	result.closer = func() {
+		require.NoError(t, os.Remove(tmpfile.Name()))
+	}
+
+	return &result
+}
+
+// Dialects runs test once with a blank Postgres database and once with a blank
+// SQLite database, as subtests named after the dialect.
+func Dialects(t *testing.T, test func(t *testing.T, db *DB)) {
+	dialects := []struct {
+		name      string
+		provision func(testing.TB) *DB
+	}{
+		{"postgres", Postgres},
+		{"sqlite3", Sqlite},
+	}
+	for _, dialect := range dialects {
+		provision := dialect.provision
+		t.Run(dialect.name, func(t *testing.T) {
+			db := provision(t)
+			defer db.Close()
+			test(t, db)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package dbtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqlite(t *testing.T) {
+	db := Sqlite(t)
+	t.Log("tempdb url", db.DSN)
+
+	conn := db.Open()
+	_, err := conn.Exec("CREATE TABLE people (name text)")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	db.Close()
+
+	_, err = os.Stat(db.DSN)
+	assert.True(t, os.IsNotExist(err))
+
+	// ensure Close() can be called multiple times
+	db.Close()
+}
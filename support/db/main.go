@@ -27,13 +27,23 @@ import (
 const (
 	// postgresQueryMaxParams defines the maximum number of parameters in a query.
 	postgresQueryMaxParams = 65535
-	maxDBPingAttempts      = 30
+	// sqliteQueryMaxParams defines the maximum number of parameters in a
+	// query on SQLite (SQLITE_MAX_VARIABLE_NUMBER).
+	sqliteQueryMaxParams = 999
+	maxDBPingAttempts    = 30
 )
 
 var (
 	// ErrCancelled is an error returned by Session methods when request has
 	// been cancelled (ex. context cancelled).
 	ErrCancelled = errors.New("canceling statement due to user request")
+	// ErrStatementTimeout is an error returned by Session methods when a query
+	// runs longer than the statement timeout of the session.
+	ErrStatementTimeout = errors.New("canceling statement due to statement timeout")
+	// ErrQueryCostExceeded is an error returned by Session methods when the
+	// cost of a query estimated by the query planner is higher than the
+	// MaxQueryCost of the session.
+	ErrQueryCostExceeded = errors.New("query cost exceeds the maximum query cost")
 )
 
 // Conn represents a connection to a single database.
@@ -104,6 +114,11 @@ type Session struct {
 	// Ctx is the context in which the repo is operating under.
 	Ctx context.Context
 
+	// MaxQueryCost, when greater than zero, is the maximum total cost of the
+	// queries run by Get, Select and Query, as estimated by EXPLAIN. More
+	// expensive queries are not run and fail with ErrQueryCostExceeded.
+	MaxQueryCost float64
+
 	tx        *sqlx.Tx
 	txOptions *sql.TxOptions
 }
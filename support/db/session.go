@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -67,12 +68,13 @@ func (s *Session) GetTxOptions() *sql.TxOptions {
 }
 
 // Clone clones the receiver, returning a new instance backed by the same
-// context and db. The result will not be bound to any transaction that the
-// source is currently within.
+// context, db and maximum query cost. The result will not be bound to any
+// transaction that the source is currently within.
 func (s *Session) Clone() *Session {
 	return &Session{
-		DB:  s.DB,
-		Ctx: s.Ctx,
+		DB:           s.DB,
+		Ctx:          s.Ctx,
+		MaxQueryCost: s.MaxQueryCost,
 	}
 }
 
@@ -101,6 +103,15 @@ func (s *Session) Dialect() string {
 	return s.DB.DriverName()
 }
 
+// maxQueryParams returns the maximum number of parameters of a query in the
+// dialect of the session.
+func (s *Session) maxQueryParams() int {
+	if s.Dialect() == "sqlite3" {
+		return sqliteQueryMaxParams
+	}
+	return postgresQueryMaxParams
+}
+
 // DeleteRange deletes a range of rows from a sql table between `start` and
 // `end` (exclusive).
 func (s *Session) DeleteRange(
@@ -135,6 +146,10 @@ func (s *Session) GetRaw(dest interface{}, query string, args ...interface{}) er
 		return errors.Wrap(err, "replace placeholders failed")
 	}
 
+	if err = s.checkQueryCost(query, args); err != nil {
+		return err
+	}
+
 	start := time.Now()
 	err = s.conn().GetContext(s.Ctx, dest, query, args...)
 	s.log("get", start, query, args)
@@ -147,6 +162,11 @@ func (s *Session) GetRaw(dest interface{}, query string, args ...interface{}) er
 		return ErrCancelled
 	}
 
+	if s.timedOut(err) {
+		s.logLimitExceeded("statement_timeout", query)
+		return ErrStatementTimeout
+	}
+
 	if s.NoRows(err) {
 		return err
 	}
@@ -162,7 +182,18 @@ func (s *Session) GetTable(name string) *Table {
 	}
 }
 
+// TruncateTables deletes all rows of tables. SQLite doesn't support TRUNCATE so
+// the rows are deleted with DELETE statements instead.
 func (s *Session) TruncateTables(tables []string) error {
+	if s.Dialect() == "sqlite3" {
+		for _, table := range tables {
+			if _, err := s.ExecRaw("DELETE FROM " + table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	truncateCmd := fmt.Sprintf("truncate %s restart identity cascade", strings.Join(tables[:], ","))
 	_, err := s.ExecRaw(truncateCmd)
 	return err
@@ -216,6 +247,11 @@ func (s *Session) ExecRaw(query string, args ...interface{}) (sql.Result, error)
 		return nil, ErrCancelled
 	}
 
+	if s.timedOut(err) {
+		s.logLimitExceeded("statement_timeout", query)
+		return nil, ErrStatementTimeout
+	}
+
 	if s.NoRows(err) {
 		return nil, err
 	}
@@ -234,6 +270,59 @@ func (s *Session) cancelled(err error) bool {
 	return strings.Contains(err.Error(), "pq: canceling statement due to user request")
 }
 
+// timedOut returns true if the provided error resulted from a statement
+// timeout.
+func (s *Session) timedOut(err error) bool {
+	return strings.Contains(err.Error(), "pq: canceling statement due to statement timeout")
+}
+
+// checkQueryCost returns ErrQueryCostExceeded if the total cost of `query`
+// estimated by the query planner is higher than MaxQueryCost.
+func (s *Session) checkQueryCost(query string, args []interface{}) error {
+	if s.MaxQueryCost <= 0 || s.DB.DriverName() != "postgres" {
+		return nil
+	}
+
+	var plan []byte
+	err := s.conn().GetContext(s.Ctx, &plan, "EXPLAIN (FORMAT JSON) "+query, args...)
+	if err != nil {
+		if s.cancelled(err) {
+			return ErrCancelled
+		}
+
+		if s.timedOut(err) {
+			s.logLimitExceeded("statement_timeout", query)
+			return ErrStatementTimeout
+		}
+
+		return errors.Wrap(err, "explain failed")
+	}
+
+	var plans []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+		}
+	}
+	if err = json.Unmarshal(plan, &plans); err != nil {
+		return errors.Wrap(err, "could not decode query plan")
+	}
+	if len(plans) == 0 {
+		return errors.New("empty query plan")
+	}
+
+	if plans[0].Plan.TotalCost > s.MaxQueryCost {
+		log.
+			Ctx(s.logCtx()).
+			WithField("cost", plans[0].Plan.TotalCost).
+			WithField("max_cost", s.MaxQueryCost).
+			WithField("query_limit", "max_query_cost").
+			WithField("sql", query).
+			Warn("sql: query limit exceeded")
+		return ErrQueryCostExceeded
+	}
+	return nil
+}
+
 // Query runs `query`, returns a *sqlx.Rows instance
 func (s *Session) Query(query sq.Sqlizer) (*sqlx.Rows, error) {
 	sql, args, err := s.build(query)
@@ -250,6 +339,10 @@ func (s *Session) QueryRaw(query string, args ...interface{}) (*sqlx.Rows, error
 		return nil, errors.Wrap(err, "replace placeholders failed")
 	}
 
+	if err = s.checkQueryCost(query, args); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	result, err := s.conn().QueryxContext(s.Ctx, query, args...)
 	s.log("query", start, query, args)
@@ -262,6 +355,11 @@ func (s *Session) QueryRaw(query string, args ...interface{}) (*sqlx.Rows, error
 		return nil, ErrCancelled
 	}
 
+	if s.timedOut(err) {
+		s.logLimitExceeded("statement_timeout", query)
+		return nil, ErrStatementTimeout
+	}
+
 	if s.NoRows(err) {
 		return nil, err
 	}
@@ -281,6 +379,21 @@ func (s *Session) ReplacePlaceholders(query string) (string, error) {
 	return format.ReplacePlaceholders(query)
 }
 
+// SetStatementTimeout sets the statement timeout of the current transaction
+// using SET LOCAL, so it is reset when the transaction ends. Queries running
+// longer than `timeout` fail with ErrStatementTimeout. A timeout of 0 disables
+// the statement timeout.
+func (s *Session) SetStatementTimeout(timeout time.Duration) error {
+	if s.tx == nil {
+		return errors.New("not in transaction")
+	}
+
+	_, err := s.ExecRaw(fmt.Sprintf(
+		"SET LOCAL statement_timeout = %d", int64(timeout/time.Millisecond),
+	))
+	return err
+}
+
 // Rollback rolls back the current transaction
 func (s *Session) Rollback() error {
 	if s.tx == nil {
@@ -315,6 +428,10 @@ func (s *Session) SelectRaw(
 		return errors.Wrap(err, "replace placeholders failed")
 	}
 
+	if err = s.checkQueryCost(query, args); err != nil {
+		return err
+	}
+
 	start := time.Now()
 	err = s.conn().SelectContext(s.Ctx, dest, query, args...)
 	s.log("select", start, query, args)
@@ -327,6 +444,11 @@ func (s *Session) SelectRaw(
 		return ErrCancelled
 	}
 
+	if s.timedOut(err) {
+		s.logLimitExceeded("statement_timeout", query)
+		return ErrStatementTimeout
+	}
+
 	if s.NoRows(err) {
 		return err
 	}
@@ -383,6 +505,16 @@ func (s *Session) log(typ string, start time.Time, query string, args []interfac
 		Debugf("sql: %s", typ)
 }
 
+// logLimitExceeded logs a warning for a query which exceeded `limit`. The
+// query_limit field is counted by the log metrics of aurora.
+func (s *Session) logLimitExceeded(limit, query string) {
+	log.
+		Ctx(s.logCtx()).
+		WithField("query_limit", limit).
+		WithField("sql", query).
+		Warn("sql: query limit exceeded")
+}
+
 func (s *Session) logBegin() {
 	log.Ctx(s.logCtx()).Debug("sql: begin")
 }
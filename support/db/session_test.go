@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hcnet/go/support/db/dbtest"
 	"github.com/stretchr/testify/assert"
@@ -90,3 +91,75 @@ func TestSession(t *testing.T) {
 		assert.Equal("$1 = $2 = $3 = ?", out)
 	}
 }
+
+func TestSessionQueryLimits(t *testing.T) {
+	db := dbtest.Postgres(t).Load(testSchema)
+	defer db.Close()
+
+	assert := assert.New(t)
+	require := require.New(t)
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	// SET LOCAL only applies to transactions
+	assert.EqualError(sess.SetStatementTimeout(time.Millisecond), "not in transaction")
+
+	require.NoError(sess.Begin(), "begin failed")
+	require.NoError(sess.SetStatementTimeout(10 * time.Millisecond))
+	_, err := sess.ExecRaw("SELECT pg_sleep(1)")
+	assert.Equal(ErrStatementTimeout, err)
+	assert.NoError(sess.Rollback(), "rollback failed")
+
+	// the timeout ends with the transaction
+	_, err = sess.ExecRaw("SELECT pg_sleep(0.05)")
+	assert.NoError(err)
+
+	var names []string
+	sess.MaxQueryCost = 0.001
+	err = sess.SelectRaw(&names, "SELECT name FROM people")
+	assert.Equal(ErrQueryCostExceeded, err)
+	assert.Equal(ErrQueryCostExceeded, sess.Clone().SelectRaw(&names, "SELECT name FROM people"))
+
+	sess.MaxQueryCost = 1000000
+	err = sess.SelectRaw(&names, "SELECT name FROM people WHERE hunger_level > ?", 0)
+	assert.NoError(err)
+	assert.Len(names, 3)
+}
+
+func TestSessionSqlite(t *testing.T) {
+	db := dbtest.Sqlite(t).Load(testSchema)
+	defer db.Close()
+
+	assert := assert.New(t)
+	sess := &Session{DB: db.Open(), Ctx: context.Background()}
+	defer sess.DB.Close()
+
+	assert.Equal("sqlite3", sess.Dialect())
+
+	var name string
+	err := sess.GetRaw(
+		&name,
+		"SELECT name FROM people WHERE hunger_level = ? AND name != '??'",
+		1000000,
+	)
+	assert.NoError(err)
+	assert.Equal("scott", name)
+
+	out, err := sess.ReplacePlaceholders("? = ? = ??")
+	if assert.NoError(err) {
+		assert.Equal("? = ? = ??", out)
+	}
+}
+
+func TestSessionTruncateTables(t *testing.T) {
+	dbtest.Dialects(t, func(t *testing.T, db *dbtest.DB) {
+		db.Load(testSchema)
+		sess := &Session{DB: db.Open(), Ctx: context.Background()}
+		defer sess.DB.Close()
+
+		require.NoError(t, sess.TruncateTables([]string{"people"}))
+		var count int
+		require.NoError(t, sess.GetRaw(&count, "SELECT COUNT(*) FROM people"))
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,10 @@
+// Package sqlite registers the "sqlite3" driver with database/sql so SQLite
+// databases can be opened with db.Open. The driver requires cgo, which is why
+// it isn't registered by package db itself: only programs and tests using
+// SQLite should import this package.
+package sqlite
+
+import (
+	// Enable sqlite
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -16,13 +16,20 @@ import (
 	"strings"
 
 	"github.com/howeyc/gopass"
+	"github.com/hcnet/go/keypair/pkcs11signer"
 	"github.com/hcnet/go/txnbuild"
-	"github.com/hcnet/go/xdr"
+	"github.com/hcnet/go/txnbuild/remotesigner"
 )
 
 var in *bufio.Reader
 
 var infile = flag.String("infile", "", "transaction envelope")
+var compare = flag.String("compare", "", "previously reviewed transaction envelope to compare with")
+var pkcs11Module = flag.String("pkcs11-module", "", "PKCS#11 library holding the signing key instead of a seed")
+var pkcs11Token = flag.String("pkcs11-token", "", "label of the PKCS#11 token")
+var pkcs11Key = flag.String("pkcs11-key", "", "label of the key on the PKCS#11 token")
+var remoteSigner = flag.String("remote-signer", "", "URL of a remote signer holding the signing key instead of a seed, the token is read from HCNET_SIGN_TOKEN")
+var remoteSignerAddress = flag.String("remote-signer-address", "", "address of the key held by the remote signer")
 
 func main() {
 	flag.Parse()
@@ -53,41 +60,54 @@ func main() {
 			log.Fatal(err)
 		}
 
-		env = string(raw)
+		env = strings.TrimSpace(string(raw))
 	}
 
 	// parse the envelope
-	var txe xdr.TransactionEnvelope
-	err = xdr.SafeUnmarshalBase64(env, &txe)
+	description, err := txnbuild.DescribeXDR(env)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	fmt.Println("")
 	fmt.Println("Transaction Summary:")
-	sourceAccount := txe.SourceAccount().ToAccountId()
-	fmt.Printf("  type: %s\n", txe.Type.String())
-	fmt.Printf("  source: %s\n", sourceAccount.Address())
-	fmt.Printf("  ops: %d\n", len(txe.Operations()))
-	fmt.Printf("  sigs: %d\n", len(txe.Signatures()))
-	if txe.IsFeeBump() {
-		fmt.Printf("  fee bump sigs: %d\n", len(txe.FeeBumpSignatures()))
-	}
+	fmt.Print(description.String())
 	fmt.Println("")
 
-	// TODO: add operation details
+	if warnings := description.Warnings(); len(warnings) > 0 {
+		fmt.Println("WARNING: this transaction makes dangerous changes:")
+		for _, warning := range warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+		fmt.Println("")
+	}
 
-	// read seed
-	seed, err := readLine("Enter seed: ", true)
-	if err != nil {
-		log.Fatal(err)
+	if *compare != "" {
+		var previous []byte
+		previous, err = ioutil.ReadFile(*compare)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var differences []txnbuild.Difference
+		differences, err = txnbuild.DiffXDR(strings.TrimSpace(string(previous)), env)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Differences with %s:\n", *compare)
+		if len(differences) == 0 {
+			fmt.Println("  none")
+		}
+		for _, difference := range differences {
+			fmt.Printf("  %s: %q -> %q\n", difference.Field, difference.Old, difference.New)
+		}
+		fmt.Println("")
 	}
 
-	// sign the transaction
-	kp, err := keypair.ParseFull(seed)
+	signer, closeSigner, err := readSigner()
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer closeSigner()
 
 	parsed, err := txnbuild.TransactionFromXDR(env)
 	if err != nil {
@@ -96,7 +116,7 @@ func main() {
 
 	var newEnv string
 	if tx, ok := parsed.Transaction(); ok {
-		tx, err = tx.Sign(network.PublicNetworkPassphrase, kp)
+		tx, err = tx.SignWith(network.PublicNetworkPassphrase, signer)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -106,7 +126,7 @@ func main() {
 		}
 	} else {
 		tx, _ := parsed.FeeBump()
-		tx, err = tx.Sign(network.PublicNetworkPassphrase, kp)
+		tx, err = tx.SignWith(network.PublicNetworkPassphrase, signer)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -123,6 +143,44 @@ func main() {
 
 }
 
+// readSigner returns the signer selected by the flags, prompting for a seed
+// when neither a PKCS#11 token nor a remote signer is configured.
+func readSigner() (keypair.Signer, func(), error) {
+	switch {
+	case *pkcs11Module != "":
+		pin, err := readLine("Enter PIN: ", true)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, err := pkcs11signer.New(pkcs11signer.Config{
+			Module:     *pkcs11Module,
+			TokenLabel: *pkcs11Token,
+			PIN:        pin,
+			KeyLabel:   *pkcs11Key,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return signer, func() { signer.Close() }, nil
+	case *remoteSigner != "":
+		return &remotesigner.Client{
+			URL:           *remoteSigner,
+			SignerAddress: *remoteSignerAddress,
+			Token:         os.Getenv("HCNET_SIGN_TOKEN"),
+		}, func() {}, nil
+	default:
+		seed, err := readLine("Enter seed: ", true)
+		if err != nil {
+			return nil, nil, err
+		}
+		kp, err := keypair.ParseFull(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return kp, func() {}, nil
+	}
+}
+
 func readLine(prompt string, private bool) (string, error) {
 	fmt.Println(prompt)
 	var line string
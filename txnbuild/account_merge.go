@@ -39,8 +39,7 @@ func (am *AccountMerge) FromXDR(xdrOp xdr.Operation) error {
 
 	am.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
 	if xdrOp.Body.Destination != nil {
-		aid := xdrOp.Body.Destination.ToAccountId()
-		am.Destination = aid.Address()
+		am.Destination = xdrOp.Body.Destination.Address()
 	}
 
 	return nil
@@ -49,7 +48,7 @@ func (am *AccountMerge) FromXDR(xdrOp xdr.Operation) error {
 // Validate for AccountMerge validates the required struct fields. It returns an error if any of the fields are
 // invalid. Otherwise, it returns nil.
 func (am *AccountMerge) Validate() error {
-	_, err := xdr.AddressToAccountId(am.Destination)
+	_, err := xdr.AddressToMuxedAccount(am.Destination)
 	if err != nil {
 		return NewValidationError("Destination", err.Error())
 	}
@@ -0,0 +1,631 @@
+package txnbuild
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/xdr"
+)
+
+// DescriptionField is a named value of a transaction or operation
+// description.
+type DescriptionField struct {
+	Name  string
+	Value string
+}
+
+// OperationDescription is a human-readable summary of an operation.
+type OperationDescription struct {
+	Index int
+	// Type is the snake case name of the operation, e.g. "payment".
+	Type string
+	// SourceAccount is empty when the operation uses the transaction source
+	// account.
+	SourceAccount string
+	// Sponsor is the account sponsoring the reserves of the operation when it
+	// is between BeginSponsoringFutureReserves and
+	// EndSponsoringFutureReserves operations.
+	Sponsor string
+	// Summary describes the operation in one sentence.
+	Summary string
+	Fields  []DescriptionField
+	// Warnings lists dangerous changes made by the operation, for example
+	// removing the master key.
+	Warnings []string
+}
+
+// Description is a human-readable summary of a transaction, used to review
+// transactions before signing them.
+type Description struct {
+	FeeBump bool
+	// FeeAccount is the account paying the fee of a fee bump transaction.
+	FeeAccount    string
+	MaxFee        int64
+	SourceAccount string
+	Sequence      int64
+	Memo          string
+	MinTime       int64
+	MaxTime       int64
+	Signatures    int
+	Operations    []OperationDescription
+}
+
+// Difference is a field which differs between two descriptions. Old or New
+// is empty when the field is missing from one of them.
+type Difference struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Describe returns a human-readable summary of the transaction.
+func (t *Transaction) Describe() Description {
+	description := Description{
+		MaxFee:        t.MaxFee(),
+		SourceAccount: t.sourceAccount.AccountID,
+		Sequence:      t.sourceAccount.Sequence,
+		Memo:          describeMemo(t.memo),
+		MinTime:       t.timebounds.MinTime,
+		MaxTime:       t.timebounds.MaxTime,
+		Signatures:    len(t.signatures),
+	}
+
+	var sponsor string
+	for i, op := range t.operations {
+		opDescription := describeOperation(op)
+		opDescription.Index = i
+		opSource := t.sourceAccount.AccountID
+		if opDescription.SourceAccount != "" {
+			opSource = opDescription.SourceAccount
+		}
+
+		switch op.(type) {
+		case *BeginSponsoringFutureReserves:
+			sponsor = opSource
+		case *EndSponsoringFutureReserves:
+			opDescription.Sponsor = sponsor
+			sponsor = ""
+		default:
+			opDescription.Sponsor = sponsor
+		}
+		description.Operations = append(description.Operations, opDescription)
+	}
+	return description
+}
+
+// Describe returns a human-readable summary of the fee bump transaction
+// including its inner transaction.
+func (t *FeeBumpTransaction) Describe() Description {
+	description := t.inner.Describe()
+	description.FeeBump = true
+	description.FeeAccount = t.feeAccount
+	description.MaxFee = t.MaxFee()
+	description.Signatures = len(t.signatures)
+	return description
+}
+
+// DescribeXDR returns a human-readable summary of a base64 encoded
+// transaction envelope.
+func DescribeXDR(txeB64 string) (Description, error) {
+	parsed, err := TransactionFromXDR(txeB64)
+	if err != nil {
+		return Description{}, err
+	}
+	if tx, ok := parsed.Transaction(); ok {
+		return tx.Describe(), nil
+	}
+	tx, _ := parsed.FeeBump()
+	return tx.Describe(), nil
+}
+
+// Warnings returns the warnings of all operations prefixed by the operation
+// number.
+func (d Description) Warnings() []string {
+	var warnings []string
+	for _, op := range d.Operations {
+		for _, warning := range op.Warnings {
+			warnings = append(warnings, fmt.Sprintf("operation %d: %s", op.Index+1, warning))
+		}
+	}
+	return warnings
+}
+
+// String renders the description as plain text.
+func (d Description) String() string {
+	var b strings.Builder
+	if d.FeeBump {
+		b.WriteString("Fee bump transaction\n")
+		fmt.Fprintf(&b, "  fee account: %s\n", d.FeeAccount)
+	} else {
+		b.WriteString("Transaction\n")
+	}
+	fmt.Fprintf(&b, "  source: %s\n", d.SourceAccount)
+	fmt.Fprintf(&b, "  sequence: %d\n", d.Sequence)
+	fmt.Fprintf(&b, "  max fee: %d stroops\n", d.MaxFee)
+	if d.Memo != "" {
+		fmt.Fprintf(&b, "  memo: %s\n", d.Memo)
+	}
+	fmt.Fprintf(&b, "  valid: %s\n", describeTimebounds(d.MinTime, d.MaxTime))
+	fmt.Fprintf(&b, "  signatures: %d\n", d.Signatures)
+
+	fmt.Fprintf(&b, "Operations (%d)\n", len(d.Operations))
+	for _, op := range d.Operations {
+		indent := "  "
+		if op.Sponsor != "" {
+			indent = "  | "
+		}
+		fmt.Fprintf(&b, "%s%d. %s\n", indent, op.Index+1, op.Summary)
+		if op.SourceAccount != "" {
+			fmt.Fprintf(&b, "%s     source: %s\n", indent, op.SourceAccount)
+		}
+		if op.Sponsor != "" {
+			fmt.Fprintf(&b, "%s     sponsored by: %s\n", indent, op.Sponsor)
+		}
+		for _, warning := range op.Warnings {
+			fmt.Fprintf(&b, "%s     WARNING: %s\n", indent, warning)
+		}
+	}
+	return b.String()
+}
+
+// fields flattens the description to compare descriptions.
+func (d Description) fields() []DescriptionField {
+	fields := []DescriptionField{
+		{"fee_bump", strconv.FormatBool(d.FeeBump)},
+		{"fee_account", d.FeeAccount},
+		{"max_fee", strconv.FormatInt(d.MaxFee, 10)},
+		{"source_account", d.SourceAccount},
+		{"sequence", strconv.FormatInt(d.Sequence, 10)},
+		{"memo", d.Memo},
+		{"min_time", strconv.FormatInt(d.MinTime, 10)},
+		{"max_time", strconv.FormatInt(d.MaxTime, 10)},
+		{"signatures", strconv.Itoa(d.Signatures)},
+	}
+	for _, op := range d.Operations {
+		prefix := fmt.Sprintf("operations[%d].", op.Index)
+		fields = append(fields,
+			DescriptionField{prefix + "type", op.Type},
+			DescriptionField{prefix + "source_account", op.SourceAccount},
+			DescriptionField{prefix + "sponsor", op.Sponsor},
+		)
+		for _, field := range op.Fields {
+			fields = append(fields, DescriptionField{prefix + field.Name, field.Value})
+		}
+	}
+	return fields
+}
+
+// DiffDescriptions returns the fields which differ between two descriptions.
+func DiffDescriptions(before, after Description) []Difference {
+	oldFields := before.fields()
+	newFields := after.fields()
+	newValues := make(map[string]string, len(newFields))
+	for _, field := range newFields {
+		newValues[field.Name] = field.Value
+	}
+
+	var differences []Difference
+	seen := make(map[string]bool, len(oldFields))
+	for _, field := range oldFields {
+		seen[field.Name] = true
+		if value := newValues[field.Name]; value != field.Value {
+			differences = append(differences, Difference{Field: field.Name, Old: field.Value, New: value})
+		}
+	}
+	for _, field := range newFields {
+		if !seen[field.Name] && field.Value != "" {
+			differences = append(differences, Difference{Field: field.Name, New: field.Value})
+		}
+	}
+	return differences
+}
+
+// DiffXDR returns the fields which differ between two base64 encoded
+// transaction envelopes.
+func DiffXDR(oldB64, newB64 string) ([]Difference, error) {
+	before, err := DescribeXDR(oldB64)
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing old transaction")
+	}
+	after, err := DescribeXDR(newB64)
+	if err != nil {
+		return nil, errors.Wrap(err, "error describing new transaction")
+	}
+	return DiffDescriptions(before, after), nil
+}
+
+func describeMemo(memo Memo) string {
+	switch m := memo.(type) {
+	case MemoText:
+		return fmt.Sprintf("text %q", string(m))
+	case MemoID:
+		return fmt.Sprintf("id %d", uint64(m))
+	case MemoHash:
+		return "hash " + hex.EncodeToString(m[:])
+	case MemoReturn:
+		return "return " + hex.EncodeToString(m[:])
+	default:
+		return ""
+	}
+}
+
+func describeTimebounds(minTime, maxTime int64) string {
+	from := "any time"
+	if minTime > 0 {
+		from = time.Unix(minTime, 0).UTC().Format(time.RFC3339)
+	}
+	if maxTime == TimeoutInfinite {
+		return "from " + from
+	}
+	return fmt.Sprintf("from %s until %s", from, time.Unix(maxTime, 0).UTC().Format(time.RFC3339))
+}
+
+func describeAsset(asset Asset) string {
+	if asset == nil {
+		return ""
+	}
+	if asset.IsNative() {
+		return "native"
+	}
+	return asset.GetCode() + ":" + asset.GetIssuer()
+}
+
+func describePath(path []Asset) string {
+	assets := make([]string, len(path))
+	for i, asset := range path {
+		assets[i] = describeAsset(asset)
+	}
+	return strings.Join(assets, ", ")
+}
+
+func describePredicate(predicate xdr.ClaimPredicate) string {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return "unconditional"
+	case xdr.ClaimPredicateTypeClaimPredicateAnd, xdr.ClaimPredicateTypeClaimPredicateOr:
+		predicates := predicate.AndPredicates
+		operator := " and "
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			predicates = predicate.OrPredicates
+			operator = " or "
+		}
+		if predicates == nil {
+			return ""
+		}
+		parts := make([]string, len(*predicates))
+		for i, p := range *predicates {
+			parts[i] = "(" + describePredicate(p) + ")"
+		}
+		return strings.Join(parts, operator)
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		if predicate.NotPredicate == nil || *predicate.NotPredicate == nil {
+			return "not"
+		}
+		return "not (" + describePredicate(**predicate.NotPredicate) + ")"
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		if predicate.AbsBefore == nil {
+			return ""
+		}
+		return "before " + time.Unix(int64(*predicate.AbsBefore), 0).UTC().Format(time.RFC3339)
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		if predicate.RelBefore == nil {
+			return ""
+		}
+		return fmt.Sprintf("within %d seconds", int64(*predicate.RelBefore))
+	default:
+		return predicate.Type.String()
+	}
+}
+
+var accountFlagNames = []struct {
+	flag AccountFlag
+	name string
+}{
+	{AuthRequired, "AUTH_REQUIRED"},
+	{AuthRevocable, "AUTH_REVOCABLE"},
+	{AuthImmutable, "AUTH_IMMUTABLE"},
+}
+
+func describeFlags(flags []AccountFlag) string {
+	var names []string
+	for _, f := range accountFlagNames {
+		for _, flag := range flags {
+			if flag&f.flag != 0 {
+				names = append(names, f.name)
+				break
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func hasFlag(flags []AccountFlag, flag AccountFlag) bool {
+	for _, f := range flags {
+		if f&flag != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// describeOperation returns the description of op without its index and
+// sponsor.
+func describeOperation(op Operation) OperationDescription {
+	var d OperationDescription
+	if source := op.GetSourceAccount(); source != nil {
+		d.SourceAccount = source.GetAccountID()
+	}
+	field := func(name, value string) {
+		d.Fields = append(d.Fields, DescriptionField{name, value})
+	}
+
+	switch o := op.(type) {
+	case *CreateAccount:
+		d.Type = "create_account"
+		d.Summary = fmt.Sprintf("Create account %s with %s native", o.Destination, o.Amount)
+		field("destination", o.Destination)
+		field("amount", o.Amount)
+	case *Payment:
+		d.Type = "payment"
+		d.Summary = fmt.Sprintf("Pay %s %s to %s", o.Amount, describeAsset(o.Asset), o.Destination)
+		field("destination", o.Destination)
+		field("asset", describeAsset(o.Asset))
+		field("amount", o.Amount)
+	case *PathPayment:
+		d.Type = "path_payment_strict_receive"
+		d.Summary = fmt.Sprintf("Pay %s %s to %s, sending at most %s %s",
+			o.DestAmount, describeAsset(o.DestAsset), o.Destination, o.SendMax, describeAsset(o.SendAsset))
+		field("destination", o.Destination)
+		field("send_asset", describeAsset(o.SendAsset))
+		field("send_max", o.SendMax)
+		field("dest_asset", describeAsset(o.DestAsset))
+		field("dest_amount", o.DestAmount)
+		field("path", describePath(o.Path))
+	case *PathPaymentStrictSend:
+		d.Type = "path_payment_strict_send"
+		d.Summary = fmt.Sprintf("Send %s %s to %s, delivering at least %s %s",
+			o.SendAmount, describeAsset(o.SendAsset), o.Destination, o.DestMin, describeAsset(o.DestAsset))
+		field("destination", o.Destination)
+		field("send_asset", describeAsset(o.SendAsset))
+		field("send_amount", o.SendAmount)
+		field("dest_asset", describeAsset(o.DestAsset))
+		field("dest_min", o.DestMin)
+		field("path", describePath(o.Path))
+	case *ManageSellOffer:
+		d.Type = "manage_sell_offer"
+		d.Summary = describeOffer("Sell", o.Amount, o.Selling, o.Buying, o.Price, o.OfferID)
+		describeOfferFields(field, o.Selling, o.Buying, o.Amount, o.Price, o.OfferID)
+	case *ManageBuyOffer:
+		d.Type = "manage_buy_offer"
+		d.Summary = describeOffer("Buy", o.Amount, o.Buying, o.Selling, o.Price, o.OfferID)
+		describeOfferFields(field, o.Selling, o.Buying, o.Amount, o.Price, o.OfferID)
+	case *CreatePassiveSellOffer:
+		d.Type = "create_passive_sell_offer"
+		d.Summary = fmt.Sprintf("Passively sell %s %s for %s at price %s",
+			o.Amount, describeAsset(o.Selling), describeAsset(o.Buying), o.Price)
+		describeOfferFields(field, o.Selling, o.Buying, o.Amount, o.Price, 0)
+	case *SetOptions:
+		d.Type = "set_options"
+		describeSetOptions(o, &d, field)
+	case *ChangeTrust:
+		d.Type = "change_trust"
+		if o.Limit == "0" || o.Limit == "0.0000000" {
+			d.Summary = fmt.Sprintf("Remove trustline to %s", describeAsset(o.Line))
+		} else if o.Limit == "" || o.Limit == MaxTrustlineLimit {
+			d.Summary = fmt.Sprintf("Trust %s without limit", describeAsset(o.Line))
+		} else {
+			d.Summary = fmt.Sprintf("Trust %s up to %s", describeAsset(o.Line), o.Limit)
+		}
+		field("asset", describeAsset(o.Line))
+		field("limit", o.Limit)
+	case *AllowTrust:
+		d.Type = "allow_trust"
+		asset := ""
+		if o.Type != nil {
+			asset = o.Type.GetCode()
+		}
+		switch {
+		case o.Authorize:
+			d.Summary = fmt.Sprintf("Authorize %s to hold %s", o.Trustor, asset)
+		case o.AuthorizeToMaintainLiabilities:
+			d.Summary = fmt.Sprintf("Authorize %s to maintain liabilities in %s", o.Trustor, asset)
+		default:
+			d.Summary = fmt.Sprintf("Revoke authorization of %s to hold %s", o.Trustor, asset)
+			d.Warnings = append(d.Warnings, "revokes the trustline authorization of "+o.Trustor)
+		}
+		field("trustor", o.Trustor)
+		field("asset_code", asset)
+		field("authorize", strconv.FormatBool(o.Authorize))
+		field("authorize_to_maintain_liabilities", strconv.FormatBool(o.AuthorizeToMaintainLiabilities))
+	case *AccountMerge:
+		d.Type = "account_merge"
+		d.Summary = fmt.Sprintf("Merge account into %s", o.Destination)
+		d.Warnings = append(d.Warnings, "deletes the source account and sends its native balance to "+o.Destination)
+		field("destination", o.Destination)
+	case *Inflation:
+		d.Type = "inflation"
+		d.Summary = "Run inflation"
+	case *ManageData:
+		d.Type = "manage_data"
+		if o.Value == nil {
+			d.Summary = fmt.Sprintf("Delete data entry %q", o.Name)
+		} else {
+			d.Summary = fmt.Sprintf("Set data entry %q to %s", o.Name, describeDataValue(o.Value))
+		}
+		field("name", o.Name)
+		field("value", describeDataValue(o.Value))
+	case *BumpSequence:
+		d.Type = "bump_sequence"
+		d.Summary = fmt.Sprintf("Bump sequence number to %d", o.BumpTo)
+		d.Warnings = append(d.Warnings, "bumps the sequence number, invalidating transactions with lower sequence numbers")
+		field("bump_to", strconv.FormatInt(o.BumpTo, 10))
+	case *CreateClaimableBalance:
+		d.Type = "create_claimable_balance"
+		d.Summary = fmt.Sprintf("Create claimable balance of %s %s for %d claimants",
+			o.Amount, describeAsset(o.Asset), len(o.Destinations))
+		field("asset", describeAsset(o.Asset))
+		field("amount", o.Amount)
+		for i, claimant := range o.Destinations {
+			field(fmt.Sprintf("claimants[%d]", i), claimant.Destination+" "+describePredicate(claimant.Predicate))
+		}
+	case *ClaimClaimableBalance:
+		d.Type = "claim_claimable_balance"
+		d.Summary = fmt.Sprintf("Claim balance %s", o.BalanceID)
+		field("balance_id", o.BalanceID)
+	case *BeginSponsoringFutureReserves:
+		d.Type = "begin_sponsoring_future_reserves"
+		d.Summary = fmt.Sprintf("Begin sponsoring the reserves of %s", o.SponsoredID)
+		field("sponsored_id", o.SponsoredID)
+	case *EndSponsoringFutureReserves:
+		d.Type = "end_sponsoring_future_reserves"
+		d.Summary = "End sponsoring future reserves"
+	case *RevokeSponsorship:
+		d.Type = "revoke_sponsorship"
+		d.Summary = "Revoke sponsorship of " + describeRevokedSponsorship(o)
+		field("sponsorship", describeRevokedSponsorship(o))
+	default:
+		d.Type = fmt.Sprintf("%T", op)
+		d.Summary = d.Type
+	}
+	return d
+}
+
+func describeOffer(action, amount string, asset, counter Asset, price string, offerID int64) string {
+	if offerID != 0 && (amount == "0" || amount == "0.0000000") {
+		return fmt.Sprintf("Delete offer %d", offerID)
+	}
+	summary := fmt.Sprintf("%s %s %s for %s at price %s",
+		action, amount, describeAsset(asset), describeAsset(counter), price)
+	if offerID != 0 {
+		summary += fmt.Sprintf(" (updates offer %d)", offerID)
+	}
+	return summary
+}
+
+func describeOfferFields(field func(name, value string), selling, buying Asset, amount, price string, offerID int64) {
+	field("selling", describeAsset(selling))
+	field("buying", describeAsset(buying))
+	field("amount", amount)
+	field("price", price)
+	field("offer_id", strconv.FormatInt(offerID, 10))
+}
+
+func describeSetOptions(o *SetOptions, d *OperationDescription, field func(name, value string)) {
+	var changes []string
+	if o.InflationDestination != nil {
+		changes = append(changes, "inflation destination to "+*o.InflationDestination)
+		field("inflation_destination", *o.InflationDestination)
+	}
+	if len(o.SetFlags) > 0 {
+		flags := describeFlags(o.SetFlags)
+		changes = append(changes, "set flags "+flags)
+		field("set_flags", flags)
+		if hasFlag(o.SetFlags, AuthRevocable) {
+			d.Warnings = append(d.Warnings, "sets AUTH_REVOCABLE, the issuer can revoke trustlines to its assets")
+		}
+		if hasFlag(o.SetFlags, AuthImmutable) {
+			d.Warnings = append(d.Warnings, "sets AUTH_IMMUTABLE, the authorization flags can never be changed and the account can never be merged")
+		}
+	}
+	if len(o.ClearFlags) > 0 {
+		flags := describeFlags(o.ClearFlags)
+		changes = append(changes, "clear flags "+flags)
+		field("clear_flags", flags)
+	}
+	if o.MasterWeight != nil {
+		changes = append(changes, fmt.Sprintf("master weight to %d", *o.MasterWeight))
+		field("master_weight", strconv.Itoa(int(*o.MasterWeight)))
+		if *o.MasterWeight == 0 {
+			d.Warnings = append(d.Warnings, "sets the master weight to 0, the master key can no longer sign")
+		}
+	}
+	thresholds := []struct {
+		name      string
+		threshold *Threshold
+	}{
+		{"low_threshold", o.LowThreshold},
+		{"medium_threshold", o.MediumThreshold},
+		{"high_threshold", o.HighThreshold},
+	}
+	var thresholdChanges []string
+	for _, t := range thresholds {
+		if t.threshold == nil {
+			continue
+		}
+		value := strconv.Itoa(int(*t.threshold))
+		thresholdChanges = append(thresholdChanges, strings.TrimSuffix(t.name, "_threshold")+" "+value)
+		field(t.name, value)
+	}
+	if len(thresholdChanges) > 0 {
+		changes = append(changes, "thresholds to "+strings.Join(thresholdChanges, ", "))
+		d.Warnings = append(d.Warnings, "changes the signing thresholds to "+strings.Join(thresholdChanges, ", "))
+	}
+	if o.HomeDomain != nil {
+		changes = append(changes, fmt.Sprintf("home domain to %q", *o.HomeDomain))
+		field("home_domain", *o.HomeDomain)
+	}
+	if o.Signer != nil {
+		field("signer", o.Signer.Address)
+		field("signer_weight", strconv.Itoa(int(o.Signer.Weight)))
+		if o.Signer.Weight == 0 {
+			changes = append(changes, "remove signer "+o.Signer.Address)
+			d.Warnings = append(d.Warnings, "removes signer "+o.Signer.Address)
+		} else {
+			changes = append(changes, fmt.Sprintf("signer %s with weight %d", o.Signer.Address, o.Signer.Weight))
+			d.Warnings = append(d.Warnings, fmt.Sprintf("adds or updates signer %s with weight %d", o.Signer.Address, o.Signer.Weight))
+		}
+	}
+
+	if len(changes) == 0 {
+		d.Summary = "Set options without changes"
+		return
+	}
+	d.Summary = "Set " + strings.Join(changes, "; ")
+}
+
+func describeDataValue(value []byte) string {
+	if value == nil {
+		return ""
+	}
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e {
+			return "base64 " + base64.StdEncoding.EncodeToString(value)
+		}
+	}
+	return strconv.Quote(string(value))
+}
+
+func describeRevokedSponsorship(o *RevokeSponsorship) string {
+	switch o.SponsorshipType {
+	case RevokeSponsorshipTypeAccount:
+		if o.Account != nil {
+			return "account " + *o.Account
+		}
+	case RevokeSponsorshipTypeTrustLine:
+		if o.TrustLine != nil {
+			return fmt.Sprintf("trustline of %s to %s", o.TrustLine.Account, describeAsset(o.TrustLine.Asset))
+		}
+	case RevokeSponsorshipTypeOffer:
+		if o.Offer != nil {
+			return fmt.Sprintf("offer %d of %s", o.Offer.OfferID, o.Offer.SellerAccountAddress)
+		}
+	case RevokeSponsorshipTypeData:
+		if o.Data != nil {
+			return fmt.Sprintf("data entry %q of %s", o.Data.DataName, o.Data.Account)
+		}
+	case RevokeSponsorshipTypeClaimableBalance:
+		if o.ClaimableBalance != nil {
+			return "claimable balance " + *o.ClaimableBalance
+		}
+	case RevokeSponsorshipTypeSigner:
+		if o.Signer != nil {
+			return fmt.Sprintf("signer %s of %s", o.Signer.SignerAddress, o.Signer.AccountID)
+		}
+	}
+	return "unknown entry"
+}
@@ -0,0 +1,154 @@
+package txnbuild
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	masterWeight := Threshold(0)
+	highThreshold := Threshold(3)
+	usd := CreditAsset{Code: "USD", Issuer: kp1.Address()}
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &sourceAccount,
+			IncrementSequenceNum: true,
+			Operations: []Operation{
+				&Payment{Destination: kp1.Address(), Amount: "10", Asset: usd},
+				&ManageSellOffer{Selling: NativeAsset{}, Buying: usd, Amount: "100", Price: "0.5"},
+				&BeginSponsoringFutureReserves{SponsoredID: kp1.Address()},
+				&ChangeTrust{Line: usd, SourceAccount: &SimpleAccount{AccountID: kp1.Address()}},
+				&EndSponsoringFutureReserves{SourceAccount: &SimpleAccount{AccountID: kp1.Address()}},
+				&SetOptions{
+					MasterWeight:  &masterWeight,
+					HighThreshold: &highThreshold,
+					SetFlags:      []AccountFlag{AuthRevocable},
+				},
+			},
+			BaseFee:    MinBaseFee,
+			Memo:       MemoText("order 24"),
+			Timebounds: NewTimebounds(0, 1600000000),
+		},
+	)
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+
+	description := tx.Describe()
+	assert.Equal(t, kp0.Address(), description.SourceAccount)
+	assert.Equal(t, int64(9605939170639898), description.Sequence)
+	assert.Equal(t, int64(600), description.MaxFee)
+	assert.Equal(t, `text "order 24"`, description.Memo)
+	assert.Equal(t, 1, description.Signatures)
+	require.Len(t, description.Operations, 6)
+
+	payment := description.Operations[0]
+	assert.Equal(t, "payment", payment.Type)
+	assert.Equal(t, "Pay 10 USD:"+kp1.Address()+" to "+kp1.Address(), payment.Summary)
+	assert.Equal(t, "Sell 100 native for USD:"+kp1.Address()+" at price 0.5", description.Operations[1].Summary)
+
+	assert.Equal(t, "", description.Operations[2].Sponsor)
+	assert.Equal(t, kp0.Address(), description.Operations[3].Sponsor)
+	assert.Equal(t, kp1.Address(), description.Operations[3].SourceAccount)
+	assert.Equal(t, "Trust USD:"+kp1.Address()+" without limit", description.Operations[3].Summary)
+	assert.Equal(t, kp0.Address(), description.Operations[4].Sponsor)
+
+	assert.Equal(t, []string{
+		"operation 6: sets AUTH_REVOCABLE, the issuer can revoke trustlines to its assets",
+		"operation 6: sets the master weight to 0, the master key can no longer sign",
+		"operation 6: changes the signing thresholds to high 3",
+	}, description.Warnings())
+
+	text := description.String()
+	assert.Contains(t, text, "  valid: from any time until 2020-09-13T12:26:40Z\n")
+	assert.Contains(t, text, "  | 4. Trust USD:"+kp1.Address()+" without limit\n")
+	assert.Contains(t, text, "  6. Set set flags AUTH_REVOCABLE; master weight to 0; thresholds to high 3\n")
+	assert.Contains(t, text, "     WARNING: sets the master weight to 0, the master key can no longer sign\n")
+
+	// Descriptions of decoded envelopes are the same.
+	envelope, err := tx.Base64()
+	require.NoError(t, err)
+	decoded, err := DescribeXDR(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, "Pay 10.0000000 USD:"+kp1.Address()+" to "+kp1.Address(), decoded.Operations[0].Summary)
+	assert.Equal(t, description.Warnings(), decoded.Warnings())
+}
+
+func TestDescribeFeeBump(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+	inner, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &sourceAccount,
+			IncrementSequenceNum: true,
+			Operations:           []Operation{&AccountMerge{Destination: kp1.Address()}},
+			BaseFee:              MinBaseFee,
+			Timebounds:           NewInfiniteTimeout(),
+		},
+	)
+	require.NoError(t, err)
+	feeBump, err := NewFeeBumpTransaction(FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: kp1.Address(),
+		BaseFee:    2 * MinBaseFee,
+	})
+	require.NoError(t, err)
+
+	description := feeBump.Describe()
+	assert.True(t, description.FeeBump)
+	assert.Equal(t, kp1.Address(), description.FeeAccount)
+	assert.Equal(t, int64(400), description.MaxFee)
+	assert.Equal(t, []string{
+		"operation 1: deletes the source account and sends its native balance to " + kp1.Address(),
+	}, description.Warnings())
+	assert.Contains(t, description.String(), "Fee bump transaction\n  fee account: "+kp1.Address()+"\n")
+}
+
+func TestDiffXDR(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	build := func(amount string, ops ...Operation) string {
+		sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+		tx, err := NewTransaction(
+			TransactionParams{
+				SourceAccount:        &sourceAccount,
+				IncrementSequenceNum: true,
+				Operations: append([]Operation{
+					&Payment{Destination: kp1.Address(), Amount: amount, Asset: NativeAsset{}},
+				}, ops...),
+				BaseFee:    MinBaseFee,
+				Timebounds: NewInfiniteTimeout(),
+			},
+		)
+		require.NoError(t, err)
+		envelope, err := tx.Base64()
+		require.NoError(t, err)
+		return envelope
+	}
+
+	before := build("10")
+	differences, err := DiffXDR(before, before)
+	require.NoError(t, err)
+	assert.Empty(t, differences)
+
+	after := build("20", &BumpSequence{BumpTo: 5})
+	differences, err = DiffXDR(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []Difference{
+		{Field: "max_fee", Old: "100", New: "200"},
+		{Field: "operations[0].amount", Old: "10.0000000", New: "20.0000000"},
+		{Field: "operations[1].type", New: "bump_sequence"},
+		{Field: "operations[1].bump_to", New: "5"},
+	}, differences)
+
+	_, err = DiffXDR(before, "AAAA")
+	assert.Error(t, err)
+}
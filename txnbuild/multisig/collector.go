@@ -0,0 +1,263 @@
+package multisig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/hcnet/go/keypair"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+)
+
+// Signer types as returned by Aurora.
+const (
+	signerTypeEd25519 = "ed25519_public_key"
+	signerTypePreAuth = "preauth_tx"
+	signerTypeHashX   = "sha256_hash"
+)
+
+// maxSignerWeight is the maximum weight a signer contributes, greater
+// weights are capped by hcnet-core.
+const maxSignerWeight = 255
+
+var (
+	// ErrInvalidSignature is returned when a signature has the hint of a
+	// signer of a source account but is not valid for the transaction.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExtraneousSignature is returned when a signature doesn't belong to
+	// any signer of the source accounts. Such signatures make the
+	// transaction fail with tx_bad_auth_extra.
+	ErrExtraneousSignature = errors.New("signature does not belong to any signer of the source accounts")
+	// ErrTransactionMismatch is returned when signatures are added from a
+	// different transaction.
+	ErrTransactionMismatch = errors.New("transaction hash does not match")
+)
+
+// AccountStatus is the signing progress of a source account.
+type AccountStatus struct {
+	AccountID string
+	Level     ThresholdLevel
+	// Threshold is the weight required. Transactions need at least one
+	// signature even if the threshold of the account is 0.
+	Threshold int32
+	Weight    int32
+	Missing   int32
+	// Signed lists the keys of the signers that signed.
+	Signed []string
+	// Unsigned lists the keys of the signers that did not sign yet.
+	Unsigned []string
+}
+
+// Complete returns true when the account reached its threshold.
+func (s AccountStatus) Complete() bool {
+	return s.Missing == 0
+}
+
+// Status is the signing progress of a transaction.
+type Status struct {
+	Accounts []AccountStatus
+}
+
+// Complete returns true when all source accounts reached their thresholds.
+func (s Status) Complete() bool {
+	for _, account := range s.Accounts {
+		if !account.Complete() {
+			return false
+		}
+	}
+	return true
+}
+
+// Collector merges the signatures of a transaction sent by several signers.
+// It is safe for concurrent use.
+type Collector struct {
+	tx                *txnbuild.Transaction
+	networkPassphrase string
+	hash              [32]byte
+	preAuthKey        string
+	requirements      []Requirement
+	accounts          map[string]hProtocol.Account
+
+	mutex      sync.Mutex
+	signatures []xdr.DecoratedSignature
+	signedBy   map[string]bool
+}
+
+// NewCollector returns a Collector for tx. accounts must contain all source
+// accounts of tx, see LoadAccounts. The signatures of tx are added to the
+// collector.
+func NewCollector(tx *txnbuild.Transaction, networkPassphrase string, accounts []hProtocol.Account) (*Collector, error) {
+	requirements, err := Requirements(tx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]hProtocol.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.AccountID] = account
+	}
+	for _, requirement := range requirements {
+		if _, ok := byID[requirement.AccountID]; !ok {
+			return nil, errors.Errorf("source account %s not loaded", requirement.AccountID)
+		}
+	}
+
+	hash, err := tx.Hash(networkPassphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "error hashing transaction")
+	}
+	preAuthKey, err := strkey.Encode(strkey.VersionByteHashTx, hash[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding transaction hash")
+	}
+	unsigned, err := tx.ClearSignatures()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Collector{
+		tx:                unsigned,
+		networkPassphrase: networkPassphrase,
+		hash:              hash,
+		preAuthKey:        preAuthKey,
+		requirements:      requirements,
+		accounts:          byID,
+		signedBy:          map[string]bool{},
+	}
+	if _, err := c.AddSignatures(tx.Signatures()...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add merges the signatures of signed, which must be the transaction of the
+// collector, and returns the number of new signatures. See AddSignatures.
+func (c *Collector) Add(signed *txnbuild.Transaction) (int, error) {
+	hash, err := signed.Hash(c.networkPassphrase)
+	if err != nil {
+		return 0, errors.Wrap(err, "error hashing transaction")
+	}
+	if hash != c.hash {
+		return 0, ErrTransactionMismatch
+	}
+	return c.AddSignatures(signed.Signatures()...)
+}
+
+// AddSignatures merges signatures and returns the number of new signatures.
+// Signatures already collected are skipped. If any signature is invalid or
+// extraneous an error is returned and none of the signatures are added.
+func (c *Collector) AddSignatures(signatures ...xdr.DecoratedSignature) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var added []xdr.DecoratedSignature
+	signedBy := map[string]bool{}
+	for i, signature := range signatures {
+		key, err := c.matchSignature(signature)
+		if err != nil {
+			return 0, errors.Wrapf(err, "signature %d", i)
+		}
+		if c.signedBy[key] || signedBy[key] {
+			continue
+		}
+		signedBy[key] = true
+		added = append(added, signature)
+	}
+
+	c.signatures = append(c.signatures, added...)
+	for key := range signedBy {
+		c.signedBy[key] = true
+	}
+	return len(added), nil
+}
+
+// matchSignature returns the key of the signer of signature.
+func (c *Collector) matchSignature(signature xdr.DecoratedSignature) (string, error) {
+	hintMatched := false
+	for _, requirement := range c.requirements {
+		for _, signer := range c.accounts[requirement.AccountID].Signers {
+			switch signer.Type {
+			case signerTypeEd25519:
+				kp, err := keypair.ParseAddress(signer.Key)
+				if err != nil || kp.Hint() != signature.Hint {
+					continue
+				}
+				hintMatched = true
+				if kp.Verify(c.hash[:], signature.Signature) == nil {
+					return signer.Key, nil
+				}
+			case signerTypeHashX:
+				raw, err := strkey.Decode(strkey.VersionByteHashX, signer.Key)
+				if err != nil || !bytes.Equal(raw[len(raw)-4:], signature.Hint[:]) {
+					continue
+				}
+				hintMatched = true
+				hash := sha256.Sum256(signature.Signature)
+				if bytes.Equal(hash[:], raw) {
+					return signer.Key, nil
+				}
+			}
+		}
+	}
+	if hintMatched {
+		return "", ErrInvalidSignature
+	}
+	return "", ErrExtraneousSignature
+}
+
+// Status returns the weight collected and missing for every source account.
+func (c *Collector) Status() Status {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	status := Status{Accounts: make([]AccountStatus, 0, len(c.requirements))}
+	for _, requirement := range c.requirements {
+		account := c.accounts[requirement.AccountID]
+		accountStatus := AccountStatus{
+			AccountID: requirement.AccountID,
+			Level:     requirement.Level,
+			Threshold: int32(requirement.Level.threshold(account.Thresholds)),
+		}
+		if accountStatus.Threshold == 0 {
+			accountStatus.Threshold = 1
+		}
+
+		for _, signer := range account.Signers {
+			weight := signer.Weight
+			if weight > maxSignerWeight {
+				weight = maxSignerWeight
+			}
+			if weight <= 0 {
+				continue
+			}
+			switch {
+			case c.signedBy[signer.Key]:
+			case signer.Type == signerTypePreAuth && signer.Key == c.preAuthKey:
+				// Pre-authorized transaction signers don't need a signature.
+			case signer.Type == signerTypePreAuth:
+				continue
+			default:
+				accountStatus.Unsigned = append(accountStatus.Unsigned, signer.Key)
+				continue
+			}
+			accountStatus.Signed = append(accountStatus.Signed, signer.Key)
+			accountStatus.Weight += weight
+		}
+
+		if accountStatus.Weight < accountStatus.Threshold {
+			accountStatus.Missing = accountStatus.Threshold - accountStatus.Weight
+		}
+		status.Accounts = append(status.Accounts, accountStatus)
+	}
+	return status
+}
+
+// Transaction returns the transaction with all signatures collected.
+func (c *Collector) Transaction() (*txnbuild.Transaction, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.tx.AddSignatureDecorated(c.signatures...)
+}
@@ -0,0 +1,156 @@
+package multisig
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ed25519Signer(kp keypair.KP, weight int32) hProtocol.Signer {
+	return hProtocol.Signer{Key: kp.Address(), Weight: weight, Type: signerTypeEd25519}
+}
+
+func TestCollector(t *testing.T) {
+	source := keypair.MustRandom()
+	signer1 := keypair.MustRandom()
+	signer2 := keypair.MustRandom()
+	stranger := keypair.MustRandom()
+
+	tx := buildTransaction(t, source, &txnbuild.Payment{
+		Destination: stranger.Address(),
+		Amount:      "10",
+		Asset:       txnbuild.NativeAsset{},
+	})
+	account := hProtocol.Account{
+		AccountID: source.Address(),
+		Thresholds: hProtocol.AccountThresholds{
+			LowThreshold:  1,
+			MedThreshold:  3,
+			HighThreshold: 5,
+		},
+		Signers: []hProtocol.Signer{
+			ed25519Signer(source, 1),
+			ed25519Signer(signer1, 1),
+			ed25519Signer(signer2, 2),
+		},
+	}
+
+	sourceSigned, err := tx.Sign(network.TestNetworkPassphrase, source)
+	require.NoError(t, err)
+	collector, err := NewCollector(sourceSigned, network.TestNetworkPassphrase, []hProtocol.Account{account})
+	require.NoError(t, err)
+
+	status := collector.Status()
+	assert.False(t, status.Complete())
+	require.Len(t, status.Accounts, 1)
+	assert.Equal(t, AccountStatus{
+		AccountID: source.Address(),
+		Level:     ThresholdMedium,
+		Threshold: 3,
+		Weight:    1,
+		Missing:   2,
+		Signed:    []string{source.Address()},
+		Unsigned:  []string{signer1.Address(), signer2.Address()},
+	}, status.Accounts[0])
+
+	// Signatures already collected are skipped.
+	signed, err := tx.Sign(network.TestNetworkPassphrase, source, signer1)
+	require.NoError(t, err)
+	added, err := collector.Add(signed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, int32(1), collector.Status().Accounts[0].Missing)
+
+	// Extraneous signatures are rejected together with valid ones.
+	signed, err = tx.Sign(network.TestNetworkPassphrase, signer2, stranger)
+	require.NoError(t, err)
+	_, err = collector.Add(signed)
+	assert.Equal(t, ErrExtraneousSignature, errors.Cause(err))
+	assert.EqualError(t, err, "signature 1: signature does not belong to any signer of the source accounts")
+	assert.False(t, collector.Status().Complete())
+
+	// Signatures of another transaction are invalid.
+	otherTx := buildTransaction(t, source, &txnbuild.BumpSequence{BumpTo: 1})
+	otherSigned, err := otherTx.Sign(network.TestNetworkPassphrase, signer2)
+	require.NoError(t, err)
+	_, err = collector.Add(otherSigned)
+	assert.Equal(t, ErrTransactionMismatch, err)
+	_, err = collector.AddSignatures(otherSigned.Signatures()...)
+	assert.Equal(t, ErrInvalidSignature, errors.Cause(err))
+
+	signed, err = tx.Sign(network.TestNetworkPassphrase, signer2)
+	require.NoError(t, err)
+	added, err = collector.Add(signed)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.True(t, collector.Status().Complete())
+
+	merged, err := collector.Transaction()
+	require.NoError(t, err)
+	assert.Len(t, merged.Signatures(), 3)
+	mergedHash, err := merged.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	txHash, err := tx.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, txHash, mergedHash)
+}
+
+func TestCollectorPreAuthAndHashX(t *testing.T) {
+	source := keypair.MustRandom()
+	tx := buildTransaction(t, source, &txnbuild.BumpSequence{BumpTo: 10})
+	txHash, err := tx.Hash(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	preimage := []byte("secret")
+	hashX := sha256.Sum256(preimage)
+	account := hProtocol.Account{
+		AccountID:  source.Address(),
+		Thresholds: hProtocol.AccountThresholds{LowThreshold: 2},
+		Signers: []hProtocol.Signer{
+			{Key: strkey.MustEncode(strkey.VersionByteHashTx, txHash[:]), Weight: 1, Type: signerTypePreAuth},
+			{Key: strkey.MustEncode(strkey.VersionByteHashX, hashX[:]), Weight: 1, Type: signerTypeHashX},
+			ed25519Signer(source, 0),
+		},
+	}
+
+	collector, err := NewCollector(tx, network.TestNetworkPassphrase, []hProtocol.Account{account})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), collector.Status().Accounts[0].Missing)
+
+	_, err = collector.AddSignatures(xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint{hashX[28], hashX[29], hashX[30], hashX[31]},
+		Signature: xdr.Signature("wrong"),
+	})
+	assert.Equal(t, ErrInvalidSignature, errors.Cause(err))
+
+	signed, err := tx.SignHashX(preimage)
+	require.NoError(t, err)
+	_, err = collector.Add(signed)
+	require.NoError(t, err)
+	assert.True(t, collector.Status().Complete())
+}
+
+func TestCollectorZeroThreshold(t *testing.T) {
+	source := keypair.MustRandom()
+	tx := buildTransaction(t, source, &txnbuild.BumpSequence{BumpTo: 10})
+	account := hProtocol.Account{
+		AccountID: source.Address(),
+		Signers:   []hProtocol.Signer{ed25519Signer(source, 1)},
+	}
+	collector, err := NewCollector(tx, network.TestNetworkPassphrase, []hProtocol.Account{account})
+	require.NoError(t, err)
+
+	// At least one signature is required.
+	status := collector.Status()
+	assert.Equal(t, int32(1), status.Accounts[0].Threshold)
+	assert.False(t, status.Complete())
+}
@@ -0,0 +1,148 @@
+/*
+Package multisig coordinates the signing of transactions which need
+signatures from several parties.
+
+Requirements computes the threshold every source account of a transaction
+requires. A Collector merges the signatures sent back by the co-signers,
+rejecting invalid or extraneous ones, and reports the weight still missing
+for every source account. EncodeURI and DecodeURI exchange partially signed
+transactions as SEP-7 web+hcnet:tx URIs.
+*/
+package multisig
+
+import (
+	"github.com/hcnet/go/clients/auroraclient"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+)
+
+// ThresholdLevel is the threshold category an operation or transaction
+// belongs to.
+type ThresholdLevel int
+
+// Threshold levels, in increasing order.
+const (
+	ThresholdLow ThresholdLevel = iota
+	ThresholdMedium
+	ThresholdHigh
+)
+
+// String returns the name of the threshold level.
+func (l ThresholdLevel) String() string {
+	switch l {
+	case ThresholdLow:
+		return "low"
+	case ThresholdMedium:
+		return "medium"
+	case ThresholdHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// threshold returns the threshold of the account for the level.
+func (l ThresholdLevel) threshold(thresholds hProtocol.AccountThresholds) byte {
+	switch l {
+	case ThresholdLow:
+		return thresholds.LowThreshold
+	case ThresholdMedium:
+		return thresholds.MedThreshold
+	default:
+		return thresholds.HighThreshold
+	}
+}
+
+// OperationThreshold returns the threshold level required to authorize op.
+func OperationThreshold(op txnbuild.Operation) ThresholdLevel {
+	switch o := op.(type) {
+	case *txnbuild.AllowTrust, *txnbuild.BumpSequence,
+		*txnbuild.ClaimClaimableBalance, *txnbuild.Inflation:
+		return ThresholdLow
+	case *txnbuild.AccountMerge:
+		return ThresholdHigh
+	case *txnbuild.SetOptions:
+		if o.MasterWeight != nil || o.LowThreshold != nil ||
+			o.MediumThreshold != nil || o.HighThreshold != nil || o.Signer != nil {
+			return ThresholdHigh
+		}
+		return ThresholdMedium
+	default:
+		return ThresholdMedium
+	}
+}
+
+// Requirement is the threshold level an account has to reach for a
+// transaction to be valid.
+type Requirement struct {
+	AccountID string
+	Level     ThresholdLevel
+}
+
+// Requirements returns the threshold level required from every source
+// account of tx, the transaction source account first followed by the
+// operation source accounts in order of appearance. Muxed accounts are
+// replaced by their underlying G... account.
+func Requirements(tx *txnbuild.Transaction) ([]Requirement, error) {
+	var requirements []Requirement
+	index := map[string]int{}
+	add := func(address string, level ThresholdLevel) error {
+		accountID, err := underlyingAccountID(address)
+		if err != nil {
+			return err
+		}
+		if i, ok := index[accountID]; ok {
+			if level > requirements[i].Level {
+				requirements[i].Level = level
+			}
+			return nil
+		}
+		index[accountID] = len(requirements)
+		requirements = append(requirements, Requirement{AccountID: accountID, Level: level})
+		return nil
+	}
+
+	source := tx.SourceAccount().AccountID
+	// The transaction source account authorizes the fee and sequence number.
+	if err := add(source, ThresholdLow); err != nil {
+		return nil, errors.Wrap(err, "invalid transaction source account")
+	}
+	for i, op := range tx.Operations() {
+		opSource := source
+		if account := op.GetSourceAccount(); account != nil {
+			opSource = account.GetAccountID()
+		}
+		if err := add(opSource, OperationThreshold(op)); err != nil {
+			return nil, errors.Wrapf(err, "invalid source account of operation %d", i)
+		}
+	}
+	return requirements, nil
+}
+
+// LoadAccounts loads the source accounts of tx from Aurora.
+func LoadAccounts(client auroraclient.ClientInterface, tx *txnbuild.Transaction) ([]hProtocol.Account, error) {
+	requirements, err := Requirements(tx)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]hProtocol.Account, 0, len(requirements))
+	for _, requirement := range requirements {
+		account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: requirement.AccountID})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error loading account %s", requirement.AccountID)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func underlyingAccountID(address string) (string, error) {
+	var muxed xdr.MuxedAccount
+	if err := muxed.SetAddress(address); err != nil {
+		return "", err
+	}
+	accountID := muxed.ToAccountId()
+	return accountID.GetAddress()
+}
@@ -0,0 +1,82 @@
+package multisig
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/clients/auroraclient"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	hProtocol "github.com/hcnet/go/protocols/aurora"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTransaction(t *testing.T, source *keypair.Full, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	account := txnbuild.NewSimpleAccount(source.Address(), 1)
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestRequirements(t *testing.T) {
+	source := keypair.MustRandom()
+	other := keypair.MustRandom()
+	weight := txnbuild.Threshold(2)
+
+	tx := buildTransaction(t, source,
+		&txnbuild.BumpSequence{BumpTo: 10},
+		&txnbuild.Payment{
+			Destination:   source.Address(),
+			Amount:        "10",
+			Asset:         txnbuild.NativeAsset{},
+			SourceAccount: &txnbuild.SimpleAccount{AccountID: other.Address()},
+		},
+		&txnbuild.SetOptions{HomeDomain: txnbuild.NewHomeDomain("example.com")},
+		&txnbuild.SetOptions{
+			MasterWeight:  &weight,
+			SourceAccount: &txnbuild.SimpleAccount{AccountID: other.Address()},
+		},
+	)
+	requirements, err := Requirements(tx)
+	require.NoError(t, err)
+	assert.Equal(t, []Requirement{
+		{AccountID: source.Address(), Level: ThresholdMedium},
+		{AccountID: other.Address(), Level: ThresholdHigh},
+	}, requirements)
+
+	tx = buildTransaction(t, source, &txnbuild.BumpSequence{BumpTo: 10})
+	requirements, err = Requirements(tx)
+	require.NoError(t, err)
+	assert.Equal(t, []Requirement{{AccountID: source.Address(), Level: ThresholdLow}}, requirements)
+}
+
+func TestLoadAccounts(t *testing.T) {
+	source := keypair.MustRandom()
+	other := keypair.MustRandom()
+	tx := buildTransaction(t, source, &txnbuild.AccountMerge{
+		Destination:   source.Address(),
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: other.Address()},
+	})
+
+	client := &auroraclient.MockClient{}
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: source.Address()}).
+		Return(hProtocol.Account{AccountID: source.Address()}, nil)
+	client.On("AccountDetail", auroraclient.AccountRequest{AccountID: other.Address()}).
+		Return(hProtocol.Account{AccountID: other.Address()}, nil)
+
+	accounts, err := LoadAccounts(client, tx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, source.Address(), accounts[0].AccountID)
+	assert.Equal(t, other.Address(), accounts[1].AccountID)
+
+	_, err = NewCollector(tx, network.TestNetworkPassphrase, accounts[:1])
+	assert.EqualError(t, err, "source account "+other.Address()+" not loaded")
+}
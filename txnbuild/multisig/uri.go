@@ -0,0 +1,40 @@
+package multisig
+
+import (
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/txnbuild/sep7"
+)
+
+// EncodeURI returns a SEP-7 web+hcnet:tx URI carrying tx and its
+// signatures, to be passed to the next co-signer.
+func EncodeURI(tx *txnbuild.Transaction, networkPassphrase string) (string, error) {
+	u, err := sep7.NewTransactionURI(tx)
+	if err != nil {
+		return "", err
+	}
+	u.NetworkPassphrase = networkPassphrase
+	return u.String()
+}
+
+// DecodeURI parses a web+hcnet:tx URI returned by EncodeURI. The network
+// passphrase is empty when the URI doesn't contain one.
+func DecodeURI(uri string) (*txnbuild.Transaction, string, error) {
+	parsed, err := sep7.Parse(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	u, ok := parsed.(*sep7.TransactionURI)
+	if !ok {
+		return nil, "", errors.Errorf("unsupported operation %s", parsed.Operation())
+	}
+	generic, err := u.Transaction()
+	if err != nil {
+		return nil, "", err
+	}
+	tx, ok := generic.Transaction()
+	if !ok {
+		return nil, "", errors.New("fee bump transactions are not supported")
+	}
+	return tx, u.NetworkPassphrase, nil
+}
@@ -0,0 +1,37 @@
+package multisig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURIRoundTrip(t *testing.T) {
+	source := keypair.MustRandom()
+	tx := buildTransaction(t, source, &txnbuild.BumpSequence{BumpTo: 10})
+	tx, err := tx.Sign(network.TestNetworkPassphrase, source)
+	require.NoError(t, err)
+
+	uri, err := EncodeURI(tx, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(uri, "web+hcnet:tx?"))
+
+	decoded, passphrase, err := DecodeURI(uri)
+	require.NoError(t, err)
+	assert.Equal(t, network.TestNetworkPassphrase, passphrase)
+	expected, err := tx.Base64()
+	require.NoError(t, err)
+	actual, err := decoded.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, _, err = DecodeURI("web+hcnet:pay?destination=" + source.Address())
+	assert.EqualError(t, err, "unsupported operation pay")
+	_, _, err = DecodeURI("web+hcnet:tx?network_passphrase=a")
+	assert.EqualError(t, err, "xdr is required")
+}
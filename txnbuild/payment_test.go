@@ -80,3 +80,18 @@ func TestPaymentValidateAsset(t *testing.T) {
 		assert.Contains(t, err.Error(), expected)
 	}
 }
+
+func TestPaymentMuxedAccountsRoundTrip(t *testing.T) {
+	opSource := NewSimpleAccount("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJUAAAAAAAAAAAACJUQ", 0)
+	payment := &Payment{
+		SourceAccount: &opSource,
+		Destination:   "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK",
+		Amount:        "10.0000000",
+		Asset:         NativeAsset{},
+	}
+	accountMerge := &AccountMerge{
+		Destination: "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK",
+	}
+
+	roundTrip(t, []Operation{payment, accountMerge})
+}
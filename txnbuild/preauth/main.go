@@ -0,0 +1,308 @@
+/*
+Package preauth builds bundles of pre-authorized transactions for escrow-style
+flows.
+
+A bundle is a list of stages submitted in order. Every stage holds one or more
+alternative transactions sharing the same sequence number, for example an
+unlock transaction valid after a date and a recovery transaction valid after a
+later date. Only one transaction of each stage can be submitted. The
+transactions of the first stage are signed when the bundle is built, every
+transaction of a stage adds the hashes of the transactions of the next stage
+as pre-authorized transaction (T...) signers of the source account so they
+can be submitted later without any signature.
+*/
+package preauth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// Step is a transaction of a bundle.
+type Step struct {
+	// Name identifies the transaction in its stage.
+	Name       string
+	Operations []txnbuild.Operation
+	Timebounds txnbuild.Timebounds
+	Memo       txnbuild.Memo
+}
+
+// Stage lists alternative steps sharing the same sequence number.
+type Stage []Step
+
+// BuildParams configures Build.
+type BuildParams struct {
+	// SourceAccount is the source of all transactions with its current
+	// sequence number. The first stage uses the next sequence number.
+	SourceAccount txnbuild.Account
+	// NetworkPassphrase is used to compute the pre-authorized transaction
+	// hashes.
+	NetworkPassphrase string
+	BaseFee           int64
+	// PreAuthWeight is the weight of the pre-authorized transaction signers.
+	// It must reach the thresholds required by the operations of the next
+	// stage. Defaults to 1.
+	PreAuthWeight txnbuild.Threshold
+	Stages        []Stage
+	// Signers sign the transactions of the first stage.
+	Signers []*keypair.Full
+}
+
+// Bundle is a set of pre-authorized transactions. It's encoded as JSON to be
+// stored until the transactions are submitted.
+type Bundle struct {
+	NetworkPassphrase string `json:"network_passphrase"`
+	AccountID         string `json:"account_id"`
+	// FirstSequence is the sequence number of the first stage.
+	FirstSequence int64         `json:"first_sequence,string"`
+	Stages        []BundleStage `json:"stages"`
+}
+
+// BundleStage is a stage of a bundle.
+type BundleStage struct {
+	Transactions []BundleTransaction `json:"transactions"`
+}
+
+// BundleTransaction is a transaction of a bundle.
+type BundleTransaction struct {
+	Name     string `json:"name"`
+	Hash     string `json:"hash"`
+	Sequence int64  `json:"sequence,string"`
+	MinTime  int64  `json:"min_time"`
+	MaxTime  int64  `json:"max_time"`
+	XDR      string `json:"xdr"`
+}
+
+// Build returns a bundle of transactions following params.Stages.
+func Build(params BuildParams) (*Bundle, error) {
+	if params.SourceAccount == nil {
+		return nil, errors.New("source account is required")
+	}
+	if len(params.Stages) == 0 {
+		return nil, errors.New("at least one stage is required")
+	}
+	sequence, err := params.SourceAccount.GetSequenceNumber()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting source account sequence number")
+	}
+	weight := params.PreAuthWeight
+	if weight == 0 {
+		weight = 1
+	}
+
+	bundle := &Bundle{
+		NetworkPassphrase: params.NetworkPassphrase,
+		AccountID:         params.SourceAccount.GetAccountID(),
+		FirstSequence:     sequence + 1,
+		Stages:            make([]BundleStage, len(params.Stages)),
+	}
+
+	// Transactions are built from the last stage because every stage adds
+	// the hashes of the next one as signers.
+	var nextSigners []txnbuild.Operation
+	for i := len(params.Stages) - 1; i >= 0; i-- {
+		stage := params.Stages[i]
+		if len(stage) == 0 {
+			return nil, errors.Errorf("stage %d has no steps", i)
+		}
+		var signers []txnbuild.Operation
+		names := map[string]bool{}
+		for _, step := range stage {
+			if names[step.Name] {
+				return nil, errors.Errorf("stage %d has several steps named %q", i, step.Name)
+			}
+			names[step.Name] = true
+
+			tx, err := buildStep(params, step, bundle.FirstSequence+int64(i), nextSigners)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error building step %q of stage %d", step.Name, i)
+			}
+			if i == 0 && len(params.Signers) > 0 {
+				if tx, err = tx.Sign(params.NetworkPassphrase, params.Signers...); err != nil {
+					return nil, errors.Wrapf(err, "error signing step %q", step.Name)
+				}
+			}
+
+			bundleTx, err := newBundleTransaction(step.Name, tx, params.NetworkPassphrase)
+			if err != nil {
+				return nil, err
+			}
+			bundle.Stages[i].Transactions = append(bundle.Stages[i].Transactions, bundleTx)
+
+			signer, err := PreAuthSigner(tx, params.NetworkPassphrase)
+			if err != nil {
+				return nil, err
+			}
+			signers = append(signers, &txnbuild.SetOptions{
+				Signer: &txnbuild.Signer{Address: signer, Weight: weight},
+			})
+		}
+		nextSigners = signers
+	}
+	return bundle, nil
+}
+
+func buildStep(params BuildParams, step Step, sequence int64, signers []txnbuild.Operation) (*txnbuild.Transaction, error) {
+	operations := make([]txnbuild.Operation, 0, len(step.Operations)+len(signers))
+	operations = append(operations, step.Operations...)
+	operations = append(operations, signers...)
+
+	source := txnbuild.NewSimpleAccount(params.SourceAccount.GetAccountID(), sequence-1)
+	return txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &source,
+		IncrementSequenceNum: true,
+		Operations:           operations,
+		BaseFee:              params.BaseFee,
+		Memo:                 step.Memo,
+		Timebounds:           step.Timebounds,
+	})
+}
+
+func newBundleTransaction(name string, tx *txnbuild.Transaction, networkPassphrase string) (BundleTransaction, error) {
+	hash, err := tx.HashHex(networkPassphrase)
+	if err != nil {
+		return BundleTransaction{}, errors.Wrap(err, "error hashing transaction")
+	}
+	envelope, err := tx.Base64()
+	if err != nil {
+		return BundleTransaction{}, errors.Wrap(err, "error encoding transaction")
+	}
+	return BundleTransaction{
+		Name:     name,
+		Hash:     hash,
+		Sequence: tx.SourceAccount().Sequence,
+		MinTime:  tx.Timebounds().MinTime,
+		MaxTime:  tx.Timebounds().MaxTime,
+		XDR:      envelope,
+	}, nil
+}
+
+// PreAuthSigner returns the T... signer key pre-authorizing tx.
+func PreAuthSigner(tx *txnbuild.Transaction, networkPassphrase string) (string, error) {
+	hash, err := tx.Hash(networkPassphrase)
+	if err != nil {
+		return "", errors.Wrap(err, "error hashing transaction")
+	}
+	return strkey.Encode(strkey.VersionByteHashTx, hash[:])
+}
+
+// HashXSigner returns the X... signer key of preimage. Transactions are
+// authorized by the signer when signed with txnbuild.Transaction.SignHashX.
+func HashXSigner(preimage []byte) (string, error) {
+	hash := sha256.Sum256(preimage)
+	return strkey.Encode(strkey.VersionByteHashX, hash[:])
+}
+
+// Transaction returns the transaction named name in stage.
+func (b *Bundle) Transaction(stage int, name string) (*txnbuild.Transaction, error) {
+	if stage < 0 || stage >= len(b.Stages) {
+		return nil, errors.Errorf("stage %d does not exist", stage)
+	}
+	for _, bundleTx := range b.Stages[stage].Transactions {
+		if bundleTx.Name == name {
+			return bundleTx.transaction()
+		}
+	}
+	return nil, errors.Errorf("stage %d has no step named %q", stage, name)
+}
+
+func (t BundleTransaction) transaction() (*txnbuild.Transaction, error) {
+	parsed, err := txnbuild.TransactionFromXDR(t.XDR)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding transaction %q", t.Name)
+	}
+	tx, ok := parsed.Transaction()
+	if !ok {
+		return nil, errors.Errorf("transaction %q is a fee bump transaction", t.Name)
+	}
+	return tx, nil
+}
+
+// Validate checks that the transactions of the bundle have consecutive
+// sequence numbers, match their hashes and pre-authorize the transactions of
+// the next stage.
+func (b *Bundle) Validate() error {
+	if len(b.Stages) == 0 {
+		return errors.New("bundle has no stages")
+	}
+
+	var nextSigners map[string]bool
+	for i := len(b.Stages) - 1; i >= 0; i-- {
+		stage := b.Stages[i]
+		if len(stage.Transactions) == 0 {
+			return errors.Errorf("stage %d has no transactions", i)
+		}
+		signers := map[string]bool{}
+		for _, bundleTx := range stage.Transactions {
+			tx, err := bundleTx.transaction()
+			if err != nil {
+				return err
+			}
+			if err = b.validateTransaction(i, bundleTx, tx, nextSigners); err != nil {
+				return errors.Wrapf(err, "invalid transaction %q of stage %d", bundleTx.Name, i)
+			}
+			signer, err := PreAuthSigner(tx, b.NetworkPassphrase)
+			if err != nil {
+				return err
+			}
+			signers[signer] = true
+		}
+		nextSigners = signers
+	}
+	return nil
+}
+
+func (b *Bundle) validateTransaction(stage int, bundleTx BundleTransaction, tx *txnbuild.Transaction, nextSigners map[string]bool) error {
+	if source := tx.SourceAccount(); source.AccountID != b.AccountID {
+		return errors.Errorf("source account %s does not match %s", source.AccountID, b.AccountID)
+	}
+	expected := b.FirstSequence + int64(stage)
+	if sequence := tx.SourceAccount().Sequence; sequence != expected || bundleTx.Sequence != expected {
+		return errors.Errorf("sequence number %d does not follow the previous stage, expected %d", sequence, expected)
+	}
+	hash, err := tx.Hash(b.NetworkPassphrase)
+	if err != nil {
+		return errors.Wrap(err, "error hashing transaction")
+	}
+	if hex.EncodeToString(hash[:]) != bundleTx.Hash {
+		return errors.Errorf("hash %s does not match the transaction", bundleTx.Hash)
+	}
+
+	added := map[string]bool{}
+	for _, op := range tx.Operations() {
+		setOptions, ok := op.(*txnbuild.SetOptions)
+		if !ok || setOptions.Signer == nil || setOptions.Signer.Weight == 0 {
+			continue
+		}
+		if setOptions.SourceAccount != nil && setOptions.SourceAccount.GetAccountID() != b.AccountID {
+			continue
+		}
+		added[setOptions.Signer.Address] = true
+	}
+	for signer := range nextSigners {
+		if !added[signer] {
+			return errors.Errorf("pre-authorized signer %s of the next stage is not added", signer)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes and validates a bundle.
+func (b *Bundle) UnmarshalJSON(data []byte) error {
+	type bundle Bundle
+	var decoded bundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := (*Bundle)(&decoded).Validate(); err != nil {
+		return errors.Wrap(err, "invalid bundle")
+	}
+	*b = Bundle(decoded)
+	return nil
+}
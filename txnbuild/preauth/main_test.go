@@ -0,0 +1,122 @@
+package preauth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func escrowParams(escrow, destination *keypair.Full) BuildParams {
+	account := txnbuild.NewSimpleAccount(escrow.Address(), 100)
+	threshold := txnbuild.Threshold(2)
+	return BuildParams{
+		SourceAccount:     &account,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		BaseFee:           txnbuild.MinBaseFee,
+		PreAuthWeight:     2,
+		Signers:           []*keypair.Full{escrow},
+		Stages: []Stage{
+			{{
+				Name: "setup",
+				Operations: []txnbuild.Operation{&txnbuild.SetOptions{
+					LowThreshold:    &threshold,
+					MediumThreshold: &threshold,
+					HighThreshold:   &threshold,
+				}},
+				Timebounds: txnbuild.NewInfiniteTimeout(),
+			}},
+			{
+				{
+					Name:       "unlock",
+					Operations: []txnbuild.Operation{&txnbuild.AccountMerge{Destination: destination.Address()}},
+					Timebounds: txnbuild.NewTimebounds(1000, 0),
+				},
+				{
+					Name:       "recover",
+					Operations: []txnbuild.Operation{&txnbuild.AccountMerge{Destination: escrow.Address()}},
+					Timebounds: txnbuild.NewTimebounds(2000, 0),
+				},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	escrow := keypair.MustRandom()
+	destination := keypair.MustRandom()
+	bundle, err := Build(escrowParams(escrow, destination))
+	require.NoError(t, err)
+	require.NoError(t, bundle.Validate())
+
+	assert.Equal(t, escrow.Address(), bundle.AccountID)
+	assert.Equal(t, int64(101), bundle.FirstSequence)
+	require.Len(t, bundle.Stages, 2)
+	require.Len(t, bundle.Stages[1].Transactions, 2)
+	assert.Equal(t, int64(102), bundle.Stages[1].Transactions[0].Sequence)
+	assert.Equal(t, int64(2000), bundle.Stages[1].Transactions[1].MinTime)
+
+	setup, err := bundle.Transaction(0, "setup")
+	require.NoError(t, err)
+	assert.Len(t, setup.Signatures(), 1)
+	require.Len(t, setup.Operations(), 3)
+
+	unlock, err := bundle.Transaction(1, "unlock")
+	require.NoError(t, err)
+	assert.Len(t, unlock.Signatures(), 0)
+	unlockSigner, err := PreAuthSigner(unlock, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, &txnbuild.Signer{Address: unlockSigner, Weight: 2}, setup.Operations()[1].(*txnbuild.SetOptions).Signer)
+
+	_, err = bundle.Transaction(1, "missing")
+	assert.EqualError(t, err, `stage 1 has no step named "missing"`)
+}
+
+func TestBundleJSON(t *testing.T) {
+	escrow := keypair.MustRandom()
+	destination := keypair.MustRandom()
+	bundle, err := Build(escrowParams(escrow, destination))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	var decoded Bundle
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *bundle, decoded)
+
+	// Transactions which don't chain are rejected.
+	other, err := Build(escrowParams(escrow, keypair.MustRandom()))
+	require.NoError(t, err)
+	tampered := *bundle
+	tampered.Stages = []BundleStage{bundle.Stages[0], other.Stages[1]}
+	data, err = json.Marshal(tampered)
+	require.NoError(t, err)
+	err = json.Unmarshal(data, &decoded)
+	assert.Contains(t, err.Error(), `invalid bundle: invalid transaction "setup" of stage 0: pre-authorized signer`)
+
+	tampered.Stages = []BundleStage{bundle.Stages[1]}
+	assert.EqualError(t, tampered.Validate(), `invalid transaction "unlock" of stage 0: sequence number 102 does not follow the previous stage, expected 101`)
+}
+
+func TestBuildErrors(t *testing.T) {
+	escrow := keypair.MustRandom()
+	params := escrowParams(escrow, keypair.MustRandom())
+	params.Stages[1][1].Name = "unlock"
+	_, err := Build(params)
+	assert.EqualError(t, err, `stage 1 has several steps named "unlock"`)
+
+	params = escrowParams(escrow, keypair.MustRandom())
+	params.Stages = append(params.Stages, Stage{})
+	_, err = Build(params)
+	assert.EqualError(t, err, "stage 2 has no steps")
+}
+
+func TestHashXSigner(t *testing.T) {
+	signer, err := HashXSigner([]byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, byte('X'), signer[0])
+}
@@ -0,0 +1,109 @@
+package remotesigner
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/hcnet/go/xdr"
+)
+
+// HTTP is the interface of the HTTP client used by Client.
+type HTTP interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client signs with a key held by a remote signing Server. It implements
+// txnbuild.TransactionSigner.
+type Client struct {
+	// URL is the base URL of the server.
+	URL string
+	// SignerAddress is the address of the key to sign with.
+	SignerAddress string
+	// Token is the bearer token sent with every request.
+	Token string
+	// HTTP defaults to http.DefaultClient.
+	HTTP HTTP
+}
+
+var _ txnbuild.TransactionSigner = (*Client)(nil)
+
+// Address returns the address of the remote key.
+func (c *Client) Address() string {
+	return c.SignerAddress
+}
+
+// Hint returns the signature hint of the remote key. It's zero when
+// SignerAddress is invalid.
+func (c *Client) Hint() [4]byte {
+	kp, err := keypair.ParseAddress(c.SignerAddress)
+	if err != nil {
+		return [4]byte{}
+	}
+	return kp.Hint()
+}
+
+// Sign signs arbitrary data. The server only allows it when its policy
+// enables raw signing.
+func (c *Client) Sign(input []byte) ([]byte, error) {
+	return c.post(SignPath, signRequest{Address: c.SignerAddress, Data: input})
+}
+
+// SignTransaction sends the transaction envelope to the server which checks
+// it against its policy before signing it.
+func (c *Client) SignTransaction(networkPassphrase, txeB64 string) (xdr.DecoratedSignature, error) {
+	sig, err := c.post(SignTransactionPath, signTransactionRequest{
+		Address:           c.SignerAddress,
+		NetworkPassphrase: networkPassphrase,
+		Transaction:       txeB64,
+	})
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(c.Hint()),
+		Signature: xdr.Signature(sig),
+	}, nil
+}
+
+func (c *Client) post(path string, request interface{}) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding request")
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(c.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error sending request to remote signer")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+			return nil, errors.Errorf("remote signer returned status %d", resp.StatusCode)
+		}
+		return nil, errors.Errorf("remote signer refused to sign: %s", errResp.Error)
+	}
+	var signResp signResponse
+	if err = json.NewDecoder(resp.Body).Decode(&signResp); err != nil {
+		return nil, errors.Wrap(err, "error decoding remote signer response")
+	}
+	return signResp.Signature, nil
+}
@@ -0,0 +1,46 @@
+/*
+Package remotesigner implements a signing service holding secret keys and a
+client signing transactions with it.
+
+The Server checks every transaction against a Policy before signing it, for
+example to only sign payments on the public network. The Client implements
+txnbuild.TransactionSigner so it can be passed to
+txnbuild.Transaction.SignWith like any other key.
+
+The protocol is JSON over HTTP, requests are authenticated with a bearer
+token. The Server rejects every request when it has no token configured:
+
+	POST /sign_transaction {"address": "G...", "network_passphrase": "...", "transaction": "AAAA..."}
+	POST /sign             {"address": "G...", "data": "base64..."}
+
+Both return {"signature": "base64..."} or {"error": "..."}.
+*/
+package remotesigner
+
+const (
+	// SignTransactionPath is the path of the endpoint signing transaction
+	// envelopes.
+	SignTransactionPath = "/sign_transaction"
+	// SignPath is the path of the endpoint signing arbitrary data. It's only
+	// enabled when Policy.AllowRawSigning is set.
+	SignPath = "/sign"
+)
+
+type signTransactionRequest struct {
+	Address           string `json:"address"`
+	NetworkPassphrase string `json:"network_passphrase"`
+	Transaction       string `json:"transaction"`
+}
+
+type signRequest struct {
+	Address string `json:"address"`
+	Data    []byte `json:"data"`
+}
+
+type signResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,175 @@
+package remotesigner
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// impostor claims the address of one key but signs with another.
+type impostor struct {
+	*keypair.Full
+	address string
+}
+
+func (i impostor) Address() string {
+	return i.address
+}
+
+func newTestServer(t *testing.T, policy Policy, signers ...keypair.Signer) *httptest.Server {
+	server := httptest.NewServer(&Server{
+		Signers: signers,
+		Token:   "secret",
+		Policy:  policy,
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func buildTransaction(t *testing.T, source string, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: source, Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestSignTransaction(t *testing.T) {
+	kp := keypair.MustRandom()
+	server := newTestServer(t, Policy{
+		NetworkPassphrases: []string{network.TestNetworkPassphrase},
+	}, kp)
+	client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "secret"}
+
+	tx := buildTransaction(t, kp.Address(), &txnbuild.ManageData{Name: "name", Value: []byte("value")})
+	signed, err := tx.SignWith(network.TestNetworkPassphrase, client)
+	require.NoError(t, err)
+
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Signatures(), signed.Signatures())
+
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      signed,
+		FeeAccount: kp.Address(),
+		BaseFee:    txnbuild.MinBaseFee * 2,
+	})
+	require.NoError(t, err)
+	signedFeeBump, err := feeBump.SignWith(network.TestNetworkPassphrase, client)
+	require.NoError(t, err)
+	expectedFeeBump, err := feeBump.Sign(network.TestNetworkPassphrase, kp)
+	require.NoError(t, err)
+	assert.Equal(t, expectedFeeBump.Signatures(), signedFeeBump.Signatures())
+}
+
+func TestSignTransactionPolicy(t *testing.T) {
+	kp := keypair.MustRandom()
+	destination := keypair.MustRandom().Address()
+	server := newTestServer(t, Policy{
+		NetworkPassphrases: []string{network.TestNetworkPassphrase},
+		AllowedOperations:  []string{"payment", "set_options"},
+		MaxFee:             txnbuild.MinBaseFee,
+	}, kp)
+	client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "secret"}
+
+	payment := &txnbuild.Payment{Destination: destination, Amount: "10", Asset: txnbuild.NativeAsset{}}
+
+	_, err := buildTransaction(t, kp.Address(), payment).SignWith(network.PublicNetworkPassphrase, client)
+	assert.EqualError(t, err, `failed to sign transaction: remote signer refused to sign: network "`+network.PublicNetworkPassphrase+`" is not allowed`)
+
+	_, err = buildTransaction(t, kp.Address(), payment, payment).SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: remote signer refused to sign: fee 200 exceeds the maximum fee 100")
+
+	_, err = buildTransaction(t, kp.Address(), &txnbuild.BumpSequence{BumpTo: 10}).SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: remote signer refused to sign: operation 1: bump_sequence is not allowed")
+
+	weight := txnbuild.Threshold(0)
+	_, err = buildTransaction(t, kp.Address(), &txnbuild.SetOptions{MasterWeight: &weight}).SignWith(network.TestNetworkPassphrase, client)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "remote signer refused to sign: dangerous transaction: operation 1: ")
+
+	_, err = buildTransaction(t, kp.Address(), payment).SignWith(network.TestNetworkPassphrase, client)
+	assert.NoError(t, err)
+}
+
+func TestSignTransactionCheck(t *testing.T) {
+	kp := keypair.MustRandom()
+	server := newTestServer(t, Policy{
+		NetworkPassphrases: []string{network.TestNetworkPassphrase},
+		Check: func(networkPassphrase string, description txnbuild.Description) error {
+			if description.Memo == "" {
+				return assert.AnError
+			}
+			return nil
+		},
+	}, kp)
+	client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "secret"}
+
+	_, err := buildTransaction(t, kp.Address(), &txnbuild.ManageData{Name: "name", Value: []byte("value")}).SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: remote signer refused to sign: "+assert.AnError.Error())
+}
+
+func TestSignTransactionErrors(t *testing.T) {
+	kp := keypair.MustRandom()
+	other := keypair.MustRandom()
+	server := newTestServer(t, Policy{
+		NetworkPassphrases: []string{network.TestNetworkPassphrase},
+	}, kp, impostor{Full: kp, address: other.Address()})
+	tx := buildTransaction(t, kp.Address(), &txnbuild.ManageData{Name: "name", Value: []byte("value")})
+
+	client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "wrong"}
+	_, err := tx.SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: remote signer refused to sign: invalid token")
+
+	client = &Client{URL: server.URL, SignerAddress: keypair.MustRandom().Address(), Token: "secret"}
+	_, err = tx.SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: remote signer refused to sign: unknown signer")
+
+	// Signatures made with another key than the address are rejected by
+	// txnbuild.
+	client = &Client{URL: server.URL, SignerAddress: other.Address(), Token: "secret"}
+	_, err = tx.SignWith(network.TestNetworkPassphrase, client)
+	assert.EqualError(t, err, "failed to sign transaction: invalid signature returned by "+other.Address())
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	kp := keypair.MustRandom()
+	server := httptest.NewServer(&Server{
+		Signers: []keypair.Signer{kp},
+		Policy:  Policy{AllowRawSigning: true},
+	})
+	defer server.Close()
+
+	for _, token := range []string{"", "secret"} {
+		client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: token}
+		_, err := client.Sign([]byte("hello"))
+		assert.EqualError(t, err, "remote signer refused to sign: no token configured")
+	}
+}
+
+func TestSign(t *testing.T) {
+	kp := keypair.MustRandom()
+	message := []byte("hello")
+
+	server := newTestServer(t, Policy{}, kp)
+	client := &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "secret"}
+	_, err := client.Sign(message)
+	assert.EqualError(t, err, "remote signer refused to sign: raw signing is not allowed")
+
+	server = newTestServer(t, Policy{AllowRawSigning: true}, kp)
+	client = &Client{URL: server.URL, SignerAddress: kp.Address(), Token: "secret"}
+	sig, err := client.Sign(message)
+	require.NoError(t, err)
+	assert.NoError(t, kp.Verify(message, sig))
+	assert.Equal(t, kp.Hint(), client.Hint())
+}
@@ -0,0 +1,62 @@
+package remotesigner
+
+import (
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// Policy restricts the transactions a Server signs.
+type Policy struct {
+	// NetworkPassphrases lists the networks transactions may be signed
+	// for. At least one is required.
+	NetworkPassphrases []string
+	// AllowedOperations lists the operation types which may be signed, named
+	// like txnbuild.OperationDescription.Type, e.g. "payment". All operation
+	// types are allowed when empty.
+	AllowedOperations []string
+	// AllowDangerous allows signing transactions with warnings, for example
+	// removing signers or merging accounts.
+	AllowDangerous bool
+	// MaxFee is the maximum fee in stroops a transaction may pay. It's not
+	// limited when zero.
+	MaxFee int64
+	// AllowRawSigning enables signing arbitrary data, which bypasses all
+	// other checks.
+	AllowRawSigning bool
+	// Check is an optional additional check run after the others.
+	Check func(networkPassphrase string, description txnbuild.Description) error
+}
+
+// Validate returns an error when the policy forbids signing the transaction
+// described by description for the network.
+func (p Policy) Validate(networkPassphrase string, description txnbuild.Description) error {
+	if !contains(p.NetworkPassphrases, networkPassphrase) {
+		return errors.Errorf("network %q is not allowed", networkPassphrase)
+	}
+	if p.MaxFee > 0 && description.MaxFee > p.MaxFee {
+		return errors.Errorf("fee %d exceeds the maximum fee %d", description.MaxFee, p.MaxFee)
+	}
+	if len(p.AllowedOperations) > 0 {
+		for _, op := range description.Operations {
+			if !contains(p.AllowedOperations, op.Type) {
+				return errors.Errorf("operation %d: %s is not allowed", op.Index+1, op.Type)
+			}
+		}
+	}
+	if warnings := description.Warnings(); !p.AllowDangerous && len(warnings) > 0 {
+		return errors.Errorf("dangerous transaction: %s", warnings[0])
+	}
+	if p.Check != nil {
+		return p.Check(networkPassphrase, description)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package remotesigner
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/support/http/httpauthz"
+	supportlog "github.com/hcnet/go/support/log"
+	"github.com/hcnet/go/support/render/httpjson"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// Server is an http.Handler signing transactions with the keys it holds,
+// following its policy.
+type Server struct {
+	// Signers are the keys held by the server.
+	Signers []keypair.Signer
+	// Token is the bearer token clients must send. It's required, every
+	// request is rejected when it's empty.
+	Token  string
+	Policy Policy
+	Logger *supportlog.Entry
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.Token == "" {
+		s.logger().Error("Remote signer has no token configured, rejecting request.")
+		renderError(w, http.StatusServiceUnavailable, "no token configured")
+		return
+	}
+	token := httpauthz.ParseBearerToken(r.Header.Get("Authorization"))
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+		renderError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	switch r.URL.Path {
+	case SignTransactionPath:
+		s.signTransaction(w, r)
+	case SignPath:
+		s.sign(w, r)
+	default:
+		renderError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) signTransaction(w http.ResponseWriter, r *http.Request) {
+	var req signTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	signer := s.signer(req.Address)
+	if signer == nil {
+		renderError(w, http.StatusNotFound, "unknown signer")
+		return
+	}
+	l := s.logger().WithField("signer", req.Address)
+
+	parsed, err := txnbuild.TransactionFromXDR(req.Transaction)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "invalid transaction")
+		return
+	}
+	var (
+		description txnbuild.Description
+		hash        [32]byte
+	)
+	if tx, ok := parsed.Transaction(); ok {
+		description = tx.Describe()
+		hash, err = tx.Hash(req.NetworkPassphrase)
+	} else {
+		tx, _ := parsed.FeeBump()
+		description = tx.Describe()
+		hash, err = tx.Hash(req.NetworkPassphrase)
+	}
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "invalid transaction")
+		return
+	}
+
+	if err = s.Policy.Validate(req.NetworkPassphrase, description); err != nil {
+		l.WithField("reason", err.Error()).Info("Transaction rejected by policy.")
+		renderError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		l.Error(errors.Wrap(err, "error signing transaction"))
+		renderError(w, http.StatusInternalServerError, "error signing transaction")
+		return
+	}
+	l.WithField("hash", hex.EncodeToString(hash[:])).Info("Transaction signed.")
+	httpjson.Render(w, signResponse{Signature: sig}, httpjson.JSON)
+}
+
+func (s *Server) sign(w http.ResponseWriter, r *http.Request) {
+	if !s.Policy.AllowRawSigning {
+		renderError(w, http.StatusForbidden, "raw signing is not allowed")
+		return
+	}
+	var req signRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	signer := s.signer(req.Address)
+	if signer == nil {
+		renderError(w, http.StatusNotFound, "unknown signer")
+		return
+	}
+
+	sig, err := signer.Sign(req.Data)
+	if err != nil {
+		s.logger().WithField("signer", req.Address).Error(errors.Wrap(err, "error signing data"))
+		renderError(w, http.StatusInternalServerError, "error signing data")
+		return
+	}
+	httpjson.Render(w, signResponse{Signature: sig}, httpjson.JSON)
+}
+
+func (s *Server) signer(address string) keypair.Signer {
+	for _, signer := range s.Signers {
+		if signer.Address() == address {
+			return signer
+		}
+	}
+	return nil
+}
+
+func (s *Server) logger() *supportlog.Entry {
+	if s.Logger == nil {
+		return supportlog.DefaultLogger
+	}
+	return s.Logger
+}
+
+func renderError(w http.ResponseWriter, status int, message string) {
+	httpjson.RenderStatus(w, status, errorResponse{Error: message}, httpjson.JSON)
+}
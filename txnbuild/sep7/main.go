@@ -0,0 +1,154 @@
+/*
+Package sep7 builds, parses, signs and verifies SEP-7 web+hcnet:tx and
+web+hcnet:pay URIs.
+
+See: https://github.com/hcnet/hcnet-protocol/blob/master/ecosystem/sep-0007.md
+*/
+package sep7
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hcnet/go/support/errors"
+)
+
+// Scheme is the scheme of SEP-7 URIs.
+const Scheme = "web+hcnet"
+
+// Operations supported by SEP-7 URIs.
+const (
+	OperationTransaction = "tx"
+	OperationPay         = "pay"
+)
+
+// MaxMessageLength is the maximum length of the msg parameter.
+const MaxMessageLength = 300
+
+// callbackPrefix prefixes the callback parameter when the callback is an URL.
+const callbackPrefix = "url:"
+
+// URI is a parsed SEP-7 URI, either a *TransactionURI or a *PayURI.
+type URI interface {
+	// Operation returns OperationTransaction or OperationPay.
+	Operation() string
+	// String encodes the URI. The signature parameter is always last.
+	String() (string, error)
+	// GetParams returns the parameters shared by all operations.
+	GetParams() *Params
+}
+
+// Params are the parameters shared by tx and pay URIs.
+type Params struct {
+	// Callback is the URL the signed transaction is posted to instead of
+	// being submitted to the network.
+	Callback string
+	// Message is shown to the user. It can be at most MaxMessageLength
+	// characters long.
+	Message string
+	// NetworkPassphrase is the passphrase of the network of the
+	// transaction. The public network is implied when empty.
+	NetworkPassphrase string
+	// OriginDomain is the domain whose URI_REQUEST_SIGNING_KEY signed the
+	// URI. Signature is required when set.
+	OriginDomain string
+	// Signature is the base64 signature of the URI by the signing key of
+	// OriginDomain, see Sign.
+	Signature string
+}
+
+func (p *Params) validate() error {
+	if utf8.RuneCountInString(p.Message) > MaxMessageLength {
+		return errors.Errorf("msg can't be longer than %d characters", MaxMessageLength)
+	}
+	if p.Callback != "" {
+		if _, err := url.ParseRequestURI(p.Callback); err != nil {
+			return errors.Wrap(err, "invalid callback")
+		}
+	}
+	return nil
+}
+
+func (p *Params) encode(q *query) {
+	if p.Callback != "" {
+		q.add("callback", callbackPrefix+p.Callback)
+	}
+	q.add("msg", p.Message)
+	q.add("network_passphrase", p.NetworkPassphrase)
+	q.add("origin_domain", p.OriginDomain)
+	q.add("signature", p.Signature)
+}
+
+func (p *Params) decode(values url.Values) error {
+	if callback := values.Get("callback"); callback != "" {
+		if !strings.HasPrefix(callback, callbackPrefix) {
+			return errors.Errorf("unsupported callback %s", callback)
+		}
+		p.Callback = strings.TrimPrefix(callback, callbackPrefix)
+	}
+	p.Message = values.Get("msg")
+	p.NetworkPassphrase = values.Get("network_passphrase")
+	p.OriginDomain = values.Get("origin_domain")
+	p.Signature = values.Get("signature")
+	return nil
+}
+
+// Parse parses a SEP-7 URI.
+func Parse(uri string) (URI, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing uri")
+	}
+	if u.Scheme != Scheme {
+		return nil, errors.Errorf("unsupported scheme %s", u.Scheme)
+	}
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing uri parameters")
+	}
+
+	var parsed URI
+	switch u.Opaque {
+	case OperationTransaction:
+		tx := &TransactionURI{}
+		err = tx.decode(values)
+		parsed = tx
+	case OperationPay:
+		pay := &PayURI{}
+		err = pay.decode(values)
+		parsed = pay
+	default:
+		return nil, errors.Errorf("unsupported operation %s", u.Opaque)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// query encodes parameters in the order they are added, escaping spaces as
+// %20 like the examples of SEP-7.
+type query struct {
+	builder strings.Builder
+}
+
+func (q *query) add(key, value string) {
+	if value == "" {
+		return
+	}
+	if q.builder.Len() > 0 {
+		q.builder.WriteByte('&')
+	}
+	q.builder.WriteString(key)
+	q.builder.WriteByte('=')
+	q.builder.WriteString(escape(value))
+}
+
+func (q *query) uri(operation string) string {
+	return Scheme + ":" + operation + "?" + q.builder.String()
+}
+
+func escape(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
@@ -0,0 +1,107 @@
+package sep7
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/network"
+	"github.com/hcnet/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTransaction(t *testing.T, source string) *txnbuild.Transaction {
+	account := txnbuild.NewSimpleAccount(source, 1)
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 10}},
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestTransactionURI(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	tx := buildTransaction(t, source)
+	u, err := NewTransactionURI(tx)
+	require.NoError(t, err)
+	u.PublicKey = source
+	u.Replace = []Replacement{
+		{Path: "sourceAccount", ID: "X", Hint: "account to bump"},
+		{Path: "operations[0].sourceAccount", ID: "X", Hint: "account to bump"},
+	}
+	u.Callback = "https://example.com/callback"
+	u.Message = "order number 24"
+	u.NetworkPassphrase = network.TestNetworkPassphrase
+
+	uri, err := u.String()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(uri, "web+hcnet:tx?xdr="))
+	assert.Contains(t, uri, "&replace=sourceAccount%3AX%2Coperations%5B0%5D.sourceAccount%3AX%3BX%3Aaccount%20to%20bump&")
+	assert.Contains(t, uri, "&callback=url%3Ahttps%3A%2F%2Fexample.com%2Fcallback&msg=order%20number%2024&")
+
+	parsed, err := Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, OperationTransaction, parsed.Operation())
+	assert.Equal(t, u, parsed)
+
+	generic, err := parsed.(*TransactionURI).Transaction()
+	require.NoError(t, err)
+	decoded, ok := generic.Transaction()
+	require.True(t, ok)
+	assert.Equal(t, source, decoded.SourceAccount().AccountID)
+}
+
+func TestPayURI(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	issuer := keypair.MustRandom().Address()
+	u := &PayURI{
+		Destination: destination,
+		Amount:      "120.1234567",
+		AssetCode:   "USD",
+		AssetIssuer: issuer,
+		Memo:        "skdjfasf",
+		MemoType:    MemoTypeText,
+		Params:      Params{Message: "pay me with lumens"},
+	}
+	uri, err := u.String()
+	require.NoError(t, err)
+	assert.Equal(t, "web+hcnet:pay?destination="+destination+"&amount=120.1234567&asset_code=USD&asset_issuer="+issuer+"&memo=skdjfasf&memo_type=MEMO_TEXT&msg=pay%20me%20with%20lumens", uri)
+
+	parsed, err := Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, u, parsed)
+	assert.Equal(t, txnbuild.CreditAsset{Code: "USD", Issuer: issuer}, parsed.(*PayURI).Asset())
+
+	// Spaces encoded as + are accepted.
+	parsed, err = Parse("web+hcnet:pay?destination=" + destination + "&msg=pay+me")
+	require.NoError(t, err)
+	assert.Equal(t, "pay me", parsed.GetParams().Message)
+	assert.Equal(t, txnbuild.NativeAsset{}, parsed.(*PayURI).Asset())
+}
+
+func TestParseErrors(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{"https://example.com", "unsupported scheme https"},
+		{"web+hcnet:sign?xdr=a", "unsupported operation sign"},
+		{"web+hcnet:tx?msg=a", "xdr is required"},
+		{"web+hcnet:pay?amount=1", "destination is required"},
+		{"web+hcnet:pay?destination=GA", "invalid destination"},
+		{"web+hcnet:pay?destination=" + destination + "&amount=a", "invalid amount: invalid amount format: a"},
+		{"web+hcnet:pay?destination=" + destination + "&memo_type=MEMO_TEXTS", "invalid memo_type MEMO_TEXTS"},
+		{"web+hcnet:pay?destination=" + destination + "&callback=https://example.com", "unsupported callback https://example.com"},
+		{"web+hcnet:pay?destination=" + destination + "&msg=" + strings.Repeat("a", 301), "msg can't be longer than 300 characters"},
+		{"web+hcnet:tx?xdr=AAAA&replace=sourceAccount", "invalid replace field sourceAccount"},
+	} {
+		_, err := Parse(tc.uri)
+		assert.EqualError(t, err, tc.err, tc.uri)
+	}
+}
@@ -0,0 +1,109 @@
+package sep7
+
+import (
+	"net/url"
+
+	"github.com/hcnet/go/amount"
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// Memo types of pay URIs.
+const (
+	MemoTypeText   = "MEMO_TEXT"
+	MemoTypeID     = "MEMO_ID"
+	MemoTypeHash   = "MEMO_HASH"
+	MemoTypeReturn = "MEMO_RETURN"
+)
+
+// PayURI is a web+hcnet:pay URI asking the user to pay the destination.
+type PayURI struct {
+	Params
+	Destination string
+	// Amount is optional, the user chooses the amount when empty.
+	Amount string
+	// AssetCode and AssetIssuer are empty for the native asset.
+	AssetCode   string
+	AssetIssuer string
+	Memo        string
+	// MemoType defaults to MemoTypeText. Hash and return memos are base64
+	// encoded.
+	MemoType string
+}
+
+// Operation implements URI.
+func (u *PayURI) Operation() string {
+	return OperationPay
+}
+
+// GetParams implements URI.
+func (u *PayURI) GetParams() *Params {
+	return &u.Params
+}
+
+// Asset returns the asset of the payment.
+func (u *PayURI) Asset() txnbuild.Asset {
+	if u.AssetCode == "" {
+		return txnbuild.NativeAsset{}
+	}
+	return txnbuild.CreditAsset{Code: u.AssetCode, Issuer: u.AssetIssuer}
+}
+
+// String implements URI.
+func (u *PayURI) String() (string, error) {
+	if err := u.validate(); err != nil {
+		return "", err
+	}
+	var q query
+	q.add("destination", u.Destination)
+	q.add("amount", u.Amount)
+	q.add("asset_code", u.AssetCode)
+	q.add("asset_issuer", u.AssetIssuer)
+	q.add("memo", u.Memo)
+	q.add("memo_type", u.MemoType)
+	u.Params.encode(&q)
+	return q.uri(OperationPay), nil
+}
+
+func (u *PayURI) validate() error {
+	if u.Destination == "" {
+		return errors.New("destination is required")
+	}
+	if !strkey.IsValidEd25519PublicKey(u.Destination) {
+		if _, err := strkey.Decode(strkey.VersionByteMuxedAccount, u.Destination); err != nil {
+			return errors.New("invalid destination")
+		}
+	}
+	if u.Amount != "" {
+		if _, err := amount.Parse(u.Amount); err != nil {
+			return errors.Wrap(err, "invalid amount")
+		}
+	}
+	if u.AssetCode != "" {
+		if _, err := u.Asset().ToXDR(); err != nil {
+			return errors.Wrap(err, "invalid asset")
+		}
+	} else if u.AssetIssuer != "" {
+		return errors.New("asset_issuer requires asset_code")
+	}
+	switch u.MemoType {
+	case "", MemoTypeText, MemoTypeID, MemoTypeHash, MemoTypeReturn:
+	default:
+		return errors.Errorf("invalid memo_type %s", u.MemoType)
+	}
+	return u.Params.validate()
+}
+
+func (u *PayURI) decode(values url.Values) error {
+	u.Destination = values.Get("destination")
+	u.Amount = values.Get("amount")
+	u.AssetCode = values.Get("asset_code")
+	u.AssetIssuer = values.Get("asset_issuer")
+	u.Memo = values.Get("memo")
+	u.MemoType = values.Get("memo_type")
+	if err := u.Params.decode(values); err != nil {
+		return err
+	}
+	return u.validate()
+}
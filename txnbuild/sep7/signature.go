@@ -0,0 +1,87 @@
+package sep7
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/hcnet/go/clients/hcnettoml"
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/support/errors"
+)
+
+// signaturePrefix is prepended to the URI before signing, the first 35
+// bytes are zeros and the 36th is 4.
+var signaturePrefix = append(make([]byte, 35), 4)
+
+const signatureMessage = "hcnet.sep.7 - URI Scheme"
+
+// ErrNotSigned is returned by Verify when the URI has no origin_domain or
+// signature parameter.
+var ErrNotSigned = errors.New("uri is not signed")
+
+func signaturePayload(unsigned string) []byte {
+	payload := make([]byte, 0, len(signaturePrefix)+len(signatureMessage)+len(unsigned))
+	payload = append(payload, signaturePrefix...)
+	payload = append(payload, signatureMessage...)
+	return append(payload, unsigned...)
+}
+
+// Sign signs u with signer, the URI_REQUEST_SIGNING_KEY of the origin domain
+// of u, sets its signature and returns the signed URI.
+func Sign(u URI, signer *keypair.Full) (string, error) {
+	params := u.GetParams()
+	if params.OriginDomain == "" {
+		return "", errors.New("origin_domain is required to sign")
+	}
+	params.Signature = ""
+	unsigned, err := u.String()
+	if err != nil {
+		return "", err
+	}
+	signature, err := signer.Sign(signaturePayload(unsigned))
+	if err != nil {
+		return "", errors.Wrap(err, "error signing uri")
+	}
+	params.Signature = base64.StdEncoding.EncodeToString(signature)
+	return u.String()
+}
+
+// Verify parses uri and verifies its signature with the
+// URI_REQUEST_SIGNING_KEY of the hcnet.toml of its origin domain.
+func Verify(uri string, client hcnettoml.ClientInterface) (URI, error) {
+	u, err := Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	params := u.GetParams()
+	if params.OriginDomain == "" || params.Signature == "" {
+		return nil, ErrNotSigned
+	}
+
+	// The signature parameter must be the last one, the payload is the URI
+	// as received without it.
+	i := strings.LastIndex(uri, "&signature=")
+	if i < 0 || strings.Contains(uri[i+1:], "&") {
+		return nil, errors.New("signature must be the last parameter")
+	}
+	signature, err := base64.StdEncoding.DecodeString(params.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature encoding")
+	}
+
+	toml, err := client.GetHcnetToml(params.OriginDomain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching hcnet.toml of %s", params.OriginDomain)
+	}
+	if toml.UriRequestSigningKey == "" {
+		return nil, errors.Errorf("hcnet.toml of %s has no URI_REQUEST_SIGNING_KEY", params.OriginDomain)
+	}
+	kp, err := keypair.ParseAddress(toml.UriRequestSigningKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid URI_REQUEST_SIGNING_KEY")
+	}
+	if err := kp.Verify(signaturePayload(uri[:i]), signature); err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+	return u, nil
+}
@@ -0,0 +1,66 @@
+package sep7
+
+import (
+	"testing"
+
+	"github.com/hcnet/go/clients/hcnettoml"
+	"github.com/hcnet/go/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	signer := keypair.MustRandom()
+	client := &hcnettoml.MockClient{}
+	client.On("GetHcnetToml", "example.com").
+		Return(&hcnettoml.Response{UriRequestSigningKey: signer.Address()}, nil)
+
+	u := &PayURI{
+		Destination: keypair.MustRandom().Address(),
+		Amount:      "10",
+		Params: Params{
+			Message:      "pay for order 24",
+			OriginDomain: "example.com",
+		},
+	}
+	uri, err := Sign(u, signer)
+	require.NoError(t, err)
+	assert.NotEmpty(t, u.Signature)
+
+	verified, err := Verify(uri, client)
+	require.NoError(t, err)
+	assert.Equal(t, u, verified)
+
+	// Changing any parameter invalidates the signature.
+	u.Amount = "1000"
+	tampered, err := u.String()
+	require.NoError(t, err)
+	_, err = Verify(tampered, client)
+	assert.EqualError(t, err, "invalid signature: signature verification failed")
+
+	// Signatures by other keys are rejected.
+	u.Amount = "10"
+	uri, err = Sign(u, keypair.MustRandom())
+	require.NoError(t, err)
+	_, err = Verify(uri, client)
+	assert.EqualError(t, err, "invalid signature: signature verification failed")
+}
+
+func TestVerifyErrors(t *testing.T) {
+	client := &hcnettoml.MockClient{}
+	client.On("GetHcnetToml", "example.com").
+		Return(&hcnettoml.Response{}, nil)
+	destination := keypair.MustRandom().Address()
+
+	_, err := Verify("web+hcnet:pay?destination="+destination, client)
+	assert.Equal(t, ErrNotSigned, err)
+
+	_, err = Verify("web+hcnet:pay?destination="+destination+"&signature=YQ%3D%3D&origin_domain=example.com", client)
+	assert.EqualError(t, err, "signature must be the last parameter")
+
+	_, err = Verify("web+hcnet:pay?destination="+destination+"&origin_domain=example.com&signature=YQ%3D%3D", client)
+	assert.EqualError(t, err, "hcnet.toml of example.com has no URI_REQUEST_SIGNING_KEY")
+
+	_, err = Sign(&PayURI{Destination: destination}, keypair.MustRandom())
+	assert.EqualError(t, err, "origin_domain is required to sign")
+}
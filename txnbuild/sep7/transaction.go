@@ -0,0 +1,153 @@
+package sep7
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/hcnet/go/strkey"
+	"github.com/hcnet/go/support/errors"
+	"github.com/hcnet/go/txnbuild"
+)
+
+// TransactionURI is a web+hcnet:tx URI asking the user to sign a
+// transaction.
+type TransactionURI struct {
+	Params
+	// XDR is the base64 encoded transaction envelope.
+	XDR string
+	// Replace lists the fields of the transaction the wallet should replace
+	// before signing.
+	Replace []Replacement
+	// PublicKey is the account which should sign the transaction. Optional.
+	PublicKey string
+}
+
+// NewTransactionURI returns a TransactionURI for tx.
+func NewTransactionURI(tx *txnbuild.Transaction) (*TransactionURI, error) {
+	envelope, err := tx.Base64()
+	if err != nil {
+		return nil, errors.Wrap(err, "error encoding transaction")
+	}
+	return &TransactionURI{XDR: envelope}, nil
+}
+
+// Operation implements URI.
+func (u *TransactionURI) Operation() string {
+	return OperationTransaction
+}
+
+// GetParams implements URI.
+func (u *TransactionURI) GetParams() *Params {
+	return &u.Params
+}
+
+// Transaction decodes the transaction of the URI.
+func (u *TransactionURI) Transaction() (*txnbuild.GenericTransaction, error) {
+	tx, err := txnbuild.TransactionFromXDR(u.XDR)
+	return tx, errors.Wrap(err, "invalid xdr")
+}
+
+// String implements URI.
+func (u *TransactionURI) String() (string, error) {
+	if err := u.validate(); err != nil {
+		return "", err
+	}
+	var q query
+	q.add("xdr", u.XDR)
+	q.add("replace", encodeReplacements(u.Replace))
+	q.add("pubkey", u.PublicKey)
+	u.Params.encode(&q)
+	return q.uri(OperationTransaction), nil
+}
+
+func (u *TransactionURI) validate() error {
+	if u.XDR == "" {
+		return errors.New("xdr is required")
+	}
+	if _, err := u.Transaction(); err != nil {
+		return err
+	}
+	if u.PublicKey != "" {
+		if _, err := strkey.Decode(strkey.VersionByteAccountID, u.PublicKey); err != nil {
+			return errors.Wrap(err, "invalid pubkey")
+		}
+	}
+	return u.Params.validate()
+}
+
+func (u *TransactionURI) decode(values url.Values) error {
+	u.XDR = values.Get("xdr")
+	u.PublicKey = values.Get("pubkey")
+	var err error
+	if u.Replace, err = decodeReplacements(values.Get("replace")); err != nil {
+		return err
+	}
+	if err = u.Params.decode(values); err != nil {
+		return err
+	}
+	return u.validate()
+}
+
+// Replacement is a field of the transaction the wallet should replace, for
+// example the source account, before signing.
+type Replacement struct {
+	// Path is the Txrep path of the field, e.g. sourceAccount or
+	// operations[0].sourceAccount.
+	Path string
+	// ID identifies the value. Fields sharing the same ID are replaced by
+	// the same value.
+	ID string
+	// Hint describes the value to the user.
+	Hint string
+}
+
+// encodeReplacements returns the replace parameter, the list of fields
+// followed by the hints of the IDs: "path:ID,...;ID:hint,...".
+func encodeReplacements(replacements []Replacement) string {
+	if len(replacements) == 0 {
+		return ""
+	}
+	fields := make([]string, 0, len(replacements))
+	var hints []string
+	seen := map[string]bool{}
+	for _, r := range replacements {
+		fields = append(fields, r.Path+":"+r.ID)
+		if !seen[r.ID] {
+			seen[r.ID] = true
+			hints = append(hints, r.ID+":"+r.Hint)
+		}
+	}
+	return strings.Join(fields, ",") + ";" + strings.Join(hints, ",")
+}
+
+func decodeReplacements(value string) ([]Replacement, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(value, ";", 2)
+	hints := map[string]string{}
+	if len(parts) == 2 {
+		for _, hint := range strings.Split(parts[1], ",") {
+			kv := strings.SplitN(hint, ":", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("invalid replace hint %s", hint)
+			}
+			hints[kv[0]] = kv[1]
+		}
+	}
+
+	var replacements []Replacement
+	for _, field := range strings.Split(parts[0], ",") {
+		i := strings.LastIndex(field, ":")
+		if i <= 0 || i == len(field)-1 {
+			return nil, errors.Errorf("invalid replace field %s", field)
+		}
+		id := field[i+1:]
+		replacements = append(replacements, Replacement{
+			Path: field[:i],
+			ID:   id,
+			Hint: hints[id],
+		})
+	}
+	return replacements, nil
+}
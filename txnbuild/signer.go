@@ -0,0 +1,17 @@
+package txnbuild
+
+import (
+	"github.com/hcnet/go/keypair"
+	"github.com/hcnet/go/xdr"
+)
+
+// TransactionSigner is a keypair.Signer which signs whole transactions
+// instead of their hashes, for example to check them against a policy before
+// signing. SignWith uses SignTransaction instead of Sign for such signers and
+// verifies the returned signatures.
+type TransactionSigner interface {
+	keypair.Signer
+	// SignTransaction returns the signature of the base64 encoded
+	// transaction envelope for the network.
+	SignTransaction(networkPassphrase, txeB64 string) (xdr.DecoratedSignature, error)
+}
@@ -572,3 +572,58 @@ func TestFeeBumpSigningImmutability(t *testing.T) {
 	assert.Equal(t, expectedRightB64, rightB64)
 	verifySignatures(t, right, kp1, kp2)
 }
+
+// envelopeSigner is a TransactionSigner signing with a keypair.
+type envelopeSigner struct {
+	*keypair.Full
+	envelopes []string
+	signer    *keypair.Full
+}
+
+func (s *envelopeSigner) SignTransaction(networkPassphrase, txeB64 string) (xdr.DecoratedSignature, error) {
+	s.envelopes = append(s.envelopes, txeB64)
+	parsed, err := TransactionFromXDR(txeB64)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	tx, _ := parsed.Transaction()
+	hash, err := tx.Hash(networkPassphrase)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	return s.signer.SignDecorated(hash[:])
+}
+
+func TestSignWith(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	txSourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639898))
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &txSourceAccount,
+			IncrementSequenceNum: true,
+			Operations:           []Operation{&BumpSequence{BumpTo: 1}},
+			BaseFee:              MinBaseFee,
+			Timebounds:           NewInfiniteTimeout(),
+		},
+	)
+	assert.NoError(t, err)
+
+	expected, err := tx.Sign(network.TestNetworkPassphrase, kp0, kp1)
+	assert.NoError(t, err)
+
+	signer := &envelopeSigner{Full: kp1, signer: kp1}
+	signed, err := tx.SignWith(network.TestNetworkPassphrase, kp0, signer)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Signatures(), signed.Signatures())
+	if assert.Len(t, signer.envelopes, 1) {
+		unsigned, _ := tx.Base64()
+		assert.Equal(t, unsigned, signer.envelopes[0])
+	}
+
+	// Signatures returned by transaction signers are verified.
+	signer = &envelopeSigner{Full: kp1, signer: kp0}
+	_, err = tx.SignWith(network.TestNetworkPassphrase, signer)
+	assert.EqualError(t, err, "failed to sign transaction: invalid signature returned by "+kp1.Address())
+}
@@ -51,7 +51,7 @@ func concatSignatures(
 	e xdr.TransactionEnvelope,
 	networkStr string,
 	signatures []xdr.DecoratedSignature,
-	kps ...*keypair.Full,
+	signers ...keypair.Signer,
 ) ([]xdr.DecoratedSignature, error) {
 	// Hash the transaction
 	h, err := network.HashTransactionInEnvelope(e, networkStr)
@@ -62,12 +62,17 @@ func concatSignatures(
 	extended := make(
 		[]xdr.DecoratedSignature,
 		len(signatures),
-		len(signatures)+len(kps),
+		len(signatures)+len(signers),
 	)
 	copy(extended, signatures)
 	// Sign the hash
-	for _, kp := range kps {
-		sig, err := kp.SignDecorated(h[:])
+	for _, signer := range signers {
+		var sig xdr.DecoratedSignature
+		if txSigner, ok := signer.(TransactionSigner); ok {
+			sig, err = signTransaction(e, networkStr, signatures, h, txSigner)
+		} else {
+			sig, err = keypair.SignDecorated(signer, h[:])
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to sign transaction")
 		}
@@ -76,6 +81,41 @@ func concatSignatures(
 	return extended, nil
 }
 
+// signTransaction signs the envelope with a TransactionSigner and checks the
+// returned signature.
+func signTransaction(
+	e xdr.TransactionEnvelope,
+	networkStr string,
+	signatures []xdr.DecoratedSignature,
+	hash [32]byte,
+	signer TransactionSigner,
+) (xdr.DecoratedSignature, error) {
+	txeB64, err := marshallBase64(e, signatures)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	sig, err := signer.SignTransaction(networkStr, txeB64)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	kp, err := keypair.ParseAddress(signer.Address())
+	if err != nil {
+		return xdr.DecoratedSignature{}, errors.Wrap(err, "invalid signer address")
+	}
+	if sig.Hint != xdr.SignatureHint(kp.Hint()) || kp.Verify(hash[:], sig.Signature) != nil {
+		return xdr.DecoratedSignature{}, errors.Errorf("invalid signature returned by %s", signer.Address())
+	}
+	return sig, nil
+}
+
+func fullsToSigners(kps []*keypair.Full) []keypair.Signer {
+	signers := make([]keypair.Signer, len(kps))
+	for i, kp := range kps {
+		signers[i] = kp
+	}
+	return signers
+}
+
 func concatSignatureBase64(e xdr.TransactionEnvelope, signatures []xdr.DecoratedSignature, networkStr, publicKey, signature string) ([]xdr.DecoratedSignature, error) {
 	if signature == "" {
 		return nil, errors.New("signature not presented")
@@ -263,7 +303,13 @@ func (t *Transaction) HashHex(network string) (string, error) {
 // Sign returns a new Transaction instance which extends the current instance
 // with additional signatures derived from the given list of keypair instances.
 func (t *Transaction) Sign(network string, kps ...*keypair.Full) (*Transaction, error) {
-	extendedSignatures, err := concatSignatures(t.envelope, network, t.signatures, kps...)
+	return t.SignWith(network, fullsToSigners(kps)...)
+}
+
+// SignWith returns a new Transaction instance which extends the current instance
+// with additional signatures derived from the given list of signers.
+func (t *Transaction) SignWith(network string, signers ...keypair.Signer) (*Transaction, error) {
+	extendedSignatures, err := concatSignatures(t.envelope, network, t.signatures, signers...)
 	if err != nil {
 		return nil, err
 	}
@@ -313,6 +359,28 @@ func (t *Transaction) AddSignatureBase64(network, publicKey, signature string) (
 	return newTx, nil
 }
 
+// AddSignatureDecorated returns a new Transaction instance which extends the current instance
+// with the given decorated signatures. The signatures are not verified.
+func (t *Transaction) AddSignatureDecorated(signature ...xdr.DecoratedSignature) (*Transaction, error) {
+	extendedSignatures := make([]xdr.DecoratedSignature, len(t.signatures), len(t.signatures)+len(signature))
+	copy(extendedSignatures, t.signatures)
+	extendedSignatures = append(extendedSignatures, signature...)
+
+	newTx := new(Transaction)
+	*newTx = *t
+	newTx.signatures = extendedSignatures
+	return newTx, nil
+}
+
+// ClearSignatures returns a new Transaction instance equal to the current instance
+// without any signatures.
+func (t *Transaction) ClearSignatures() (*Transaction, error) {
+	newTx := new(Transaction)
+	*newTx = *t
+	newTx.signatures = nil
+	return newTx, nil
+}
+
 // TxEnvelope returns the a xdr.TransactionEnvelope instance which is
 // equivalent to this transaction.
 func (t *Transaction) TxEnvelope() (xdr.TransactionEnvelope, error) {
@@ -443,7 +511,13 @@ func (t *FeeBumpTransaction) Hash(networkStr string) ([32]byte, error) {
 // Sign returns a new FeeBumpTransaction instance which extends the current instance
 // with additional signatures derived from the given list of keypair instances.
 func (t *FeeBumpTransaction) Sign(network string, kps ...*keypair.Full) (*FeeBumpTransaction, error) {
-	extendedSignatures, err := concatSignatures(t.envelope, network, t.signatures, kps...)
+	return t.SignWith(network, fullsToSigners(kps)...)
+}
+
+// SignWith returns a new FeeBumpTransaction instance which extends the current instance
+// with additional signatures derived from the given list of signers.
+func (t *FeeBumpTransaction) SignWith(network string, signers ...keypair.Signer) (*FeeBumpTransaction, error) {
+	extendedSignatures, err := concatSignatures(t.envelope, network, t.signatures, signers...)
 	if err != nil {
 		return nil, err
 	}
@@ -454,6 +528,19 @@ func (t *FeeBumpTransaction) Sign(network string, kps ...*keypair.Full) (*FeeBum
 	return newTx, nil
 }
 
+// AddSignatureDecorated returns a new FeeBumpTransaction instance which extends the current instance
+// with the given decorated signatures. The signatures are not verified.
+func (t *FeeBumpTransaction) AddSignatureDecorated(signature ...xdr.DecoratedSignature) (*FeeBumpTransaction, error) {
+	extendedSignatures := make([]xdr.DecoratedSignature, len(t.signatures), len(t.signatures)+len(signature))
+	copy(extendedSignatures, t.signatures)
+	extendedSignatures = append(extendedSignatures, signature...)
+
+	newTx := new(FeeBumpTransaction)
+	*newTx = *t
+	newTx.signatures = extendedSignatures
+	return newTx, nil
+}
+
 // TxEnvelope returns the a xdr.TransactionEnvelope instance which is
 // equivalent to this transaction.
 func (t *FeeBumpTransaction) TxEnvelope() (xdr.TransactionEnvelope, error) {
@@ -538,7 +625,6 @@ func transactionFromParsedXDR(xdrEnv xdr.TransactionEnvelope) (*GenericTransacti
 		if err != nil {
 			return newTx, errors.New("could not parse inner transaction")
 		}
-		feeBumpAccount := xdrEnv.FeeBumpAccount().ToAccountId()
 		newTx.feeBump = &FeeBumpTransaction{
 			envelope: xdrEnv,
 			// A fee-bump transaction has an effective number of operations equal to one plus the
@@ -548,14 +634,12 @@ func transactionFromParsedXDR(xdrEnv xdr.TransactionEnvelope) (*GenericTransacti
 			baseFee:    xdrEnv.FeeBumpFee() / int64(len(innerTx.simple.operations)+1),
 			maxFee:     xdrEnv.FeeBumpFee(),
 			inner:      innerTx.simple,
-			feeAccount: feeBumpAccount.Address(),
+			feeAccount: xdrEnv.FeeBumpAccount().Address(),
 			signatures: xdrEnv.FeeBumpSignatures(),
 		}
 		return newTx, nil
 	}
 
-	sourceAccount := xdrEnv.SourceAccount().ToAccountId()
-
 	totalFee := int64(xdrEnv.Fee())
 	baseFee := totalFee
 	if count := int64(len(xdrEnv.Operations())); count > 0 {
@@ -567,7 +651,7 @@ func transactionFromParsedXDR(xdrEnv xdr.TransactionEnvelope) (*GenericTransacti
 		baseFee:  baseFee,
 		maxFee:   totalFee,
 		sourceAccount: SimpleAccount{
-			AccountID: sourceAccount.Address(),
+			AccountID: xdrEnv.SourceAccount().Address(),
 			Sequence:  xdrEnv.SeqNum(),
 		},
 		operations: nil,
@@ -638,7 +722,7 @@ func NewTransaction(params TransactionParams) (*Transaction, error) {
 		signatures: nil,
 	}
 
-	accountID, err := xdr.AddressToAccountId(tx.sourceAccount.AccountID)
+	sourceAccount, err := xdr.AddressToMuxedAccount(tx.sourceAccount.AccountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "account id is not valid")
 	}
@@ -672,7 +756,7 @@ func NewTransaction(params TransactionParams) (*Transaction, error) {
 		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
 		V1: &xdr.TransactionV1Envelope{
 			Tx: xdr.Transaction{
-				SourceAccount: accountID.ToMuxedAccount(),
+				SourceAccount: sourceAccount,
 				Fee:           xdr.Uint32(tx.maxFee),
 				SeqNum:        xdr.SequenceNumber(sequence),
 				TimeBounds: &xdr.TimeBounds{
@@ -781,7 +865,7 @@ func NewFeeBumpTransaction(params FeeBumpTransactionParams) (*FeeBumpTransaction
 		)
 	}
 
-	accountID, err := xdr.AddressToAccountId(tx.feeAccount)
+	feeAccount, err := xdr.AddressToMuxedAccount(tx.feeAccount)
 	if err != nil {
 		return tx, errors.Wrap(err, "fee account is not a valid address")
 	}
@@ -790,7 +874,7 @@ func NewFeeBumpTransaction(params FeeBumpTransactionParams) (*FeeBumpTransaction
 		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
 		FeeBump: &xdr.FeeBumpTransactionEnvelope{
 			Tx: xdr.FeeBumpTransaction{
-				FeeSource: accountID.ToMuxedAccount(),
+				FeeSource: feeAccount,
 				Fee:       xdr.Int64(tx.maxFee),
 				InnerTx: xdr.FeeBumpTransactionInnerTx{
 					Type: xdr.EnvelopeTypeEnvelopeTypeTx,
@@ -1496,6 +1496,47 @@ func TestAddSignatureBase64(t *testing.T) {
 	assert.Equal(t, expected, actual, "base64 xdr should match")
 }
 
+func TestAddSignatureDecorated(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	txSource := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	createAccount := CreateAccount{
+		Destination: "GCCOBXW2XQNUSL467IEILE6MMCNRR66SSVL4YQADUNYYNUVREF3FIV2Z",
+		Amount:      "10",
+	}
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &txSource,
+			IncrementSequenceNum: true,
+			Operations:           []Operation{&createAccount},
+			BaseFee:              MinBaseFee,
+			Timebounds:           NewInfiniteTimeout(),
+		},
+	)
+	assert.NoError(t, err)
+
+	signed, err := tx.Sign(network.TestNetworkPassphrase, kp0, kp1)
+	assert.NoError(t, err)
+	expected, err := signed.Base64()
+	assert.NoError(t, err)
+
+	tx1, err := tx.AddSignatureDecorated(signed.Signatures()[0])
+	assert.NoError(t, err)
+	tx1, err = tx1.AddSignatureDecorated(signed.Signatures()[1])
+	assert.NoError(t, err)
+	assert.Len(t, tx.Signatures(), 0)
+
+	actual, err := tx1.Base64()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual, "base64 xdr should match")
+
+	cleared, err := tx1.ClearSignatures()
+	assert.NoError(t, err)
+	assert.Len(t, cleared.Signatures(), 0)
+	assert.Len(t, tx1.Signatures(), 2)
+}
+
 func TestReadChallengeTx_validSignedByServerAndClient(t *testing.T) {
 	serverKP := newKeypair0()
 	clientKP := newKeypair1()
@@ -3305,3 +3346,41 @@ func TestVerifyTxSignatureInvalid(t *testing.T) {
 		assert.Contains(t, err.Error(), "transaction not signed by GATBMIXTHXYKSUZSZUEJKACZ2OS2IYUWP2AIF3CA32PIDLJ67CH6Y5UY")
 	}
 }
+
+func TestMuxedTransactionAndFeeBumpSource(t *testing.T) {
+	muxedSource := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+	sourceAccount := NewSimpleAccount(muxedSource, 1)
+
+	tx, err := NewTransaction(
+		TransactionParams{
+			SourceAccount:        &sourceAccount,
+			IncrementSequenceNum: true,
+			Operations:           []Operation{&BumpSequence{BumpTo: 0}},
+			BaseFee:              MinBaseFee,
+			Timebounds:           NewInfiniteTimeout(),
+		},
+	)
+	require.NoError(t, err)
+
+	env, err := tx.TxEnvelope()
+	require.NoError(t, err)
+	assert.Equal(t, xdr.CryptoKeyTypeKeyTypeMuxedEd25519, env.SourceAccount().Type)
+
+	feeBump, err := NewFeeBumpTransaction(
+		FeeBumpTransactionParams{
+			FeeAccount: muxedSource,
+			BaseFee:    MinBaseFee,
+			Inner:      tx,
+		},
+	)
+	require.NoError(t, err)
+
+	b64, err := feeBump.Base64()
+	require.NoError(t, err)
+	parsed, err := TransactionFromXDR(b64)
+	require.NoError(t, err)
+	parsedFeeBump, ok := parsed.FeeBump()
+	require.True(t, ok)
+	assert.Equal(t, muxedSource, parsedFeeBump.FeeAccount())
+	assert.Equal(t, muxedSource, parsedFeeBump.InnerTransaction().SourceAccount().AccountID)
+}
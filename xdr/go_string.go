@@ -80,15 +80,8 @@ func (m Memo) GoString() string {
 // GoString implements fmt.GoStringer.
 func (m MuxedAccount) GoString() string {
 	switch m.Type {
-	case CryptoKeyTypeKeyTypeEd25519:
-		accountID := m.ToAccountId()
-		return fmt.Sprintf("xdr.MustMuxedAddress(%#v)", accountID.Address())
-	case CryptoKeyTypeKeyTypeMuxedEd25519:
-		var sb strings.Builder
-		sb.WriteString("xdr.MuxedAccount{Type: xdr.CryptoKeyTypeKeyTypeMuxedEd25519,")
-		sb.WriteString(fmt.Sprintf("Med25519: &%#v", *m.Med25519))
-		sb.WriteString("}")
-		return sb.String()
+	case CryptoKeyTypeKeyTypeEd25519, CryptoKeyTypeKeyTypeMuxedEd25519:
+		return fmt.Sprintf("xdr.MustMuxedAddress(%#v)", m.Address())
 	default:
 		panic("Unknown type")
 	}
@@ -99,12 +92,7 @@ func (o Operation) GoString() string {
 	var sb strings.Builder
 	sb.WriteString("xdr.Operation{")
 	if o.SourceAccount != nil {
-		if o.SourceAccount.Type == CryptoKeyTypeKeyTypeEd25519 {
-			accountID := o.SourceAccount.ToAccountId()
-			sb.WriteString(fmt.Sprintf("SourceAccount: xdr.MustMuxedAddressPtr(%#v),", accountID.Address()))
-		} else {
-			sb.WriteString(fmt.Sprintf("SourceAccount: &%#v,", *o.SourceAccount))
-		}
+		sb.WriteString(fmt.Sprintf("SourceAccount: xdr.MustMuxedAddressPtr(%#v),", o.SourceAccount.Address()))
 	}
 	sb.WriteString(fmt.Sprintf("Body: %#v", o.Body))
 	sb.WriteString("}")
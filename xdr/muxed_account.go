@@ -21,8 +21,17 @@ func MustMuxedAddressPtr(address string) *MuxedAccount {
 	return &muxed
 }
 
+// AddressToMuxedAccount returns an MuxedAccount for a given G... or M...
+// address.
+func AddressToMuxedAccount(address string) (MuxedAccount, error) {
+	result := MuxedAccount{}
+	err := result.SetAddress(address)
+
+	return result, err
+}
+
 // SetAddress modifies the receiver, setting it's value to the MuxedAccount form
-// of the provided address.
+// of the provided address. Both G... and SEP-23 M... addresses are accepted.
 func (m *MuxedAccount) SetAddress(address string) error {
 	if m == nil {
 		return nil
@@ -41,12 +50,67 @@ func (m *MuxedAccount) SetAddress(address string) error {
 		copy(ui[:], raw)
 		*m, err = NewMuxedAccount(CryptoKeyTypeKeyTypeEd25519, ui)
 		return err
+	case 69:
+		raw, id, err := strkey.DecodeMuxedAccount(address)
+		if err != nil {
+			return err
+		}
+		muxed := MuxedAccountMed25519{Id: Uint64(id)}
+		copy(muxed.Ed25519[:], raw)
+		*m, err = NewMuxedAccount(CryptoKeyTypeKeyTypeMuxedEd25519, muxed)
+		return err
 	default:
+		// return the strkey decoding error if there is one
+		if _, _, err := strkey.DecodeAny(address); err != nil {
+			return err
+		}
 		return errors.New("invalid address")
 	}
 
 }
 
+// Address returns the strkey encoded form of this MuxedAccount: a G... address
+// for plain ed25519 accounts and a SEP-23 M... address for muxed accounts. This
+// method will panic if the MuxedAccount is of an unknown type.
+func (m MuxedAccount) Address() string {
+	address, err := m.GetAddress()
+	if err != nil {
+		panic(err)
+	}
+	return address
+}
+
+// GetAddress returns the strkey encoded form of this MuxedAccount, and an
+// error if the MuxedAccount is of an unknown type.
+func (m MuxedAccount) GetAddress() (string, error) {
+	switch m.Type {
+	case CryptoKeyTypeKeyTypeEd25519:
+		ed, ok := m.GetEd25519()
+		if !ok {
+			return "", fmt.Errorf("Could not get Ed25519")
+		}
+		return strkey.Encode(strkey.VersionByteAccountID, ed[:])
+	case CryptoKeyTypeKeyTypeMuxedEd25519:
+		muxed, ok := m.GetMed25519()
+		if !ok {
+			return "", fmt.Errorf("Could not get Med25519")
+		}
+		return strkey.EncodeMuxedAccount(muxed.Ed25519[:], uint64(muxed.Id))
+	default:
+		return "", fmt.Errorf("Unknown muxed account type: %v", m.Type)
+	}
+}
+
+// ID returns the id of a muxed (M...) account and false if the account is
+// a plain ed25519 account.
+func (m MuxedAccount) ID() (uint64, bool) {
+	muxed, ok := m.GetMed25519()
+	if !ok {
+		return 0, false
+	}
+	return uint64(muxed.Id), true
+}
+
 // ToAccountId transforms a MuxedAccount to an AccountId, dropping the
 // memo Id if necessary
 func (m MuxedAccount) ToAccountId() AccountId {
@@ -26,6 +26,34 @@ var _ = Describe("xdr.MuxedAccount#Get/SetAddress()", func() {
 		err = muxed.SetAddress("G47QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVP2I")
 		Expect(err).Should(HaveOccurred())
 
+		err = muxed.SetAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLL")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("round trips G addresses", func() {
+		var muxed MuxedAccount
+		err := muxed.SetAddress("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(muxed.Type).To(Equal(CryptoKeyTypeKeyTypeEd25519))
+		Expect(muxed.Address()).To(Equal("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"))
+
+		_, ok := muxed.ID()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("round trips M addresses", func() {
+		var muxed MuxedAccount
+		err := muxed.SetAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(muxed.Type).To(Equal(CryptoKeyTypeKeyTypeMuxedEd25519))
+		Expect(muxed.Address()).To(Equal("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"))
+
+		id, ok := muxed.ID()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(uint64(9223372036854775808)))
+
+		aid := muxed.ToAccountId()
+		Expect(aid.Address()).To(Equal("GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"))
 	})
 })
 